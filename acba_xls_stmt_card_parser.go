@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 	"strings"
-	"time"
 
 	"github.com/shakinm/xlsReader/xls"
 )
@@ -20,6 +19,26 @@ const (
 	acbaCardFinishRow         = "Քաղվածքի վերջ"
 )
 
+const (
+	// AcbaCardXlsParserName is a name of parser to use in `sources` configuration.
+	AcbaCardXlsParserName = "acbaCardXls"
+	acbaCardXlsTypeName   = "Acba Card XLS statement"
+)
+
+func init() {
+	RegisterParser(ParserRegistration{
+		Name:        AcbaCardXlsParserName,
+		TypeName:    acbaCardXlsTypeName,
+		Tag:         "AcbaCardExcel",
+		Version:     3,
+		DefaultGlob: "CardStatement*.xls",
+		NewParser:   newParserWithoutOptions(AcbaCardExcelFileParser{}),
+		Detect: func(sniff *FileSniff) bool {
+			return sniff.Container == FileContainerXls && sniff.HasRow(acbaCardXlsHeaders)
+		},
+	})
+}
+
 type AcbaCardExcelFileParser struct {
 }
 
@@ -44,6 +63,7 @@ func (p AcbaCardExcelFileParser) ParseRawTransactionsFromFile(
 	var accountCurrency = ""
 	var source TransactionsSource
 	var isHeaderRowFound bool
+	balancesFinder := newAcbaBalancesFinder()
 	for i := 0; i <= firstSheet.GetNumberRows(); i++ {
 		row, err := firstSheet.GetRow(i)
 		if err != nil {
@@ -78,6 +98,11 @@ func (p AcbaCardExcelFileParser) ParseRawTransactionsFromFile(
 			}
 			rowString := builder.String()
 
+			// Find balances in the summary.
+			if err := balancesFinder.handleRow(row.GetCols()); err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+
 			// Try to find account number and currency first.
 			if len(accountNumber) < 1 {
 				if strings.Contains(rowString, acbaCardAccountCellPrefix) {
@@ -106,11 +131,13 @@ func (p AcbaCardExcelFileParser) ParseRawTransactionsFromFile(
 					return nil, fmt.Errorf("can't find account number and/or currency down to row %d", i+1)
 				}
 				source = TransactionsSource{
-					TypeName:        "Acba Card XLS statement",
+					TypeName:        acbaCardXlsTypeName,
 					Tag:             "AcbaCardExcel:" + accountCurrency,
 					FilePath:        filePath,
 					AccountNumber:   accountNumber,
 					AccountCurrency: accountCurrency,
+					OpeningBalance:  balancesFinder.Opening,
+					ClosingBalance:  balancesFinder.Closing,
 				}
 			}
 
@@ -137,14 +164,14 @@ func (p AcbaCardExcelFileParser) ParseRawTransactionsFromFile(
 		}
 
 		// Try to parse date.
-		date, err := time.Parse(acbaCardStmtDateFormat, dateStr)
+		date, err := parseDate(dateStr, acbaCardStmtDateFormat)
 		if err != nil {
 			// Skip rows without date.
 			continue
 		}
 
 		// Try to parse amount from cell 5 (amount cell).
-		amount := MoneyWith2DecimalPlaces{}
+		amount := Money{}
 		amountStr := cells[5].GetString()
 		err = amount.ParseAmountWithoutLettersFromString(amountStr)
 		if err != nil {
@@ -153,7 +180,7 @@ func (p AcbaCardExcelFileParser) ParseRawTransactionsFromFile(
 		isExpense := true // We always check "Credit" column so start with expense assumption.
 
 		// Try to parse credit amount from cell 8 (credit amount cell).
-		creditAmount := MoneyWith2DecimalPlaces{}
+		creditAmount := Money{}
 		creditStr := cells[8].GetString()
 		if creditStr != "" {
 			err = creditAmount.ParseAmountWithoutLettersFromString(creditStr)
@@ -205,7 +232,7 @@ func (p AcbaCardExcelFileParser) ParseRawTransactionsFromFile(
 
 		// Set "origin currency" fields.
 		originCurrency := ""
-		originCurrencyAmount := MoneyWith2DecimalPlaces{int: 0}
+		originCurrencyAmount := Money{int: 0}
 		// If transaction currency is different from account currency then set "origin currency" fields.
 		if currency != accountCurrency {
 			originCurrency = currency
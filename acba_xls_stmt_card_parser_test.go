@@ -20,6 +20,8 @@ func TestAcbaCardExcelFileParser_ParseRawTransactionsFromFile_Valid(t *testing.T
 		FilePath:        validFilePath,
 		AccountNumber:   accountNumber,
 		AccountCurrency: accountCurrency,
+		OpeningBalance:  &BalanceRecord{Date: time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC), Amount: Money{int: 64209600}},
+		ClosingBalance:  &BalanceRecord{Date: time.Date(2025, time.October, 10, 0, 0, 0, 0, time.UTC), Amount: Money{int: 31479600}},
 	}
 
 	got, err := AcbaCardExcelFileParser{}.ParseRawTransactionsFromFile(validFilePath)
@@ -31,85 +33,85 @@ func TestAcbaCardExcelFileParser_ParseRawTransactionsFromFile_Valid(t *testing.T
 		{
 			Date:                 time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC),
 			IsExpense:            true,
-			Amount:               MoneyWith2DecimalPlaces{int: 300000}, // 3,000.00
+			Amount:               Money{int: 3000000}, // 3,000.00
 			FromAccount:          accountNumber,
 			ToAccount:            "",
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "Յուքոմ բջջ . պարտքի վճար /43210123/ (մոբայլ բանկինց 150123456)",
 		},
 		{
 			Date:                 time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC),
 			IsExpense:            false,
-			Amount:               MoneyWith2DecimalPlaces{int: 1230000}, // 12,300.00
+			Amount:               Money{int: 12300000}, // 12,300.00
 			FromAccount:          "",
 			ToAccount:            accountNumber,
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "Արժ, գնում RUR Name Surname (մոբայլ բանկինց 150123457)",
 		},
 		{
 			Date:                 time.Date(2025, time.October, 4, 0, 0, 0, 0, time.UTC),
 			IsExpense:            true,
-			Amount:               MoneyWith2DecimalPlaces{int: 420000}, // 4,200.00
+			Amount:               Money{int: 4200000}, // 4,200.00
 			FromAccount:          accountNumber,
 			ToAccount:            "",
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "Վճարում   ZOOVET CENTER",
 		},
 		{
 			Date:                 time.Date(2025, time.October, 4, 0, 0, 0, 0, time.UTC),
 			IsExpense:            true,
-			Amount:               MoneyWith2DecimalPlaces{int: 170000}, // 1,700.00
+			Amount:               Money{int: 1700000}, // 1,700.00
 			FromAccount:          accountNumber,
 			ToAccount:            "",
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "Էլեկտրոնային վճարում   YANDEX.GO",
 		},
 		{
 			Date:                 time.Date(2025, time.October, 4, 0, 0, 0, 0, time.UTC),
 			IsExpense:            true,
-			Amount:               MoneyWith2DecimalPlaces{int: 1100000}, // 11,000.00
+			Amount:               Money{int: 11000000}, // 11,000.00
 			FromAccount:          accountNumber,
 			ToAccount:            "",
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "Վճարում   SIGMA 90 LLC",
 		},
 		{
 			Date:                 time.Date(2025, time.October, 4, 0, 0, 0, 0, time.UTC),
 			IsExpense:            true,
-			Amount:               MoneyWith2DecimalPlaces{int: 45000}, // 450.00
+			Amount:               Money{int: 450000}, // 450.00
 			FromAccount:          accountNumber,
 			ToAccount:            "",
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "Էլեկտրոնային վճարում   TELCELL 1",
 		},
 		{
 			Date:                 time.Date(2025, time.October, 4, 0, 0, 0, 0, time.UTC),
 			IsExpense:            false,
-			Amount:               MoneyWith2DecimalPlaces{int: 440000}, // 4,400.00
+			Amount:               Money{int: 4400000}, // 4,400.00
 			FromAccount:          "",
 			ToAccount:            accountNumber,
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "Քարտից քարտ փոխանցում, INECOBANK P2P   INECOBANK P2P",
 		},
 	}
@@ -3,10 +3,12 @@ package main
 import (
 	"fmt"
 	"log"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/shakinm/xlsReader/xls"
+	"github.com/shakinm/xlsReader/xls/structure"
 )
 
 const (
@@ -17,8 +19,95 @@ const (
 	acbaAccountXlsHeaders                           = "ԱմսաթիվԳումարԱրժույթՄուտքԵլք"
 	acbaAccountFinishRowContains                    = "... ..."
 	acbaAccountFinishRow                            = "Քաղվածքի վերջ"
+	// acbaOpeningBalanceCellPrefix and acbaClosingBalanceCellPrefix are labels of balances in both account and card statements.
+	acbaOpeningBalanceCellPrefix = "Սկզբնական մնացորդ"
+	acbaClosingBalanceCellPrefix = "Վերջնական մնացորդ"
+	// acbaBalanceDateFormat is an alternative to `acbaCardStmtDateFormat` format of dates in labels of balances.
+	acbaBalanceDateFormat = "02/01/2006"
 )
 
+// acbaBalanceDateRegexp finds date in labels of balances, like "(30/09/2025 դրությամբ)" or "(27.09.2025 դրությամբ)".
+var acbaBalanceDateRegexp = regexp.MustCompile(`\d{2}[./]\d{2}[./]\d{4}`)
+
+const (
+	// AcbaRegularAccountXlsParserName is a name of parser to use in `sources` configuration.
+	AcbaRegularAccountXlsParserName = "acbaRegularAccountXls"
+	acbaRegularAccountXlsTypeName   = "Acba Regular Account XLS statement"
+)
+
+func init() {
+	RegisterParser(ParserRegistration{
+		Name:        AcbaRegularAccountXlsParserName,
+		TypeName:    acbaRegularAccountXlsTypeName,
+		Tag:         "AcbaAccountExcel",
+		Version:     3,
+		DefaultGlob: "AccountStatement*.xls",
+		NewParser:   newParserWithoutOptions(AcbaRegularAccountExcelFileParser{}),
+		Detect: func(sniff *FileSniff) bool {
+			return sniff.Container == FileContainerXls && sniff.HasRow(acbaAccountXlsHeaders)
+		},
+	})
+}
+
+// acbaBalancesFinder finds opening and closing balances in the summary of ACBA statements.
+// Summary consists of the row with labels like "Սկզբնական մնացորդ\n(27.09.2025 դրությամբ)"
+// and the row with amounts under these labels.
+type acbaBalancesFinder struct {
+	openingColumn int
+	closingColumn int
+	openingDate   time.Time
+	closingDate   time.Time
+	// Opening is a found opening balance.
+	Opening *BalanceRecord
+	// Closing is a found closing balance.
+	Closing *BalanceRecord
+}
+
+func newAcbaBalancesFinder() *acbaBalancesFinder {
+	return &acbaBalancesFinder{openingColumn: -1, closingColumn: -1}
+}
+
+// handleRow checks the row above transactions header.
+func (f *acbaBalancesFinder) handleRow(cells []structure.CellData) error {
+	if f.Opening != nil {
+		return nil
+	}
+	// Row with amounts goes right after the row with labels.
+	if f.openingColumn >= 0 && f.closingColumn >= 0 {
+		if len(cells) <= f.openingColumn || len(cells) <= f.closingColumn {
+			return fmt.Errorf("balances row has only %d cells", len(cells))
+		}
+		var opening, closing Money
+		if err := opening.ParseAmountWithoutLettersFromString(cells[f.openingColumn].GetString()); err != nil {
+			return fmt.Errorf("failed to parse opening balance: %w", err)
+		}
+		if err := closing.ParseAmountWithoutLettersFromString(cells[f.closingColumn].GetString()); err != nil {
+			return fmt.Errorf("failed to parse closing balance: %w", err)
+		}
+		f.Opening = &BalanceRecord{Date: f.openingDate, Amount: opening}
+		// Closing balance is at the end of the day.
+		f.Closing = &BalanceRecord{Date: f.closingDate.AddDate(0, 0, 1), Amount: closing}
+		return nil
+	}
+	for j, cell := range cells {
+		value := strings.TrimSpace(cell.GetString())
+		isOpening := strings.HasPrefix(value, acbaOpeningBalanceCellPrefix)
+		if !isOpening && !strings.HasPrefix(value, acbaClosingBalanceCellPrefix) {
+			continue
+		}
+		date, err := parseDate(acbaBalanceDateRegexp.FindString(value), acbaCardStmtDateFormat, acbaBalanceDateFormat)
+		if err != nil {
+			return fmt.Errorf("failed to parse date of balance from '%s': %w", value, err)
+		}
+		if isOpening {
+			f.openingColumn, f.openingDate = j, date
+		} else {
+			f.closingColumn, f.closingDate = j, date
+		}
+	}
+	return nil
+}
+
 type AcbaRegularAccountExcelFileParser struct {
 }
 
@@ -43,6 +132,7 @@ func (p AcbaRegularAccountExcelFileParser) ParseRawTransactionsFromFile(
 	var accountCurrency = ""
 	var source TransactionsSource
 	var isHeaderRowFound bool
+	balancesFinder := newAcbaBalancesFinder()
 	for i := 0; i <= firstSheet.GetNumberRows(); i++ {
 		row, err := firstSheet.GetRow(i)
 		if err != nil {
@@ -77,6 +167,11 @@ func (p AcbaRegularAccountExcelFileParser) ParseRawTransactionsFromFile(
 			}
 			rowString := builder.String()
 
+			// Find balances in the summary.
+			if err := balancesFinder.handleRow(row.GetCols()); err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+
 			// Try to find account number and currency first.
 			if len(accountNumber) < 1 {
 				if strings.Contains(rowString, acbaAccountAccountCellPrefix) {
@@ -105,11 +200,13 @@ func (p AcbaRegularAccountExcelFileParser) ParseRawTransactionsFromFile(
 					return nil, fmt.Errorf("can't find account number and/or currency down to row %d", i+1)
 				}
 				source = TransactionsSource{
-					TypeName:        "Acba Regular Account XLS statement",
+					TypeName:        acbaRegularAccountXlsTypeName,
 					Tag:             "AcbaAccountExcel:" + accountCurrency,
 					FilePath:        filePath,
 					AccountNumber:   accountNumber,
 					AccountCurrency: accountCurrency,
+					OpeningBalance:  balancesFinder.Opening,
+					ClosingBalance:  balancesFinder.Closing,
 				}
 			}
 
@@ -133,12 +230,11 @@ func (p AcbaRegularAccountExcelFileParser) ParseRawTransactionsFromFile(
 		// Try to parse date - it might be an Excel serial number
 		var date time.Time
 		if dateFloat := cells[1].GetFloat64(); dateFloat > 0 {
-			// Excel serial number - convert to date
-			excelEpoch := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
-			date = excelEpoch.AddDate(0, 0, int(dateFloat)-2) // Excel has a leap year bug, so subtract 2
+			// Excel serial number - convert to date without time.
+			date = excelSerialDate(dateFloat).Truncate(24 * time.Hour)
 		} else {
 			// Try to parse as string date
-			date, err = time.Parse(acbaAccountStmtDateFormat, dateStr)
+			date, err = parseDate(dateStr, acbaAccountStmtDateFormat)
 			if err != nil {
 				// Skip rows without dates.
 				continue
@@ -146,9 +242,9 @@ func (p AcbaRegularAccountExcelFileParser) ParseRawTransactionsFromFile(
 		}
 
 		// Parse amount - now we can get proper numeric values
-		amount := MoneyWith2DecimalPlaces{}
+		amount := Money{}
 		if amountInt := cells[2].GetInt64(); amountInt != 0 {
-			amount.int = int(amountInt * 100)
+			amount.int = int(amountInt) * moneyUnitsInOne
 		} else {
 			// Fallback to string parsing
 			err = amount.ParseAmountWithoutLettersFromString(cells[2].GetString())
@@ -158,13 +254,13 @@ func (p AcbaRegularAccountExcelFileParser) ParseRawTransactionsFromFile(
 		}
 
 		// Parse other amounts as proper numeric values
-		creditAmount := MoneyWith2DecimalPlaces{}
+		creditAmount := Money{}
 		// Parse credit amount from string (it's formatted like "0.00" or "+ 1,449.00")
 		err = creditAmount.ParseAmountWithoutLettersFromString(cells[4].GetString())
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse credit amount from cell %d of %d row: %w", 4, i+1, err)
 		}
-		debitAmount := MoneyWith2DecimalPlaces{}
+		debitAmount := Money{}
 		// Parse debit amount from string (it's formatted like "- 1.60" or "0.00")
 		err = debitAmount.ParseAmountWithoutLettersFromString(cells[6].GetString())
 		if err != nil {
@@ -200,14 +296,14 @@ func (p AcbaRegularAccountExcelFileParser) ParseRawTransactionsFromFile(
 			isExpense = true
 			from = receiverSenderAccountNumber
 			to = accountNumber
-			originCurrencyAmount = MoneyWith2DecimalPlaces{int: -debitAmount.int}
+			originCurrencyAmount = Money{int: -debitAmount.int}
 		}
 
 		// Clear "origin currency" fields if account currency is used.
 		originCurrency := currency
 		if currency == accountCurrency {
 			originCurrency = ""
-			originCurrencyAmount = MoneyWith2DecimalPlaces{int: 0}
+			originCurrencyAmount = Money{int: 0}
 		}
 
 		// Build native transaction.
@@ -20,6 +20,8 @@ func TestAcbaRegularAccountExcelFileParser_ParseRawTransactionsFromFile_Valid(t
 		FilePath:        validFilePath,
 		AccountNumber:   accountNumber,
 		AccountCurrency: accountCurrency,
+		OpeningBalance:  &BalanceRecord{Date: time.Date(2025, time.September, 27, 0, 0, 0, 0, time.UTC), Amount: Money{int: 502989900}},
+		ClosingBalance:  &BalanceRecord{Date: time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC), Amount: Money{int: 504292400}},
 	}
 
 	got, err := AcbaRegularAccountExcelFileParser{}.ParseRawTransactionsFromFile(validFilePath)
@@ -31,37 +33,37 @@ func TestAcbaRegularAccountExcelFileParser_ParseRawTransactionsFromFile_Valid(t
 		{
 			Date:                 time.Date(2025, time.September, 27, 0, 0, 0, 0, time.UTC),
 			IsExpense:            true,
-			Amount:               MoneyWith2DecimalPlaces{int: 160},
+			Amount:               Money{int: 1600},
 			FromAccount:          accountNumber,
 			ToAccount:            "220483381467000",
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "Փոխանցում հաշվին (մոբայլ բանկինգ 189909288) - հ/հ 220483381467000  ստացող Name Surname",
 		},
 		{
 			Date:                 time.Date(2025, time.September, 30, 0, 0, 0, 0, time.UTC),
 			IsExpense:            true,
-			Amount:               MoneyWith2DecimalPlaces{int: 14490},
+			Amount:               Money{int: 144900},
 			FromAccount:          accountNumber,
 			ToAccount:            "",
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "Խնայողական հաշվի եկամտահարկ",
 		},
 		{
 			Date:                 time.Date(2025, time.September, 30, 0, 0, 0, 0, time.UTC),
 			IsExpense:            false,
-			Amount:               MoneyWith2DecimalPlaces{int: 144900},
+			Amount:               Money{int: 1449000},
 			FromAccount:          "220485212843000",
 			ToAccount:            accountNumber,
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "Խնայողական հաշվի տոկոսի վճարում - հ/հ 220485212843000  փոխանցող Name Surname",
 		},
 	}
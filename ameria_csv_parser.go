@@ -12,6 +12,8 @@ import (
 
 const AmeriaBusinessDateFormat = "02/01/2006"
 const giveUpFindHeaderInAmeriaCsvAfterNoHeaderLines = 20
+const ameriaCsvOpeningBalanceLabel = "Opening Balance on "
+const ameriaCsvClosingBalanceLabel = "Closing Balance on "
 
 var (
 	csvHeaders = []string{
@@ -43,14 +45,35 @@ type AmeriaBusinessTransaction struct {
 	DocNo               string
 	TransactionType     string
 	Account             string
-	Credit              MoneyWith2DecimalPlaces
-	CreditAmd           MoneyWith2DecimalPlaces
-	Debit               MoneyWith2DecimalPlaces
-	DebitAmd            MoneyWith2DecimalPlaces
+	Credit              Money
+	CreditAmd           Money
+	Debit               Money
+	DebitAmd            Money
 	RemitterBeneficiary string
 	Details             string
 }
 
+const (
+	// AmeriaCsvParserName is a name of parser to use in `sources` configuration.
+	AmeriaCsvParserName = "ameriaCsv"
+	ameriaCsvTypeName   = "AmeriaBank CSV statement"
+)
+
+func init() {
+	RegisterParser(ParserRegistration{
+		Name:        AmeriaCsvParserName,
+		TypeName:    ameriaCsvTypeName,
+		Tag:         "AmeriaCsv",
+		Version:     3,
+		DefaultGlob: "AccountStatement*.csv",
+		NewParser:   newParserWithoutOptions(AmeriaCsvFileParser{}),
+		Detect: func(sniff *FileSniff) bool {
+			return sniff.Container == FileContainerText &&
+				(sniff.HasRow(strings.Join(csvHeaders, "")) || sniff.HasRow(strings.Join(csvHeadersWithAmd, "")))
+		},
+	})
+}
+
 type AmeriaCsvFileParser struct {
 }
 
@@ -63,8 +86,9 @@ func (p AmeriaCsvFileParser) ParseRawTransactionsFromFile(
 	}
 	defer file.Close()
 
-	// Initialize variables for currency and account number
+	// Initialize variables for currency, account number and balances.
 	var currency, accountNumber string
+	var openingBalance, closingBalance *BalanceRecord
 
 	// Read the file into a byte slice
 	fileData, err := io.ReadAll(file)
@@ -103,6 +127,22 @@ func (p AmeriaCsvFileParser) ParseRawTransactionsFromFile(
 			accountNumber = record[3]
 			continue
 		}
+		if strings.HasPrefix(record[0], ameriaCsvOpeningBalanceLabel) {
+			openingBalance, err = parseAmeriaCsvBalance(record, ameriaCsvOpeningBalanceLabel)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if strings.HasPrefix(record[0], ameriaCsvClosingBalanceLabel) {
+			closingBalance, err = parseAmeriaCsvBalance(record, ameriaCsvClosingBalanceLabel)
+			if err != nil {
+				return nil, err
+			}
+			// Closing balance is at the end of the day.
+			closingBalance.Date = closingBalance.Date.AddDate(0, 0, 1)
+			continue
+		}
 
 		// Check if the current row is a header row
 		currentRowStr := rowCellsToString(record)
@@ -155,7 +195,7 @@ func (p AmeriaCsvFileParser) ParseRawTransactionsFromFile(
 		}
 
 		// Parse date
-		date, err := time.Parse(AmeriaBusinessDateFormat, record[0])
+		date, err := parseDate(record[0], AmeriaBusinessDateFormat)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse date: %w", err)
 		}
@@ -165,7 +205,7 @@ func (p AmeriaCsvFileParser) ParseRawTransactionsFromFile(
 		// Therefore transactions for not AMD accounts may have Type=MSC rows
 		// where Credit=0, Debit=0, but Debit(AMD) is non-zero - fees for transaction.
 		// Real transaction with both Debit!=0 and Debit(AMD)!=0 goes below with Type=CEX.
-		var credit, debit MoneyWith2DecimalPlaces
+		var credit, debit Money
 		if err := credit.UnmarshalText([]byte(record[6])); err != nil {
 			return nil, fmt.Errorf("failed to parse credit %v: %w", record, err)
 		}
@@ -190,7 +230,7 @@ func (p AmeriaCsvFileParser) ParseRawTransactionsFromFile(
 		}
 		// If currency is not AMD then use credit and debit in AMD amounts.
 		if currency != "AMD" {
-			var creditAmd, debitAmd MoneyWith2DecimalPlaces
+			var creditAmd, debitAmd Money
 			if err := debitAmd.UnmarshalText([]byte(record[8])); err != nil {
 				return nil, fmt.Errorf("failed to parse debit(AMD) %v: %w", record, err)
 			}
@@ -204,11 +244,13 @@ func (p AmeriaCsvFileParser) ParseRawTransactionsFromFile(
 	}
 
 	sourceType := TransactionsSource{
-		TypeName:        "AmeriaBank CSV statement",
+		TypeName:        ameriaCsvTypeName,
 		Tag:             "AmeriaCsv:" + currency,
 		FilePath:        filePath,
 		AccountNumber:   accountNumber,
 		AccountCurrency: currency,
+		OpeningBalance:  openingBalance,
+		ClosingBalance:  closingBalance,
 	}
 
 	// Convert CSV rows to unified transactions and separate expenses from incomes.
@@ -254,6 +296,23 @@ func (p AmeriaCsvFileParser) ParseRawTransactionsFromFile(
 	return transactions, nil
 }
 
+// parseAmeriaCsvBalance parses row like ["Opening Balance on 01/04/2024", "", "", "1,000.00"].
+// Returns balance at the start of the day from the label.
+func parseAmeriaCsvBalance(record []string, label string) (*BalanceRecord, error) {
+	if len(record) < 4 {
+		return nil, fmt.Errorf("balance row has only %d cells: %v", len(record), record)
+	}
+	date, err := parseDate(strings.TrimPrefix(record[0], label), AmeriaBusinessDateFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse date of balance from '%s': %w", record[0], err)
+	}
+	var amount Money
+	if err := amount.UnmarshalText([]byte(strings.TrimSpace(record[3]))); err != nil {
+		return nil, fmt.Errorf("failed to parse balance from '%s': %w", record[3], err)
+	}
+	return &BalanceRecord{Date: date, Amount: amount}, nil
+}
+
 func rowCellsToString(rowCells []string) string {
 	for i, cell := range rowCells {
 		rowCells[i] = strings.TrimSpace(strings.Trim(cell, `"`))
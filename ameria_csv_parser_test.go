@@ -9,7 +9,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
-var moneyComparer = cmp.Comparer(func(x, y MoneyWith2DecimalPlaces) bool {
+var moneyComparer = cmp.Comparer(func(x, y Money) bool {
 	return x.int == y.int
 })
 
@@ -39,6 +39,8 @@ func TestAmeriaCsvFileParser_ParseRawTransactionsFromFile_BOMInHeader(t *testing
 		FilePath:        filePath,
 		AccountNumber:   "9999999999999999",
 		AccountCurrency: "AMD",
+		OpeningBalance:  &BalanceRecord{Date: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		ClosingBalance:  &BalanceRecord{Date: time.Date(2024, time.September, 9, 0, 0, 0, 0, time.UTC), Amount: Money{int: 100500990}},
 	}
 	transactions, err := AmeriaCsvFileParser{}.ParseRawTransactionsFromFile(filePath)
 	if err != nil {
@@ -50,7 +52,7 @@ func TestAmeriaCsvFileParser_ParseRawTransactionsFromFile_BOMInHeader(t *testing
 			IsExpense:       false,
 			Date:            time.Date(2024, time.May, 17, 0, 0, 0, 0, time.UTC),
 			Details:         "Ք: Քարտից քարտ փոխանցում\\",
-			Amount:          MoneyWith2DecimalPlaces{int: 20000000},
+			Amount:          Money{int: 200000000},
 			Source:          source,
 			AccountCurrency: "AMD",
 			FromAccount:     "1234567890123456",
@@ -60,7 +62,7 @@ func TestAmeriaCsvFileParser_ParseRawTransactionsFromFile_BOMInHeader(t *testing
 			IsExpense:       true,
 			Date:            time.Date(2024, time.May, 20, 0, 0, 0, 0, time.UTC),
 			Details:         "Ք: SOME TEXT",
-			Amount:          MoneyWith2DecimalPlaces{int: 55000},
+			Amount:          Money{int: 550000},
 			Source:          source,
 			AccountCurrency: "AMD",
 			FromAccount:     "9999999999999999",
@@ -38,14 +38,51 @@ type MyAmeriaTransaction struct {
 	Details            string
 	Status             string
 	Comment            string
-	Amount             MoneyWith2DecimalPlaces
+	Amount             Money
 	Currency           string
 }
 
+const (
+	// MyAmeriaHistoryXlsParserName is a name of parser to use in `sources` configuration.
+	MyAmeriaHistoryXlsParserName = "myAmeriaHistoryXls"
+	myAmeriaHistoryXlsTypeName   = "MyAmeria History XLS"
+)
+
+func init() {
+	RegisterParser(ParserRegistration{
+		Name:        MyAmeriaHistoryXlsParserName,
+		TypeName:    myAmeriaHistoryXlsTypeName,
+		Tag:         "MyAmeriaXls",
+		Version:     2,
+		DefaultGlob: "History *.xls",
+		NewParser:   newMyAmeriaExcelFileParser,
+		Detect: func(sniff *FileSniff) bool {
+			return sniff.Container == FileContainerXlsx && sniff.HasRowStartingWith(xlsxHeaders...)
+		},
+	})
+}
+
 type MyAmeriaExcelFileParser struct {
 	MyAccounts map[string]string
 }
 
+// MyAmeriaExcelFileParserOptions are options of "myAmeriaHistoryXls" source.
+type MyAmeriaExcelFileParserOptions struct {
+	// MyAccounts is a map of account numbers to currencies, by default `myAmeriaMyAccounts` setting is used.
+	MyAccounts map[string]string `yaml:"myAccounts"`
+}
+
+func newMyAmeriaExcelFileParser(options map[string]any, config *Config) (FileParser, error) {
+	parserOptions := MyAmeriaExcelFileParserOptions{}
+	if err := decodeParserOptions(options, &parserOptions); err != nil {
+		return nil, err
+	}
+	if parserOptions.MyAccounts == nil {
+		parserOptions.MyAccounts = config.MyAmeriaMyAccounts
+	}
+	return MyAmeriaExcelFileParser{MyAccounts: parserOptions.MyAccounts}, nil
+}
+
 func (p MyAmeriaExcelFileParser) ParseRawTransactionsFromFile(
 	filePath string,
 ) ([]Transaction, error) {
@@ -98,11 +135,11 @@ func (p MyAmeriaExcelFileParser) ParseRawTransactionsFromFile(
 		}
 
 		// Parse date and amount.
-		date, err := time.Parse(MyAmeriaHistoryDateFormat, cells[0].String())
+		date, err := parseDate(cells[0].String(), MyAmeriaHistoryDateFormat)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse date from 1st cell of %d row: %w", i, err)
 		}
-		var amount MoneyWith2DecimalPlaces
+		var amount Money
 		if err := amount.UnmarshalText([]byte(cells[9].String())); err != nil {
 			return nil, fmt.Errorf("failed to parse amount from 10th cell of %d row: %w", i, err)
 		}
@@ -124,13 +161,13 @@ func (p MyAmeriaExcelFileParser) ParseRawTransactionsFromFile(
 	}
 
 	source := TransactionsSource{
-		TypeName:        "MyAmeria History XLS",
-		FilePath:        filePath,
+		TypeName: myAmeriaHistoryXlsTypeName,
+		FilePath: filePath,
 	}
 
 	// Convert MyAmeria rows to unified transactions and separate expenses from incomes.
 	var ok bool
-	zeroAmount := MoneyWith2DecimalPlaces{
+	zeroAmount := Money{
 		int: 0,
 	}
 	transactions := make([]Transaction, len(myAmeriaTransactions))
@@ -182,4 +219,4 @@ func (p MyAmeriaExcelFileParser) ParseRawTransactionsFromFile(
 	return transactions, nil
 }
 
-var _ FileParser = MyAmeriaExcelFileParser{}
\ No newline at end of file
+var _ FileParser = MyAmeriaExcelFileParser{}
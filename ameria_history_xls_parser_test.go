@@ -39,9 +39,9 @@ func TestMyAmeriaExcelFileParserParseRawTransactionsFromFile(t *testing.T) {
 					Details:              "ԱԱՀ այդ թվում` 16.67%",
 					Source:               source,
 					AccountCurrency:      "AMD",
-					Amount:               MoneyWith2DecimalPlaces{int: 10010},
+					Amount:               Money{int: 100100},
 					OriginCurrency:       "",
-					OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+					OriginCurrencyAmount: Money{int: 0},
 					FromAccount:          "1234567890123456",
 					ToAccount:            "9999999999999999",
 				},
@@ -51,9 +51,9 @@ func TestMyAmeriaExcelFileParserParseRawTransactionsFromFile(t *testing.T) {
 					Details:              "Payment for services",
 					Source:               source,
 					AccountCurrency:      "AMD",
-					Amount:               MoneyWith2DecimalPlaces{int: 0},
+					Amount:               Money{int: 0},
 					OriginCurrency:       "USD",
-					OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 50010},
+					OriginCurrencyAmount: Money{int: 500100},
 					FromAccount:          "1234567890123456",
 					ToAccount:            "9999999999999999",
 				},
@@ -63,9 +63,9 @@ func TestMyAmeriaExcelFileParserParseRawTransactionsFromFile(t *testing.T) {
 					Details:              "Transfer to myself",
 					Source:               source,
 					AccountCurrency:      "AMD",
-					Amount:               MoneyWith2DecimalPlaces{int: 0},
+					Amount:               Money{int: 0},
 					OriginCurrency:       "USD",
-					OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 100000},
+					OriginCurrencyAmount: Money{int: 1000000},
 					FromAccount:          "9999999999999999",
 					ToAccount:            "1234567890123456",
 				},
@@ -75,9 +75,9 @@ func TestMyAmeriaExcelFileParserParseRawTransactionsFromFile(t *testing.T) {
 					Details:              "Բանկի ձևանմուշից տարբերվող տեղեկա",
 					Source:               source,
 					AccountCurrency:      "AMD",
-					Amount:               MoneyWith2DecimalPlaces{int: 99999999999},
+					Amount:               Money{int: 999999999990},
 					OriginCurrency:       "",
-					OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+					OriginCurrencyAmount: Money{int: 0},
 					FromAccount:          "9999999999999999",
 					ToAccount:            "1234567890123456",
 				},
@@ -2,15 +2,14 @@ package main
 
 import (
 	"fmt"
-	"log"
 	"strings"
 	"time"
-
-	"github.com/tealeg/xlsx"
 )
 
 const giveUpFindHeaderInAmeriaExcelStmtAfterRows = 18
 const MyAmeriaStmtDateFormat = "02.01.2006"
+const myAmeriaStmtOpeningBalanceLabel = "Opening balance ("
+const myAmeriaStmtClosingBalanceLabel = "Closing balance ("
 
 var (
 	// Headers which exists in all files. Doesn't include "Amount" which are different from file to file.
@@ -26,10 +25,31 @@ type MyAmeriaStmtTransaction struct {
 	OperationType        string
 	Purpose              string
 	Currency             string
-	CreditOriginCurrency MoneyWith2DecimalPlaces
-	CreditAMD            MoneyWith2DecimalPlaces
-	DebitOriginCurrency  MoneyWith2DecimalPlaces
-	DebitAMD             MoneyWith2DecimalPlaces
+	CreditOriginCurrency Money
+	CreditAMD            Money
+	DebitOriginCurrency  Money
+	DebitAMD             Money
+}
+
+const (
+	// MyAmeriaXlsParserName is a name of parser to use in `sources` configuration.
+	MyAmeriaXlsParserName = "myAmeriaXls"
+	myAmeriaXlsTypeName   = "MyAmeria XLS statement"
+)
+
+func init() {
+	RegisterParser(ParserRegistration{
+		Name:        MyAmeriaXlsParserName,
+		TypeName:    myAmeriaXlsTypeName,
+		Tag:         "MyAmeriaXls",
+		Version:     4,
+		DefaultGlob: "* account statement *.xls",
+		NewParser:   newParserWithoutOptions(MyAmeriaExcelStmtFileParser{}),
+		Detect: func(sniff *FileSniff) bool {
+			return (sniff.Container == FileContainerXlsx || sniff.Container == FileContainerXls) &&
+				sniff.HasRowStartingWith(ameriaXlsHeaders...)
+		},
+	})
 }
 
 type MyAmeriaExcelStmtFileParser struct {
@@ -38,26 +58,23 @@ type MyAmeriaExcelStmtFileParser struct {
 func (p MyAmeriaExcelStmtFileParser) ParseRawTransactionsFromFile(
 	filePath string,
 ) ([]Transaction, error) {
-	f, err := xlsx.OpenFile(filePath)
+	// Statements are exported both as XLSX and as old XLS files, read the first sheet of any.
+	rows, err := readSpreadsheetRows(filePath, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, err
 	}
 
-	// Find first sheet.
-	firstSheet := f.Sheets[0]
-	log.Println(i18n.T("file parsing first sheet s from n sheets", "file", filePath, "s", firstSheet.Name, "n", len(f.Sheets)))
-
 	// Parse myAmeriaStmtTransactions.
 	var myAmeriaStmtTransactions []MyAmeriaStmtTransaction
 	var accountNumber = ""
 	var accountCurrency = ""
+	var openingBalance, closingBalance *BalanceRecord
 	var isHeaderRowFound bool
 	var creditColumnIndex = -1
 	var creditAmdColumnIndex = -1
 	var debitColumnIndex = -1
 	var debitAmdColumnIndex = -1
-	for i, row := range firstSheet.Rows {
-		cells := row.Cells
+	for i, cells := range rows {
 		if len(cells) < len(ameriaXlsHeaders) {
 			return nil, fmt.Errorf(
 				"%d row has only %d cells while need to find information for headers %v",
@@ -75,22 +92,41 @@ func (p MyAmeriaExcelStmtFileParser) ParseRawTransactionsFromFile(
 
 			// Try to find account number and currency first.
 			if len(accountNumber) < 1 {
-				if cells[0].String() == "Account No" {
+				if cells[0] == "Account No" {
 					// Account number contains extra "'" character.
-					accountNumber = strings.Trim(cells[2].String(), "'")
+					accountNumber = strings.Trim(cells[2], "'")
 				}
 			}
 			if len(accountCurrency) < 1 {
 				// Currency is placed under "Overdraft current limit" and "Overdraft used amount" labels.
-				if cells[0].String() == "Overdraft current limit" {
+				if cells[0] == "Overdraft current limit" {
 					// Currency cell contains extra spaces.
-					accountCurrency = strings.TrimSpace(cells[2].String())
+					accountCurrency = strings.TrimSpace(cells[2])
+				}
+			}
+
+			// Balances are placed at the right like "Opening balance (01/04/2024)" with "0  USD" amount.
+			if len(cells) > 9 {
+				label := strings.TrimSpace(cells[6])
+				if strings.HasPrefix(label, myAmeriaStmtOpeningBalanceLabel) {
+					openingBalance, err = parseMyAmeriaStmtBalance(label, cells[9])
+					if err != nil {
+						return nil, fmt.Errorf("%d row: %w", i+1, err)
+					}
+				}
+				if strings.HasPrefix(label, myAmeriaStmtClosingBalanceLabel) {
+					closingBalance, err = parseMyAmeriaStmtBalance(label, cells[9])
+					if err != nil {
+						return nil, fmt.Errorf("%d row: %w", i+1, err)
+					}
+					// Closing balance is at the end of the day.
+					closingBalance.Date = closingBalance.Date.AddDate(0, 0, 1)
 				}
 			}
 
 			var isCellMatches = true
 			for cellIndex, header := range ameriaXlsHeaders {
-				if strings.TrimSpace(cells[cellIndex].String()) != header {
+				if strings.TrimSpace(cells[cellIndex]) != header {
 					isCellMatches = false
 					break
 				}
@@ -99,8 +135,7 @@ func (p MyAmeriaExcelStmtFileParser) ParseRawTransactionsFromFile(
 				isHeaderRowFound = true
 				// This row contains also headers for "Credit XXX" and "Debit XXX" columns.
 				// Search indexes of these columns.
-				for cellIndex, cell := range cells {
-					header := cell.String()
+				for cellIndex, header := range cells {
 					if header == "Credit "+accountCurrency {
 						creditColumnIndex = cellIndex
 						continue // Skip this row to avoid getting "creditAmdColumnIndex" set if account currency is AMD.
@@ -128,48 +163,38 @@ func (p MyAmeriaExcelStmtFileParser) ParseRawTransactionsFromFile(
 		}
 
 		// Stop if row doesn't have enough cells or first cell is empty.
-		if len(cells) < len(ameriaXlsHeaders) || cells[0].String() == "" {
+		if len(cells) < len(ameriaXlsHeaders) || cells[0] == "" {
 			break
 		}
 
 		// Parse date and amounts.
-		date, err := time.Parse(MyAmeriaStmtDateFormat, cells[0].String())
+		date, err := parseDate(cells[0], MyAmeriaStmtDateFormat)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse date from 1st cell of %d row: %w", i, err)
 		}
-		var creditAmount MoneyWith2DecimalPlaces
-		err = creditAmount.ParseAmountWithoutLettersFromString(cells[creditColumnIndex].String())
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse credit amount from cell %d of %d row: %w", creditColumnIndex+1, i+1, err)
-		}
-		var creditAmdAmount MoneyWith2DecimalPlaces
-		err = creditAmdAmount.ParseAmountWithoutLettersFromString(cells[creditAmdColumnIndex].String())
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse credit AMD amount from cell %d of %d row: %w", creditAmdColumnIndex+1, i+1, err)
-		}
-		var debitAmount MoneyWith2DecimalPlaces
-		err = debitAmount.ParseAmountWithoutLettersFromString(cells[debitColumnIndex].String())
-		if creditColumnIndex != -1 && cells[creditColumnIndex].String() != "" {
-			err = creditAmount.ParseAmountWithoutLettersFromString(cells[creditColumnIndex].String())
+		// Cells of the other direction and of missing columns are empty.
+		var creditAmount, creditAmdAmount, debitAmount Money
+		if creditColumnIndex != -1 && cells[creditColumnIndex] != "" {
+			err = creditAmount.ParseAmountWithoutLettersFromString(cells[creditColumnIndex])
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse credit amount from cell %d of %d row: %w", creditColumnIndex+1, i+1, err)
 			}
 		}
-		if creditAmdColumnIndex != -1 && cells[creditAmdColumnIndex].String() != "" {
-			err = creditAmdAmount.ParseAmountWithoutLettersFromString(cells[creditAmdColumnIndex].String())
+		if creditAmdColumnIndex != -1 && cells[creditAmdColumnIndex] != "" {
+			err = creditAmdAmount.ParseAmountWithoutLettersFromString(cells[creditAmdColumnIndex])
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse credit AMD amount from cell %d of %d row: %w", creditAmdColumnIndex+1, i+1, err)
 			}
 		}
-		if debitColumnIndex != -1 && cells[debitColumnIndex].String() != "" {
-			err = debitAmount.ParseAmountWithoutLettersFromString(cells[debitColumnIndex].String())
+		if debitColumnIndex != -1 && cells[debitColumnIndex] != "" {
+			err = debitAmount.ParseAmountWithoutLettersFromString(cells[debitColumnIndex])
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse debit amount from cell %d of %d row: %w", debitColumnIndex+1, i+1, err)
 			}
 		}
-		var debitAmdAmount MoneyWith2DecimalPlaces
-		if debitAmdColumnIndex != -1 && cells[debitAmdColumnIndex].String() != "" {
-			err = debitAmdAmount.ParseAmountWithoutLettersFromString(cells[debitAmdColumnIndex].String())
+		var debitAmdAmount Money
+		if debitAmdColumnIndex != -1 && cells[debitAmdColumnIndex] != "" {
+			err = debitAmdAmount.ParseAmountWithoutLettersFromString(cells[debitAmdColumnIndex])
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse debit AMD amount from cell %d of %d row: %w", debitAmdColumnIndex+1, i+1, err)
 			}
@@ -177,10 +202,10 @@ func (p MyAmeriaExcelStmtFileParser) ParseRawTransactionsFromFile(
 		// Build MyAmeria Statement transaction.
 		myAmeriaStmtTransactions = append(myAmeriaStmtTransactions, MyAmeriaStmtTransaction{
 			Date:                 date,
-			Account:              cells[1].String(),
-			RecipientOrSender:    cells[2].String(),
-			OperationType:        cells[3].String(),
-			Purpose:              cells[4].String(),
+			Account:              cells[1],
+			RecipientOrSender:    cells[2],
+			OperationType:        cells[3],
+			Purpose:              cells[4],
 			Currency:             accountCurrency,
 			CreditOriginCurrency: creditAmount,
 			CreditAMD:            creditAmdAmount,
@@ -190,15 +215,17 @@ func (p MyAmeriaExcelStmtFileParser) ParseRawTransactionsFromFile(
 	}
 
 	source := TransactionsSource{
-		TypeName:        "MyAmeria XLS statement",
+		TypeName:        myAmeriaXlsTypeName,
 		Tag:             "MyAmeriaXls:" + accountCurrency,
 		FilePath:        filePath,
 		AccountNumber:   accountNumber,
 		AccountCurrency: accountCurrency,
+		OpeningBalance:  openingBalance,
+		ClosingBalance:  closingBalance,
 	}
 
 	// Convert MyAmeria rows to unified transactions and separate expenses from incomes.
-	// Keep AMD as "originCurrency" for case when account currency is AMD and values are provided in "Credit AMD" or "Debit AMD" columns.
+	// Keep values of "Credit AMD" or "Debit AMD" columns of accounts in other currencies as origin amounts in AMD.
 	transactions := make([]Transaction, 0, len(myAmeriaStmtTransactions))
 	for _, t := range myAmeriaStmtTransactions {
 		isExpense := false
@@ -218,6 +245,10 @@ func (p MyAmeriaExcelStmtFileParser) ParseRawTransactionsFromFile(
 		if amount.int == 0 && originCurrencyAmount.int == 0 {
 			continue
 		}
+		originCurrency := ""
+		if originCurrencyAmount.int != 0 {
+			originCurrency = "AMD"
+		}
 		transactions = append(transactions, Transaction{
 			IsExpense:            isExpense,
 			Date:                 t.Date,
@@ -225,7 +256,7 @@ func (p MyAmeriaExcelStmtFileParser) ParseRawTransactionsFromFile(
 			Source:               &source,
 			AccountCurrency:      accountCurrency,
 			Amount:               amount,
-			OriginCurrency:       t.Currency,
+			OriginCurrency:       originCurrency,
 			OriginCurrencyAmount: originCurrencyAmount,
 			FromAccount:          from,
 			ToAccount:            to,
@@ -234,3 +265,22 @@ func (p MyAmeriaExcelStmtFileParser) ParseRawTransactionsFromFile(
 
 	return transactions, nil
 }
+
+// parseMyAmeriaStmtBalance parses balance from label like "Opening balance (01/04/2024)" and value like "99500.25  USD".
+// Returns balance at the start of the day from the label.
+func parseMyAmeriaStmtBalance(label, value string) (*BalanceRecord, error) {
+	start := strings.Index(label, "(")
+	end := strings.Index(label, ")")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("can't find date of balance in '%s'", label)
+	}
+	date, err := parseDate(label[start+1:end], AmeriaBusinessDateFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse date of balance from '%s': %w", label, err)
+	}
+	var amount Money
+	if err := amount.ParseAmountWithoutLettersFromString(value); err != nil {
+		return nil, fmt.Errorf("failed to parse balance from '%s': %w", value, err)
+	}
+	return &BalanceRecord{Date: date, Amount: amount}, nil
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// AnomalyDetectionConfig configures detection of unusual spending comparing with previous months.
+// Deviation is measured as robust z-score: difference with median of previous months
+// divided by median absolute deviation (MAD) scaled to be comparable with standard deviation.
+type AnomalyDetectionConfig struct {
+	// Disabled turns detection off.
+	Disabled bool `yaml:"disabled,omitempty"`
+	// BaselineMonths is a number of previous months to compare with.
+	BaselineMonths int `yaml:"baselineMonths,omitempty" validate:"min=0,max=120"`
+	// Threshold is a minimal robust z-score of anomaly.
+	Threshold float64 `yaml:"threshold,omitempty" validate:"min=0"`
+}
+
+const (
+	// defaultAnomalyBaselineMonths is a default number of previous months to compare with.
+	defaultAnomalyBaselineMonths = 6
+	// defaultAnomalyThreshold is a default robust z-score of anomaly, common choice for the MAD based score.
+	defaultAnomalyThreshold = 3.5
+	// anomalyMinBaselineMonths is a minimal number of previous months to detect anomalies of categories.
+	anomalyMinBaselineMonths = 3
+	// anomalyMinBaselineTransactions is a minimal number of previous transactions in the group
+	// to detect unusually large transactions.
+	anomalyMinBaselineTransactions = 5
+	// anomalyMADToStdDev scales MAD to be comparable with standard deviation of normal distribution.
+	anomalyMADToStdDev = 1.4826
+	// anomalyMinRelativeScale is a minimal deviation scale relative to median.
+	// Protects from infinite scores when all previous values are (almost) the same, like rent.
+	anomalyMinRelativeScale = 0.1
+)
+
+// getBaselineMonths returns number of previous months to compare with.
+func (c *AnomalyDetectionConfig) getBaselineMonths() int {
+	if c == nil || c.BaselineMonths == 0 {
+		return defaultAnomalyBaselineMonths
+	}
+	return c.BaselineMonths
+}
+
+// getThreshold returns minimal robust z-score of anomaly.
+func (c *AnomalyDetectionConfig) getThreshold() float64 {
+	if c == nil || c.Threshold == 0 {
+		return defaultAnomalyThreshold
+	}
+	return c.Threshold
+}
+
+// CategoryAnomaly is an unusually large total of expenses in the group comparing with previous months.
+type CategoryAnomaly struct {
+	// Name is a name of the group.
+	Name string
+	// Actual is a total of the group in the month.
+	Actual Money
+	// Median is a median of totals of the group in previous months.
+	Median Money
+	// Score is a robust z-score, always positive because only spending more than usual is reported.
+	Score float64
+}
+
+// TransactionAnomaly is an unusually large expense comparing with previous expenses in the same group.
+type TransactionAnomaly struct {
+	// Group is a name of the group.
+	Group string
+	// Entry is the journal entry.
+	Entry JournalEntry
+	// Amount is an amount of the entry in currency of statistics.
+	Amount Money
+	// Median is a median of amounts of expenses in the group in previous months.
+	Median Money
+	// Score is a robust z-score.
+	Score float64
+}
+
+// medianOf returns median of values. Sorts values.
+func medianOf(values []float64) float64 {
+	sort.Float64s(values)
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+	return values[middle]
+}
+
+// robustScore returns median of the baseline and robust z-score of the value against it.
+// Score is 0 if baseline has no spread and median is 0.
+func robustScore(value float64, baseline []float64) (float64, float64) {
+	values := append([]float64{}, baseline...)
+	median := medianOf(values)
+	for i, v := range values {
+		values[i] = math.Abs(v - median)
+	}
+	scale := math.Max(anomalyMADToStdDev*medianOf(values), anomalyMinRelativeScale*math.Abs(median))
+	if scale == 0 {
+		return median, 0
+	}
+	return median, (value - median) / scale
+}
+
+// getEntryAmount returns amount of the journal entry in the currency in money units.
+func getEntryAmount(je *JournalEntry, currency string) (int, bool) {
+	amount, ok := je.Amounts[currency]
+	return amount.Amount.int, ok
+}
+
+// buildAnomalies sets `Anomalies` and `TransactionAnomalies` in all provided statistics.
+// Months should be in chronological order. Each month is compared with previous months
+// which have statistics in the same currency.
+func buildAnomalies(monthlyStatistics []map[string]*IntervalStatistic, config *AnomalyDetectionConfig) {
+	if config != nil && config.Disabled {
+		return
+	}
+	baselineMonths := config.getBaselineMonths()
+	threshold := config.getThreshold()
+
+	// Previous months statistics per currency.
+	history := make(map[string][]*IntervalStatistic)
+	for _, month := range monthlyStatistics {
+		for currency, stat := range month {
+			baseline := history[currency]
+			if len(baseline) > baselineMonths {
+				baseline = baseline[len(baseline)-baselineMonths:]
+			}
+			stat.Anomalies = findCategoryAnomalies(stat, baseline, threshold)
+			stat.TransactionAnomalies = findTransactionAnomalies(stat, baseline, threshold)
+			history[currency] = append(history[currency], stat)
+		}
+	}
+}
+
+// findCategoryAnomalies compares totals of expense groups with previous months.
+// Group absent in a previous month is counted as zero spending in it.
+// Only totals larger than usual are reported because smaller ones are expected in the current, not finished month.
+func findCategoryAnomalies(stat *IntervalStatistic, baseline []*IntervalStatistic, threshold float64) []*CategoryAnomaly {
+	result := make([]*CategoryAnomaly, 0)
+	if len(baseline) < anomalyMinBaselineMonths {
+		return result
+	}
+	names := make(map[string]struct{})
+	for name := range stat.Expense {
+		names[name] = struct{}{}
+	}
+	for _, previous := range baseline {
+		for name := range previous.Expense {
+			names[name] = struct{}{}
+		}
+	}
+	for name := range names {
+		actual := 0
+		if group, ok := stat.Expense[name]; ok {
+			actual = group.Total.int
+		}
+		totals := make([]float64, len(baseline))
+		for i, previous := range baseline {
+			if group, ok := previous.Expense[name]; ok {
+				totals[i] = float64(group.Total.int)
+			}
+		}
+		median, score := robustScore(float64(actual), totals)
+		if score < threshold {
+			continue
+		}
+		result = append(result, &CategoryAnomaly{
+			Name:   name,
+			Actual: Money{int: actual},
+			Median: Money{int: int(math.Round(median))},
+			Score:  score,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// findTransactionAnomalies finds expenses which are unusually large for their groups
+// comparing with expenses of the same groups in previous months.
+func findTransactionAnomalies(stat *IntervalStatistic, baseline []*IntervalStatistic, threshold float64) []*TransactionAnomaly {
+	result := make([]*TransactionAnomaly, 0)
+	for name, group := range stat.Expense {
+		amounts := make([]float64, 0)
+		for _, previous := range baseline {
+			if previousGroup, ok := previous.Expense[name]; ok {
+				for i := range previousGroup.JournalEntries {
+					if amount, ok := getEntryAmount(&previousGroup.JournalEntries[i], stat.Currency); ok {
+						amounts = append(amounts, float64(amount))
+					}
+				}
+			}
+		}
+		if len(amounts) < anomalyMinBaselineTransactions {
+			continue
+		}
+		for _, je := range group.JournalEntries {
+			amount, ok := getEntryAmount(&je, stat.Currency)
+			if !ok {
+				continue
+			}
+			median, score := robustScore(float64(amount), amounts)
+			if score < threshold {
+				continue
+			}
+			result = append(result, &TransactionAnomaly{
+				Group:  name,
+				Entry:  je,
+				Amount: Money{int: amount},
+				Median: Money{int: int(math.Round(median))},
+				Score:  score,
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].Entry.Date.Before(result[j].Entry.Date)
+	})
+	return result
+}
+
+// anomaliesToString returns human readable unusual spending of the interval.
+func anomaliesToString(categories []*CategoryAnomaly, transactions []*TransactionAnomaly) string {
+	if len(categories) == 0 && len(transactions) == 0 {
+		return ""
+	}
+	details := make([]string, 0, len(categories)+len(transactions))
+	for _, anomaly := range categories {
+		details = append(details, i18n.T("groupName actual usually median score",
+			"groupName", anomaly.Name,
+			"actual", anomaly.Actual.StringNoIndent(),
+			"median", anomaly.Median.StringNoIndent(),
+			"score", fmt.Sprintf("%+.1f", anomaly.Score),
+		))
+	}
+	for _, anomaly := range transactions {
+		details = append(details, i18n.T("date details amount in group usually median score",
+			"date", anomaly.Entry.Date.Format(OutputDateFormat),
+			"details", anomaly.Entry.Details,
+			"amount", anomaly.Amount.StringNoIndent(),
+			"group", anomaly.Group,
+			"median", anomaly.Median.StringNoIndent(),
+			"score", fmt.Sprintf("%+.1f", anomaly.Score),
+		))
+	}
+	return i18n.T("Anomalies_format",
+		"nCategories", len(categories),
+		"nTransactions", len(transactions),
+		"detailsAnomalies", details,
+	)
+}
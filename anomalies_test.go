@@ -0,0 +1,180 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRobustScore(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          float64
+		baseline       []float64
+		expectedMedian float64
+		expectedScore  float64
+	}{
+		{"usual", 100, []float64{80, 100, 120, 100, 90}, 100, 0},
+		{"higher", 200, []float64{80, 100, 120, 100, 90}, 100, 100 / (10 * anomalyMADToStdDev)},
+		{"lower", 0, []float64{80, 100, 120, 100}, 100, -100 / (10 * anomalyMADToStdDev)},
+		{"small_spread", 120, []float64{99, 100, 101}, 100, 2},
+		{"same_baseline", 150, []float64{100, 100, 100}, 100, 5},
+		{"zero_baseline", 150, []float64{0, 0, 0}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			median, score := robustScore(tt.value, tt.baseline)
+
+			// Assert
+			if median != tt.expectedMedian || math.Abs(score-tt.expectedScore) > 1e-9 {
+				t.Errorf("expected median %v and score %v, got %v and %v", tt.expectedMedian, tt.expectedScore, median, score)
+			}
+		})
+	}
+}
+
+func TestBuildAnomalies(t *testing.T) {
+	usualFood := []int{10000, 12000, 9000, 11000, 10000, 13000}
+	tests := []struct {
+		name                 string
+		lastMonth            map[string][]int
+		config               *AnomalyDetectionConfig
+		expectedAnomalies    []string
+		expectedTransactions []string
+	}{
+		{
+			name:      "usual",
+			lastMonth: map[string][]int{"Food": usualFood, "Rent": {100000}},
+		},
+		{
+			name:                 "large_transaction",
+			lastMonth:            map[string][]int{"Food": append([]int{50000}, usualFood...), "Rent": {100000}},
+			expectedAnomalies:    []string{"Food"},
+			expectedTransactions: []string{"Food"},
+		},
+		{
+			name:              "many_usual_transactions",
+			lastMonth:         map[string][]int{"Food": append(append([]int{}, usualFood...), usualFood...), "Rent": {100000}},
+			expectedAnomalies: []string{"Food"},
+		},
+		{
+			name:              "rent_increased_and_no_food",
+			lastMonth:         map[string][]int{"Rent": {150000}},
+			expectedAnomalies: []string{"Rent"},
+		},
+		{
+			name:      "half_month",
+			lastMonth: map[string][]int{"Food": usualFood[:3], "Rent": {100000}},
+		},
+		{
+			name:      "high_threshold",
+			lastMonth: map[string][]int{"Rent": {150000}},
+			config:    &AnomalyDetectionConfig{Threshold: 100},
+		},
+		{
+			name:      "disabled",
+			lastMonth: map[string][]int{"Food": append([]int{50000}, usualFood...), "Rent": {150000}},
+			config:    &AnomalyDetectionConfig{Disabled: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			statistics := make([]map[string]*IntervalStatistic, 0)
+			for month := time.January; month <= time.March; month++ {
+				statistics = append(statistics, newTestMonth(month, map[string]map[string][]int{
+					"AMD": {"Food": usualFood, "Rent": {100000}},
+				}))
+			}
+			statistics = append(statistics, newTestMonth(time.April, map[string]map[string][]int{"AMD": tt.lastMonth}))
+
+			// Act
+			buildAnomalies(statistics, tt.config)
+
+			// Assert
+			for _, month := range statistics[:3] {
+				if len(month["AMD"].Anomalies) > 0 {
+					t.Errorf("expected no anomalies without baseline, got %+v", month["AMD"].Anomalies)
+				}
+			}
+			last := statistics[3]["AMD"]
+			actualAnomalies := []string{}
+			for _, anomaly := range last.Anomalies {
+				actualAnomalies = append(actualAnomalies, anomaly.Name)
+			}
+			actualTransactions := []string{}
+			for _, anomaly := range last.TransactionAnomalies {
+				actualTransactions = append(actualTransactions, anomaly.Group)
+			}
+			if strings.Join(actualAnomalies, ",") != strings.Join(tt.expectedAnomalies, ",") {
+				t.Errorf("expected anomalies %v, got %+v", tt.expectedAnomalies, last.Anomalies)
+			}
+			if strings.Join(actualTransactions, ",") != strings.Join(tt.expectedTransactions, ",") {
+				t.Errorf("expected transaction anomalies %v, got %+v", tt.expectedTransactions, last.TransactionAnomalies)
+			}
+		})
+	}
+}
+
+func TestBuildAnomalies_BaselineMonths(t *testing.T) {
+	// Arrange
+	statistics := []map[string]*IntervalStatistic{
+		newTestMonth(time.January, map[string]map[string][]int{"AMD": {"Rent": {50000}}}),
+		newTestMonth(time.February, map[string]map[string][]int{"AMD": {"Rent": {50000}}}),
+		newTestMonth(time.March, map[string]map[string][]int{"AMD": {"Rent": {100000}}}),
+		newTestMonth(time.April, map[string]map[string][]int{"AMD": {"Rent": {100000}}}),
+		newTestMonth(time.May, map[string]map[string][]int{"AMD": {"Rent": {100000}}}),
+		newTestMonth(time.June, map[string]map[string][]int{"AMD": {"Rent": {100000}}}),
+	}
+
+	// Act
+	buildAnomalies(statistics, &AnomalyDetectionConfig{BaselineMonths: 3})
+
+	// Assert
+	if anomalies := statistics[3]["AMD"].Anomalies; len(anomalies) != 1 || anomalies[0].Median.int != 50000 {
+		t.Errorf("expected rent increase comparing with median 500.00, got %+v", anomalies)
+	}
+	for _, month := range statistics[4:] {
+		if anomalies := month["AMD"].Anomalies; len(anomalies) != 0 {
+			t.Errorf("expected new rent to become usual in 3 months baseline, got %+v", anomalies[0])
+		}
+	}
+}
+
+func TestAnomaliesToString(t *testing.T) {
+	// Arrange
+	categories := []*CategoryAnomaly{
+		{Name: "Rent", Actual: Money{int: 150000000}, Median: Money{int: 100000000}, Score: 5},
+	}
+	transactions := []*TransactionAnomaly{
+		{
+			Group:  "Food",
+			Entry:  JournalEntry{Date: testDate, Details: "RESTAURANT"},
+			Amount: Money{int: 50000000},
+			Median: Money{int: 10000000},
+			Score:  12.345,
+		},
+	}
+
+	// Act
+	actual := anomaliesToString(categories, transactions)
+
+	// Assert
+	for _, expected := range []string{
+		"Unusual spending (groups 1, transactions 1)",
+		"Rent",
+		"150,000.00",
+		"usually 100,000.00 (score +5.0)",
+		"RESTAURANT: 50,000.00 in 'Food', usually 10,000.00 (score +12.3)",
+	} {
+		if !strings.Contains(actual, expected) {
+			t.Errorf("expected %q in:\n%s", expected, actual)
+		}
+	}
+	if anomaliesToString(nil, nil) != "" {
+		t.Error("expected empty string without anomalies")
+	}
+}
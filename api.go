@@ -0,0 +1,799 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// APIPrefix is a prefix of all JSON API endpoints. Version is changed only on breaking changes.
+	APIPrefix = "/api/v1"
+	// apiDefaultLimit is a page size if `limit` parameter is not provided.
+	apiDefaultLimit = 100
+	// apiMaxLimit is a maximum allowed page size.
+	apiMaxLimit = 1000
+)
+
+// apiError is an error with HTTP status to return from API handlers.
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+// newBadRequestError creates apiError for wrong request parameters.
+func newBadRequestError(format string, args ...interface{}) error {
+	return &apiError{status: http.StatusBadRequest, message: fmt.Sprintf(format, args...)}
+}
+
+// apiMoney is an amount in API responses. Is serialized as JSON number with 2 decimal places,
+// or 3 if amount has thousandths like in KWD, so scripts don't need to parse formatted strings
+// and values are not affected by float rounding.
+type apiMoney Money
+
+func (m apiMoney) MarshalJSON() ([]byte, error) {
+	decimalPlaces := displayDecimalPlaces
+	if m.int%pow10(moneyDecimalPlaces-displayDecimalPlaces) != 0 {
+		decimalPlaces = moneyDecimalPlaces
+	}
+	return []byte(Money(m).format(decimalPlaces, "")), nil
+}
+
+// apiPage is a page of items with information to request other pages.
+type apiPage[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// apiListQuery contains common parameters of list endpoints.
+type apiListQuery struct {
+	offset int
+	limit  int
+	// sortField is a name of field to sort by.
+	sortField string
+	// isDescending is true if `sort` parameter starts with "-".
+	isDescending bool
+}
+
+// parseAPIListQuery parses `offset`, `limit` and `sort` parameters.
+// `sort` is a name of field from `sortFields`, with "-" prefix for descending order.
+func parseAPIListQuery(query url.Values, sortFields []string) (apiListQuery, error) {
+	result := apiListQuery{limit: apiDefaultLimit, sortField: sortFields[0]}
+	var err error
+	if value := query.Get("offset"); value != "" {
+		result.offset, err = strconv.Atoi(value)
+		if err != nil || result.offset < 0 {
+			return result, newBadRequestError("'offset' should be not negative integer, got '%s'", value)
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		result.limit, err = strconv.Atoi(value)
+		if err != nil || result.limit < 1 || result.limit > apiMaxLimit {
+			return result, newBadRequestError("'limit' should be integer from 1 to %d, got '%s'", apiMaxLimit, value)
+		}
+	}
+	if value := query.Get("sort"); value != "" {
+		result.isDescending = strings.HasPrefix(value, "-")
+		result.sortField = strings.TrimPrefix(value, "-")
+		if !slices.Contains(sortFields, result.sortField) {
+			return result, newBadRequestError("'sort' should be one of %v with optional '-' prefix, got '%s'", sortFields, value)
+		}
+	}
+	return result, nil
+}
+
+// parseAPIDate parses optional date parameter in "YYYY-MM-DD" format.
+func parseAPIDate(query url.Values, name string) (string, error) {
+	value := query.Get(name)
+	if value == "" {
+		return "", nil
+	}
+	if _, err := time.Parse(OutputDateFormat, value); err != nil {
+		return "", newBadRequestError("'%s' should be a date in YYYY-MM-DD format, got '%s'", name, value)
+	}
+	return value, nil
+}
+
+// isDateInRange checks that date in "YYYY-MM-DD" format is between optional inclusive bounds.
+// Dates are compared as strings to don't depend on time zones.
+func isDateInRange(date, from, to string) bool {
+	return (from == "" || date >= from) && (to == "" || date <= to)
+}
+
+// sortAndPaginate sorts items with comparator for the requested field and returns the requested page.
+func sortAndPaginate[T any](items []T, query apiListQuery, comparators map[string]func(a, b T) int) apiPage[T] {
+	compare := comparators[query.sortField]
+	slices.SortStableFunc(items, func(a, b T) int {
+		if query.isDescending {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	start := min(query.offset, len(items))
+	end := min(start+query.limit, len(items))
+	return apiPage[T]{
+		Items:  items[start:end],
+		Total:  len(items),
+		Offset: query.offset,
+		Limit:  query.limit,
+	}
+}
+
+// withTieBreaker returns comparators which compare items by unique key when compared fields are equal.
+// Items are often built from maps, so without it order of equal items, and so pages, may change between requests.
+func withTieBreaker[T any](comparators map[string]func(a, b T) int, compareKeys func(a, b T) int) map[string]func(a, b T) int {
+	result := make(map[string]func(a, b T) int, len(comparators))
+	for field, compare := range comparators {
+		compare := compare
+		result[field] = func(a, b T) int {
+			if result := compare(a, b); result != 0 {
+				return result
+			}
+			return compareKeys(a, b)
+		}
+	}
+	return result
+}
+
+// firstNonZero returns the first non-zero result of comparisons, useful to compare by several fields.
+func firstNonZero(results ...int) int {
+	for _, result := range results {
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// sortFieldsOf returns names of fields to sort by with the default one first.
+func sortFieldsOf[T any](defaultField string, comparators map[string]func(a, b T) int) []string {
+	fields := []string{defaultField}
+	for field := range comparators {
+		if field != defaultField {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields[1:])
+	return fields
+}
+
+// handleAPI wraps function which builds response from the current data snapshot into JSON HTTP handler.
+func handleAPI(dataHandler *DataHandler, handle func(snapshot *DataSnapshot, query url.Values) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		var response interface{}
+		var err error
+		if r.Method != http.MethodGet {
+			err = &apiError{status: http.StatusMethodNotAllowed, message: "only GET method is supported"}
+		} else {
+			response, err = handle(dataHandler.GetSnapshot(), r.URL.Query())
+		}
+		status := http.StatusOK
+		if err != nil {
+			status = http.StatusInternalServerError
+			var requestErr *apiError
+			if errors.As(err, &requestErr) {
+				status = requestErr.status
+			} else {
+				log.Printf("API %s failed: %v", r.URL.Path, err)
+			}
+			response = struct {
+				Error string `json:"error"`
+			}{err.Error()}
+		}
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Printf("Can't write API response for %s: %v", r.URL.Path, err)
+		}
+	}
+}
+
+// registerAPIHandlers adds all JSON API endpoints into the router.
+func registerAPIHandlers(mux *http.ServeMux, dataHandler *DataHandler) {
+	mux.HandleFunc(APIPrefix+"/journal-entries", handleAPI(dataHandler, apiJournalEntries))
+	mux.HandleFunc(APIPrefix+"/statistics/monthly", handleAPI(dataHandler, apiMonthlyStatistics))
+	mux.HandleFunc(APIPrefix+"/accounts", handleAPI(dataHandler, apiAccounts))
+	mux.HandleFunc(APIPrefix+"/currencies", handleAPI(dataHandler, apiCurrencies))
+	mux.HandleFunc(APIPrefix+"/exchange-rates", handleAPI(dataHandler, apiExchangeRates))
+	mux.HandleFunc(APIPrefix+"/files", handleAPI(dataHandler, apiFiles))
+	mux.HandleFunc(APIPrefix+"/groups", handleAPI(dataHandler, apiGroups))
+	mux.HandleFunc(APIPrefix+"/", handleAPI(dataHandler, func(*DataSnapshot, url.Values) (interface{}, error) {
+		return nil, &apiError{status: http.StatusNotFound, message: "unknown API endpoint"}
+	}))
+}
+
+// APIJournalEntry is a journal entry in API responses.
+type APIJournalEntry struct {
+	Date string `json:"date"`
+	// Type is one of "income", "expense" or "transfer".
+	Type                  string              `json:"type"`
+	Category              string              `json:"category"`
+	CategoryPath          []string            `json:"categoryPath,omitempty"`
+	Details               string              `json:"details"`
+	FromAccount           string              `json:"fromAccount"`
+	ToAccount             string              `json:"toAccount"`
+	AccountCurrency       string              `json:"accountCurrency"`
+	AccountCurrencyAmount apiMoney            `json:"accountCurrencyAmount"`
+	OriginCurrency        string              `json:"originCurrency,omitempty"`
+	OriginCurrencyAmount  *apiMoney           `json:"originCurrencyAmount,omitempty"`
+	Amounts               map[string]apiMoney `json:"amounts"`
+	RuleType              RuleType            `json:"ruleType,omitempty"`
+	RuleValue             string              `json:"ruleValue,omitempty"`
+	SourceType            string              `json:"sourceType"`
+	SourceFile            string              `json:"sourceFile"`
+	// Fingerprint identifies transaction for manual overrides and splits.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// journalEntryType returns type of journal entry for API.
+func journalEntryType(entry *JournalEntry) string {
+	switch {
+	case entry.IsTransfer:
+		return "transfer"
+	case entry.IsExpense:
+		return "expense"
+	default:
+		return "income"
+	}
+}
+
+func newAPIJournalEntry(entry *JournalEntry) APIJournalEntry {
+	result := APIJournalEntry{
+		Date:                  entry.Date.Format(OutputDateFormat),
+		Type:                  journalEntryType(entry),
+		Category:              entry.Category,
+		CategoryPath:          entry.CategoryPath,
+		Details:               entry.Details,
+		FromAccount:           entry.FromAccount,
+		ToAccount:             entry.ToAccount,
+		AccountCurrency:       entry.AccountCurrency,
+		AccountCurrencyAmount: apiMoney(entry.AccountCurrencyAmount),
+		OriginCurrency:        entry.OriginCurrency,
+		Amounts:               make(map[string]apiMoney, len(entry.Amounts)),
+		RuleType:              entry.RuleType,
+		RuleValue:             entry.RuleValue,
+		Fingerprint:           entry.Fingerprint,
+	}
+	if entry.OriginCurrency != "" {
+		amount := apiMoney(entry.OriginCurrencyAmount)
+		result.OriginCurrencyAmount = &amount
+	}
+	for currency, amount := range entry.Amounts {
+		result.Amounts[currency] = apiMoney(amount.Amount)
+	}
+	if entry.Source != nil {
+		result.SourceType = entry.Source.TypeName
+		result.SourceFile = entry.Source.FilePath
+	}
+	return result
+}
+
+var journalEntriesComparators = withTieBreaker(map[string]func(a, b APIJournalEntry) int{
+	"date": func(a, b APIJournalEntry) int { return cmp.Compare(a.Date, b.Date) },
+	"amount": func(a, b APIJournalEntry) int {
+		return cmp.Compare(a.AccountCurrencyAmount.int, b.AccountCurrencyAmount.int)
+	},
+	"category": func(a, b APIJournalEntry) int { return cmp.Compare(a.Category, b.Category) },
+	"details":  func(a, b APIJournalEntry) int { return cmp.Compare(a.Details, b.Details) },
+}, func(a, b APIJournalEntry) int {
+	// Parts of a split have the same fingerprint but different categories.
+	return firstNonZero(
+		cmp.Compare(a.Date, b.Date),
+		cmp.Compare(a.Details, b.Details),
+		cmp.Compare(a.Fingerprint, b.Fingerprint),
+		cmp.Compare(a.Category, b.Category),
+		cmp.Compare(a.AccountCurrencyAmount.int, b.AccountCurrencyAmount.int),
+	)
+})
+
+// apiJournalEntries returns journal entries filtered by `from` and `to` dates, `category` (including
+// nested categories), `account` (either "from" or "to"), `currency` (account or origin one) and `type`.
+func apiJournalEntries(snapshot *DataSnapshot, query url.Values) (interface{}, error) {
+	listQuery, err := parseAPIListQuery(query, sortFieldsOf("date", journalEntriesComparators))
+	if err != nil {
+		return nil, err
+	}
+	from, err := parseAPIDate(query, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseAPIDate(query, "to")
+	if err != nil {
+		return nil, err
+	}
+	category := query.Get("category")
+	account := query.Get("account")
+	currency := query.Get("currency")
+	entryType := query.Get("type")
+	if entryType != "" && !slices.Contains([]string{"income", "expense", "transfer"}, entryType) {
+		return nil, newBadRequestError("'type' should be one of income, expense or transfer, got '%s'", entryType)
+	}
+
+	journalEntries, err := snapshot.GetJournalEntries()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]APIJournalEntry, 0)
+	for i := range journalEntries {
+		entry := &journalEntries[i]
+		if !isDateInRange(entry.Date.Format(OutputDateFormat), from, to) ||
+			(category != "" && !isInCategory(entry, category)) ||
+			(account != "" && entry.FromAccount != account && entry.ToAccount != account) ||
+			(currency != "" && entry.AccountCurrency != currency && entry.OriginCurrency != currency) ||
+			(entryType != "" && journalEntryType(entry) != entryType) {
+			continue
+		}
+		items = append(items, newAPIJournalEntry(entry))
+	}
+	return sortAndPaginate(items, listQuery, journalEntriesComparators), nil
+}
+
+// isInCategory returns true if journal entry belongs to the category or to one of its subcategories.
+func isInCategory(entry *JournalEntry, category string) bool {
+	if entry.Category == category {
+		return true
+	}
+	path := strings.Join(entry.CategoryPath, CategorySeparator)
+	return strings.HasPrefix(path, category+CategorySeparator)
+}
+
+// APIGroupTotal is a total of a group of journal entries.
+type APIGroupTotal struct {
+	Name         string   `json:"name"`
+	Total        apiMoney `json:"total"`
+	EntriesCount int      `json:"entriesCount"`
+}
+
+// APIIntervalStatistic is a statistic for one period (month by default) in one currency.
+type APIIntervalStatistic struct {
+	Name         string          `json:"name"`
+	Currency     string          `json:"currency"`
+	Start        string          `json:"start"`
+	End          string          `json:"end"`
+	TotalIncome  apiMoney        `json:"totalIncome"`
+	TotalExpense apiMoney        `json:"totalExpense"`
+	Income       []APIGroupTotal `json:"income"`
+	Expense      []APIGroupTotal `json:"expense"`
+	Transfers    []APIGroupTotal `json:"transfers"`
+	// IncomeTree and ExpenseTree contain hierarchy of categories with rolled up totals.
+	IncomeTree  []APICategoryNode `json:"incomeTree"`
+	ExpenseTree []APICategoryNode `json:"expenseTree"`
+	Budgets     []APIBudget       `json:"budgets"`
+	// Anomalies and TransactionAnomalies contain unusual spending comparing with previous months.
+	Anomalies            []APICategoryAnomaly    `json:"anomalies"`
+	TransactionAnomalies []APITransactionAnomaly `json:"transactionAnomalies"`
+}
+
+// APIBudget is a progress of the budget in the month.
+type APIBudget struct {
+	Name       string   `json:"name"`
+	Limit      apiMoney `json:"limit"`
+	RolledOver apiMoney `json:"rolledOver"`
+	Available  apiMoney `json:"available"`
+	Actual     apiMoney `json:"actual"`
+	IsExceeded bool     `json:"isExceeded"`
+}
+
+func newAPIBudgets(budgets []*BudgetStatistic) []APIBudget {
+	result := make([]APIBudget, 0, len(budgets))
+	for _, budget := range budgets {
+		result = append(result, APIBudget{
+			Name:       budget.Name,
+			Limit:      apiMoney(budget.Limit),
+			RolledOver: apiMoney(budget.RolledOver),
+			Available:  apiMoney(budget.Available),
+			Actual:     apiMoney(budget.Actual),
+			IsExceeded: budget.IsExceeded,
+		})
+	}
+	return result
+}
+
+// APICategoryAnomaly is an expense group with unusual total in the month.
+type APICategoryAnomaly struct {
+	Name   string   `json:"name"`
+	Actual apiMoney `json:"actual"`
+	Median apiMoney `json:"median"`
+	Score  float64  `json:"score"`
+}
+
+// APITransactionAnomaly is an expense unusually large for its group.
+type APITransactionAnomaly struct {
+	Group  string          `json:"group"`
+	Entry  APIJournalEntry `json:"entry"`
+	Amount apiMoney        `json:"amount"`
+	Median apiMoney        `json:"median"`
+	Score  float64         `json:"score"`
+}
+
+func newAPIAnomalies(categories []*CategoryAnomaly, transactions []*TransactionAnomaly) ([]APICategoryAnomaly, []APITransactionAnomaly) {
+	categoryAnomalies := make([]APICategoryAnomaly, 0, len(categories))
+	for _, anomaly := range categories {
+		categoryAnomalies = append(categoryAnomalies, APICategoryAnomaly{
+			Name:   anomaly.Name,
+			Actual: apiMoney(anomaly.Actual),
+			Median: apiMoney(anomaly.Median),
+			Score:  anomaly.Score,
+		})
+	}
+	transactionAnomalies := make([]APITransactionAnomaly, 0, len(transactions))
+	for _, anomaly := range transactions {
+		transactionAnomalies = append(transactionAnomalies, APITransactionAnomaly{
+			Group:  anomaly.Group,
+			Entry:  newAPIJournalEntry(&anomaly.Entry),
+			Amount: apiMoney(anomaly.Amount),
+			Median: apiMoney(anomaly.Median),
+			Score:  anomaly.Score,
+		})
+	}
+	return categoryAnomalies, transactionAnomalies
+}
+
+// APICategoryNode is a category with total of it and all its subcategories.
+type APICategoryNode struct {
+	Name     string            `json:"name"`
+	Path     string            `json:"path"`
+	Total    apiMoney          `json:"total"`
+	Children []APICategoryNode `json:"children,omitempty"`
+}
+
+func newAPICategoryNodes(nodes []*CategoryNode) []APICategoryNode {
+	result := make([]APICategoryNode, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, APICategoryNode{
+			Name:     node.Name,
+			Path:     node.Path,
+			Total:    apiMoney(node.Total),
+			Children: newAPICategoryNodes(node.Children),
+		})
+	}
+	return result
+}
+
+// newAPIGroupTotals converts groups into list sorted by name. Returns total amount of groups as well.
+func newAPIGroupTotals(groups map[string]*Group) ([]APIGroupTotal, apiMoney) {
+	result := make([]APIGroupTotal, 0, len(groups))
+	total := 0
+	for _, group := range groups {
+		result = append(result, APIGroupTotal{
+			Name:         group.Name,
+			Total:        apiMoney(group.Total),
+			EntriesCount: len(group.JournalEntries),
+		})
+		total += group.Total.int
+	}
+	slices.SortFunc(result, func(a, b APIGroupTotal) int { return cmp.Compare(a.Name, b.Name) })
+	return result, apiMoney{int: total}
+}
+
+var monthlyStatisticsComparators = withTieBreaker(map[string]func(a, b APIIntervalStatistic) int{
+	"start": func(a, b APIIntervalStatistic) int {
+		if result := cmp.Compare(a.Start, b.Start); result != 0 {
+			return result
+		}
+		return cmp.Compare(a.Currency, b.Currency)
+	},
+	"totalIncome": func(a, b APIIntervalStatistic) int {
+		return cmp.Compare(a.TotalIncome.int, b.TotalIncome.int)
+	},
+	"totalExpense": func(a, b APIIntervalStatistic) int {
+		return cmp.Compare(a.TotalExpense.int, b.TotalExpense.int)
+	},
+}, func(a, b APIIntervalStatistic) int {
+	return firstNonZero(cmp.Compare(a.Start, b.Start), cmp.Compare(a.Currency, b.Currency))
+})
+
+// apiMonthlyStatistics returns statistics per period and currency filtered by `currency`
+// and `from`, `to` dates of period start. Type of periods is set by `period`, configured one by default.
+func apiMonthlyStatistics(snapshot *DataSnapshot, query url.Values) (interface{}, error) {
+	listQuery, err := parseAPIListQuery(query, sortFieldsOf("start", monthlyStatisticsComparators))
+	if err != nil {
+		return nil, err
+	}
+	from, err := parseAPIDate(query, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseAPIDate(query, "to")
+	if err != nil {
+		return nil, err
+	}
+	currency := query.Get("currency")
+	periodType, err := ParsePeriodType(query.Get("period"))
+	if err != nil {
+		return nil, newBadRequestError("%s", err)
+	}
+
+	statistics, err := snapshot.GetPeriodStatistics(periodType)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]APIIntervalStatistic, 0)
+	for _, month := range statistics {
+		for _, stat := range month {
+			start := stat.Start.Format(OutputDateFormat)
+			if (currency != "" && stat.Currency != currency) || !isDateInRange(start, from, to) {
+				continue
+			}
+			item := APIIntervalStatistic{
+				Name:     stat.Name,
+				Currency: stat.Currency,
+				Start:    start,
+				End:      stat.End.Format(OutputDateFormat),
+			}
+			item.Income, item.TotalIncome = newAPIGroupTotals(stat.Income)
+			item.Expense, item.TotalExpense = newAPIGroupTotals(stat.Expense)
+			item.Transfers, _ = newAPIGroupTotals(stat.Transfers)
+			item.IncomeTree = newAPICategoryNodes(stat.IncomeTree)
+			item.ExpenseTree = newAPICategoryNodes(stat.ExpenseTree)
+			item.Budgets = newAPIBudgets(stat.Budgets)
+			item.Anomalies, item.TransactionAnomalies = newAPIAnomalies(stat.Anomalies, stat.TransactionAnomalies)
+			items = append(items, item)
+		}
+	}
+	return sortAndPaginate(items, listQuery, monthlyStatisticsComparators), nil
+}
+
+// APIAccount is an account found in transactions.
+type APIAccount struct {
+	Number string `json:"number"`
+	// IsMyAccount is true for accounts which statements are parsed.
+	IsMyAccount bool   `json:"isMyAccount"`
+	SourceType  string `json:"sourceType"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Occurrences int    `json:"occurrences"`
+}
+
+var accountsComparators = withTieBreaker(map[string]func(a, b APIAccount) int{
+	"number":      func(a, b APIAccount) int { return cmp.Compare(a.Number, b.Number) },
+	"occurrences": func(a, b APIAccount) int { return cmp.Compare(a.Occurrences, b.Occurrences) },
+	"from":        func(a, b APIAccount) int { return cmp.Compare(a.From, b.From) },
+	"to":          func(a, b APIAccount) int { return cmp.Compare(a.To, b.To) },
+}, func(a, b APIAccount) int { return cmp.Compare(a.Number, b.Number) })
+
+// apiAccounts returns accounts, optionally only mine with `mine=true`.
+func apiAccounts(snapshot *DataSnapshot, query url.Values) (interface{}, error) {
+	listQuery, err := parseAPIListQuery(query, sortFieldsOf("number", accountsComparators))
+	if err != nil {
+		return nil, err
+	}
+	isOnlyMine := query.Get("mine") == "true"
+	items := make([]APIAccount, 0, len(snapshot.DataMart.Accounts))
+	for _, account := range snapshot.DataMart.Accounts {
+		if isOnlyMine && !account.IsTransactionAccount {
+			continue
+		}
+		item := APIAccount{
+			Number:      account.Number,
+			IsMyAccount: account.IsTransactionAccount,
+			From:        account.From.Format(OutputDateFormat),
+			To:          account.To.Format(OutputDateFormat),
+			Occurrences: account.OccurencesInTransactions,
+		}
+		if account.Source != nil {
+			item.SourceType = account.Source.TypeName
+		}
+		items = append(items, item)
+	}
+	return sortAndPaginate(items, listQuery, accountsComparators), nil
+}
+
+// APICurrency is a currency found in transactions.
+type APICurrency struct {
+	Name string `json:"name"`
+	// IsConvertible is true if amounts in other currencies can be converted into this one.
+	IsConvertible      bool     `json:"isConvertible"`
+	From               string   `json:"from"`
+	To                 string   `json:"to"`
+	Occurrences        int      `json:"occurrences"`
+	TotalAmount        apiMoney `json:"totalAmount"`
+	Sources            []string `json:"sources"`
+	ExchangeRatesCount int      `json:"exchangeRatesCount"`
+}
+
+var currenciesComparators = withTieBreaker(map[string]func(a, b APICurrency) int{
+	"name":        func(a, b APICurrency) int { return cmp.Compare(a.Name, b.Name) },
+	"occurrences": func(a, b APICurrency) int { return cmp.Compare(a.Occurrences, b.Occurrences) },
+}, func(a, b APICurrency) int { return cmp.Compare(a.Name, b.Name) })
+
+func apiCurrencies(snapshot *DataSnapshot, query url.Values) (interface{}, error) {
+	listQuery, err := parseAPIListQuery(query, sortFieldsOf("name", currenciesComparators))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]APICurrency, 0, len(snapshot.DataMart.AllCurrencies))
+	for name, currency := range snapshot.DataMart.AllCurrencies {
+		sources := make([]string, 0, len(currency.MetInSources))
+		for source := range currency.MetInSources {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+		_, isConvertible := snapshot.DataMart.ConvertibleCurrencies[name]
+		items = append(items, APICurrency{
+			Name:               name,
+			IsConvertible:      isConvertible,
+			From:               currency.From.Format(OutputDateFormat),
+			To:                 currency.To.Format(OutputDateFormat),
+			Occurrences:        currency.MetTimes,
+			TotalAmount:        apiMoney(currency.TotalAmount),
+			Sources:            sources,
+			ExchangeRatesCount: len(currency.ExchangeRates),
+		})
+	}
+	return sortAndPaginate(items, listQuery, currenciesComparators), nil
+}
+
+// APIExchangeRate is an exchange rate found in transactions or configuration.
+type APIExchangeRate struct {
+	Date         string  `json:"date"`
+	CurrencyFrom string  `json:"currencyFrom"`
+	CurrencyTo   string  `json:"currencyTo"`
+	Rate         float64 `json:"rate"`
+	SourceFile   string  `json:"sourceFile"`
+}
+
+var exchangeRatesComparators = withTieBreaker(map[string]func(a, b APIExchangeRate) int{
+	"date": func(a, b APIExchangeRate) int {
+		if result := cmp.Compare(a.Date, b.Date); result != 0 {
+			return result
+		}
+		if result := cmp.Compare(a.CurrencyFrom, b.CurrencyFrom); result != 0 {
+			return result
+		}
+		return cmp.Compare(a.CurrencyTo, b.CurrencyTo)
+	},
+	"rate": func(a, b APIExchangeRate) int { return cmp.Compare(a.Rate, b.Rate) },
+}, func(a, b APIExchangeRate) int {
+	return firstNonZero(
+		cmp.Compare(a.Date, b.Date),
+		cmp.Compare(a.CurrencyFrom, b.CurrencyFrom),
+		cmp.Compare(a.CurrencyTo, b.CurrencyTo),
+		cmp.Compare(a.Rate, b.Rate),
+		cmp.Compare(a.SourceFile, b.SourceFile),
+	)
+})
+
+// apiExchangeRates returns exchange rates filtered by `currency` (either side) and `from`, `to` dates.
+func apiExchangeRates(snapshot *DataSnapshot, query url.Values) (interface{}, error) {
+	listQuery, err := parseAPIListQuery(query, sortFieldsOf("date", exchangeRatesComparators))
+	if err != nil {
+		return nil, err
+	}
+	from, err := parseAPIDate(query, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseAPIDate(query, "to")
+	if err != nil {
+		return nil, err
+	}
+	currency := query.Get("currency")
+
+	// The same exchange rate is referenced by both currencies.
+	seen := make(map[*ExchangeRate]struct{})
+	items := make([]APIExchangeRate, 0)
+	for _, currencyStatistics := range snapshot.DataMart.AllCurrencies {
+		for _, rate := range currencyStatistics.ExchangeRates {
+			if _, ok := seen[rate]; ok {
+				continue
+			}
+			seen[rate] = struct{}{}
+			date := rate.date.Format(OutputDateFormat)
+			if (currency != "" && rate.currencyFrom != currency && rate.currencyTo != currency) ||
+				!isDateInRange(date, from, to) {
+				continue
+			}
+			item := APIExchangeRate{
+				Date:         date,
+				CurrencyFrom: rate.currencyFrom,
+				CurrencyTo:   rate.currencyTo,
+				Rate:         rate.exchangeRate,
+			}
+			if rate.source != nil {
+				item.SourceFile = rate.source.FilePath
+			}
+			items = append(items, item)
+		}
+	}
+	return sortAndPaginate(items, listQuery, exchangeRatesComparators), nil
+}
+
+// APIFile is a parsed file with transactions.
+type APIFile struct {
+	Path              string    `json:"path"`
+	SourceType        string    `json:"sourceType"`
+	AccountNumber     string    `json:"accountNumber"`
+	TransactionsCount int       `json:"transactionsCount"`
+	ModifiedTime      time.Time `json:"modifiedTime"`
+	FromDate          string    `json:"fromDate"`
+	ToDate            string    `json:"toDate"`
+}
+
+var filesComparators = withTieBreaker(map[string]func(a, b APIFile) int{
+	"path":         func(a, b APIFile) int { return cmp.Compare(a.Path, b.Path) },
+	"modifiedTime": func(a, b APIFile) int { return a.ModifiedTime.Compare(b.ModifiedTime) },
+	"fromDate":     func(a, b APIFile) int { return cmp.Compare(a.FromDate, b.FromDate) },
+	"toDate":       func(a, b APIFile) int { return cmp.Compare(a.ToDate, b.ToDate) },
+}, func(a, b APIFile) int {
+	// File may contain statements of several accounts.
+	return firstNonZero(cmp.Compare(a.Path, b.Path), cmp.Compare(a.AccountNumber, b.AccountNumber))
+})
+
+func apiFiles(snapshot *DataSnapshot, query url.Values) (interface{}, error) {
+	listQuery, err := parseAPIListQuery(query, sortFieldsOf("path", filesComparators))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]APIFile, 0, len(snapshot.FileInfos))
+	for _, fileInfo := range snapshot.FileInfos {
+		item := APIFile{
+			Path:              fileInfo.Path,
+			AccountNumber:     fileInfo.AccountNumber,
+			TransactionsCount: fileInfo.TransactionsCount,
+			ModifiedTime:      fileInfo.ModifiedTime,
+			FromDate:          fileInfo.FromDate.Format(OutputDateFormat),
+			ToDate:            fileInfo.ToDate.Format(OutputDateFormat),
+		}
+		if fileInfo.Source != nil {
+			item.SourceType = fileInfo.Source.TypeName
+		}
+		items = append(items, item)
+	}
+	return sortAndPaginate(items, listQuery, filesComparators), nil
+}
+
+// APIGroup is a group of transactions from configuration.
+type APIGroup struct {
+	Name         string       `json:"name"`
+	Substrings   []string     `json:"substrings"`
+	FromAccounts []string     `json:"fromAccounts"`
+	ToAccounts   []string     `json:"toAccounts"`
+	Rules        []RuleConfig `json:"rules,omitempty"`
+}
+
+var groupsComparators = withTieBreaker(map[string]func(a, b APIGroup) int{
+	"name": func(a, b APIGroup) int { return cmp.Compare(a.Name, b.Name) },
+}, func(a, b APIGroup) int { return cmp.Compare(a.Name, b.Name) })
+
+func apiGroups(snapshot *DataSnapshot, query url.Values) (interface{}, error) {
+	listQuery, err := parseAPIListQuery(query, sortFieldsOf("name", groupsComparators))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]APIGroup, 0, len(snapshot.Config.Groups))
+	for name, group := range snapshot.Config.Groups {
+		items = append(items, APIGroup{
+			Name:         name,
+			Substrings:   emptyIfNil(group.Substrings),
+			FromAccounts: emptyIfNil(group.FromAccounts),
+			ToAccounts:   emptyIfNil(group.ToAccounts),
+			Rules:        group.Rules,
+		})
+	}
+	return sortAndPaginate(items, listQuery, groupsComparators), nil
+}
+
+// emptyIfNil returns empty slice instead of nil to have `[]` instead of `null` in JSON.
+func emptyIfNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
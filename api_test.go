@@ -0,0 +1,378 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApiMoney_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int
+		expected string
+	}{
+		{"zero", 0, "0.00"},
+		{"cents", 50, "0.05"},
+		{"big", 1234567890, "1234567.89"},
+		{"negative", -123450, "-123.45"},
+		{"negative_cents", -50, "-0.05"},
+		{"thousandths", 1234, "1.234"},
+		{"negative_thousandths", -5, "-0.005"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			actual, err := json.Marshal(apiMoney{int: tt.amount})
+
+			// Assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSortAndPaginate(t *testing.T) {
+	comparators := map[string]func(a, b APIGroup) int{
+		"name": groupsComparators["name"],
+	}
+	tests := []struct {
+		name     string
+		query    string
+		expected apiPage[APIGroup]
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			expected: apiPage[APIGroup]{
+				Items: []APIGroup{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+				Total: 3, Offset: 0, Limit: apiDefaultLimit,
+			},
+		},
+		{
+			name:  "descending_page",
+			query: "sort=-name&offset=1&limit=1",
+			expected: apiPage[APIGroup]{
+				Items: []APIGroup{{Name: "b"}},
+				Total: 3, Offset: 1, Limit: 1,
+			},
+		},
+		{
+			name:  "offset_after_end",
+			query: "offset=10",
+			expected: apiPage[APIGroup]{
+				Items: []APIGroup{},
+				Total: 3, Offset: 10, Limit: apiDefaultLimit,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Arrange
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			listQuery, err := parseAPIListQuery(values, sortFieldsOf("name", comparators))
+			if err != nil {
+				t.Fatal(err)
+			}
+			items := []APIGroup{{Name: "b"}, {Name: "c"}, {Name: "a"}}
+
+			// Act
+			actual := sortAndPaginate(items, listQuery, comparators)
+
+			// Assert
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Errorf("page mismatch (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSortAndPaginate_TiesAreOrderedByKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{"ascending", "sort=occurrences", []string{"a", "b", "c", "d"}},
+		{"descending", "sort=-occurrences", []string{"d", "c", "b", "a"}},
+		{"page", "sort=occurrences&offset=1&limit=2", []string{"b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Arrange
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			listQuery, err := parseAPIListQuery(values, sortFieldsOf("number", accountsComparators))
+			if err != nil {
+				t.Fatal(err)
+			}
+			items := []APIAccount{{Number: "c"}, {Number: "a"}, {Number: "d"}, {Number: "b"}}
+
+			// Act
+			actual := sortAndPaginate(items, listQuery, accountsComparators)
+
+			// Assert
+			numbers := make([]string, 0, len(actual.Items))
+			for _, item := range actual.Items {
+				numbers = append(numbers, item.Number)
+			}
+			if diff := cmp.Diff(tt.expected, numbers); diff != "" {
+				t.Errorf("order mismatch (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+// apiTestPage is a page of API response with not parsed items.
+type apiTestPage struct {
+	Items  []map[string]interface{} `json:"items"`
+	Total  int                      `json:"total"`
+	Offset int                      `json:"offset"`
+	Limit  int                      `json:"limit"`
+}
+
+func TestAPI_Endpoints(t *testing.T) {
+	dataHandler := newTestDataHandler(t)
+	server := httptest.NewServer(newServeMux(dataHandler, NewEventsBroker()))
+	defer server.Close()
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		// check validates items of successful responses.
+		check func(t *testing.T, page apiTestPage)
+	}{
+		{
+			name:           "journal_entries_sorted_by_date",
+			path:           "/journal-entries?limit=1000",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, page apiTestPage) {
+				if page.Total == 0 || len(page.Items) != page.Total {
+					t.Errorf("expected all entries on one page, got %d of %d", len(page.Items), page.Total)
+				}
+				for i := 1; i < len(page.Items); i++ {
+					if page.Items[i-1]["date"].(string) > page.Items[i]["date"].(string) {
+						t.Errorf("entries are not sorted by date: %v", page.Items[i])
+					}
+				}
+			},
+		},
+		{
+			name:           "journal_entries_filtered",
+			path:           "/journal-entries?type=expense&currency=AMD&from=2024-06-01&to=2024-06-30&sort=-amount&limit=5",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, page apiTestPage) {
+				if len(page.Items) == 0 || len(page.Items) > 5 {
+					t.Errorf("expected from 1 to 5 entries, got %d", len(page.Items))
+				}
+				for i, item := range page.Items {
+					date := item["date"].(string)
+					if item["type"] != "expense" || date < "2024-06-01" || date > "2024-06-30" {
+						t.Errorf("entry doesn't match filters: %v", item)
+					}
+					if item["accountCurrency"] != "AMD" && item["originCurrency"] != "AMD" {
+						t.Errorf("entry doesn't match currency filter: %v", item)
+					}
+					if i > 0 && page.Items[i-1]["accountCurrencyAmount"].(float64) < item["accountCurrencyAmount"].(float64) {
+						t.Errorf("entries are not sorted by amount descending: %v", item)
+					}
+				}
+			},
+		},
+		{
+			name:           "monthly_statistics",
+			path:           "/statistics/monthly?currency=AMD",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, page apiTestPage) {
+				if page.Total == 0 {
+					t.Errorf("expected statistics")
+				}
+				for _, item := range page.Items {
+					if item["currency"] != "AMD" {
+						t.Errorf("statistic doesn't match currency filter: %v", item)
+					}
+				}
+			},
+		},
+		{
+			name:           "yearly_statistics",
+			path:           "/statistics/monthly?period=year",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, page apiTestPage) {
+				for _, item := range page.Items {
+					if name, _ := item["name"].(string); len(name) != 4 || !strings.HasSuffix(item["start"].(string), "-01-01") {
+						t.Errorf("expected yearly statistic, got %v", item)
+					}
+				}
+			},
+		},
+		{
+			name:           "wrong_period",
+			path:           "/statistics/monthly?period=decade",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "my_accounts",
+			path:           "/accounts?mine=true",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, page apiTestPage) {
+				if page.Total == 0 {
+					t.Errorf("expected accounts")
+				}
+				for _, item := range page.Items {
+					if item["isMyAccount"] != true {
+						t.Errorf("account doesn't match filter: %v", item)
+					}
+				}
+			},
+		},
+		{
+			name:           "currencies",
+			path:           "/currencies",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, page apiTestPage) {
+				if page.Total == 0 || page.Items[0]["name"] == "" {
+					t.Errorf("expected currencies, got %v", page.Items)
+				}
+			},
+		},
+		{
+			name:           "exchange_rates",
+			path:           "/exchange-rates?sort=-date",
+			expectedStatus: http.StatusOK,
+			check:          func(t *testing.T, page apiTestPage) {},
+		},
+		{
+			name:           "files",
+			path:           "/files",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, page apiTestPage) {
+				if page.Total != 2 {
+					t.Errorf("expected 2 files, got %v", page.Items)
+				}
+			},
+		},
+		{
+			name:           "groups",
+			path:           "/groups",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, page apiTestPage) {
+				if page.Total != 1 || page.Items[0]["name"] != "Food" {
+					t.Errorf("expected Food group, got %v", page.Items)
+				}
+			},
+		},
+		{
+			name:           "wrong_sort",
+			path:           "/journal-entries?sort=unknown",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "wrong_date",
+			path:           "/journal-entries?from=01.06.2024",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "wrong_limit",
+			path:           "/accounts?limit=100500",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown_endpoint",
+			path:           "/unknown",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			response, err := server.Client().Get(server.URL + APIPrefix + tt.path)
+
+			// Assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer response.Body.Close()
+			if response.StatusCode != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, response.StatusCode)
+			}
+			if tt.check == nil {
+				var body struct {
+					Error string `json:"error"`
+				}
+				if err := json.NewDecoder(response.Body).Decode(&body); err != nil || body.Error == "" {
+					t.Errorf("expected error message in response, got %v", err)
+				}
+				return
+			}
+			var page apiTestPage
+			if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, page)
+		})
+	}
+}
+
+func TestAPI_MethodNotAllowed(t *testing.T) {
+	// Arrange
+	dataHandler := newTestDataHandler(t)
+	server := httptest.NewServer(newServeMux(dataHandler, NewEventsBroker()))
+	defer server.Close()
+
+	// Act
+	response, err := server.Client().Post(server.URL+APIPrefix+"/groups", "application/json", nil)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, response.StatusCode)
+	}
+}
+
+func TestIsInCategory(t *testing.T) {
+	entry := &JournalEntry{Category: "Pizza", CategoryPath: []string{"Food", "Restaurants", "Pizza"}}
+	tests := []struct {
+		category string
+		expected bool
+	}{
+		{"Pizza", true},
+		{"Food", true},
+		{"Food:Restaurants", true},
+		{"Foo", false},
+		{"Restaurants", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+
+			// Act
+			actual := isInCategory(entry, tt.category)
+
+			// Assert
+			if actual != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
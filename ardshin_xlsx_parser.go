@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 	"strings"
-	"time"
 
 	"github.com/tealeg/xlsx"
 )
@@ -19,6 +18,26 @@ const (
 	ardshinXlsxHeaders2String              = "DateAmountCurrencyCreditsDebits"
 )
 
+const (
+	// ArdshinXlsxParserName is a name of parser to use in `sources` configuration.
+	ArdshinXlsxParserName = "ardshinXlsx"
+	ardshinXlsxTypeName   = "Ardshin XLS statement"
+)
+
+func init() {
+	RegisterParser(ParserRegistration{
+		Name:        ArdshinXlsxParserName,
+		TypeName:    ardshinXlsxTypeName,
+		Tag:         "ArdshinXlsx",
+		Version:     2,
+		DefaultGlob: "STATEMENT_*.xlsx",
+		NewParser:   newParserWithoutOptions(ArdshinXlsxFileParser{}),
+		Detect: func(sniff *FileSniff) bool {
+			return sniff.Container == FileContainerXlsx && sniff.HasRow(ardshinXlsxHeaders1String)
+		},
+	})
+}
+
 type ArdshinXlsxFileParser struct {
 }
 
@@ -115,7 +134,7 @@ func (p ArdshinXlsxFileParser) ParseRawTransactionsFromFile(
 				if isHeader2RowFound {
 					// Build source.
 					source = TransactionsSource{
-						TypeName:        "Ardshin XLS statement",
+						TypeName:        ardshinXlsxTypeName,
 						Tag:             "ArdshinXlsx:" + accountCurrency,
 						FilePath:        filePath,
 						AccountNumber:   accountNumber,
@@ -145,7 +164,7 @@ func (p ArdshinXlsxFileParser) ParseRawTransactionsFromFile(
 			Details:         cells[14].String(),
 		}
 		// Parse transaction date.
-		date, err := time.Parse(ardshinXlsxDateFormat, cells[0].String())
+		date, err := parseDate(cells[0].String(), ardshinXlsxDateFormat)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse date from 1st cell of %d row: %w", i, err)
 		}
@@ -174,7 +193,7 @@ func (p ArdshinXlsxFileParser) ParseRawTransactionsFromFile(
 		// Check we have amount in original currency.
 		currencyCellValue := cells[2].String()
 		if currencyCellValue != accountCurrency {
-			originCurAmount := MoneyWith2DecimalPlaces{}
+			originCurAmount := Money{}
 			err = originCurAmount.ParseString(cells[1].String())
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse amount from cell 2 of %d row: %w", i+1, err)
@@ -31,73 +31,73 @@ func TestArdshinXlsxFileParser_ParseRawTransactionsFromFile_Valid(t *testing.T)
 		{
 			Date:                 time.Date(2025, time.August, 15, 0, 0, 0, 0, time.UTC),
 			IsExpense:            false,
-			Amount:               MoneyWith2DecimalPlaces{int: 12300},
+			Amount:               Money{int: 123000},
 			FromAccount:          "2470087380460000",
 			ToAccount:            accountNumber,
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "MCDONALDS AM LLC 4454********1234 Payment order",
 		},
 		{
 			Date:                 time.Date(2025, time.August, 14, 0, 0, 0, 0, time.UTC),
 			IsExpense:            true,
-			Amount:               MoneyWith2DecimalPlaces{int: 10800000},
+			Amount:               Money{int: 108000000},
 			FromAccount:          accountNumber,
 			ToAccount:            "2470010211270000",
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "*ARCA 4454********1234 Amount transfer from card to card",
 		},
 		{
 			Date:                 time.Date(2025, time.August, 14, 0, 0, 0, 0, time.UTC),
 			IsExpense:            true,
-			Amount:               MoneyWith2DecimalPlaces{int: 54000},
+			Amount:               Money{int: 540000},
 			FromAccount:          accountNumber,
 			ToAccount:            "2470010211270000",
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "*ARCA 4454********1234 Amount transfer from card to card",
 		},
 		{
 			Date:                 time.Date(2025, time.August, 15, 0, 0, 0, 0, time.UTC),
 			IsExpense:            true,
-			Amount:               MoneyWith2DecimalPlaces{int: 500000},
+			Amount:               Money{int: 5000000},
 			FromAccount:          accountNumber,
 			ToAccount:            "2470023040920000",
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "*Yukom  PBE 4454********1234 Utility payment (B-C online)",
 		},
 		{
 			Date:                 time.Date(2025, time.August, 16, 0, 0, 0, 0, time.UTC),
 			IsExpense:            true,
-			Amount:               MoneyWith2DecimalPlaces{int: 404910},
+			Amount:               Money{int: 4049100},
 			FromAccount:          accountNumber,
 			ToAccount:            "2470000348080010",
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "USD",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 1049},
+			OriginCurrencyAmount: Money{int: 10490},
 			Details:              "*Visa Int. partav.  AMN dolarov 4454********1234 \\840\\123-567-8910\\WL *STEAM PUR",
 		},
 		{
 			Date:                 time.Date(2025, time.August, 18, 0, 0, 0, 0, time.UTC),
 			IsExpense:            true,
-			Amount:               MoneyWith2DecimalPlaces{int: 681000},
+			Amount:               Money{int: 6810000},
 			FromAccount:          accountNumber,
 			ToAccount:            "2470010211270000",
 			Source:               source,
 			AccountCurrency:      accountCurrency,
 			OriginCurrency:       "",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 0},
+			OriginCurrencyAmount: Money{int: 0},
 			Details:              "*ARCA 4454********1234 21016919\\AM\\YEREVAN\\ZOVQ BAR",
 		},
 	}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// DailyBalance is a balance of the account at the end of the day.
+type DailyBalance struct {
+	// Date is a start of the day.
+	Date time.Time
+	// Amount is a balance in the account currency.
+	Amount Money
+}
+
+// BalanceReconciliation is a check that opening balance of the statement plus transactions
+// during the statement period equals to closing balance of the statement.
+type BalanceReconciliation struct {
+	// Source is a statement with both opening and closing balances.
+	Source *TransactionsSource
+	// Calculated is an opening balance plus all transactions of the account during the statement period.
+	Calculated Money
+}
+
+// To returns the last day of the statement period.
+func (r *BalanceReconciliation) To() time.Time {
+	return r.Source.ClosingBalance.Date.AddDate(0, 0, -1)
+}
+
+// Difference returns closing balance of the statement minus calculated one.
+func (r *BalanceReconciliation) Difference() Money {
+	return Money{int: r.Source.ClosingBalance.Amount.int - r.Calculated.int}
+}
+
+// IsReconciled returns true if calculated balance equals to closing balance of the statement.
+func (r *BalanceReconciliation) IsReconciled() bool {
+	return r.Difference().int == 0
+}
+
+// AccountBalances contains balances of one own account reconstructed from statements and transactions.
+type AccountBalances struct {
+	// Account is an account number.
+	Account string
+	// Currency is a currency of the account.
+	Currency string
+	// Source is the latest statement of the account. Used to name the account.
+	Source *TransactionsSource
+	// Records are unique balances provided by statements, sorted by date.
+	Records []BalanceRecord
+	// Daily are balances at the end of each day from the first transaction or balance to the last one.
+	Daily []DailyBalance
+	// Reconciliations are checks of statements with both opening and closing balances.
+	Reconciliations []*BalanceReconciliation
+}
+
+// Last returns the latest known balance of the account.
+func (a *AccountBalances) Last() DailyBalance {
+	return a.Daily[len(a.Daily)-1]
+}
+
+// Mismatches returns reconciliations which failed.
+func (a *AccountBalances) Mismatches() []*BalanceReconciliation {
+	result := make([]*BalanceReconciliation, 0)
+	for _, reconciliation := range a.Reconciliations {
+		if !reconciliation.IsReconciled() {
+			result = append(result, reconciliation)
+		}
+	}
+	return result
+}
+
+// NetWorthPoint is a sum of balances of all own accounts at the end of the day.
+type NetWorthPoint struct {
+	// Date is a start of the day.
+	Date time.Time
+	// Amounts are sums of balances converted to each of convertible currencies.
+	// Currencies which some balance can't be converted to on this day are missing.
+	Amounts map[string]Money
+}
+
+// Balances contains balances of own accounts and net worth timeline.
+type Balances struct {
+	// Accounts are balances per account and currency sorted by account number and currency.
+	Accounts []*AccountBalances
+	// NetWorth is a daily net worth timeline.
+	NetWorth []NetWorthPoint
+	// Currencies are sorted currencies of net worth amounts.
+	Currencies []string
+}
+
+// balanceDay returns a start of the day of the date in UTC.
+func balanceDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// BuildBalances reconstructs daily balances of accounts which statements provide balances for.
+// Balances are anchored to the latest balance record of the account and calculated back and forth
+// by transactions, so transactions missed in files are visible as reconciliation mismatches.
+// Multi-currency accounts get separate balances per currency.
+// Net worth is calculated for all convertible currencies.
+func BuildBalances(dataMart *DataMart) *Balances {
+	// Collect transactions and statements per account and currency.
+	type accountKey struct {
+		number   string
+		currency string
+	}
+	type accountData struct {
+		balances *AccountBalances
+		deltas   map[time.Time]int
+		sources  []*TransactionsSource
+		firstDay time.Time
+		lastDay  time.Time
+	}
+	accounts := make(map[accountKey]*accountData)
+	for _, t := range dataMart.SortedTransactions {
+		if t.Source == nil || t.Source.AccountNumber == "" {
+			continue
+		}
+		currency := t.Source.AccountCurrency
+		if currency == "" {
+			currency = t.AccountCurrency
+		}
+		key := accountKey{number: t.Source.AccountNumber, currency: currency}
+		data, ok := accounts[key]
+		if !ok {
+			data = &accountData{
+				balances: &AccountBalances{Account: t.Source.AccountNumber, Currency: currency},
+				deltas:   make(map[time.Time]int),
+			}
+			accounts[key] = data
+		}
+		isKnownSource := false
+		for _, source := range data.sources {
+			if source == t.Source {
+				isKnownSource = true
+				break
+			}
+		}
+		if !isKnownSource {
+			data.sources = append(data.sources, t.Source)
+		}
+		if t.Amount.int == 0 {
+			continue
+		}
+		day := balanceDay(t.Date)
+		if t.IsExpense {
+			data.deltas[day] -= t.Amount.int
+		} else {
+			data.deltas[day] += t.Amount.int
+		}
+		if data.firstDay.IsZero() || day.Before(data.firstDay) {
+			data.firstDay = day
+		}
+		if day.After(data.lastDay) {
+			data.lastDay = day
+		}
+	}
+
+	result := &Balances{Accounts: make([]*AccountBalances, 0)}
+	for _, data := range accounts {
+		account := data.balances
+		// Collect unique balance records from all statements of the account.
+		records := make(map[BalanceRecord]struct{})
+		for _, source := range data.sources {
+			for _, record := range []*BalanceRecord{source.OpeningBalance, source.ClosingBalance} {
+				if record != nil {
+					records[BalanceRecord{Date: balanceDay(record.Date), Amount: record.Amount}] = struct{}{}
+				}
+			}
+			if account.Source == nil || source.ClosingBalance != nil && (account.Source.ClosingBalance == nil ||
+				source.ClosingBalance.Date.After(account.Source.ClosingBalance.Date)) {
+				account.Source = source
+			}
+		}
+		if len(records) == 0 {
+			continue
+		}
+		for record := range records {
+			account.Records = append(account.Records, record)
+		}
+		sort.Slice(account.Records, func(i, j int) bool {
+			if !account.Records[i].Date.Equal(account.Records[j].Date) {
+				return account.Records[i].Date.Before(account.Records[j].Date)
+			}
+			return account.Records[i].Amount.int < account.Records[j].Amount.int
+		})
+
+		// Balance record at the start of the day equals to balance at the end of the previous day.
+		firstDay := account.Records[0].Date.AddDate(0, 0, -1)
+		if !data.firstDay.IsZero() && data.firstDay.Before(firstDay) {
+			firstDay = data.firstDay
+		}
+		lastDay := account.Records[len(account.Records)-1].Date.AddDate(0, 0, -1)
+		if data.lastDay.After(lastDay) {
+			lastDay = data.lastDay
+		}
+		sum := 0
+		for day := firstDay; !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+			sum += data.deltas[day]
+			account.Daily = append(account.Daily, DailyBalance{Date: day, Amount: Money{int: sum}})
+		}
+		anchor := account.Records[len(account.Records)-1]
+		anchorIndex := int(anchor.Date.AddDate(0, 0, -1).Sub(firstDay).Hours() / 24)
+		offset := anchor.Amount.int - account.Daily[anchorIndex].Amount.int
+		for i := range account.Daily {
+			account.Daily[i].Amount.int += offset
+		}
+
+		// Check statements which have both balances.
+		for _, source := range data.sources {
+			if source.OpeningBalance == nil || source.ClosingBalance == nil {
+				continue
+			}
+			calculated := source.OpeningBalance.Amount.int
+			from := balanceDay(source.OpeningBalance.Date)
+			to := balanceDay(source.ClosingBalance.Date)
+			for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+				calculated += data.deltas[day]
+			}
+			account.Reconciliations = append(account.Reconciliations, &BalanceReconciliation{
+				Source:     source,
+				Calculated: Money{int: calculated},
+			})
+		}
+		sort.Slice(account.Reconciliations, func(i, j int) bool {
+			return account.Reconciliations[i].Source.OpeningBalance.Date.Before(account.Reconciliations[j].Source.OpeningBalance.Date)
+		})
+		result.Accounts = append(result.Accounts, account)
+	}
+	sort.Slice(result.Accounts, func(i, j int) bool {
+		if result.Accounts[i].Account != result.Accounts[j].Account {
+			return result.Accounts[i].Account < result.Accounts[j].Account
+		}
+		return result.Accounts[i].Currency < result.Accounts[j].Currency
+	})
+
+	result.NetWorth, result.Currencies = buildNetWorth(result.Accounts, dataMart)
+	return result
+}
+
+// buildNetWorth sums daily balances of accounts converted to each of convertible currencies.
+// Accounts are not counted before their first day and keep the last balance after their last day.
+// If some balance can't be converted to a currency on a day then net worth in this currency
+// is skipped for the day and a warning is logged once per account and currency.
+func buildNetWorth(accounts []*AccountBalances, dataMart *DataMart) ([]NetWorthPoint, []string) {
+	currencies := make([]string, 0, len(dataMart.ConvertibleCurrencies))
+	for currency := range dataMart.ConvertibleCurrencies {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	if len(accounts) == 0 || len(currencies) == 0 {
+		return []NetWorthPoint{}, currencies
+	}
+
+	// Make map of currencyState to convert balances in chronological order.
+	curStates := make(map[string]*currencyState, len(dataMart.AllCurrencies))
+	for currency, statistics := range dataMart.AllCurrencies {
+		curStates[currency] = &currencyState{
+			currency:                       currency,
+			statistics:                     statistics,
+			exchangeRateIndexesPerCurrency: make(map[string]int),
+		}
+	}
+	firstDay := accounts[0].Daily[0].Date
+	lastDay := accounts[0].Last().Date
+	for _, account := range accounts[1:] {
+		if account.Daily[0].Date.Before(firstDay) {
+			firstDay = account.Daily[0].Date
+		}
+		if account.Last().Date.After(lastDay) {
+			lastDay = account.Last().Date
+		}
+	}
+	type warningKey struct {
+		account      string
+		currencyFrom string
+		currencyTo   string
+	}
+	warned := make(map[warningKey]struct{})
+	result := make([]NetWorthPoint, 0)
+	for day := firstDay; !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+		point := NetWorthPoint{Date: day, Amounts: make(map[string]Money, len(currencies))}
+		skipped := make(map[string]struct{})
+		for _, account := range accounts {
+			if day.Before(account.Daily[0].Date) {
+				continue
+			}
+			balance := account.Last().Amount
+			if index := int(day.Sub(account.Daily[0].Date).Hours() / 24); index < len(account.Daily) {
+				balance = account.Daily[index].Amount
+			}
+			if balance.int == 0 {
+				continue
+			}
+			for _, currency := range currencies {
+				converted, precision, _ := convertToCurrency(balance, account.Currency, currency, day, curStates)
+				if precision == math.MaxInt {
+					skipped[currency] = struct{}{}
+					key := warningKey{account: account.Account, currencyFrom: account.Currency, currencyTo: currency}
+					if _, ok := warned[key]; !ok {
+						warned[key] = struct{}{}
+						log.Println(i18n.T("balance of account a in c1 can't be converted to c2 on date",
+							"a", account.Account,
+							"c1", account.Currency,
+							"c2", currency,
+							"date", day.Format(OutputDateFormat),
+						))
+					}
+					continue
+				}
+				amount := point.Amounts[currency]
+				amount.int += converted.int
+				point.Amounts[currency] = amount
+			}
+		}
+		for currency := range skipped {
+			delete(point.Amounts, currency)
+		}
+		result = append(result, point)
+	}
+	return result, currencies
+}
+
+// balancesToString returns human readable latest balances of accounts and failed reconciliations.
+func balancesToString(balances *Balances) string {
+	if balances == nil || len(balances.Accounts) == 0 {
+		return ""
+	}
+	details := make([]string, 0, len(balances.Accounts))
+	nMismatches := 0
+	for _, account := range balances.Accounts {
+		last := account.Last()
+		details = append(details, i18n.T("account balance currency on date",
+			"account", account.Account,
+			"balance", last.Amount.StringNoIndent(),
+			"currency", account.Currency,
+			"date", last.Date.Format(OutputDateFormat),
+		))
+		for _, mismatch := range account.Mismatches() {
+			nMismatches++
+			details = append(details, i18n.T("statement file from to doesn't reconcile: calculated, closing, difference",
+				"file", mismatch.Source.FilePath,
+				"from", mismatch.Source.OpeningBalance.Date.Format(OutputDateFormat),
+				"to", mismatch.To().Format(OutputDateFormat),
+				"calculated", mismatch.Calculated.StringNoIndent(),
+				"closing", mismatch.Source.ClosingBalance.Amount.StringNoIndent(),
+				"difference", mismatch.Difference().StringNoIndent(),
+			))
+		}
+	}
+	return i18n.T("Balances_format",
+		"nAccounts", len(balances.Accounts),
+		"nMismatches", nMismatches,
+		"detailsBalances", details,
+	)
+}
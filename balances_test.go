@@ -0,0 +1,292 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// dailyAmounts returns amounts of daily balances.
+func dailyAmounts(daily []DailyBalance) []int {
+	result := make([]int, 0, len(daily))
+	for _, balance := range daily {
+		result = append(result, balance.Amount.int)
+	}
+	return result
+}
+
+func TestBuildBalances(t *testing.T) {
+	tests := []struct {
+		name                string
+		opening             *int
+		closing             *int
+		expectedFirstDay    time.Time
+		expectedDaily       []int
+		expectedDifferences []int
+	}{
+		{
+			name:                "reconciled",
+			opening:             intPtr(1000000),
+			closing:             intPtr(1300000),
+			expectedFirstDay:    utcDate(2023, 12, 31),
+			expectedDaily:       []int{1000000, 1500000, 1300000, 1300000},
+			expectedDifferences: []int{0},
+		},
+		{
+			name:                "missed_transaction",
+			opening:             intPtr(1000000),
+			closing:             intPtr(1400000),
+			expectedFirstDay:    utcDate(2023, 12, 31),
+			expectedDaily:       []int{1100000, 1600000, 1400000, 1400000},
+			expectedDifferences: []int{100000},
+		},
+		{
+			name:             "opening_only",
+			opening:          intPtr(1000000),
+			expectedFirstDay: utcDate(2023, 12, 31),
+			expectedDaily:    []int{1000000, 1500000, 1300000},
+		},
+		{
+			name:             "closing_only",
+			closing:          intPtr(-100000),
+			expectedFirstDay: utcDate(2024, 1, 1),
+			expectedDaily:    []int{500000, 300000, -100000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			source := newTestSource("amd", "AMD", utcDate(2024, 1, 1), utcDate(2024, 1, 3), tt.opening, tt.closing)
+			noBalancesSource := newTestSource("other", "AMD", utcDate(2024, 1, 1), utcDate(2024, 1, 3), nil, nil)
+			dataMart := &DataMart{
+				SortedTransactions: []Transaction{
+					newTestTransaction(source, utcDate(2024, 1, 1), 500000, "", ""),
+					newTestTransaction(noBalancesSource, utcDate(2024, 1, 1), 700000, "", ""),
+					newTestTransaction(source, time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC), -200000, "", ""),
+				},
+				AllCurrencies:         map[string]*CurrencyStatistics{"AMD": {Name: "AMD"}},
+				ConvertibleCurrencies: map[string]*CurrencyStatistics{"AMD": {Name: "AMD"}},
+			}
+			if tt.opening == nil {
+				// Statement without opening balance has expense which makes balance negative.
+				dataMart.SortedTransactions = append(dataMart.SortedTransactions, newTestTransaction(source, utcDate(2024, 1, 3), -400000, "", ""))
+			}
+
+			// Act
+			actual := BuildBalances(dataMart)
+
+			// Assert
+			if len(actual.Accounts) != 1 || actual.Accounts[0].Account != "amd" {
+				t.Fatalf("expected only 'amd' account, got %+v", actual.Accounts)
+			}
+			account := actual.Accounts[0]
+			if !account.Daily[0].Date.Equal(tt.expectedFirstDay) {
+				t.Errorf("expected first day %v, got %v", tt.expectedFirstDay, account.Daily[0].Date)
+			}
+			if diff := cmp.Diff(tt.expectedDaily, dailyAmounts(account.Daily)); diff != "" {
+				t.Errorf("daily balances mismatch (-expected +actual):\n%s", diff)
+			}
+			actualDifferences := []int{}
+			for _, reconciliation := range account.Reconciliations {
+				actualDifferences = append(actualDifferences, reconciliation.Difference().int)
+			}
+			if diff := cmp.Diff(tt.expectedDifferences, actualDifferences, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("reconciliation differences mismatch (-expected +actual):\n%s", diff)
+			}
+			if len(actual.NetWorth) != len(tt.expectedDaily) {
+				t.Fatalf("expected %d net worth points, got %d", len(tt.expectedDaily), len(actual.NetWorth))
+			}
+			if lastNetWorth := actual.NetWorth[len(actual.NetWorth)-1].Amounts["AMD"].int; lastNetWorth != tt.expectedDaily[len(tt.expectedDaily)-1] {
+				t.Errorf("expected last net worth %d, got %d", tt.expectedDaily[len(tt.expectedDaily)-1], lastNetWorth)
+			}
+		})
+	}
+}
+
+func TestBuildBalances_NetWorth(t *testing.T) {
+	// Arrange
+	amdSource := newTestSource("amd", "AMD", utcDate(2024, 1, 1), utcDate(2024, 1, 2), intPtr(40000000), intPtr(40000000))
+	usdSource := newTestSource("usd", "USD", utcDate(2024, 1, 2), utcDate(2024, 1, 3), intPtr(100000), intPtr(150000))
+	rates := []*ExchangeRate{
+		{date: utcDate(2024, 1, 1), currencyFrom: "AMD", currencyTo: "USD", exchangeRate: 400},
+	}
+	amd := &CurrencyStatistics{Name: "AMD", ExchangeRates: rates}
+	usd := &CurrencyStatistics{Name: "USD", ExchangeRates: rates}
+	dataMart := &DataMart{
+		SortedTransactions: []Transaction{
+			newTestTransaction(amdSource, utcDate(2024, 1, 1), 1000, "", ""),
+			newTestTransaction(amdSource, utcDate(2024, 1, 2), -1000, "", ""),
+			newTestTransaction(usdSource, utcDate(2024, 1, 3), 50000, "", ""),
+		},
+		AllCurrencies:         map[string]*CurrencyStatistics{"AMD": amd, "USD": usd},
+		ConvertibleCurrencies: map[string]*CurrencyStatistics{"AMD": amd, "USD": usd},
+	}
+
+	// Act
+	actual := BuildBalances(dataMart)
+
+	// Assert
+	expected := []NetWorthPoint{
+		{Date: utcDate(2023, 12, 31), Amounts: map[string]Money{"AMD": {40000000}, "USD": {100000}}},
+		{Date: utcDate(2024, 1, 1), Amounts: map[string]Money{"AMD": {80001000}, "USD": {200000}}},
+		{Date: utcDate(2024, 1, 2), Amounts: map[string]Money{"AMD": {80000000}, "USD": {200000}}},
+		{Date: utcDate(2024, 1, 3), Amounts: map[string]Money{"AMD": {100000000}, "USD": {250000}}},
+	}
+	if diff := cmp.Diff(expected, actual.NetWorth, cmp.AllowUnexported(Money{})); diff != "" {
+		t.Errorf("net worth mismatch (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"AMD", "USD"}, actual.Currencies); diff != "" {
+		t.Errorf("currencies mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestBuildBalances_NotConvertible(t *testing.T) {
+	// Arrange
+	amdSource := newTestSource("amd", "AMD", utcDate(2024, 1, 1), utcDate(2024, 1, 2), intPtr(40000000), nil)
+	usdSource := newTestSource("usd", "USD", utcDate(2024, 1, 2), utcDate(2024, 1, 2), intPtr(100000), nil)
+	dataMart := &DataMart{
+		SortedTransactions: []Transaction{
+			newTestTransaction(amdSource, utcDate(2024, 1, 1), 1000, "", ""),
+			newTestTransaction(usdSource, utcDate(2024, 1, 2), 1000, "", ""),
+		},
+		AllCurrencies:         map[string]*CurrencyStatistics{"AMD": {Name: "AMD"}, "USD": {Name: "USD"}},
+		ConvertibleCurrencies: map[string]*CurrencyStatistics{"AMD": {Name: "AMD"}},
+	}
+
+	// Act
+	actual := BuildBalances(dataMart)
+
+	// Assert
+	if len(actual.Accounts) != 2 {
+		t.Fatalf("expected balances of 2 accounts, got %+v", actual.Accounts)
+	}
+	// Net worth in AMD is skipped since USD balance appears.
+	expected := []NetWorthPoint{
+		{Date: utcDate(2023, 12, 31), Amounts: map[string]Money{"AMD": {40000000}}},
+		{Date: utcDate(2024, 1, 1), Amounts: map[string]Money{}},
+		{Date: utcDate(2024, 1, 2), Amounts: map[string]Money{}},
+	}
+	if diff := cmp.Diff(expected, actual.NetWorth, cmp.AllowUnexported(Money{})); diff != "" {
+		t.Errorf("net worth mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestBuildBalances_MultiCurrencyAccount(t *testing.T) {
+	// Arrange
+	amdSource := newTestSource("multi", "AMD", utcDate(2024, 1, 1), utcDate(2024, 1, 1), intPtr(1000000), intPtr(1500000))
+	usdSource := newTestSource("multi", "USD", utcDate(2024, 1, 1), utcDate(2024, 1, 1), intPtr(10000), intPtr(5000))
+	dataMart := &DataMart{
+		SortedTransactions: []Transaction{
+			newTestTransaction(amdSource, utcDate(2024, 1, 1), 500000, "", ""),
+			newTestTransaction(usdSource, utcDate(2024, 1, 1), -5000, "", ""),
+		},
+		AllCurrencies: map[string]*CurrencyStatistics{"AMD": {Name: "AMD"}, "USD": {Name: "USD"}},
+	}
+
+	// Act
+	actual := BuildBalances(dataMart)
+
+	// Assert
+	if len(actual.Accounts) != 2 {
+		t.Fatalf("expected balances per currency, got %+v", actual.Accounts)
+	}
+	for i, expected := range []struct {
+		currency string
+		daily    []int
+	}{
+		{"AMD", []int{1000000, 1500000}},
+		{"USD", []int{10000, 5000}},
+	} {
+		account := actual.Accounts[i]
+		if account.Account != "multi" || account.Currency != expected.currency {
+			t.Errorf("expected 'multi' account in %s, got '%s' in %s", expected.currency, account.Account, account.Currency)
+		}
+		if diff := cmp.Diff(expected.daily, dailyAmounts(account.Daily)); diff != "" {
+			t.Errorf("daily balances in %s mismatch (-expected +actual):\n%s", expected.currency, diff)
+		}
+		if len(account.Mismatches()) != 0 {
+			t.Errorf("expected reconciled statements in %s, got %+v", expected.currency, account.Mismatches())
+		}
+	}
+}
+
+func TestBalancesToString(t *testing.T) {
+	// Arrange
+	source := newTestSource("amd", "AMD", utcDate(2024, 1, 1), utcDate(2024, 1, 3), intPtr(1000000), intPtr(1400000))
+	balances := &Balances{
+		Accounts: []*AccountBalances{
+			{
+				Account:  "amd",
+				Currency: "AMD",
+				Daily:    []DailyBalance{{Date: utcDate(2024, 1, 3), Amount: Money{int: -1234560}}},
+				Reconciliations: []*BalanceReconciliation{
+					{Source: source, Calculated: Money{int: 1300000}},
+				},
+			},
+		},
+	}
+
+	// Act
+	actual := balancesToString(balances)
+
+	// Assert
+	for _, expected := range []string{
+		"Account balances (accounts 1, reconciliation mismatches 1)",
+		"-1,234.56      AMD on 2024-01-03",
+		"statement 'amd.csv' 2024-01-01..2024-01-03 doesn't reconcile: calculated 1,300.00, closing 1,400.00, difference 100.00",
+	} {
+		if !strings.Contains(actual, expected) {
+			t.Errorf("expected %q in:\n%s", expected, actual)
+		}
+	}
+	if balancesToString(&Balances{}) != "" {
+		t.Error("expected empty string without balances")
+	}
+}
+
+func TestBuildBeancountFile_Balances(t *testing.T) {
+	// Arrange
+	source := newTestSource("amd", "AMD", utcDate(2024, 1, 1), utcDate(2024, 1, 3), intPtr(1000000), intPtr(1300000))
+	accounts := map[string]*AccountStatistics{
+		"amd": {Number: "amd", Source: source, From: utcDate(2024, 1, 2)},
+	}
+	balances := []*AccountBalances{
+		{
+			Account:  "amd",
+			Currency: "AMD",
+			Records: []BalanceRecord{
+				*source.OpeningBalance,
+				*source.ClosingBalance,
+			},
+			Daily: []DailyBalance{{Date: utcDate(2023, 12, 31)}},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "test.beancount")
+
+	// Act
+	_, err := buildBeancountFile([]JournalEntry{}, nil, accounts, balances, path)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"2023-12-31 open Assets:Test:amd\n",
+		"2023-12-31 pad Assets:Test:amd Equity:Opening-Balances\n",
+		"2024-01-01 balance Assets:Test:amd 1,000.00 AMD\n",
+		"2024-01-04 balance Assets:Test:amd 1,300.00 AMD\n",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("expected %q in:\n%s", expected, content)
+		}
+	}
+}
@@ -34,11 +34,13 @@ import (
 const beancountOutputTimeFormat = "2006-01-02"
 
 // buildBeancountFile creates a beancount file with journal entries.
+// Balances of accounts, if provided, are dumped as "balance" assertions.
 // Returns number of journal entries and error if any.
 func buildBeancountFile(
 	journalEntries []JournalEntry,
 	currencies map[string]*CurrencyStatistics,
 	accounts map[string]*AccountStatistics,
+	balances []*AccountBalances,
 	outputFileName string,
 ) (int, error) {
 
@@ -60,14 +62,29 @@ func buildBeancountFile(
 	}
 	fmt.Fprintln(file, "")
 
+	// Balances may start before the first transaction of the account.
+	// Multi-currency accounts have balances per currency.
+	balancesPerAccount := make(map[string][]*AccountBalances, len(balances))
+	for _, accountBalances := range balances {
+		if _, ok := accounts[accountBalances.Account]; ok && len(accountBalances.Records) > 0 {
+			balancesPerAccount[accountBalances.Account] = append(balancesPerAccount[accountBalances.Account], accountBalances)
+		}
+	}
+
 	// Check all found accounts and dump "open accounts" for my own accounts.
 	fmt.Fprintln(file, ";; Open accounts")
 	for _, account := range accounts {
 		if account.Source != nil {
+			openDate := account.From
+			for _, accountBalances := range balancesPerAccount[account.Number] {
+				if accountBalances.Daily[0].Date.Before(openDate) {
+					openDate = accountBalances.Daily[0].Date
+				}
+			}
 			fmt.Fprintf(
 				file,
 				"%s open Assets:%s:%s\n",
-				account.From.Format(beancountOutputTimeFormat),
+				openDate.Format(beancountOutputTimeFormat),
 				account.Source.Tag,
 				account.Number,
 			)
@@ -75,9 +92,50 @@ func buildBeancountFile(
 	}
 	fmt.Fprintln(file, "")
 
+	// Dump balances from statements as assertions. Beancount checks balance at the start of the day.
+	// The first balance of each currency is reached by padding from "Equity:Opening-Balances"
+	// a day before the first balance of the account.
+	if len(balancesPerAccount) > 0 {
+		fmt.Fprintln(file, ";; Balances")
+		isPadded := make(map[string]bool, len(balancesPerAccount))
+		for _, accountBalances := range balances {
+			perCurrency, ok := balancesPerAccount[accountBalances.Account]
+			if !ok || len(accountBalances.Records) == 0 {
+				continue
+			}
+			accountName := fmt.Sprintf("Assets:%s:%s", accounts[accountBalances.Account].Source.Tag, accountBalances.Account)
+			if !isPadded[accountBalances.Account] {
+				isPadded[accountBalances.Account] = true
+				padDate := perCurrency[0].Records[0].Date
+				for _, other := range perCurrency[1:] {
+					if other.Records[0].Date.Before(padDate) {
+						padDate = other.Records[0].Date
+					}
+				}
+				fmt.Fprintf(
+					file,
+					"%s pad %s Equity:Opening-Balances\n",
+					padDate.AddDate(0, 0, -1).Format(beancountOutputTimeFormat),
+					accountName,
+				)
+			}
+			for _, record := range accountBalances.Records {
+				fmt.Fprintf(
+					file,
+					"%s balance %s %s %s\n",
+					record.Date.Format(beancountOutputTimeFormat),
+					accountName,
+					record.Amount.StringInCurrency(accountBalances.Currency),
+					accountBalances.Currency,
+				)
+			}
+		}
+		fmt.Fprintln(file, "")
+	}
+
 	// Now iterate all Journal Entries, find expenses category and dump.
 	// Prepare "group name - substrings" map
-	for _, je := range journalEntries {
+	for i, je := range journalEntries {
 		// Validate currencies.
 		if je.AccountCurrency != "" && !checkCurrency(je.AccountCurrency) {
 			return 0, errors.New(
@@ -93,18 +151,32 @@ func buildBeancountFile(
 				),
 			)
 		}
+		// Transfers between my accounts have both postings in assets.
+		if je.IsTransfer {
+			transfer, err := buildBeancountTransfer(je, accounts)
+			if err != nil {
+				return 0, err
+			}
+			file.WriteString(transfer)
+			continue
+		}
 		// Add journal entry to the file.
 		var sb strings.Builder
 		// Make category name to be a valid account name.
-		categoryName := normalizeAccountName(je.Category)
+		categoryName := normalizeCategoryName(je)
 		// Add extra line and comment with transaction 'direction' and source file.
 		name := "expense"
 		if !je.IsExpense {
 			name = "income"
 		}
-		sb.WriteString(fmt.Sprintf("\n; %s from %s '%s'\n", name, je.Source.Tag, je.Source.FilePath))
-		// 2014-05-05 * "Some details"
-		sb.WriteString(fmt.Sprintf("%s * \"%s\"\n", je.Date.Format(beancountOutputTimeFormat), je.Details))
+		// Parts of split transaction are postings of the same Beancount transaction.
+		isSplitContinuation := je.RuleType == RuleTypeSplit && i > 0 &&
+			journalEntries[i-1].RuleType == RuleTypeSplit && journalEntries[i-1].Fingerprint == je.Fingerprint
+		if !isSplitContinuation {
+			sb.WriteString(fmt.Sprintf("\n; %s from %s '%s'\n", name, je.Source.Tag, je.Source.FilePath))
+			// 2014-05-05 * "Some details"
+			sb.WriteString(fmt.Sprintf("%s * \"%s\"\n", je.Date.Format(beancountOutputTimeFormat), je.Details))
+		}
 		// FYI: transaction (source of journal entry) may be provided in different currencies:
 		// - origin currency only -> use it
 		// - account currency only -> use it
@@ -139,18 +211,18 @@ func buildBeancountFile(
 				sb.WriteString(
 					fmt.Sprintf("  %s    -%s %s @@ %s %s\n",
 						source,
-						je.AccountCurrencyAmount.StringNoIndent(),
+						je.AccountCurrencyAmount.StringInCurrency(je.AccountCurrency),
 						je.AccountCurrency,
-						je.OriginCurrencyAmount.StringNoIndent(),
+						je.OriginCurrencyAmount.StringInCurrency(je.OriginCurrency),
 						je.OriginCurrency,
 					),
 				)
 				sb.WriteString(
 					fmt.Sprintf("  %s    %s %s @@ %s %s\n",
 						destination,
-						je.AccountCurrencyAmount.StringNoIndent(),
+						je.AccountCurrencyAmount.StringInCurrency(je.AccountCurrency),
 						je.AccountCurrency,
-						je.OriginCurrencyAmount.StringNoIndent(),
+						je.OriginCurrencyAmount.StringInCurrency(je.OriginCurrency),
 						je.OriginCurrency,
 					),
 				)
@@ -158,14 +230,14 @@ func buildBeancountFile(
 				sb.WriteString(
 					fmt.Sprintf("  %s    -%s %s\n",
 						source,
-						je.AccountCurrencyAmount.StringNoIndent(),
+						je.AccountCurrencyAmount.StringInCurrency(je.AccountCurrency),
 						je.AccountCurrency,
 					),
 				)
 				sb.WriteString(
 					fmt.Sprintf("  %s    %s %s\n",
 						destination,
-						je.AccountCurrencyAmount.StringNoIndent(),
+						je.AccountCurrencyAmount.StringInCurrency(je.AccountCurrency),
 						je.AccountCurrency,
 					),
 				)
@@ -174,14 +246,14 @@ func buildBeancountFile(
 				sb.WriteString(
 					fmt.Sprintf("  %s    -%s %s\n",
 						source,
-						je.OriginCurrencyAmount.StringNoIndent(),
+						je.OriginCurrencyAmount.StringInCurrency(je.OriginCurrency),
 						je.OriginCurrency,
 					),
 				)
 				sb.WriteString(
 					fmt.Sprintf("  %s    %s %s\n",
 						destination,
-						je.OriginCurrencyAmount.StringNoIndent(),
+						je.OriginCurrencyAmount.StringInCurrency(je.OriginCurrency),
 						je.OriginCurrency,
 					),
 				)
@@ -216,18 +288,18 @@ func buildBeancountFile(
 				sb.WriteString(
 					fmt.Sprintf("  %s    -%s %s @@ %s %s\n",
 						source,
-						je.AccountCurrencyAmount.StringNoIndent(),
+						je.AccountCurrencyAmount.StringInCurrency(je.AccountCurrency),
 						je.AccountCurrency,
-						je.OriginCurrencyAmount.StringNoIndent(),
+						je.OriginCurrencyAmount.StringInCurrency(je.OriginCurrency),
 						je.OriginCurrency,
 					),
 				)
 				sb.WriteString(
 					fmt.Sprintf("  %s    %s %s @@ %s %s\n",
 						destination,
-						je.AccountCurrencyAmount.StringNoIndent(),
+						je.AccountCurrencyAmount.StringInCurrency(je.AccountCurrency),
 						je.AccountCurrency,
-						je.OriginCurrencyAmount.StringNoIndent(),
+						je.OriginCurrencyAmount.StringInCurrency(je.OriginCurrency),
 						je.OriginCurrency,
 					),
 				)
@@ -235,14 +307,14 @@ func buildBeancountFile(
 				sb.WriteString(
 					fmt.Sprintf("  %s    -%s %s\n",
 						source,
-						je.AccountCurrencyAmount.StringNoIndent(),
+						je.AccountCurrencyAmount.StringInCurrency(je.AccountCurrency),
 						je.AccountCurrency,
 					),
 				)
 				sb.WriteString(
 					fmt.Sprintf("  %s    %s %s\n",
 						destination,
-						je.AccountCurrencyAmount.StringNoIndent(),
+						je.AccountCurrencyAmount.StringInCurrency(je.AccountCurrency),
 						je.AccountCurrency,
 					),
 				)
@@ -251,14 +323,14 @@ func buildBeancountFile(
 				sb.WriteString(
 					fmt.Sprintf("  %s    -%s %s\n",
 						source,
-						je.OriginCurrencyAmount.StringNoIndent(),
+						je.OriginCurrencyAmount.StringInCurrency(je.OriginCurrency),
 						je.OriginCurrency,
 					),
 				)
 				sb.WriteString(
 					fmt.Sprintf("  %s    %s %s\n",
 						destination,
-						je.OriginCurrencyAmount.StringNoIndent(),
+						je.OriginCurrencyAmount.StringInCurrency(je.OriginCurrency),
 						je.OriginCurrency,
 					),
 				)
@@ -276,6 +348,61 @@ func buildBeancountFile(
 	return len(journalEntries), nil
 }
 
+// buildBeancountTransfer returns Beancount transaction for transfer between my accounts.
+// If currencies of accounts are different then outgoing amount is "priced" with incoming amount.
+func buildBeancountTransfer(je JournalEntry, accounts map[string]*AccountStatistics) (string, error) {
+	source, ok := accounts[je.FromAccount]
+	if !ok || source.Source == nil {
+		return "", errors.New(i18n.T("source account a not found", "a", je.FromAccount))
+	}
+	destination, ok := accounts[je.ToAccount]
+	if !ok || destination.Source == nil {
+		return "", errors.New(i18n.T("destination account a not found", "a", je.ToAccount))
+	}
+	incoming := je.IncomingLeg
+	if incoming == nil || je.AccountCurrency == "" || incoming.AccountCurrency == "" {
+		return "", errors.New(
+			i18n.T("journal entry t has no amount in account or origin currency",
+				"t", je,
+			),
+		)
+	}
+	if !checkCurrency(incoming.AccountCurrency) {
+		return "", errors.New(
+			i18n.T("invalid account currency c in journal entry t",
+				"c", incoming.AccountCurrency, "t", je,
+			),
+		)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n; transfer from %s '%s' to %s '%s'\n",
+		je.Source.Tag, je.Source.FilePath, incoming.Source.Tag, incoming.Source.FilePath))
+	sb.WriteString(fmt.Sprintf("%s * \"%s\"\n", je.Date.Format(beancountOutputTimeFormat), je.Details))
+	sourceAccount := fmt.Sprintf("Assets:%s:%s", source.Source.Tag, source.Number)
+	destinationAccount := fmt.Sprintf("Assets:%s:%s", destination.Source.Tag, destination.Number)
+	if je.AccountCurrency == incoming.AccountCurrency {
+		// SOURCE       -100 USD
+		// DESTINATION   100 USD
+		sb.WriteString(fmt.Sprintf("  %s    -%s %s\n",
+			sourceAccount, je.AccountCurrencyAmount.StringInCurrency(je.AccountCurrency), je.AccountCurrency))
+		sb.WriteString(fmt.Sprintf("  %s    %s %s\n",
+			destinationAccount, je.AccountCurrencyAmount.StringInCurrency(je.AccountCurrency), je.AccountCurrency))
+	} else {
+		// SOURCE       -100 USD @@ 40000 AMD
+		// DESTINATION  40000 AMD
+		sb.WriteString(fmt.Sprintf("  %s    -%s %s @@ %s %s\n",
+			sourceAccount,
+			je.AccountCurrencyAmount.StringInCurrency(je.AccountCurrency),
+			je.AccountCurrency,
+			incoming.AccountCurrencyAmount.StringInCurrency(incoming.AccountCurrency),
+			incoming.AccountCurrency,
+		))
+		sb.WriteString(fmt.Sprintf("  %s    %s %s\n",
+			destinationAccount, incoming.AccountCurrencyAmount.StringInCurrency(incoming.AccountCurrency), incoming.AccountCurrency))
+	}
+	return sb.String(), nil
+}
+
 var validCurrencyRegex = regexp.MustCompile(`^[A-Z][A-Z0-9'._-]{0,22}[A-Z0-9]$`)
 
 func checkCurrency(currency string) bool {
@@ -289,6 +416,18 @@ func checkCurrency(currency string) bool {
 
 var validAccountNameRegex = regexp.MustCompile(`[^\p{L}\p{N}]+`)
 
+// normalizeCategoryName converts category of journal entry into (possibly nested) account name.
+func normalizeCategoryName(je JournalEntry) string {
+	if len(je.CategoryPath) == 0 {
+		return normalizeAccountName(je.Category)
+	}
+	parts := make([]string, len(je.CategoryPath))
+	for i, part := range je.CategoryPath {
+		parts[i] = normalizeAccountName(part)
+	}
+	return strings.Join(parts, ":")
+}
+
 func normalizeAccountName(account string) string {
 	normalized := validAccountNameRegex.ReplaceAllString(account, "-")
 	return strings.Trim(normalized, "-")
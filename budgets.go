@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// BudgetConfig is a monthly limit of expenses for a group or a category in hierarchy of categories.
+type BudgetConfig struct {
+	// Limit is a monthly limit in the currency of statistics, used for currencies without `Limits`.
+	Limit *float64 `yaml:"limit,omitempty" validate:"omitempty,gt=0"`
+	// Limits are monthly limits per currency. Have precedence over `Limit`.
+	Limits map[string]float64 `yaml:"limits,omitempty" validate:"omitempty,dive,gt=0"`
+	// Rollover turns on carrying of unspent (or overspent) amount to the next month.
+	Rollover bool `yaml:"rollover,omitempty"`
+}
+
+// getLimit returns monthly limit in money units for the currency and false if there is no limit for it.
+func (b *BudgetConfig) getLimit(currency string) (int, bool) {
+	if limit, ok := b.Limits[currency]; ok {
+		return moneyFromFloat(limit).int, true
+	}
+	if b.Limit != nil {
+		return moneyFromFloat(*b.Limit).int, true
+	}
+	return 0, false
+}
+
+// BudgetStatistic is a progress of the budget in one month and one currency.
+type BudgetStatistic struct {
+	// Name is a name of the group or path of the category.
+	Name string
+	// Limit is a monthly limit.
+	Limit Money
+	// RolledOver is an amount carried from previous months, negative if they were overspent.
+	RolledOver Money
+	// Available is an amount available for the month, i.e. `Limit` plus `RolledOver`.
+	Available Money
+	// Actual is an amount spent in the month.
+	Actual Money
+	// IsExceeded is true if `Actual` is greater than `Available`.
+	IsExceeded bool
+}
+
+// Remaining returns amount left for the month, negative if budget is exceeded.
+func (b *BudgetStatistic) Remaining() Money {
+	return Money{int: b.Available.int - b.Actual.int}
+}
+
+// Percent returns spent part of available amount in percents.
+// Nothing available (because of overspending in previous months) is treated as 100% if there are expenses.
+func (b *BudgetStatistic) Percent() int {
+	if b.Available.int <= 0 {
+		if b.Actual.int > 0 {
+			return 100
+		}
+		return 0
+	}
+	return int(math.Round(float64(b.Actual.int) * 100 / float64(b.Available.int)))
+}
+
+// findCategoryNode finds node by path of category or by name of the group in it.
+func findCategoryNode(nodes []*CategoryNode, name string) *CategoryNode {
+	for _, node := range nodes {
+		if node.Path == name || node.Group == name {
+			return node
+		}
+		if found := findCategoryNode(node.Children, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// getExpenseTotal returns expenses of the group or category (with all subcategories) in the interval.
+func getExpenseTotal(stat *IntervalStatistic, name string) Money {
+	if node := findCategoryNode(stat.ExpenseTree, name); node != nil {
+		return node.Total
+	}
+	if group, ok := stat.Expense[name]; ok {
+		return group.Total
+	}
+	return Money{}
+}
+
+// buildBudgetStatistics sets `Budgets` in all provided statistics.
+// Months should be in chronological order to carry amounts for budgets with rollover.
+func buildBudgetStatistics(monthlyStatistics []map[string]*IntervalStatistic, budgets map[string]*BudgetConfig) {
+	if len(budgets) == 0 {
+		return
+	}
+	names := make([]string, 0, len(budgets))
+	for name := range budgets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	currencies := make(map[string]bool)
+	for _, month := range monthlyStatistics {
+		for currency, stat := range month {
+			currencies[currency] = true
+			stat.Budgets = make([]*BudgetStatistic, 0, len(names))
+		}
+	}
+
+	// Months without statistics in the currency have no expenses but still carry amounts.
+	for currency := range currencies {
+		for _, name := range names {
+			budget := budgets[name]
+			limit, ok := budget.getLimit(currency)
+			if !ok {
+				continue
+			}
+			rolledOver := 0
+			for _, month := range monthlyStatistics {
+				stat := month[currency]
+				budgetStat := &BudgetStatistic{
+					Name:       name,
+					Limit:      Money{int: limit},
+					RolledOver: Money{int: rolledOver},
+					Available:  Money{int: limit + rolledOver},
+				}
+				if stat != nil {
+					budgetStat.Actual = getExpenseTotal(stat, name)
+					stat.Budgets = append(stat.Budgets, budgetStat)
+				}
+				budgetStat.IsExceeded = budgetStat.Actual.int > budgetStat.Available.int
+				if budget.Rollover {
+					rolledOver = budgetStat.Remaining().int
+				}
+			}
+		}
+	}
+}
+
+// budgetsToString returns human readable progress of budgets with flags on exceeded ones.
+func budgetsToString(budgets []*BudgetStatistic) string {
+	if len(budgets) == 0 {
+		return ""
+	}
+	details := make([]string, 0, len(budgets))
+	nExceeded := 0
+	for _, budget := range budgets {
+		line := i18n.T("budgetName actual available percent",
+			"budgetName", budget.Name,
+			"actual", budget.Actual,
+			"available", budget.Available,
+			"percent", fmt.Sprintf("%3d", budget.Percent()),
+		)
+		if budget.IsExceeded {
+			nExceeded++
+			line += i18n.T(" EXCEEDED by amount", "amount", Money{int: -budget.Remaining().int}.StringNoIndent())
+		}
+		details = append(details, line)
+	}
+	return i18n.T("Budgets_format",
+		"nBudgets", len(budgets),
+		"nExceeded", nExceeded,
+		"detailsBudgets", details,
+	)
+}
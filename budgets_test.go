@@ -0,0 +1,107 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildBudgetStatistics(t *testing.T) {
+	// Arrange
+	months := []map[string]*IntervalStatistic{
+		newTestMonth(time.January, map[string]map[string][]int{
+			"AMD": {"Food:Groceries": {80000}, "Food:Cafe": {40000}, "Taxi": {10000}},
+			"USD": {"Food:Groceries": {200}},
+		}),
+		newTestMonth(time.February, map[string]map[string][]int{
+			"AMD": {"Food:Groceries": {50000}, "Taxi": {30000}},
+			"USD": {"Food:Groceries": {100}},
+		}),
+	}
+	budgets := map[string]*BudgetConfig{
+		"Food": {Limits: map[string]float64{"AMD": 100}, Rollover: true},
+		"Taxi": {Limit: floatPtr(20), Limits: map[string]float64{"USD": 50}},
+	}
+
+	// Act
+	buildBudgetStatistics(months, budgets)
+
+	// Assert
+	money := func(amount int) Money { return Money{int: amount} }
+	expected := [][]*BudgetStatistic{
+		{
+			{Name: "Food", Limit: money(100000), Available: money(100000), Actual: money(120000), IsExceeded: true},
+			{Name: "Taxi", Limit: money(20000), Available: money(20000), Actual: money(10000)},
+		},
+		{
+			{Name: "Food", Limit: money(100000), RolledOver: money(-20000), Available: money(80000), Actual: money(50000)},
+			{Name: "Taxi", Limit: money(20000), Available: money(20000), Actual: money(30000), IsExceeded: true},
+		},
+	}
+	for i, month := range months {
+		if diff := cmp.Diff(expected[i], month["AMD"].Budgets, cmp.AllowUnexported(Money{})); diff != "" {
+			t.Errorf("month #%d AMD budgets mismatch (-expected +actual):\n%s", i+1, diff)
+		}
+		usdBudgets := month["USD"].Budgets
+		if len(usdBudgets) != 1 || usdBudgets[0].Name != "Taxi" || usdBudgets[0].Limit.int != 50000 || usdBudgets[0].Actual.int != 0 {
+			t.Errorf("month #%d expected only 'Taxi' budget with 50.00 limit in USD, got %+v", i+1, usdBudgets)
+		}
+	}
+}
+
+func TestBuildBudgetStatistics_RolloverOverEmptyMonth(t *testing.T) {
+	// Arrange
+	months := []map[string]*IntervalStatistic{
+		newTestMonth(time.January, map[string]map[string][]int{
+			"AMD": {"Food": {50000}},
+			"USD": {"Food": {120000}},
+		}),
+		newTestMonth(time.February, map[string]map[string][]int{
+			"AMD": {"Food": {50000}},
+		}),
+		newTestMonth(time.March, map[string]map[string][]int{
+			"AMD": {"Food": {50000}},
+			"USD": {"Food": {50000}},
+		}),
+	}
+	budgets := map[string]*BudgetConfig{
+		"Food": {Limit: floatPtr(100), Rollover: true},
+	}
+
+	// Act
+	buildBudgetStatistics(months, budgets)
+
+	// Assert
+	money := func(amount int) Money { return Money{int: amount} }
+	expected := []*BudgetStatistic{
+		{Name: "Food", Limit: money(100000), RolledOver: money(80000), Available: money(180000), Actual: money(50000)},
+	}
+	if diff := cmp.Diff(expected, months[2]["USD"].Budgets, cmp.AllowUnexported(Money{})); diff != "" {
+		t.Errorf("USD budgets after empty month mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestBudgetsToString(t *testing.T) {
+	// Arrange
+	budgets := []*BudgetStatistic{
+		{Name: "Food", Available: Money{int: 100000}, Actual: Money{int: 120000}, IsExceeded: true},
+		{Name: "Taxi", Available: Money{int: 20000}, Actual: Money{int: 10000}},
+	}
+
+	// Act
+	actual := budgetsToString(budgets)
+
+	// Assert
+	lines := strings.Split(actual, "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected header and 2 budgets, got:\n%s", actual)
+	}
+	if !strings.Contains(lines[2], "Food") || !strings.Contains(lines[2], "(120%)") || !strings.Contains(lines[2], "EXCEEDED by 20.00") {
+		t.Errorf("expected exceeded 'Food' budget, got %q", lines[2])
+	}
+	if !strings.Contains(lines[3], "Taxi") || !strings.Contains(lines[3], "( 50%)") || strings.Contains(lines[3], "EXCEEDED") {
+		t.Errorf("expected not exceeded 'Taxi' budget, got %q", lines[3])
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TransactionsCacheFileName is a name of file with cached transactions, it is placed next to the configuration file.
+const TransactionsCacheFileName = ".am-budget-view-cache.json"
+
+// transactionsCacheFormatVersion should be increased on any change of cache file structure.
+const transactionsCacheFormatVersion = 4
+
+// TransactionsCache keeps transactions parsed from files to don't parse not changed files again.
+// File is treated as not changed if it has the same size, modification time and SHA-256 hash of content.
+// Results are also bound to parser name, version and settings, so any change of them invalidates cache.
+// Methods are safe to call on nil cache, it means that cache is disabled.
+type TransactionsCache struct {
+	path      string
+	mutex     sync.Mutex
+	entries   map[string]*transactionsCacheEntry
+	usedPaths map[string]struct{}
+	isChanged bool
+}
+
+// transactionsCacheFile is a structure of cache file.
+type transactionsCacheFile struct {
+	FormatVersion int                                `json:"formatVersion"`
+	AppVersion    string                             `json:"appVersion"`
+	Entries       map[string]*transactionsCacheEntry `json:"entries"`
+}
+
+// transactionsCacheEntry contains transactions parsed from one file.
+type transactionsCacheEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Sha256  string    `json:"sha256"`
+	// Parser identifies parser with its version and settings.
+	Parser string `json:"parser"`
+	// Sources are shared by transactions so are stored separately.
+	Sources      []cachedSource      `json:"sources"`
+	Transactions []cachedTransaction `json:"transactions"`
+}
+
+// cachedSource is a TransactionsSource in a form suitable for JSON.
+// Balances shadow fields of the embedded source.
+type cachedSource struct {
+	TransactionsSource
+	OpeningBalance *cachedBalance `json:"openingBalance,omitempty"`
+	ClosingBalance *cachedBalance `json:"closingBalance,omitempty"`
+}
+
+// cachedBalance is a BalanceRecord in a form suitable for JSON.
+type cachedBalance struct {
+	Date   time.Time `json:"date"`
+	Amount int       `json:"amount"`
+}
+
+// newCachedSource converts source to the form suitable for JSON.
+func newCachedSource(source *TransactionsSource) cachedSource {
+	result := cachedSource{TransactionsSource: *source}
+	result.TransactionsSource.OpeningBalance = nil
+	result.TransactionsSource.ClosingBalance = nil
+	if source.OpeningBalance != nil {
+		result.OpeningBalance = &cachedBalance{Date: source.OpeningBalance.Date, Amount: source.OpeningBalance.Amount.int}
+	}
+	if source.ClosingBalance != nil {
+		result.ClosingBalance = &cachedBalance{Date: source.ClosingBalance.Date, Amount: source.ClosingBalance.Amount.int}
+	}
+	return result
+}
+
+// toTransactionsSource converts cached source back.
+func (s cachedSource) toTransactionsSource() *TransactionsSource {
+	source := s.TransactionsSource
+	if s.OpeningBalance != nil {
+		source.OpeningBalance = &BalanceRecord{Date: s.OpeningBalance.Date, Amount: Money{int: s.OpeningBalance.Amount}}
+	}
+	if s.ClosingBalance != nil {
+		source.ClosingBalance = &BalanceRecord{Date: s.ClosingBalance.Date, Amount: Money{int: s.ClosingBalance.Amount}}
+	}
+	return &source
+}
+
+// cachedTransaction is a Transaction in a form suitable for JSON.
+type cachedTransaction struct {
+	Date                 time.Time `json:"date"`
+	FromAccount          string    `json:"fromAccount"`
+	ToAccount            string    `json:"toAccount"`
+	IsExpense            bool      `json:"isExpense"`
+	Amount               int       `json:"amount"`
+	Details              string    `json:"details"`
+	SourceIndex          int       `json:"sourceIndex"`
+	AccountCurrency      string    `json:"accountCurrency"`
+	OriginCurrency       string    `json:"originCurrency"`
+	OriginCurrencyAmount int       `json:"originCurrencyAmount"`
+	ID                   string    `json:"id,omitempty"`
+}
+
+// getTransactionsCachePath returns path to the cache file for the configuration file.
+func getTransactionsCachePath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), TransactionsCacheFileName)
+}
+
+// loadTransactionsCache reads cache from the file.
+// Missing, broken or outdated file doesn't fail, it just results in empty cache.
+func loadTransactionsCache(path string) *TransactionsCache {
+	cache := &TransactionsCache{
+		path:      path,
+		entries:   make(map[string]*transactionsCacheEntry),
+		usedPaths: make(map[string]struct{}),
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Println(i18n.T("Can't read transactions cache from f file", "f", path, "err", err))
+		}
+		return cache
+	}
+	var cacheFile transactionsCacheFile
+	if err := json.Unmarshal(data, &cacheFile); err != nil {
+		log.Println(i18n.T("Can't read transactions cache from f file", "f", path, "err", err))
+		return cache
+	}
+	if cacheFile.FormatVersion != transactionsCacheFormatVersion || cacheFile.AppVersion != Version {
+		log.Println(i18n.T("Transactions cache from f file is outdated", "f", path))
+		return cache
+	}
+	if cacheFile.Entries != nil {
+		cache.entries = cacheFile.Entries
+	}
+	return cache
+}
+
+// Save writes cache into the file if it was changed.
+// Entries for files which weren't parsed since cache loading are removed.
+func (c *TransactionsCache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for path := range c.entries {
+		if _, ok := c.usedPaths[path]; !ok {
+			delete(c.entries, path)
+			c.isChanged = true
+		}
+	}
+	c.usedPaths = make(map[string]struct{})
+	if !c.isChanged {
+		return nil
+	}
+	data, err := json.Marshal(transactionsCacheFile{
+		FormatVersion: transactionsCacheFormatVersion,
+		AppVersion:    Version,
+		Entries:       c.entries,
+	})
+	if err != nil {
+		return err
+	}
+	// Write into temporary file first to don't break cache if application is stopped in the middle.
+	tempPath := c.path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, c.path); err != nil {
+		return err
+	}
+	c.isChanged = false
+	return nil
+}
+
+// wrapParser returns FileParser which uses cache for the specified parser.
+// Returns parser itself if cache is disabled.
+func (c *TransactionsCache) wrapParser(parser FileParser, registration *ParserRegistration) FileParser {
+	if c == nil {
+		return parser
+	}
+	return &cachingFileParser{
+		parser: parser,
+		cache:  c,
+		// Settings of parser are included to invalidate cache when options or related configuration change.
+		key: fmt.Sprintf("%s@%d:%+v", registration.Name, registration.Version, parser),
+	}
+}
+
+// get returns cached transactions for the file if they are still valid.
+func (c *TransactionsCache) get(path string, parserKey string, stat os.FileInfo, hash string) ([]Transaction, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.usedPaths[path] = struct{}{}
+	entry, ok := c.entries[path]
+	if !ok || entry.Parser != parserKey || entry.Size != stat.Size() ||
+		!entry.ModTime.Equal(stat.ModTime()) || entry.Sha256 != hash {
+		return nil, false
+	}
+	sources := make([]*TransactionsSource, len(entry.Sources))
+	for i := range entry.Sources {
+		sources[i] = entry.Sources[i].toTransactionsSource()
+	}
+	transactions := make([]Transaction, 0, len(entry.Transactions))
+	for _, t := range entry.Transactions {
+		var source *TransactionsSource
+		if t.SourceIndex >= 0 && t.SourceIndex < len(sources) {
+			source = sources[t.SourceIndex]
+		}
+		transactions = append(transactions, Transaction{
+			Date:                 t.Date,
+			FromAccount:          t.FromAccount,
+			ToAccount:            t.ToAccount,
+			IsExpense:            t.IsExpense,
+			Amount:               Money{int: t.Amount},
+			Details:              t.Details,
+			Source:               source,
+			AccountCurrency:      t.AccountCurrency,
+			OriginCurrency:       t.OriginCurrency,
+			OriginCurrencyAmount: Money{int: t.OriginCurrencyAmount},
+			ID:                   t.ID,
+		})
+	}
+	return transactions, true
+}
+
+// put saves transactions parsed from the file.
+func (c *TransactionsCache) put(path string, parserKey string, stat os.FileInfo, hash string, transactions []Transaction) {
+	entry := &transactionsCacheEntry{
+		Size:         stat.Size(),
+		ModTime:      stat.ModTime(),
+		Sha256:       hash,
+		Parser:       parserKey,
+		Transactions: make([]cachedTransaction, 0, len(transactions)),
+	}
+	// Copy sources right now because they may be changed after parsing (e.g. by `setDefaultTag`).
+	sourceIndexes := make(map[*TransactionsSource]int)
+	for _, t := range transactions {
+		sourceIndex := -1
+		if t.Source != nil {
+			index, ok := sourceIndexes[t.Source]
+			if !ok {
+				index = len(entry.Sources)
+				sourceIndexes[t.Source] = index
+				entry.Sources = append(entry.Sources, newCachedSource(t.Source))
+			}
+			sourceIndex = index
+		}
+		entry.Transactions = append(entry.Transactions, cachedTransaction{
+			Date:                 t.Date,
+			FromAccount:          t.FromAccount,
+			ToAccount:            t.ToAccount,
+			IsExpense:            t.IsExpense,
+			Amount:               t.Amount.int,
+			Details:              t.Details,
+			SourceIndex:          sourceIndex,
+			AccountCurrency:      t.AccountCurrency,
+			OriginCurrency:       t.OriginCurrency,
+			OriginCurrencyAmount: t.OriginCurrencyAmount.int,
+			ID:                   t.ID,
+		})
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.usedPaths[path] = struct{}{}
+	c.entries[path] = entry
+	c.isChanged = true
+}
+
+// cachingFileParser is a FileParser which returns cached transactions for not changed files.
+type cachingFileParser struct {
+	parser FileParser
+	cache  *TransactionsCache
+	key    string
+}
+
+func (p *cachingFileParser) ParseRawTransactionsFromFile(filePath string) ([]Transaction, error) {
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := fileSha256(filePath)
+	if err != nil {
+		return nil, err
+	}
+	path := absolutePath(filePath)
+	if transactions, ok := p.cache.get(path, p.key, stat, hash); ok {
+		log.Println(i18n.T("Using n cached transactions for f file", "n", len(transactions), "f", filePath))
+		return transactions, nil
+	}
+	transactions, err := p.parser.ParseRawTransactionsFromFile(filePath)
+	// Don't cache partially parsed files to show errors each time.
+	if err == nil {
+		p.cache.put(path, p.key, stat, hash, transactions)
+	}
+	return transactions, err
+}
+
+func (p *cachingFileParser) String() string {
+	return fmt.Sprintf("%v (cached)", p.parser)
+}
+
+// fileSha256 returns hex encoded SHA-256 hash of the file content.
+func fileSha256(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
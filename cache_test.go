@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// countingFileParser is a FileParser which counts calls and returns the same transactions for any file.
+type countingFileParser struct {
+	calls        *int
+	transactions []Transaction
+	err          error
+}
+
+func (p countingFileParser) ParseRawTransactionsFromFile(filePath string) ([]Transaction, error) {
+	*p.calls++
+	return p.transactions, p.err
+}
+
+func newCacheTestTransactions(filePath string) []Transaction {
+	source := &TransactionsSource{TypeName: "Test", Tag: "Test", FilePath: filePath, AccountNumber: "acc", AccountCurrency: "AMD",
+		OpeningBalance: &BalanceRecord{Date: testDate, Amount: Money{int: -5000}},
+		ClosingBalance: &BalanceRecord{Date: testDate.AddDate(0, 0, 1), Amount: Money{int: 1234567890}}}
+	return []Transaction{
+		{Date: testDate, FromAccount: "acc", ToAccount: "shop", IsExpense: true, Amount: Money{int: 123450}, Details: "Coffee", Source: source, AccountCurrency: "AMD"},
+		{Date: testDate, FromAccount: "boss", ToAccount: "acc", Amount: Money{int: 1000}, Details: "Salary", Source: source, AccountCurrency: "AMD", OriginCurrency: "USD", OriginCurrencyAmount: Money{int: 10}, ID: "FIT1"},
+	}
+}
+
+func TestTransactionsCache(t *testing.T) {
+	registration := &ParserRegistration{Name: "test", Version: 1}
+	tests := []struct {
+		name string
+		// change is called between first and second parsing, returns registration to use for second parsing.
+		change        func(t *testing.T, cache *TransactionsCache, filePath string) (*TransactionsCache, *ParserRegistration)
+		expectedCalls int
+	}{
+		{
+			name: "not changed file",
+			change: func(t *testing.T, cache *TransactionsCache, filePath string) (*TransactionsCache, *ParserRegistration) {
+				return cache, registration
+			},
+			expectedCalls: 1,
+		},
+		{
+			name: "saved and loaded cache",
+			change: func(t *testing.T, cache *TransactionsCache, filePath string) (*TransactionsCache, *ParserRegistration) {
+				if err := cache.Save(); err != nil {
+					t.Fatalf("Save failed: %v", err)
+				}
+				return loadTransactionsCache(cache.path), registration
+			},
+			expectedCalls: 1,
+		},
+		{
+			name: "changed file",
+			change: func(t *testing.T, cache *TransactionsCache, filePath string) (*TransactionsCache, *ParserRegistration) {
+				if err := os.WriteFile(filePath, []byte("other content"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return cache, registration
+			},
+			expectedCalls: 2,
+		},
+		{
+			name: "changed parser version",
+			change: func(t *testing.T, cache *TransactionsCache, filePath string) (*TransactionsCache, *ParserRegistration) {
+				return cache, &ParserRegistration{Name: "test", Version: 2}
+			},
+			expectedCalls: 2,
+		},
+		{
+			name: "disabled cache",
+			change: func(t *testing.T, cache *TransactionsCache, filePath string) (*TransactionsCache, *ParserRegistration) {
+				return nil, registration
+			},
+			expectedCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Arrange
+			dir := t.TempDir()
+			filePath := filepath.Join(dir, "statement.csv")
+			if err := os.WriteFile(filePath, []byte("content"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			calls := 0
+			expected := newCacheTestTransactions(filePath)
+			parser := countingFileParser{calls: &calls, transactions: expected}
+			cache := loadTransactionsCache(filepath.Join(dir, TransactionsCacheFileName))
+			if _, err := cache.wrapParser(parser, registration).ParseRawTransactionsFromFile(filePath); err != nil {
+				t.Fatalf("first parsing failed: %v", err)
+			}
+
+			// Act
+			cache, secondRegistration := tt.change(t, cache, filePath)
+			actual, err := cache.wrapParser(parser, secondRegistration).ParseRawTransactionsFromFile(filePath)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("second parsing failed: %v", err)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("expected %d calls of parser, got %d", tt.expectedCalls, calls)
+			}
+			if diff := cmp.Diff(expected, actual, cmp.AllowUnexported(Money{})); diff != "" {
+				t.Errorf("transactions mismatch (-expected +actual):\n%s", diff)
+			}
+			if actual[0].Source != actual[1].Source {
+				t.Errorf("transactions from one file should share source")
+			}
+		})
+	}
+}
+
+func TestTransactionsCache_DoesntCacheErrors(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "statement.csv")
+	if err := os.WriteFile(filePath, []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	parser := countingFileParser{
+		calls:        &calls,
+		transactions: newCacheTestTransactions(filePath),
+		err:          errors.New("broken row"),
+	}
+	cache := loadTransactionsCache(filepath.Join(dir, TransactionsCacheFileName))
+	cachingParser := cache.wrapParser(parser, &ParserRegistration{Name: "test"})
+
+	// Act
+	_, err1 := cachingParser.ParseRawTransactionsFromFile(filePath)
+	_, err2 := cachingParser.ParseRawTransactionsFromFile(filePath)
+
+	// Assert
+	if err1 == nil || err2 == nil {
+		t.Errorf("expected errors, got %v and %v", err1, err2)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls of parser, got %d", calls)
+	}
+}
+
+func TestTransactionsCache_Save(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	cachePath := filepath.Join(dir, TransactionsCacheFileName)
+	usedFile := filepath.Join(dir, "used.csv")
+	removedFile := filepath.Join(dir, "removed.csv")
+	for _, file := range []string{usedFile, removedFile} {
+		if err := os.WriteFile(file, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	calls := 0
+	parser := countingFileParser{calls: &calls, transactions: newCacheTestTransactions(usedFile)}
+	registration := &ParserRegistration{Name: "test"}
+	cache := loadTransactionsCache(cachePath)
+	for _, file := range []string{usedFile, removedFile} {
+		if _, err := cache.wrapParser(parser, registration).ParseRawTransactionsFromFile(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	cache = loadTransactionsCache(cachePath)
+	if _, err := cache.wrapParser(parser, registration).ParseRawTransactionsFromFile(usedFile); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	err := cache.Save()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	entries := loadTransactionsCache(cachePath).entries
+	if _, ok := entries[absolutePath(usedFile)]; !ok || len(entries) != 1 {
+		t.Errorf("expected only entry for %s, got %v", usedFile, entries)
+	}
+}
+
+func TestLoadTransactionsCache_WrongFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"broken", "{not a json"},
+		{"outdated", `{"formatVersion": 0, "appVersion": "development", "entries": {"a": {}}}`},
+		{"other_app_version", `{"formatVersion": 1, "appVersion": "v0.0.1", "entries": {"a": {}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Arrange
+			cacheFile := createTempFileWithContent(tt.content)
+			defer os.Remove(cacheFile.Name())
+
+			// Act
+			cache := loadTransactionsCache(cacheFile.Name())
+
+			// Assert
+			if len(cache.entries) != 0 {
+				t.Errorf("expected empty cache, got %v", cache.entries)
+			}
+		})
+	}
+}
@@ -2,7 +2,12 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 )
 
 type groupConfigWithName struct {
@@ -21,6 +26,258 @@ type Categorization struct {
 	fromAccountToGroupConfig map[string]*groupConfigWithName
 	// Mapping from 'to' accounts to group configurations.
 	toAccountToGroupConfig map[string]*groupConfigWithName
+	// Rules sorted by descending priority.
+	rules []*compiledRule
+	// lowPriorityRulesIndex is an index of the first rule with negative priority.
+	lowPriorityRulesIndex int
+	// categoryPaths contains paths in categories hierarchy for configured groups.
+	categoryPaths map[string][]string
+	// overrides maps transaction fingerprint to manually chosen group.
+	overrides map[string]string
+	// splits maps transaction fingerprint to split of transaction across several groups.
+	splits map[string]*TransactionSplit
+}
+
+// CategorySeparator separates parts of category path, like "Food:Groceries".
+const CategorySeparator = ":"
+
+// buildCategoryPaths returns paths in categories hierarchy for all configured groups.
+// Group name is split by `CategorySeparator` and appended to the path of `Parent` if it is set.
+// Parent may be not configured group, in this case its name is split to the path as is.
+func buildCategoryPaths(groups map[string]*GroupConfig) (map[string][]string, error) {
+	paths := make(map[string][]string, len(groups))
+	var resolve func(name string, visited []string) ([]string, error)
+	resolve = func(name string, visited []string) ([]string, error) {
+		if path, ok := paths[name]; ok {
+			return path, nil
+		}
+		if slices.Contains(visited, name) {
+			return nil, errors.New(i18n.T(
+				"wrong configuration: groups g have cyclic parents",
+				"groups", strings.Join(append(visited, name), " -> "),
+			))
+		}
+		path := splitCategoryPath(name)
+		if group, ok := groups[name]; ok && group.Parent != "" {
+			parentPath, err := resolve(group.Parent, append(visited, name))
+			if err != nil {
+				return nil, err
+			}
+			path = append(slices.Clip(parentPath), path...)
+		}
+		if _, ok := groups[name]; ok {
+			paths[name] = path
+		}
+		return path, nil
+	}
+	for name := range groups {
+		if _, err := resolve(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// groupRenamer returns function which renames the group and paths under it, like "Food:Groceries" for "Food".
+// Other names are returned as is.
+func groupRenamer(oldName, newName string) func(name string) string {
+	return func(name string) string {
+		if name == oldName {
+			return newName
+		}
+		if strings.HasPrefix(name, oldName+CategorySeparator) {
+			return newName + strings.TrimPrefix(name, oldName)
+		}
+		return name
+	}
+}
+
+// renameGroup renames group in place and keeps hierarchy of categories: groups which names or parents
+// are paths under the renamed group, like "Food:Groceries" for "Food", are moved under the new name.
+func renameGroup(groups map[string]*GroupConfig, oldName, newName string) error {
+	if _, ok := groups[oldName]; !ok {
+		return fmt.Errorf("%w: '%s'", errGroupNotFound, oldName)
+	}
+	rename := groupRenamer(oldName, newName)
+	renamed := make(map[string]*GroupConfig, len(groups))
+	for name, group := range groups {
+		newGroupName := rename(name)
+		if _, exists := groups[newGroupName]; exists && newGroupName != name {
+			return errGroupAlreadyExists
+		}
+		group.Parent = rename(group.Parent)
+		renamed[newGroupName] = group
+	}
+	clear(groups)
+	for name, group := range renamed {
+		groups[name] = group
+	}
+	return nil
+}
+
+// splitCategoryPath splits category name into not empty parts of path.
+func splitCategoryPath(name string) []string {
+	path := []string{}
+	for _, part := range strings.Split(name, CategorySeparator) {
+		if part = strings.TrimSpace(part); part != "" {
+			path = append(path, part)
+		}
+	}
+	if len(path) == 0 {
+		return []string{name}
+	}
+	return path
+}
+
+// getCategoryPath returns path of the category in hierarchy.
+// Not configured categories (like details of uncategorized transactions) are not split.
+func (c *Categorization) getCategoryPath(name string) []string {
+	if path, ok := c.categoryPaths[name]; ok {
+		return path
+	}
+	return []string{name}
+}
+
+// compiledRule is a `RuleConfig` prepared for matching.
+type compiledRule struct {
+	groupName string
+	priority  int
+	// description is a name of the rule or list of its conditions.
+	description string
+	// substring is lowercased if rule ignores case.
+	substring  string
+	ignoreCase bool
+	regexp     *regexp.Regexp
+	// minAmount and maxAmount are in money units, nil if not set.
+	minAmount   *int
+	maxAmount   *int
+	fromAccount string
+	toAccount   string
+	// isExpense is nil if direction is not set.
+	isExpense *bool
+	source    string
+	currency  string
+}
+
+// newCompiledRule validates rule and prepares it for matching. `number` is 1-based number of rule in the group.
+func newCompiledRule(groupName string, number int, rule RuleConfig) (*compiledRule, error) {
+	result := &compiledRule{
+		groupName:   groupName,
+		priority:    rule.Priority,
+		ignoreCase:  rule.IgnoreCase,
+		substring:   rule.Substring,
+		fromAccount: rule.FromAccount,
+		toAccount:   rule.ToAccount,
+		source:      rule.Source,
+		currency:    rule.Currency,
+	}
+	if rule.IgnoreCase {
+		result.substring = strings.ToLower(rule.Substring)
+	}
+	if rule.Regexp != "" {
+		expression := rule.Regexp
+		if rule.IgnoreCase {
+			expression = "(?i)" + expression
+		}
+		var err error
+		if result.regexp, err = regexp.Compile(expression); err != nil {
+			return nil, errors.New(i18n.T(
+				"wrong configuration: rule n of group g has wrong regexp",
+				"n", number, "group", groupName, "err", err,
+			))
+		}
+	}
+	if rule.MinAmount != nil {
+		minAmount := moneyFromFloat(*rule.MinAmount).int
+		result.minAmount = &minAmount
+	}
+	if rule.MaxAmount != nil {
+		maxAmount := moneyFromFloat(*rule.MaxAmount).int
+		result.maxAmount = &maxAmount
+	}
+	if result.minAmount != nil && result.maxAmount != nil && *result.minAmount > *result.maxAmount {
+		return nil, errors.New(i18n.T(
+			"wrong configuration: rule n of group g has minAmount greater than maxAmount",
+			"n", number, "group", groupName,
+		))
+	}
+	switch rule.Direction {
+	case "":
+	case "expense", "income":
+		isExpense := rule.Direction == "expense"
+		result.isExpense = &isExpense
+	default:
+		return nil, errors.New(i18n.T(
+			"wrong configuration: rule n of group g has wrong direction d",
+			"n", number, "group", groupName, "d", rule.Direction,
+		))
+	}
+	result.description = rule.Description()
+	if result.description == "" {
+		return nil, errors.New(i18n.T(
+			"wrong configuration: rule n of group g has no conditions",
+			"n", number, "group", groupName,
+		))
+	}
+	if rule.Name != "" {
+		result.description = rule.Name
+	}
+	return result, nil
+}
+
+// matches checks that transaction matches all conditions of the rule.
+func (r *compiledRule) matches(tr *Transaction) bool {
+	if r.substring != "" {
+		details := tr.Details
+		if r.ignoreCase {
+			details = strings.ToLower(details)
+		}
+		if !strings.Contains(details, r.substring) {
+			return false
+		}
+	}
+	if r.regexp != nil && !r.regexp.MatchString(tr.Details) {
+		return false
+	}
+	if (r.fromAccount != "" && tr.FromAccount != r.fromAccount) ||
+		(r.toAccount != "" && tr.ToAccount != r.toAccount) ||
+		(r.isExpense != nil && tr.IsExpense != *r.isExpense) {
+		return false
+	}
+	if r.source != "" && (tr.Source == nil || (tr.Source.Tag != r.source && tr.Source.TypeName != r.source)) {
+		return false
+	}
+	amount := tr.Amount.int
+	if r.currency != "" {
+		switch {
+		case tr.AccountCurrency == r.currency:
+		case tr.OriginCurrency == r.currency:
+			amount = tr.OriginCurrencyAmount.int
+		default:
+			return false
+		}
+	}
+	if amount < 0 {
+		amount = -amount
+	}
+	if (r.minAmount != nil && amount < *r.minAmount) || (r.maxAmount != nil && amount > *r.maxAmount) {
+		return false
+	}
+	return true
+}
+
+// matchRules returns the first matching rule from the specified range of sorted rules.
+func (c *Categorization) matchRules(tr *Transaction, from, to int) *CategoryMatch {
+	for _, rule := range c.rules[from:to] {
+		if rule.matches(tr) {
+			return &CategoryMatch{
+				Name:      rule.groupName,
+				RuleType:  RuleTypeRule,
+				RuleValue: rule.description,
+			}
+		}
+	}
+	return nil
 }
 
 // NewCategorization creates and initializes a new Categorization instance.
@@ -30,8 +287,40 @@ func NewCategorization(config *Config) (*Categorization, error) {
 		trie:                          newTrieNode(),
 		fromAccountToGroupConfig:      make(map[string]*groupConfigWithName),
 		toAccountToGroupConfig:        make(map[string]*groupConfigWithName),
+		overrides:                     make(map[string]string, len(config.CategoryOverrides)),
+		splits:                        config.TransactionSplits,
+	}
+	for fingerprint, override := range config.CategoryOverrides {
+		c.overrides[fingerprint] = override.Group
 	}
 
+	var err error
+	if c.categoryPaths, err = buildCategoryPaths(config.Groups); err != nil {
+		return nil, err
+	}
+
+	// Build rules in order of group names to don't depend on map order for rules with the same priority.
+	groupNames := make([]string, 0, len(config.Groups))
+	for groupName := range config.Groups {
+		groupNames = append(groupNames, groupName)
+	}
+	sort.Strings(groupNames)
+	for _, groupName := range groupNames {
+		for i, rule := range config.Groups[groupName].Rules {
+			compiled, err := newCompiledRule(groupName, i+1, rule)
+			if err != nil {
+				return nil, err
+			}
+			c.rules = append(c.rules, compiled)
+		}
+	}
+	sort.SliceStable(c.rules, func(i, j int) bool {
+		return c.rules[i].priority > c.rules[j].priority
+	})
+	c.lowPriorityRulesIndex = sort.Search(len(c.rules), func(i int) bool {
+		return c.rules[i].priority < 0
+	})
+
 	// Handle new Groups format.
 	for groupName, group := range config.Groups {
 		groupCopy := &groupConfigWithName{
@@ -76,16 +365,51 @@ func NewCategorization(config *Config) (*Categorization, error) {
 	return c, nil
 }
 
-// CategorizeTransaction categorizes a single transaction using the pre-built trie
+// CategorizeTransaction categorizes a single transaction using manual overrides, rules, the pre-built trie
 // and accounts mapping.
 // Returns CategoryMatch, flag if transaction is uncategorized and error.
 func (c *Categorization) CategorizeTransaction(tr *Transaction) (*CategoryMatch, bool, error) {
+	match, isUncategorized, err := c.findCategory(tr)
+	if err != nil {
+		return nil, false, err
+	}
+	match.Path = c.getCategoryPath(match.Name)
+	return match, isUncategorized, nil
+}
+
+// findCategory finds matching group for the transaction without path in categories hierarchy.
+func (c *Categorization) findCategory(tr *Transaction) (*CategoryMatch, bool, error) {
 	// Validate transaction details.
 	if tr.Details == "" {
 		return nil, false, errors.New(i18n.T("empty details for transaction from f t", "f", tr.Source, "t", tr))
 	}
 
-	// First try to find matching group by accounts.
+	// Manual splits and overrides of single transactions have precedence over any rules.
+	// Split transaction is categorized into the first group, see `SplitJournalEntry` for the rest.
+	if len(c.overrides) > 0 || len(c.splits) > 0 {
+		fingerprint := TransactionFingerprint(tr)
+		if split, ok := c.splits[fingerprint]; ok {
+			return &CategoryMatch{
+				Name:      split.Parts[0].Group,
+				RuleType:  RuleTypeSplit,
+				RuleValue: fingerprint,
+			}, false, nil
+		}
+		if group, ok := c.overrides[fingerprint]; ok {
+			return &CategoryMatch{
+				Name:      group,
+				RuleType:  RuleTypeManual,
+				RuleValue: fingerprint,
+			}, false, nil
+		}
+	}
+
+	// Rules with not negative priority go first.
+	if match := c.matchRules(tr, 0, c.lowPriorityRulesIndex); match != nil {
+		return match, false, nil
+	}
+
+	// Next try to find matching group by accounts.
 	if tr.FromAccount != "" {
 		if groupConfig, ok := c.fromAccountToGroupConfig[tr.FromAccount]; ok {
 			return &CategoryMatch{
@@ -115,6 +439,11 @@ func (c *Categorization) CategorizeTransaction(tr *Transaction) (*CategoryMatch,
 		}, false, nil
 	}
 
+	// Try rules with negative priority.
+	if match := c.matchRules(tr, c.lowPriorityRulesIndex, len(c.rules)); match != nil {
+		return match, false, nil
+	}
+
 	// Handle uncategorized case.
 	if c.isGroupAllUnknownTransactions {
 		return &CategoryMatch{
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func TestCategorization_CategorizeTransaction_Rules(t *testing.T) {
+	source := &TransactionsSource{TypeName: "Inecobank XML", Tag: "InecoXml"}
+	taxi := Transaction{
+		Date:            testDate,
+		FromAccount:     "my",
+		ToAccount:       "taxi",
+		IsExpense:       true,
+		Amount:          Money{int: 2500000},
+		Details:         "Yandex Taxi ride",
+		Source:          source,
+		AccountCurrency: "AMD",
+	}
+	foreignTaxi := taxi
+	foreignTaxi.Amount = Money{int: 4000000}
+	foreignTaxi.OriginCurrency = "USD"
+	foreignTaxi.OriginCurrencyAmount = Money{int: 10000}
+	refund := taxi
+	refund.IsExpense = false
+	tests := []struct {
+		name        string
+		groups      map[string]*GroupConfig
+		transaction Transaction
+		expected    *CategoryMatch
+	}{
+		{
+			name: "regexp_ignore_case",
+			groups: map[string]*GroupConfig{
+				"Taxi": {Rules: []RuleConfig{{Regexp: "^yandex (taxi|go)", IgnoreCase: true}}},
+			},
+			transaction: taxi,
+			expected:    &CategoryMatch{Name: "Taxi", RuleType: RuleTypeRule, RuleValue: `regexp="^yandex (taxi|go)" (ignore case)`},
+		},
+		{
+			name: "case_sensitive_substring_doesnt_match",
+			groups: map[string]*GroupConfig{
+				"Taxi": {Rules: []RuleConfig{{Substring: "yandex"}}},
+			},
+			transaction: taxi,
+			expected:    &CategoryMatch{Name: UnknownGroupName},
+		},
+		{
+			name: "all_conditions_match",
+			groups: map[string]*GroupConfig{
+				"Taxi": {Rules: []RuleConfig{{
+					Name:        "Taxi expenses",
+					Substring:   "TAXI",
+					IgnoreCase:  true,
+					FromAccount: "my",
+					ToAccount:   "taxi",
+					MinAmount:   floatPtr(2500),
+					MaxAmount:   floatPtr(2500),
+					Direction:   "expense",
+					Source:      "InecoXml",
+					Currency:    "AMD",
+				}}},
+			},
+			transaction: taxi,
+			expected:    &CategoryMatch{Name: "Taxi", RuleType: RuleTypeRule, RuleValue: "Taxi expenses"},
+		},
+		{
+			name: "direction_doesnt_match",
+			groups: map[string]*GroupConfig{
+				"Taxi": {Rules: []RuleConfig{{Substring: "Taxi", Direction: "expense"}}},
+			},
+			transaction: refund,
+			expected:    &CategoryMatch{Name: UnknownGroupName},
+		},
+		{
+			name: "amount_out_of_range",
+			groups: map[string]*GroupConfig{
+				"Taxi": {Rules: []RuleConfig{{Substring: "Taxi", MaxAmount: floatPtr(2499.99)}}},
+			},
+			transaction: taxi,
+			expected:    &CategoryMatch{Name: UnknownGroupName},
+		},
+		{
+			name: "amount_in_origin_currency",
+			groups: map[string]*GroupConfig{
+				"Taxi": {Rules: []RuleConfig{{Currency: "USD", MinAmount: floatPtr(10), MaxAmount: floatPtr(10)}}},
+			},
+			transaction: foreignTaxi,
+			expected:    &CategoryMatch{Name: "Taxi", RuleType: RuleTypeRule, RuleValue: "amount>=10.00 AND amount<=10.00 AND currency=USD"},
+		},
+		{
+			name: "source_by_type_name",
+			groups: map[string]*GroupConfig{
+				"Ineco": {Rules: []RuleConfig{{Source: "Inecobank XML"}}},
+			},
+			transaction: taxi,
+			expected:    &CategoryMatch{Name: "Ineco", RuleType: RuleTypeRule, RuleValue: "source=Inecobank XML"},
+		},
+		{
+			name: "rule_before_substrings",
+			groups: map[string]*GroupConfig{
+				"Transport": {Substrings: []string{"Taxi"}},
+				"Taxi":      {Rules: []RuleConfig{{Name: "rule", Direction: "expense"}}},
+			},
+			transaction: taxi,
+			expected:    &CategoryMatch{Name: "Taxi", RuleType: RuleTypeRule, RuleValue: "rule"},
+		},
+		{
+			name: "negative_priority_after_substrings",
+			groups: map[string]*GroupConfig{
+				"Transport": {Substrings: []string{"Taxi"}},
+				"Taxi":      {Rules: []RuleConfig{{Name: "rule", Direction: "expense", Priority: -1}}},
+			},
+			transaction: taxi,
+			expected:    &CategoryMatch{Name: "Transport", RuleType: RuleTypeSubstring, RuleValue: "Taxi"},
+		},
+		{
+			name: "negative_priority_for_uncategorized",
+			groups: map[string]*GroupConfig{
+				"Transport": {Substrings: []string{"Bus"}},
+				"Other":     {Rules: []RuleConfig{{Name: "fallback", Direction: "expense", Priority: -1}}},
+			},
+			transaction: taxi,
+			expected:    &CategoryMatch{Name: "Other", RuleType: RuleTypeRule, RuleValue: "fallback"},
+		},
+		{
+			name: "higher_priority_wins",
+			groups: map[string]*GroupConfig{
+				"A": {Rules: []RuleConfig{{Name: "a", Substring: "Taxi"}}},
+				"B": {Rules: []RuleConfig{{Name: "b", Substring: "Taxi", Priority: 10}}},
+			},
+			transaction: taxi,
+			expected:    &CategoryMatch{Name: "B", RuleType: RuleTypeRule, RuleValue: "b"},
+		},
+		{
+			name: "same_priority_by_group_name",
+			groups: map[string]*GroupConfig{
+				"B": {Rules: []RuleConfig{{Name: "b", Substring: "Taxi"}}},
+				"A": {Rules: []RuleConfig{{Name: "a", Substring: "Taxi"}}},
+			},
+			transaction: taxi,
+			expected:    &CategoryMatch{Name: "A", RuleType: RuleTypeRule, RuleValue: "a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Arrange
+			categorization, err := NewCategorization(&Config{Groups: tt.groups, GroupAllUnknownTransactions: true})
+			if err != nil {
+				t.Fatalf("NewCategorization failed: %v", err)
+			}
+
+			// Act
+			actual, _, err := categorization.CategorizeTransaction(&tt.transaction)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("CategorizeTransaction failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, actual, cmpopts.IgnoreFields(CategoryMatch{}, "Path")); diff != "" {
+				t.Errorf("match mismatch (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewCategorization_WrongRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		rule          RuleConfig
+		expectedError string
+	}{
+		{"no_conditions", RuleConfig{Name: "empty", Priority: 1}, "rule #1 of group 'Group' has no conditions"},
+		{"wrong_regexp", RuleConfig{Regexp: "(unclosed"}, "has wrong regexp"},
+		{"wrong_direction", RuleConfig{Direction: "outcome"}, "wrong direction 'outcome'"},
+		{"wrong_amounts", RuleConfig{MinAmount: floatPtr(10), MaxAmount: floatPtr(5)}, "'minAmount' greater than 'maxAmount'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Arrange
+			config := &Config{Groups: map[string]*GroupConfig{"Group": {Rules: []RuleConfig{tt.rule}}}}
+
+			// Act
+			_, err := NewCategorization(config)
+
+			// Assert
+			checkErrorContainsSubstring(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestCategorization_GetUncategorizedTransactions(t *testing.T) {
+	// Arrange
+	config := &Config{Groups: map[string]*GroupConfig{
+		"Food":   {Substrings: []string{"Coffee"}},
+		"Income": {Rules: []RuleConfig{{Direction: "income"}}},
+	}}
+	categorization, err := NewCategorization(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transactions := []Transaction{
+		{Details: "Salary", IsExpense: false},
+		{Details: "Coffee", IsExpense: true},
+		{Details: "", IsExpense: true},
+	}
+
+	// Act
+	actual := categorization.GetUncategorizedTransactions(transactions)
+
+	// Assert
+	// Only substrings are checked, rules are not.
+	if len(actual) != 1 || actual[0].Details != "Salary" {
+		t.Errorf("expected only 'Salary' transaction, got %v", actual)
+	}
+}
+
+func TestBuildCategoryPaths(t *testing.T) {
+	tests := []struct {
+		name          string
+		groups        map[string]*GroupConfig
+		expected      map[string][]string
+		expectedError string
+	}{
+		{
+			name:     "flat",
+			groups:   map[string]*GroupConfig{"Food": {}, "Taxi": {}},
+			expected: map[string][]string{"Food": {"Food"}, "Taxi": {"Taxi"}},
+		},
+		{
+			name:     "separator_in_name",
+			groups:   map[string]*GroupConfig{"Food:Coffee": {}, "Food: Restaurants ": {}},
+			expected: map[string][]string{"Food:Coffee": {"Food", "Coffee"}, "Food: Restaurants ": {"Food", "Restaurants"}},
+		},
+		{
+			name: "parents",
+			groups: map[string]*GroupConfig{
+				"Food":        {},
+				"Restaurants": {Parent: "Food"},
+				"Pizza":       {Parent: "Restaurants"},
+				"Coffee":      {Parent: "Drinks:Hot"},
+			},
+			expected: map[string][]string{
+				"Food":        {"Food"},
+				"Restaurants": {"Food", "Restaurants"},
+				"Pizza":       {"Food", "Restaurants", "Pizza"},
+				"Coffee":      {"Drinks", "Hot", "Coffee"},
+			},
+		},
+		{
+			name:          "cyclic_parents",
+			groups:        map[string]*GroupConfig{"A": {Parent: "B"}, "B": {Parent: "A"}},
+			expectedError: "cyclic parents",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			actual, err := buildCategoryPaths(tt.groups)
+
+			// Assert
+			if tt.expectedError != "" {
+				checkErrorContainsSubstring(t, err, tt.expectedError)
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildCategoryPaths failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Errorf("paths mismatch (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRenameGroup(t *testing.T) {
+	tests := []struct {
+		name          string
+		groups        map[string]*GroupConfig
+		oldName       string
+		newName       string
+		expected      map[string]*GroupConfig
+		expectedError string
+	}{
+		{
+			name: "children_by_parent_and_path",
+			groups: map[string]*GroupConfig{
+				"Food":           {Substrings: []string{"FOOD"}},
+				"Food:Groceries": {},
+				"Restaurants":    {Parent: "Food"},
+				"Pizza":          {Parent: "Food:Restaurants"},
+				"Foods":          {},
+				"Taxi":           {},
+			},
+			oldName: "Food",
+			newName: "Meals",
+			expected: map[string]*GroupConfig{
+				"Meals":           {Substrings: []string{"FOOD"}},
+				"Meals:Groceries": {},
+				"Restaurants":     {Parent: "Meals"},
+				"Pizza":           {Parent: "Meals:Restaurants"},
+				"Foods":           {},
+				"Taxi":            {},
+			},
+		},
+		{
+			name:          "renamed_child_exists",
+			groups:        map[string]*GroupConfig{"Food": {}, "Food:Groceries": {}, "Meals:Groceries": {}},
+			oldName:       "Food",
+			newName:       "Meals",
+			expectedError: errGroupAlreadyExists.Error(),
+		},
+		{
+			name:          "missing_group",
+			groups:        map[string]*GroupConfig{"Food": {}},
+			oldName:       "Taxi",
+			newName:       "Transport",
+			expectedError: "Group with this name doesn't exist: 'Taxi'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			err := renameGroup(tt.groups, tt.oldName, tt.newName)
+
+			// Assert
+			if tt.expectedError != "" {
+				checkErrorContainsSubstring(t, err, tt.expectedError)
+				return
+			}
+			if err != nil {
+				t.Fatalf("renameGroup failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, tt.groups); diff != "" {
+				t.Errorf("groups mismatch (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCategorization_CategorizeTransaction_Path(t *testing.T) {
+	// Arrange
+	config := &Config{Groups: map[string]*GroupConfig{
+		"Food":   {},
+		"Coffee": {Parent: "Food", Substrings: []string{"COFFEE"}},
+	}}
+	categorization, err := NewCategorization(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	actual, _, err := categorization.CategorizeTransaction(&Transaction{Details: "COFFEE HOUSE", IsExpense: true})
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"Food", "Coffee"}, actual.Path); diff != "" {
+		t.Errorf("path mismatch (-expected +actual):\n%s", diff)
+	}
+}
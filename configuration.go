@@ -1,8 +1,11 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"runtime"
+	"strings"
 	"time"
 
 	_ "time/tzdata"
@@ -35,18 +38,106 @@ type GroupConfig struct {
 	FromAccounts []string `yaml:"fromAccounts,omitempty"`
 	// Accounts to match in "receiver" field.
 	ToAccounts []string `yaml:"toAccounts,omitempty"`
+	// Rules with several conditions, see `RuleConfig`.
+	Rules []RuleConfig `yaml:"rules,omitempty"`
+	// Parent is a name or ":"-separated path of the parent category.
+	// Alternatively group name may be a path itself, like "Food:Groceries".
+	Parent string `yaml:"parent,omitempty"`
+}
+
+// RuleConfig is a categorization rule which matches transaction only if all set conditions match.
+// Rules are checked in order of descending `Priority`. Rules with not negative priority are checked
+// before `Substrings`, `FromAccounts` and `ToAccounts` of all groups, rules with negative priority - after.
+type RuleConfig struct {
+	// Name is an optional name to show which rule categorized transaction.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Priority of the rule, 0 by default.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+	// Substring to search in transaction's details.
+	Substring string `yaml:"substring,omitempty" json:"substring,omitempty"`
+	// Regexp is a regular expression to search in transaction's details.
+	Regexp string `yaml:"regexp,omitempty" json:"regexp,omitempty"`
+	// IgnoreCase makes `Substring` and `Regexp` case-insensitive.
+	IgnoreCase bool `yaml:"ignoreCase,omitempty" json:"ignoreCase,omitempty"`
+	// FromAccount to match with "payee" account.
+	FromAccount string `yaml:"fromAccount,omitempty" json:"fromAccount,omitempty"`
+	// ToAccount to match with "receiver" account.
+	ToAccount string `yaml:"toAccount,omitempty" json:"toAccount,omitempty"`
+	// MinAmount is a minimal (inclusive) amount of transaction.
+	MinAmount *float64 `yaml:"minAmount,omitempty" json:"minAmount,omitempty"`
+	// MaxAmount is a maximal (inclusive) amount of transaction.
+	MaxAmount *float64 `yaml:"maxAmount,omitempty" json:"maxAmount,omitempty"`
+	// Direction is "expense" or "income".
+	Direction string `yaml:"direction,omitempty" json:"direction,omitempty"`
+	// Source is a tag (like "InecoXml") or a type name of the transactions source.
+	Source string `yaml:"source,omitempty" json:"source,omitempty"`
+	// Currency is an account or origin currency of transaction.
+	// If it is origin currency then `MinAmount` and `MaxAmount` are compared with amount in origin currency.
+	Currency string `yaml:"currency,omitempty" json:"currency,omitempty"`
+}
+
+// Description returns all conditions of the rule joined with "AND". Empty if rule has no conditions.
+func (r RuleConfig) Description() string {
+	conditions := []string{}
+	if r.Substring != "" {
+		conditions = append(conditions, fmt.Sprintf("substring=%q", r.Substring))
+	}
+	if r.Regexp != "" {
+		conditions = append(conditions, fmt.Sprintf("regexp=%q", r.Regexp))
+	}
+	if r.IgnoreCase && (r.Substring != "" || r.Regexp != "") {
+		conditions[len(conditions)-1] += " (ignore case)"
+	}
+	if r.FromAccount != "" {
+		conditions = append(conditions, "fromAccount="+r.FromAccount)
+	}
+	if r.ToAccount != "" {
+		conditions = append(conditions, "toAccount="+r.ToAccount)
+	}
+	if r.MinAmount != nil {
+		conditions = append(conditions, fmt.Sprintf("amount>=%.2f", *r.MinAmount))
+	}
+	if r.MaxAmount != nil {
+		conditions = append(conditions, fmt.Sprintf("amount<=%.2f", *r.MaxAmount))
+	}
+	if r.Direction != "" {
+		conditions = append(conditions, "direction="+r.Direction)
+	}
+	if r.Source != "" {
+		conditions = append(conditions, "source="+r.Source)
+	}
+	if r.Currency != "" {
+		conditions = append(conditions, "currency="+r.Currency)
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// SourceConfig describes set of transactions files to parse with one parser.
+type SourceConfig struct {
+	// Parser is a name of registered parser, see `RegisterParser`.
+	Parser string `yaml:"parser" validate:"required"`
+	// Glob is a glob pattern of files to parse. `DefaultGlob` of the parser is used if empty.
+	Glob string `yaml:"glob,omitempty" validate:"omitempty,filepath"`
+	// Options are parser-specific settings.
+	Options map[string]any `yaml:"options,omitempty"`
 }
 
 // Config represents the application configuration.
+// Note that `*FilesGlob` fields are kept for backward compatibility, `Sources` should be used instead.
 type Config struct {
 	Language                             string                        `yaml:"language,omitempty" validate:"omitempty,oneof=en ru"`
 	EnsureTerminal                       bool                          `yaml:"ensureTerminal,omitempty"`
 	UIPort                               int                           `yaml:"uiPort,omitempty"`
-	InecobankStatementXmlFilesGlob       string                        `yaml:"inecobankStatementXmlFilesGlob" validate:"omitempty,filepath,min=1"`
-	InecobankStatementXlsxFilesGlob      string                        `yaml:"inecobankStatementXlsxFilesGlob" validate:"omitempty,filepath,min=1"`
-	AmeriaCsvFilesGlob                   string                        `yaml:"ameriaCsvFilesGlob" validate:"omitempty,filepath,min=1"`
-	MyAmeriaAccountStatementXlsFilesGlob string                        `yaml:"myAmeriaAccountStatementXlsxFilesGlob" validate:"omitempty,filepath,min=1"`
-	MyAmeriaHistoryXlsFilesGlob          string                        `yaml:"myAmeriaHistoryXlsFilesGlob" validate:"omitempty,filepath,min=1"`
+	Sources                              []SourceConfig                `yaml:"sources,omitempty" validate:"omitempty,dive"`
+	InboxGlob                            string                        `yaml:"inboxGlob,omitempty" validate:"omitempty,filepath"`
+	DisableTransactionsCache             bool                          `yaml:"disableTransactionsCache,omitempty"`
+	ParsingWorkers                       int                           `yaml:"parsingWorkers,omitempty" validate:"min=0"`
+	DisableFilesWatcher                  bool                          `yaml:"disableFilesWatcher,omitempty"`
+	InecobankStatementXmlFilesGlob       string                        `yaml:"inecobankStatementXmlFilesGlob,omitempty" validate:"omitempty,filepath,min=1"`
+	InecobankStatementXlsxFilesGlob      string                        `yaml:"inecobankStatementXlsxFilesGlob,omitempty" validate:"omitempty,filepath,min=1"`
+	AmeriaCsvFilesGlob                   string                        `yaml:"ameriaCsvFilesGlob,omitempty" validate:"omitempty,filepath,min=1"`
+	MyAmeriaAccountStatementXlsFilesGlob string                        `yaml:"myAmeriaAccountStatementXlsxFilesGlob,omitempty" validate:"omitempty,filepath,min=1"`
+	MyAmeriaHistoryXlsFilesGlob          string                        `yaml:"myAmeriaHistoryXlsFilesGlob,omitempty" validate:"omitempty,filepath,min=1"`
 	ArdshinbankXlsxFilesGlob             string                        `yaml:"ardshinbankXlsxFilesGlob,omitempty" validate:"omitempty,filepath,min=1"`
 	AcbaRegularAccountXlsFilesGlob       string                        `yaml:"acbaRegularAccountXlsFilesGlob,omitempty" validate:"omitempty,filepath,min=1"`
 	AcbaCardXlsFilesGlob                 string                        `yaml:"acbaCardXlsFilesGlob,omitempty" validate:"omitempty,filepath,min=1"`
@@ -57,6 +148,11 @@ type Config struct {
 	ConvertToCurrencies                  []string                      `yaml:"convertToCurrencies,omitempty"`
 	MinCurrencyTimespanPercent           int                           `yaml:"minCurrencyTimespanPercent,omitempty" validate:"min=0,max=100"`
 	MaxCurrencyTimespanGapDays           int                           `yaml:"maxCurrencyTimespanGapDays,omitempty" validate:"min=0"`
+	Deduplication                        *DeduplicationConfig          `yaml:"deduplication,omitempty"`
+	Transfers                            *TransferMatchingConfig       `yaml:"transfers,omitempty"`
+	Budgets                              map[string]*BudgetConfig      `yaml:"budgets,omitempty" validate:"omitempty,dive"`
+	Anomalies                            *AnomalyDetectionConfig       `yaml:"anomalies,omitempty"`
+	Period                               *PeriodConfig                 `yaml:"period,omitempty"`
 
 	DetailedOutput              bool   `yaml:"detailedOutput"`
 	CategorizeMode              bool   `yaml:"categorizeMode"`
@@ -65,6 +161,12 @@ type Config struct {
 	GroupAllUnknownTransactions bool   `yaml:"groupAllUnknownTransactions"`
 	// Transactions categorization groups.
 	Groups map[string]*GroupConfig `yaml:"groups,omitempty"`
+	// CategoryOverrides are manual categories of single transactions per transaction fingerprint.
+	// They are stored in separate file next to the configuration file, see `CategoryOverridesFileName`.
+	CategoryOverrides map[string]*CategoryOverride `yaml:"-"`
+	// TransactionSplits are splits of single transactions across several categories per transaction fingerprint.
+	// They are stored in the same file as `CategoryOverrides`.
+	TransactionSplits map[string]*TransactionSplit `yaml:"-"`
 }
 
 func readConfig(filename string) (*Config, error) {
@@ -130,9 +232,120 @@ func readConfig(filename string) (*Config, error) {
 		return nil, err
 	}
 
+	// Check that all budgets have limits.
+	for name, budget := range cfg.Budgets {
+		if budget == nil || (budget.Limit == nil && len(budget.Limits) == 0) {
+			return nil, fmt.Errorf("budget '%s' should have 'limit' or 'limits'", name)
+		}
+	}
+
+	// Check that custom periods are correct.
+	if cfg.Period != nil && (cfg.Period.Type == PeriodCustom || len(cfg.Period.Ranges) > 0) {
+		if _, err := newCustomPeriod(cfg.Period.Ranges, time.UTC); err != nil {
+			return nil, err
+		}
+	}
+
+	// Check that salary periods have known group.
+	if cfg.Period != nil && (cfg.Period.Type == PeriodSalary || cfg.Period.SalaryGroup != "") {
+		if cfg.Period.SalaryGroup == "" {
+			return nil, errors.New("'salary' period requires 'period.salaryGroup'")
+		}
+		if _, ok := cfg.Groups[cfg.Period.SalaryGroup]; !ok {
+			return nil, fmt.Errorf("salary group '%s' is not found in 'groups'", cfg.Period.SalaryGroup)
+		}
+	}
+
+	// Check that all sources have known parsers and valid options.
+	for i, source := range cfg.GetSources() {
+		if _, _, err := newParserForSource(source, cfg); err != nil {
+			return nil, fmt.Errorf("source #%d with '%s' glob is wrong: %w", i+1, source.Glob, err)
+		}
+	}
+
+	// Read manual categories and splits of single transactions.
+	overrides, err := readCategoryOverrides(getCategoryOverridesPath(filename))
+	if err != nil {
+		return nil, err
+	}
+	cfg.CategoryOverrides = overrides.Overrides
+	cfg.TransactionSplits = overrides.Splits
+
 	return cfg, nil
 }
 
+// GetParsingWorkers returns number of goroutines to parse files with.
+// By default it is number of CPUs because parsing of Excel files is CPU-bound.
+func (cfg *Config) GetParsingWorkers() int {
+	if cfg.ParsingWorkers > 0 {
+		return cfg.ParsingWorkers
+	}
+	return runtime.NumCPU()
+}
+
+// GetPeriodType returns configured type of periods or months by default.
+func (cfg *Config) GetPeriodType() PeriodType {
+	if cfg.Period == nil || cfg.Period.Type == "" {
+		return PeriodMonth
+	}
+	return cfg.Period.Type
+}
+
+// GetAvailablePeriodTypes returns types of periods which could be built with the configuration.
+// Custom and salary periods are available only if they are configured.
+func (cfg *Config) GetAvailablePeriodTypes() []PeriodType {
+	result := make([]PeriodType, 0, len(PeriodTypes))
+	for _, periodType := range PeriodTypes {
+		switch periodType {
+		case PeriodCustom:
+			if cfg.Period == nil || len(cfg.Period.Ranges) == 0 {
+				continue
+			}
+		case PeriodSalary:
+			if cfg.Period == nil || cfg.Period.SalaryGroup == "" {
+				continue
+			}
+		}
+		result = append(result, periodType)
+	}
+	return result
+}
+
+// GetSources returns all sources of transactions files to parse.
+// Sources from legacy `*FilesGlob` settings go first in the historical order.
+// Sources without glob get default glob of the parser.
+func (cfg *Config) GetSources() []SourceConfig {
+	legacyGlobs := []struct {
+		parser string
+		glob   string
+	}{
+		{InecoXmlParserName, cfg.InecobankStatementXmlFilesGlob},
+		{InecoXlsxParserName, cfg.InecobankStatementXlsxFilesGlob},
+		{MyAmeriaXlsParserName, cfg.MyAmeriaAccountStatementXlsFilesGlob},
+		{MyAmeriaHistoryXlsParserName, cfg.MyAmeriaHistoryXlsFilesGlob},
+		{AmeriaCsvParserName, cfg.AmeriaCsvFilesGlob},
+		{ArdshinXlsxParserName, cfg.ArdshinbankXlsxFilesGlob},
+		{AcbaRegularAccountXlsParserName, cfg.AcbaRegularAccountXlsFilesGlob},
+		{AcbaCardXlsParserName, cfg.AcbaCardXlsFilesGlob},
+		{GenericCsvParserName, cfg.GenericCsvFilesGlob},
+	}
+	result := make([]SourceConfig, 0, len(legacyGlobs)+len(cfg.Sources))
+	for _, legacy := range legacyGlobs {
+		if legacy.glob != "" {
+			result = append(result, SourceConfig{Parser: legacy.parser, Glob: legacy.glob})
+		}
+	}
+	for _, source := range cfg.Sources {
+		if source.Glob == "" {
+			if registration, ok := getParserRegistration(source.Parser); ok {
+				source.Glob = registration.DefaultGlob
+			}
+		}
+		result = append(result, source)
+	}
+	return result
+}
+
 // writeToFile writes the configuration to a file with preserving comments.
 // Note that comments are preserved with following limitations:
 // - Optional fields will be added with default values.
@@ -3,8 +3,10 @@ package main
 import (
 	"errors"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/thlib/go-timezone-local/tzlocal"
 )
 
@@ -461,6 +463,100 @@ groups:
 	assertStringEqual(t, actualContent, expectedContent)
 }
 
+func TestReadConfig_Sources(t *testing.T) {
+	// Arrange
+	tempFile := createTempFileWithContent(
+		`inecobankStatementXmlFilesGlob: "*.xml"
+genericCsvFilesGlob: "generic*.csv"
+sources:
+  - parser: ardshinXlsx
+  - parser: myAmeriaHistoryXls
+    glob: "History*.xls"
+    options:
+      myAccounts:
+        "1234567890123456": AMD
+groups:
+  g1:
+    substrings:
+      - Sub1
+`,
+	)
+	defer os.Remove(tempFile.Name())
+
+	// Act
+	cfg, err := readConfig(tempFile.Name())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expectedSources := []SourceConfig{
+		{Parser: InecoXmlParserName, Glob: "*.xml"},
+		{Parser: GenericCsvParserName, Glob: "generic*.csv"},
+		{Parser: ArdshinXlsxParserName, Glob: "STATEMENT_*.xlsx"},
+		{
+			Parser: MyAmeriaHistoryXlsParserName,
+			Glob:   "History*.xls",
+			Options: map[string]any{
+				"myAccounts": map[string]any{"1234567890123456": "AMD"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedSources, cfg.GetSources()); diff != "" {
+		t.Errorf("Sources mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestReadConfig_WrongSources(t *testing.T) {
+	tests := []struct {
+		name          string
+		sources       string
+		expectedError string
+	}{
+		{
+			name: "unknown parser",
+			sources: `  - parser: unknownBank
+    glob: "*.csv"`,
+			expectedError: "source #1 with '*.csv' glob is wrong: unknown parser 'unknownBank'",
+		},
+		{
+			name:          "unknown parser without glob",
+			sources:       `  - parser: unknownBank`,
+			expectedError: "source #1 with '' glob is wrong: unknown parser 'unknownBank'",
+		},
+		{
+			name: "wrong options",
+			sources: `  - parser: ameriaCsv
+    glob: "*.csv"
+    options:
+      delimiter: ";"`,
+			expectedError: "can't create 'ameriaCsv' parser: parser doesn't support options",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tempFile := createTempFileWithContent("sources:\n" + tt.sources + `
+groups:
+  g1:
+    substrings:
+      - Sub1
+`)
+			defer os.Remove(tempFile.Name())
+
+			// Act
+			_, err := readConfig(tempFile.Name())
+
+			// Assert
+			if err == nil {
+				t.Fatal("Expected error, but got no error")
+			}
+			checkErrorContainsSubstring(t, err, tt.expectedError)
+		})
+	}
+}
+
 // createTempFileWithContent creates a temporary file with the given content.
 func createTempFileWithContent(content string) *os.File {
 	tempFile, err := os.CreateTemp("", "test_config_*.yaml")
@@ -472,3 +568,139 @@ func createTempFileWithContent(content string) *os.File {
 	}
 	return tempFile
 }
+
+func TestWriteToFile_KeepsRules(t *testing.T) {
+	// Arrange.
+	initialContent := `timeZoneLocation: UTC
+groups:
+  Taxi:
+    substrings:
+      - TAXI
+    rules:
+      # Rule comment
+      - name: Expensive taxi
+        regexp: "^YANDEX"
+        ignoreCase: true
+        minAmount: 5000.5
+        direction: expense
+`
+
+	// Act.
+	result := readUseWriteConfig(t, initialContent)
+
+	// Assert.
+	tempFile := createTempFileWithContent(result)
+	defer os.Remove(tempFile.Name())
+	cfg, err := readConfig(tempFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to read written config: %v", err)
+	}
+	expected := []RuleConfig{{Name: "Expensive taxi", Regexp: "^YANDEX", IgnoreCase: true, MinAmount: floatPtr(5000.5), Direction: "expense"}}
+	if diff := cmp.Diff(expected, cfg.Groups["Taxi"].Rules); diff != "" {
+		t.Errorf("rules mismatch (-expected +actual):\n%s", diff)
+	}
+	if !strings.Contains(result, "# Rule comment") {
+		t.Errorf("comment is lost:\n%s", result)
+	}
+}
+
+func TestReadConfig_WrongBudgets(t *testing.T) {
+	tests := []struct {
+		name          string
+		budgets       string
+		expectedError string
+	}{
+		{
+			name:          "no limits",
+			budgets:       "  Food:\n    rollover: true",
+			expectedError: "budget 'Food' should have 'limit' or 'limits'",
+		},
+		{
+			name:          "negative limit",
+			budgets:       "  Food:\n    limit: -100",
+			expectedError: "Error:Field validation for 'Limit' failed on the 'gt' tag",
+		},
+		{
+			name:          "zero limit in currency",
+			budgets:       "  Food:\n    limits: {AMD: 0}",
+			expectedError: "failed on the 'gt' tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tempFile := createTempFileWithContent("budgets:\n" + tt.budgets + `
+groups:
+  Food:
+    substrings:
+      - Sub1
+`)
+			defer os.Remove(tempFile.Name())
+
+			// Act
+			_, err := readConfig(tempFile.Name())
+
+			// Assert
+			checkErrorContainsSubstring(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestReadConfig_WrongPeriod(t *testing.T) {
+	tests := []struct {
+		name          string
+		period        string
+		expectedError string
+	}{
+		{
+			name:          "unknown type",
+			period:        "  type: decade",
+			expectedError: "Error:Field validation for 'Type' failed on the 'oneof' tag",
+		},
+		{
+			name:          "custom without ranges",
+			period:        "  type: custom",
+			expectedError: "'custom' period requires 'period.ranges'",
+		},
+		{
+			name:          "overlapping ranges",
+			period:        "  ranges:\n    - {name: A, from: 2024-01-01, to: 2024-01-31}\n    - {name: B, from: 2024-01-15, to: 2024-02-15}",
+			expectedError: "period ranges 'A' and 'B' overlap",
+		},
+		{
+			name:          "salary without group",
+			period:        "  type: salary",
+			expectedError: "'salary' period requires 'period.salaryGroup'",
+		},
+		{
+			name:          "unknown salary group",
+			period:        "  type: salary\n  salaryGroup: Salary",
+			expectedError: "salary group 'Salary' is not found in 'groups'",
+		},
+		{
+			name:          "too short salary period",
+			period:        "  salaryGroup: Food\n  salaryMaxDays: 10",
+			expectedError: "Error:Field validation for 'SalaryMaxDays' failed on the 'min' tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tempFile := createTempFileWithContent("period:\n" + tt.period + `
+groups:
+  Food:
+    substrings:
+      - Sub1
+`)
+			defer os.Remove(tempFile.Name())
+
+			// Act
+			_, err := readConfig(tempFile.Name())
+
+			// Assert
+			checkErrorContainsSubstring(t, err, tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// CoverageIssueType is a type of problem with periods of statements of the account.
+type CoverageIssueType string
+
+const (
+	// CoverageGap is a period not covered by any statement.
+	CoverageGap CoverageIssueType = "gap"
+	// CoverageOverlap is a period covered by several statements.
+	CoverageOverlap CoverageIssueType = "overlap"
+	// CoverageBalanceMismatch is a closing balance of the statement not equal to opening balance of the next one.
+	CoverageBalanceMismatch CoverageIssueType = "balance"
+)
+
+// coverageTransactionsGapDays is a number of days without transactions which is not considered as a gap
+// if period of the statement is known only by dates of the first and the last transactions.
+const coverageTransactionsGapDays = 7
+
+// CoveragePeriod is a period of one statement file.
+type CoveragePeriod struct {
+	// Path is a path to the file.
+	Path string
+	// From is a first day of the statement.
+	From time.Time
+	// To is a last day of the statement.
+	To time.Time
+	// IsExact is true if period is taken from balances, otherwise from dates of transactions.
+	IsExact bool
+	// Source is a source of transactions of the file.
+	Source *TransactionsSource
+}
+
+// CoverageIssue is a problem between two statement files of the same account.
+type CoverageIssue struct {
+	// Type is a type of the problem.
+	Type CoverageIssueType
+	// From is a first day of the gap or overlap, day of balances for balance mismatch.
+	From time.Time
+	// To is a last day of the gap or overlap, the same as From for balance mismatch.
+	To time.Time
+	// Previous is a file before the problem.
+	Previous *CoveragePeriod
+	// Next is a file after the problem.
+	Next *CoveragePeriod
+}
+
+// Days returns number of days in the issue.
+func (i *CoverageIssue) Days() int {
+	return int(i.To.Sub(i.From).Hours()/24) + 1
+}
+
+// LocalizedType returns translated type of the issue.
+func (i *CoverageIssue) LocalizedType() string {
+	return i18n.T("coverage_" + string(i.Type))
+}
+
+// AccountCoverage contains periods of all statement files of one account.
+type AccountCoverage struct {
+	// Account is an account number.
+	Account string
+	// From is a first day of the first statement.
+	From time.Time
+	// To is a last day of the last statement.
+	To time.Time
+	// Periods are periods of statements sorted by start.
+	Periods []*CoveragePeriod
+	// Issues are gaps, overlaps and balance mismatches sorted by date.
+	Issues []*CoverageIssue
+}
+
+// newCoveragePeriod returns period of the file. Uses balances of the statement if they are available.
+func newCoveragePeriod(fileInfo *FileInfo) *CoveragePeriod {
+	period := &CoveragePeriod{
+		Path:   fileInfo.Path,
+		From:   balanceDay(fileInfo.FromDate),
+		To:     balanceDay(fileInfo.ToDate),
+		Source: fileInfo.Source,
+	}
+	opening, closing := fileInfo.Source.OpeningBalance, fileInfo.Source.ClosingBalance
+	if opening != nil && closing != nil {
+		period.IsExact = true
+		if day := balanceDay(opening.Date); day.Before(period.From) {
+			period.From = day
+		}
+		if day := balanceDay(closing.Date).AddDate(0, 0, -1); day.After(period.To) {
+			period.To = day
+		}
+	}
+	return period
+}
+
+// BuildCoverage finds periods covered by statement files per account and problems between them:
+// gaps without statements, overlapping statements and balance mismatches between consecutive statements.
+// Accounts are sorted by number.
+func BuildCoverage(fileInfos []FileInfo) []*AccountCoverage {
+	accounts := make(map[string]*AccountCoverage)
+	for i := range fileInfos {
+		fileInfo := &fileInfos[i]
+		if fileInfo.Source == nil || fileInfo.Source.AccountNumber == "" || fileInfo.TransactionsCount == 0 {
+			continue
+		}
+		account, ok := accounts[fileInfo.Source.AccountNumber]
+		if !ok {
+			account = &AccountCoverage{Account: fileInfo.Source.AccountNumber}
+			accounts[fileInfo.Source.AccountNumber] = account
+		}
+		account.Periods = append(account.Periods, newCoveragePeriod(fileInfo))
+	}
+
+	result := make([]*AccountCoverage, 0, len(accounts))
+	for _, account := range accounts {
+		sort.Slice(account.Periods, func(i, j int) bool {
+			if !account.Periods[i].From.Equal(account.Periods[j].From) {
+				return account.Periods[i].From.Before(account.Periods[j].From)
+			}
+			return account.Periods[i].To.Before(account.Periods[j].To)
+		})
+		account.Issues = make([]*CoverageIssue, 0)
+		// Statement which covers the latest day among already checked ones.
+		previous := account.Periods[0]
+		for _, next := range account.Periods[1:] {
+			if next.From.After(previous.To) {
+				gap := &CoverageIssue{
+					Type:     CoverageGap,
+					From:     previous.To.AddDate(0, 0, 1),
+					To:       next.From.AddDate(0, 0, -1),
+					Previous: previous,
+					Next:     next,
+				}
+				if gap.Days() > 0 && (previous.IsExact && next.IsExact || gap.Days() > coverageTransactionsGapDays) {
+					account.Issues = append(account.Issues, gap)
+				}
+			} else {
+				to := previous.To
+				if next.To.Before(to) {
+					to = next.To
+				}
+				account.Issues = append(account.Issues, &CoverageIssue{
+					Type:     CoverageOverlap,
+					From:     next.From,
+					To:       to,
+					Previous: previous,
+					Next:     next,
+				})
+			}
+			if previous.Source.ClosingBalance != nil && next.Source.OpeningBalance != nil &&
+				balanceDay(previous.Source.ClosingBalance.Date).Equal(balanceDay(next.Source.OpeningBalance.Date)) &&
+				previous.Source.ClosingBalance.Amount != next.Source.OpeningBalance.Amount {
+				day := balanceDay(next.Source.OpeningBalance.Date)
+				account.Issues = append(account.Issues, &CoverageIssue{
+					Type:     CoverageBalanceMismatch,
+					From:     day,
+					To:       day,
+					Previous: previous,
+					Next:     next,
+				})
+			}
+			if next.To.After(previous.To) {
+				previous = next
+			}
+		}
+		account.From = account.Periods[0].From
+		account.To = previous.To
+		result = append(result, account)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Account < result[j].Account
+	})
+	return result
+}
+
+// coverageToString returns human readable problems with periods of statements.
+func coverageToString(accounts []*AccountCoverage) string {
+	details := make([]string, 0)
+	for _, account := range accounts {
+		for _, issue := range account.Issues {
+			if issue.Type == CoverageBalanceMismatch {
+				details = append(details, i18n.T("account: balance mismatch on date between closing of file1 and opening of file2",
+					"account", account.Account,
+					"date", issue.From.Format(OutputDateFormat),
+					"closing", issue.Previous.Source.ClosingBalance.Amount.StringNoIndent(),
+					"file1", issue.Previous.Path,
+					"opening", issue.Next.Source.OpeningBalance.Amount.StringNoIndent(),
+					"file2", issue.Next.Path,
+				))
+				continue
+			}
+			details = append(details, i18n.T("account: issue from to (n days) between file1 and file2",
+				"account", account.Account,
+				"issue", issue.LocalizedType(),
+				"from", issue.From.Format(OutputDateFormat),
+				"to", issue.To.Format(OutputDateFormat),
+				"n", issue.Days(),
+				"file1", issue.Previous.Path,
+				"file2", issue.Next.Path,
+			))
+		}
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return i18n.T("Coverage_format",
+		"nAccounts", len(accounts),
+		"nIssues", len(details),
+		"detailsCoverage", details,
+	)
+}
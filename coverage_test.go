@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// coverageIssuesToStrings returns issues as "type from..to previous->next" strings.
+func coverageIssuesToStrings(issues []*CoverageIssue) []string {
+	result := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		result = append(result, fmt.Sprintf("%s %s..%s %s->%s", issue.Type,
+			issue.From.Format(OutputDateFormat), issue.To.Format(OutputDateFormat), issue.Previous.Path, issue.Next.Path))
+	}
+	return result
+}
+
+func TestBuildCoverage(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          []FileInfo
+		expectedFrom   time.Time
+		expectedTo     time.Time
+		expectedIssues []string
+	}{
+		{
+			name: "consecutive_statements",
+			files: []FileInfo{
+				newTestFileInfo("feb", "acc", utcDate(2024, 2, 1), utcDate(2024, 2, 29), intPtr(1000), intPtr(2000)),
+				newTestFileInfo("jan", "acc", utcDate(2024, 1, 1), utcDate(2024, 1, 31), intPtr(0), intPtr(1000)),
+			},
+			expectedFrom: utcDate(2024, 1, 1),
+			expectedTo:   utcDate(2024, 2, 29),
+		},
+		{
+			name: "gap_between_statements",
+			files: []FileInfo{
+				newTestFileInfo("jan", "acc", utcDate(2024, 1, 1), utcDate(2024, 1, 31), intPtr(0), intPtr(1000)),
+				newTestFileInfo("feb", "acc", utcDate(2024, 2, 3), utcDate(2024, 2, 29), intPtr(1000), intPtr(2000)),
+			},
+			expectedFrom:   utcDate(2024, 1, 1),
+			expectedTo:     utcDate(2024, 2, 29),
+			expectedIssues: []string{"gap 2024-02-01..2024-02-02 jan->feb"},
+		},
+		{
+			name: "short_gap_between_transactions",
+			files: []FileInfo{
+				newTestFileInfo("jan", "acc", utcDate(2024, 1, 2), utcDate(2024, 1, 28), nil, nil),
+				newTestFileInfo("feb", "acc", utcDate(2024, 2, 3), utcDate(2024, 2, 25), nil, nil),
+			},
+			expectedFrom: utcDate(2024, 1, 2),
+			expectedTo:   utcDate(2024, 2, 25),
+		},
+		{
+			name: "missed_month",
+			files: []FileInfo{
+				newTestFileInfo("jan", "acc", utcDate(2024, 1, 2), utcDate(2024, 1, 28), nil, nil),
+				newTestFileInfo("mar", "acc", utcDate(2024, 3, 3), utcDate(2024, 3, 25), nil, nil),
+			},
+			expectedFrom:   utcDate(2024, 1, 2),
+			expectedTo:     utcDate(2024, 3, 25),
+			expectedIssues: []string{"gap 2024-01-29..2024-03-02 jan->mar"},
+		},
+		{
+			name: "overlap_and_nested",
+			files: []FileInfo{
+				newTestFileInfo("q1", "acc", utcDate(2024, 1, 1), utcDate(2024, 3, 31), nil, nil),
+				newTestFileInfo("feb", "acc", utcDate(2024, 2, 1), utcDate(2024, 2, 10), nil, nil),
+				newTestFileInfo("mar-apr", "acc", utcDate(2024, 3, 20), utcDate(2024, 4, 30), nil, nil),
+			},
+			expectedFrom: utcDate(2024, 1, 1),
+			expectedTo:   utcDate(2024, 4, 30),
+			expectedIssues: []string{
+				"overlap 2024-02-01..2024-02-10 q1->feb",
+				"overlap 2024-03-20..2024-03-31 q1->mar-apr",
+			},
+		},
+		{
+			name: "balance_mismatch",
+			files: []FileInfo{
+				newTestFileInfo("jan", "acc", utcDate(2024, 1, 1), utcDate(2024, 1, 31), intPtr(0), intPtr(1000)),
+				newTestFileInfo("feb", "acc", utcDate(2024, 2, 1), utcDate(2024, 2, 29), intPtr(900), intPtr(2000)),
+			},
+			expectedFrom:   utcDate(2024, 1, 1),
+			expectedTo:     utcDate(2024, 2, 29),
+			expectedIssues: []string{"balance 2024-02-01..2024-02-01 jan->feb"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			files := append(tt.files, FileInfo{Path: "empty"}, newTestFileInfo("other", "another", utcDate(2024, 1, 1), utcDate(2024, 1, 1), nil, nil))
+
+			// Act
+			actual := BuildCoverage(files)
+
+			// Assert
+			if len(actual) != 2 || actual[0].Account != "acc" || actual[1].Account != "another" {
+				t.Fatalf("expected 'acc' and 'another' accounts, got %+v", actual)
+			}
+			if !actual[0].From.Equal(tt.expectedFrom) || !actual[0].To.Equal(tt.expectedTo) {
+				t.Errorf("expected coverage %v..%v, got %v..%v", tt.expectedFrom, tt.expectedTo, actual[0].From, actual[0].To)
+			}
+			if diff := cmp.Diff(tt.expectedIssues, coverageIssuesToStrings(actual[0].Issues), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("issues mismatch (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCoverageToString(t *testing.T) {
+	// Arrange
+	accounts := BuildCoverage([]FileInfo{
+		newTestFileInfo("jan.csv", "acc", utcDate(2024, 1, 1), utcDate(2024, 1, 31), intPtr(0), intPtr(100000)),
+		newTestFileInfo("feb.csv", "acc", utcDate(2024, 2, 1), utcDate(2024, 2, 29), intPtr(90000), intPtr(200000)),
+		newTestFileInfo("apr.csv", "acc", utcDate(2024, 4, 1), utcDate(2024, 4, 30), intPtr(200000), intPtr(200000)),
+	})
+
+	// Act
+	actual := coverageToString(accounts)
+
+	// Assert
+	for _, expected := range []string{
+		"Statements coverage (accounts 1, issues 2)",
+		"acc: closing balance 100.00 of 'jan.csv' differs from opening balance 90.00 of 'feb.csv' on 2024-02-01",
+		"acc: Gap 2024-03-01..2024-03-31 (31 days) between 'feb.csv' and 'apr.csv'",
+	} {
+		if !strings.Contains(actual, expected) {
+			t.Errorf("expected %q in:\n%s", expected, actual)
+		}
+	}
+	if coverageToString(BuildCoverage(nil)) != "" {
+		t.Error("expected empty string without issues")
+	}
+}
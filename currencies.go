@@ -7,7 +7,6 @@ import (
 	"math"
 	"regexp"
 	"slices"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -32,20 +31,22 @@ func (er *ExchangeRate) String() string {
 	return fmt.Sprintf("ExchangeRate{date: %v, currencyFrom: %q, currencyTo: %q, exchangeRate: %v, source: %+v}", er.date, er.currencyFrom, er.currencyTo, er.exchangeRate, er.source)
 }
 
-func atLeast1CentDiv(amountCents int, rate float64) MoneyWith2DecimalPlaces {
-	n := int(float64(amountCents) / rate)
-	if n == 0 {
-		n = 1
+// atLeast1MinorUnit rounds converted amount to minor units of the currency.
+// Returns at least one minor unit because zero amount means failed conversion.
+func atLeast1MinorUnit(amount float64, currency string) Money {
+	result := Money{int: roundToMinorUnits(amount, currency)}
+	if result.int == 0 {
+		result.int = minorUnitSize(currency)
 	}
-	return MoneyWith2DecimalPlaces{int: n}
+	return result
 }
 
-func atLeast1CentMul(amountCents int, rate float64) MoneyWith2DecimalPlaces {
-	n := int(float64(amountCents) * rate)
-	if n == 0 {
-		n = 1
-	}
-	return MoneyWith2DecimalPlaces{int: n}
+func atLeast1CentDiv(amount int, rate float64, currency string) Money {
+	return atLeast1MinorUnit(float64(amount)/rate, currency)
+}
+
+func atLeast1CentMul(amount int, rate float64, currency string) Money {
+	return atLeast1MinorUnit(float64(amount)*rate, currency)
 }
 
 // CurrencyStatistics is a struct representing data about a currency found in transactions.
@@ -61,9 +62,9 @@ type CurrencyStatistics struct {
 	// MetTimes is a number of times currency was occurred.
 	MetTimes int
 	// OverlappedWithOtherCurrencyAmount is a total amount of the currency overlapped with other currency.
-	OverlappedWithOtherCurrencyAmount MoneyWith2DecimalPlaces
+	OverlappedWithOtherCurrencyAmount Money
 	// TotalAmount is a total amount of the currency.
-	TotalAmount MoneyWith2DecimalPlaces
+	TotalAmount Money
 	// Transactions is a list of transactions with the currency.
 	Transactions []*Transaction
 	// ExchangeRates is a list of exchange rates for the currency.
@@ -82,6 +83,8 @@ type DataMart struct {
 	AllCurrencies map[string]*CurrencyStatistics
 	// ConvertibleCurrencies is a map of currencies for which conversion is possible.
 	ConvertibleCurrencies map[string]*CurrencyStatistics
+	// DroppedDuplicates is a list of transactions removed as duplicates from other files.
+	DroppedDuplicates []DroppedDuplicate
 }
 
 // currencyState contains data about a currency during one pass over transactions.
@@ -93,18 +96,15 @@ type currencyState struct {
 }
 
 // findAmountNearCurrency searches a number in a string before specified index.
-// Returns amount as integer with 2 decimal places.
+// Returns amount as integer in units of `Money`, see `moneyDecimalPlaces`.
 func findAmountNearCurrency(details string, currencyIndex int) int {
 	// Search for a number before specified index. Skip first space.
 	amount := ""
 	for i := currencyIndex - 2; i >= 0; i-- {
 		rune := details[i]
-		// Add all numbers and dots. Skip commas.
-		if rune >= '0' && rune <= '9' || rune == '.' {
+		// Add all numbers and separators.
+		if rune >= '0' && rune <= '9' || rune == '.' || rune == ',' {
 			amount = string(rune) + amount
-		} else if rune == ',' {
-			// Skip commas.
-			continue
 		} else if rune == ' ' && len(amount) == 0 {
 			// Skip any number of spaces at the beginning.
 			continue
@@ -112,16 +112,17 @@ func findAmountNearCurrency(details string, currencyIndex int) int {
 			break
 		}
 	}
+	// Separators before the number are punctuation, like in "fee,100 USD".
+	amount = strings.TrimLeft(amount, ".,")
 	// If no amount found then return 0.
 	if amount == "" {
 		return 0
 	}
-	// Parse amount as float with 2 decimal places.
-	amountFloat, err := strconv.ParseFloat(amount, 64)
+	parsed, err := ParseAmount(amount)
 	if err != nil {
 		return 0
 	}
-	return int(amountFloat * 100)
+	return parsed.int
 }
 
 // currencyRegex is a regex to find 3 upper case letters string with space before it.
@@ -305,12 +306,12 @@ func findClosestExchangeRateToCurrency(
 // - number representing how precise conversion was,
 // - path of conversion.
 func convertToCurrency(
-	amount MoneyWith2DecimalPlaces,
+	amount Money,
 	amountCurrency string,
 	targetCurrency string,
 	date time.Time,
 	curStates map[string]*currencyState,
-) (MoneyWith2DecimalPlaces, int, []string) {
+) (Money, int, []string) {
 	// If the same currency then no conversion, precision is 0, path is empty.
 	if amountCurrency == targetCurrency {
 		return amount, 0, []string{}
@@ -330,7 +331,7 @@ func convertToCurrency(
 			precision = ConstantExchangeRatePrecision
 		}
 		if exchangeRateDirect.currencyTo == targetCurrency {
-			return atLeast1CentDiv(amount.int, exchangeRateDirect.exchangeRate),
+			return atLeast1CentDiv(amount.int, exchangeRateDirect.exchangeRate, targetCurrency),
 				precision,
 				[]string{
 					buildConversionPath(
@@ -342,7 +343,7 @@ func convertToCurrency(
 					),
 				}
 		}
-		return atLeast1CentMul(amount.int, exchangeRateDirect.exchangeRate),
+		return atLeast1CentMul(amount.int, exchangeRateDirect.exchangeRate, targetCurrency),
 			precision,
 			[]string{
 				buildConversionPath(
@@ -359,7 +360,7 @@ func convertToCurrency(
 	// Use Dijkstra's algorithm to find shortest path (with minimal precision loss).
 	type currencyNode struct {
 		currency  string
-		amount    MoneyWith2DecimalPlaces
+		amount    Money
 		precision int
 		path      []string // Track the conversion path with exchange rate details
 	}
@@ -370,7 +371,7 @@ func convertToCurrency(
 	for currency := range curStates {
 		nodes[currency] = &currencyNode{
 			currency:  currency,
-			amount:    MoneyWith2DecimalPlaces{int: math.MaxInt},
+			amount:    Money{int: math.MaxInt},
 			precision: math.MaxInt,
 			path:      []string{},
 		}
@@ -439,10 +440,10 @@ func convertToCurrency(
 			otherNode := nodes[otherCurrency]
 			if newPrecision < otherNode.precision {
 				// Calculate converted amount based on exchange rate direction.
-				var newAmount MoneyWith2DecimalPlaces
+				var newAmount Money
 				var pathEntry string
 				if current.currency == er.currencyFrom {
-					newAmount = atLeast1CentDiv(fromNode.amount.int, er.exchangeRate)
+					newAmount = atLeast1CentDiv(fromNode.amount.int, er.exchangeRate, otherCurrency)
 					pathEntry = buildConversionPath(
 						er.currencyFrom,
 						er.currencyTo,
@@ -451,7 +452,7 @@ func convertToCurrency(
 						er.source,
 					)
 				} else {
-					newAmount = atLeast1CentMul(fromNode.amount.int, er.exchangeRate)
+					newAmount = atLeast1CentMul(fromNode.amount.int, er.exchangeRate, otherCurrency)
 					pathEntry = buildConversionPath(
 						er.currencyTo,
 						er.currencyFrom,
@@ -481,7 +482,7 @@ func convertToCurrency(
 		}
 	}
 	// If no conversion path found then return 0 amount with max precision.
-	return MoneyWith2DecimalPlaces{int: 0}, math.MaxInt, []string{}
+	return Money{int: 0}, math.MaxInt, []string{}
 }
 
 // BuildDataMart builds data required to build journal entries.
@@ -489,6 +490,16 @@ func BuildDataMart(
 	transactions []Transaction,
 	config *Config,
 ) (*DataMart, error) {
+	// Remove transactions met in several files. Time zone is validated on reading configuration.
+	timeZone, err := time.LoadLocation(config.TimeZoneLocation)
+	if err != nil {
+		return nil, err
+	}
+	transactions, droppedDuplicates := deduplicateTransactions(transactions, config.Deduplication, timeZone)
+	if len(droppedDuplicates) > 0 {
+		log.Println(i18n.T("Dropped n duplicated transactions", "n", len(droppedDuplicates)))
+	}
+
 	// Sort transactions by date to simplify processing.
 	slices.SortFunc(transactions, func(a, b Transaction) int {
 		return a.Date.Compare(b.Date)
@@ -521,6 +532,7 @@ func BuildDataMart(
 		Accounts:              accounts,
 		AllCurrencies:         currencies,
 		ConvertibleCurrencies: convertibleCurrencies,
+		DroppedDuplicates:     droppedDuplicates,
 	}, nil
 }
 
@@ -997,7 +1009,7 @@ func buildJournalEntries(
 		// Convert amounts to convertible currencies.
 		amounts := make(map[string]AmountInCurrency, len(dataMart.ConvertibleCurrencies))
 		for _, curStatistic := range dataMart.ConvertibleCurrencies {
-			var amountAccCur, amountOrgCur MoneyWith2DecimalPlaces
+			var amountAccCur, amountOrgCur Money
 			var precisionAccCur, precisionOrgCur int = math.MaxInt, math.MaxInt
 			var conversionPathAccCur, conversionPathOrgCur []string
 
@@ -1070,6 +1082,7 @@ func buildJournalEntries(
 			Source:                t.Source,
 			Details:               t.Details,
 			Category:              category.Name,
+			CategoryPath:          category.Path,
 			AccountCurrency:       t.AccountCurrency,
 			AccountCurrencyAmount: amount,
 			OriginCurrency:        t.OriginCurrency,
@@ -1079,8 +1092,16 @@ func buildJournalEntries(
 			Amounts:               amounts,
 			RuleType:              category.RuleType,
 			RuleValue:             category.RuleValue,
+			Fingerprint:           TransactionFingerprint(&t),
+		}
+		// Split transaction results in few entries.
+		// Wrong split shouldn't break all pages, transaction stays in the first group of the split instead.
+		entries, err := categorization.SplitJournalEntry(entry)
+		if err != nil {
+			log.Println(i18n.T("Split can't be applied, transaction is kept in group", "group", entry.Category, "err", err))
+			entries = []JournalEntry{entry}
 		}
-		journalEntries = append(journalEntries, entry)
+		journalEntries = append(journalEntries, entries...)
 	}
 
 	log.Println(
@@ -262,7 +262,7 @@ func TestBuildDataMart_ConstantExchangeRates(t *testing.T) {
 		{
 			Date:            testDate,
 			AccountCurrency: "USD",
-			Amount:          MoneyWith2DecimalPlaces{int: 10000}, // $100.00
+			Amount:          Money{int: 100000}, // $100.00
 			Details:         "check USD",
 			FromAccount:     "Assets:Bank:USD",
 			ToAccount:       "Expenses:Test",
@@ -272,7 +272,7 @@ func TestBuildDataMart_ConstantExchangeRates(t *testing.T) {
 		{
 			Date:            testDate.AddDate(0, 0, 1),
 			AccountCurrency: "AMD",
-			Amount:          MoneyWith2DecimalPlaces{int: 3810000}, // 38,100 AMD
+			Amount:          Money{int: 38100000}, // 38,100 AMD
 			Details:         "check AMD",
 			FromAccount:     "Assets:Bank:AMD",
 			ToAccount:       "Expenses:Test",
@@ -282,7 +282,7 @@ func TestBuildDataMart_ConstantExchangeRates(t *testing.T) {
 		{
 			Date:            testDate.AddDate(0, 0, 1),
 			AccountCurrency: "AMD",
-			Amount:          MoneyWith2DecimalPlaces{int: 100}, // 1 AMD
+			Amount:          Money{int: 1000}, // 1 AMD
 			Details:         "small amount",
 			FromAccount:     "Assets:Bank:AMD",
 			ToAccount:       "Expenses:Test",
@@ -357,29 +357,29 @@ func TestBuildDataMart_ConstantExchangeRates(t *testing.T) {
 func TestConvertToCurrency(t *testing.T) {
 	tests := []struct {
 		name              string
-		amount            MoneyWith2DecimalPlaces
+		amount            Money
 		amountCurrency    string
 		targetCurrency    string
 		date              time.Time
 		curStates         map[string]*currencyState
-		expectedAmount    MoneyWith2DecimalPlaces
+		expectedAmount    Money
 		expectedPrecision int
 		expectedPath      []string
 	}{
 		{
 			name:              "same currency",
-			amount:            MoneyWith2DecimalPlaces{int: 100},
+			amount:            Money{int: 1000},
 			amountCurrency:    "AMD",
 			targetCurrency:    "AMD",
 			date:              testDate,
 			curStates:         map[string]*currencyState{},
-			expectedAmount:    MoneyWith2DecimalPlaces{int: 100},
+			expectedAmount:    Money{int: 1000},
 			expectedPrecision: 0,
 			expectedPath:      []string{},
 		},
 		{
 			name:           "direct",
-			amount:         MoneyWith2DecimalPlaces{int: 100000}, // $1000.00
+			amount:         Money{int: 1000000}, // $1000.00
 			amountCurrency: "USD",
 			targetCurrency: "AMD",
 			date:           testDate,
@@ -402,13 +402,13 @@ func TestConvertToCurrency(t *testing.T) {
 					},
 				},
 			},
-			expectedAmount:    MoneyWith2DecimalPlaces{int: 38100000},
+			expectedAmount:    Money{int: 381000000},
 			expectedPrecision: 1, // Same day conversion.
 			expectedPath:      []string{buildConversionPath("USD", "AMD", 1.0/381, testDate, &TransactionsSource{TypeName: "test", FilePath: "test.csv"})},
 		},
 		{
 			name:           "direct via constant exchange rate",
-			amount:         MoneyWith2DecimalPlaces{int: 100000}, // 1000.00 AMD
+			amount:         Money{int: 1000000}, // 1000.00 AMD
 			amountCurrency: "AMD",
 			targetCurrency: "USD",
 			date:           testDate,
@@ -431,13 +431,13 @@ func TestConvertToCurrency(t *testing.T) {
 					},
 				},
 			},
-			expectedAmount:    MoneyWith2DecimalPlaces{int: 262},
+			expectedAmount:    Money{int: 2620},
 			expectedPrecision: 100500, // Constant exchange rate precision.
 			expectedPath:      []string{buildConversionPath("AMD", "USD", 381, testDate, &TransactionsSource{TypeName: ConstantExchangeRateSourceName, FilePath: ConstantExchangeRateSourceFilePath})},
 		},
 		{
 			name:           "conversion of very small amount",
-			amount:         MoneyWith2DecimalPlaces{int: 100}, // 1.00 AMD
+			amount:         Money{int: 1000}, // 1.00 AMD
 			amountCurrency: "AMD",
 			targetCurrency: "USD",
 			date:           testDate,
@@ -459,14 +459,14 @@ func TestConvertToCurrency(t *testing.T) {
 					},
 				},
 			},
-			expectedAmount:    MoneyWith2DecimalPlaces{int: 1}, // Expecting 0.01 USD in spite of 1 / 381 = 0.0026 USD
+			expectedAmount:    Money{int: 10}, // Expecting 0.01 USD in spite of 1 / 381 = 0.0026 USD
 			expectedPrecision: 1,
 			expectedPath:      []string{buildConversionPath("AMD", "USD", 381, testDate, &TransactionsSource{TypeName: "test", FilePath: "test.csv"})},
 		},
 		{
 			name:           "multiple conversions",
-			amount:         MoneyWith2DecimalPlaces{int: 100000}, // 1000.00 AMD
-			amountCurrency: "AMD",                                // AMD -> USD -> EUR
+			amount:         Money{int: 1000000}, // 1000.00 AMD
+			amountCurrency: "AMD",               // AMD -> USD -> EUR
 			targetCurrency: "EUR",
 			date:           testDate,
 			curStates: map[string]*currencyState{
@@ -510,8 +510,8 @@ func TestConvertToCurrency(t *testing.T) {
 					},
 				},
 			},
-			// 1000 / 381 * 0.9 = 2.36 EUR but after precision losses it is 2.35.
-			expectedAmount: MoneyWith2DecimalPlaces{int: 235},
+			// 1000 / 381 * 0.9 = 2.36 EUR, intermediate 2.62 USD is rounded to cents.
+			expectedAmount: Money{int: 2360},
 			// Precision is 1 day to USD conversion + 1 day to EUR conversion.
 			expectedPrecision: 2,
 			expectedPath:      []string{buildConversionPath("AMD", "USD", 381, testDate, &TransactionsSource{TypeName: "test", FilePath: "test.csv"}), buildConversionPath("USD", "EUR", 1.0/0.9, testDate, &TransactionsSource{TypeName: "test", FilePath: "test.csv"})},
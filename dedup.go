@@ -0,0 +1,241 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DeduplicationConfig configures removing of the same transactions found in different files.
+// For example when statements have overlapping date ranges or the same account
+// is exported in different formats.
+type DeduplicationConfig struct {
+	// Enabled turns deduplication on.
+	Enabled bool `yaml:"enabled"`
+	// DateToleranceDays is a maximum difference in calendar days between dates of duplicates,
+	// so 0 means the same day regardless of time. Useful when formats use different dates
+	// (transaction date vs. applied date).
+	DateToleranceDays int `yaml:"dateToleranceDays,omitempty" validate:"min=0,max=31"`
+	// IgnoreDetails disables comparison of details. Useful when formats describe transactions differently.
+	IgnoreDetails bool `yaml:"ignoreDetails,omitempty"`
+	// PreferredSources is a list of source tags (or their prefixes, like "InecoXml") in order of preference.
+	// Transactions from more preferred source are kept, duplicates from other sources are dropped.
+	// Sources not in the list are less preferred and are compared in the parsing order.
+	PreferredSources []string `yaml:"preferredSources,omitempty"`
+}
+
+// DroppedDuplicate is a transaction removed as a duplicate of other transaction.
+type DroppedDuplicate struct {
+	// Transaction is the dropped transaction.
+	Transaction Transaction
+	// KeptTransaction is a transaction which was kept instead.
+	KeptTransaction Transaction
+	// Reason is a localized explanation why transaction was dropped.
+	Reason string
+}
+
+// deduplicationKey contains fields which have to be equal for duplicates. Date is compared separately.
+// Transactions with ID assigned by the bank have also key with source, account and ID only.
+type deduplicationKey struct {
+	source    string
+	id        string
+	account   string
+	isExpense bool
+	amount    int
+	currency  string
+	details   string
+}
+
+// keptTransaction is a transaction which may be a "pair" for the only one duplicate.
+type keptTransaction struct {
+	transaction *Transaction
+	isMatched   bool
+}
+
+// deduplicateTransactions removes transactions which are found in other files.
+// Files are processed in order of preference so transactions from the most preferred file are kept.
+// Identical transactions inside one file are never treated as duplicates.
+// Dates are compared by calendar days in the time zone, so sources with and without time of day match.
+// Returns kept transactions in the original order and list of dropped transactions.
+func deduplicateTransactions(
+	transactions []Transaction,
+	config *DeduplicationConfig,
+	timeZone *time.Location,
+) ([]Transaction, []DroppedDuplicate) {
+	if config == nil || !config.Enabled {
+		return transactions, nil
+	}
+
+	// Group transactions per file with keeping order of files.
+	filesOrder := make([]string, 0)
+	indexesPerFile := make(map[string][]int)
+	for i, transaction := range transactions {
+		file := transactionFilePath(&transaction)
+		if _, ok := indexesPerFile[file]; !ok {
+			filesOrder = append(filesOrder, file)
+		}
+		indexesPerFile[file] = append(indexesPerFile[file], i)
+	}
+	if len(filesOrder) < 2 {
+		return transactions, nil
+	}
+	sortFilesByPreference(filesOrder, indexesPerFile, transactions, config.PreferredSources)
+
+	kept := make(map[deduplicationKey][]*keptTransaction)
+	isDropped := make([]bool, len(transactions))
+	dropped := make([]DroppedDuplicate, 0)
+	for _, file := range filesOrder {
+		newKept := make(map[deduplicationKey][]*keptTransaction)
+		for _, index := range indexesPerFile[file] {
+			transaction := &transactions[index]
+			key := buildDeduplicationKey(transaction, config.IgnoreDetails)
+			pair := findDuplicatePair(kept, key, transaction, config.DateToleranceDays, timeZone)
+			if pair == nil {
+				item := &keptTransaction{transaction: transaction}
+				newKept[key] = append(newKept[key], item)
+				if transaction.ID != "" {
+					idKey := buildIDDeduplicationKey(transaction)
+					newKept[idKey] = append(newKept[idKey], item)
+				}
+				continue
+			}
+			pair.isMatched = true
+			isDropped[index] = true
+			dropped = append(dropped, DroppedDuplicate{
+				Transaction:     *transaction,
+				KeptTransaction: *pair.transaction,
+				Reason: i18n.T(
+					"Duplicate of transaction from f file",
+					"f", transactionFilePath(pair.transaction),
+					"days", config.DateToleranceDays,
+				),
+			})
+		}
+		// Add after file is processed to don't match transactions inside one file.
+		for key, items := range newKept {
+			kept[key] = append(kept[key], items...)
+		}
+	}
+
+	result := make([]Transaction, 0, len(transactions)-len(dropped))
+	for i, transaction := range transactions {
+		if !isDropped[i] {
+			result = append(result, transaction)
+		}
+	}
+	return result, dropped
+}
+
+// sortFilesByPreference stable sorts files by index of the first preferred source matching file transactions.
+func sortFilesByPreference(
+	files []string,
+	indexesPerFile map[string][]int,
+	transactions []Transaction,
+	preferredSources []string,
+) {
+	rank := func(file string) int {
+		source := transactions[indexesPerFile[file][0]].Source
+		if source == nil {
+			return len(preferredSources)
+		}
+		for i, preferred := range preferredSources {
+			if strings.HasPrefix(source.Tag, preferred) || source.TypeName == preferred {
+				return i
+			}
+		}
+		return len(preferredSources)
+	}
+	ranks := make(map[string]int, len(files))
+	for _, file := range files {
+		ranks[file] = rank(file)
+	}
+	slices.SortStableFunc(files, func(a, b string) int {
+		return ranks[a] - ranks[b]
+	})
+}
+
+// findDuplicatePair returns not matched yet kept transaction which is a duplicate of the transaction.
+// If both transactions have IDs assigned by the bank then only IDs are compared,
+// otherwise fields of the key and calendar days of dates with tolerance.
+func findDuplicatePair(
+	kept map[deduplicationKey][]*keptTransaction,
+	key deduplicationKey,
+	transaction *Transaction,
+	toleranceDays int,
+	timeZone *time.Location,
+) *keptTransaction {
+	if transaction.ID != "" {
+		for _, candidate := range kept[buildIDDeduplicationKey(transaction)] {
+			if !candidate.isMatched {
+				return candidate
+			}
+		}
+	}
+	for _, candidate := range kept[key] {
+		if candidate.isMatched || (transaction.ID != "" && candidate.transaction.ID != "") {
+			continue
+		}
+		days := daysBetween(startOfDay(candidate.transaction.Date, timeZone), startOfDay(transaction.Date, timeZone))
+		if math.Abs(days) <= float64(toleranceDays) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// buildIDDeduplicationKey returns key of transaction with ID assigned by the bank, like FITID in OFX files.
+// IDs are unique only per source type and account.
+func buildIDDeduplicationKey(transaction *Transaction) deduplicationKey {
+	key := deduplicationKey{
+		id:      transaction.ID,
+		account: transactionOwnAccount(transaction),
+	}
+	if transaction.Source != nil {
+		key.source = transaction.Source.Tag
+	}
+	return key
+}
+
+func buildDeduplicationKey(transaction *Transaction, ignoreDetails bool) deduplicationKey {
+	key := deduplicationKey{
+		account:   transactionOwnAccount(transaction),
+		isExpense: transaction.IsExpense,
+		amount:    transaction.Amount.int,
+		currency:  transaction.AccountCurrency,
+	}
+	if !ignoreDetails {
+		key.details = normalizeDetails(transaction.Details)
+	}
+	return key
+}
+
+// transactionOwnAccount returns account of the statement owner.
+func transactionOwnAccount(transaction *Transaction) string {
+	if transaction.Source != nil && transaction.Source.AccountNumber != "" {
+		return transaction.Source.AccountNumber
+	}
+	if transaction.IsExpense {
+		return transaction.FromAccount
+	}
+	return transaction.ToAccount
+}
+
+func transactionFilePath(transaction *Transaction) string {
+	if transaction.Source == nil {
+		return ""
+	}
+	return transaction.Source.FilePath
+}
+
+// normalizeDetails keeps only lowercased letters and digits to don't depend on formatting of details.
+func normalizeDetails(details string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(details) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
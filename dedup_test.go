@@ -0,0 +1,249 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDeduplicateTransactions(t *testing.T) {
+	xmlSource := &TransactionsSource{Tag: "InecoXml:AMD", FilePath: "Statement.xml", AccountNumber: "111"}
+	xlsxSource := &TransactionsSource{Tag: "InecoExcelRegular:AMD", FilePath: "statement.xlsx", AccountNumber: "111"}
+	xmlSource2 := &TransactionsSource{Tag: "InecoXml:AMD", FilePath: "Statement2.xml", AccountNumber: "111"}
+	newTransaction := func(source *TransactionsSource, days int, amount int, details string) Transaction {
+		return Transaction{
+			Date:            testDate.AddDate(0, 0, days),
+			FromAccount:     "111",
+			ToAccount:       "222",
+			IsExpense:       true,
+			Amount:          Money{int: amount},
+			Details:         details,
+			Source:          source,
+			AccountCurrency: "AMD",
+		}
+	}
+	withID := func(transaction Transaction, id string) Transaction {
+		transaction.ID = id
+		return transaction
+	}
+
+	tests := []struct {
+		name                    string
+		transactions            []Transaction
+		config                  *DeduplicationConfig
+		expectedKeptDetails     []string
+		expectedDroppedFromFile []string
+	}{
+		{
+			name: "disabled",
+			transactions: []Transaction{
+				newTransaction(xmlSource, 0, 100, "Coffee"),
+				newTransaction(xlsxSource, 0, 100, "Coffee"),
+			},
+			config:              &DeduplicationConfig{Enabled: false},
+			expectedKeptDetails: []string{"Coffee", "Coffee"},
+		},
+		{
+			name: "overlapping files of the same format",
+			transactions: []Transaction{
+				newTransaction(xmlSource, 0, 100, "Coffee"),
+				newTransaction(xmlSource, 1, 200, "Taxi"),
+				newTransaction(xmlSource2, 1, 200, "Taxi"),
+				newTransaction(xmlSource2, 2, 300, "Food"),
+			},
+			config:                  &DeduplicationConfig{Enabled: true},
+			expectedKeptDetails:     []string{"Coffee", "Taxi", "Food"},
+			expectedDroppedFromFile: []string{"Statement2.xml"},
+		},
+		{
+			name: "identical transactions inside one file are kept",
+			transactions: []Transaction{
+				newTransaction(xmlSource, 0, 100, "Coffee"),
+				newTransaction(xmlSource, 0, 100, "Coffee"),
+				newTransaction(xmlSource2, 0, 100, "Coffee"),
+			},
+			config:                  &DeduplicationConfig{Enabled: true},
+			expectedKeptDetails:     []string{"Coffee", "Coffee"},
+			expectedDroppedFromFile: []string{"Statement2.xml"},
+		},
+		{
+			name: "preferred source is kept",
+			transactions: []Transaction{
+				newTransaction(xlsxSource, 0, 100, "COFFEE  shop"),
+				newTransaction(xmlSource, 0, 100, "Coffee shop"),
+			},
+			config:                  &DeduplicationConfig{Enabled: true, PreferredSources: []string{"InecoXml"}},
+			expectedKeptDetails:     []string{"Coffee shop"},
+			expectedDroppedFromFile: []string{"statement.xlsx"},
+		},
+		{
+			name: "date tolerance",
+			transactions: []Transaction{
+				newTransaction(xmlSource, 0, 100, "Coffee"),
+				newTransaction(xlsxSource, 2, 100, "Coffee"),
+				newTransaction(xmlSource, 10, 100, "Coffee"),
+				newTransaction(xlsxSource, 14, 100, "Coffee"),
+			},
+			config:                  &DeduplicationConfig{Enabled: true, DateToleranceDays: 2},
+			expectedKeptDetails:     []string{"Coffee", "Coffee", "Coffee"},
+			expectedDroppedFromFile: []string{"statement.xlsx"},
+		},
+		{
+			name: "different details",
+			transactions: []Transaction{
+				newTransaction(xmlSource, 0, 100, "Coffee"),
+				newTransaction(xlsxSource, 0, 100, "Card payment 1234"),
+			},
+			config:              &DeduplicationConfig{Enabled: true},
+			expectedKeptDetails: []string{"Coffee", "Card payment 1234"},
+		},
+		{
+			name: "different details ignored",
+			transactions: []Transaction{
+				newTransaction(xmlSource, 0, 100, "Coffee"),
+				newTransaction(xlsxSource, 0, 100, "Card payment 1234"),
+			},
+			config:                  &DeduplicationConfig{Enabled: true, IgnoreDetails: true},
+			expectedKeptDetails:     []string{"Coffee"},
+			expectedDroppedFromFile: []string{"statement.xlsx"},
+		},
+		{
+			name: "same IDs regardless of other fields",
+			transactions: []Transaction{
+				withID(newTransaction(xmlSource, 0, 100, "Coffee"), "1"),
+				withID(newTransaction(xmlSource2, 5, 100, "COFFEE SHOP 1234"), "1"),
+				withID(newTransaction(xmlSource2, 0, 100, "Coffee"), "2"),
+			},
+			config:                  &DeduplicationConfig{Enabled: true},
+			expectedKeptDetails:     []string{"Coffee", "Coffee"},
+			expectedDroppedFromFile: []string{"Statement2.xml"},
+		},
+		{
+			name: "ID in one file only",
+			transactions: []Transaction{
+				withID(newTransaction(xmlSource, 0, 100, "Coffee"), "1"),
+				newTransaction(xlsxSource, 0, 100, "Coffee"),
+			},
+			config:                  &DeduplicationConfig{Enabled: true},
+			expectedKeptDetails:     []string{"Coffee"},
+			expectedDroppedFromFile: []string{"statement.xlsx"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			kept, dropped := deduplicateTransactions(tt.transactions, tt.config, time.UTC)
+
+			// Assert
+			keptDetails := make([]string, 0, len(kept))
+			for _, transaction := range kept {
+				keptDetails = append(keptDetails, transaction.Details)
+			}
+			if len(keptDetails) != len(tt.expectedKeptDetails) {
+				t.Fatalf("Expected kept %v, got %v", tt.expectedKeptDetails, keptDetails)
+			}
+			for i := range keptDetails {
+				if keptDetails[i] != tt.expectedKeptDetails[i] {
+					t.Errorf("Expected kept %v, got %v", tt.expectedKeptDetails, keptDetails)
+					break
+				}
+			}
+			if len(dropped) != len(tt.expectedDroppedFromFile) {
+				t.Fatalf("Expected %d dropped, got %+v", len(tt.expectedDroppedFromFile), dropped)
+			}
+			for i, duplicate := range dropped {
+				if duplicate.Transaction.Source.FilePath != tt.expectedDroppedFromFile[i] {
+					t.Errorf("Expected dropped from '%s', got '%s'", tt.expectedDroppedFromFile[i], duplicate.Transaction.Source.FilePath)
+				}
+				if duplicate.KeptTransaction.Source.FilePath == duplicate.Transaction.Source.FilePath {
+					t.Errorf("Expected duplicate from other file, got %+v", duplicate)
+				}
+				if duplicate.Reason == "" {
+					t.Error("Expected reason to be set")
+				}
+			}
+		})
+	}
+}
+
+func TestDeduplicateTransactions_CalendarDays(t *testing.T) {
+	yerevan, err := time.LoadLocation("Asia/Yerevan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name            string
+		timedDate       time.Time
+		timeZone        *time.Location
+		expectedDropped int
+	}{
+		{"same_day", time.Date(2024, 3, 5, 18, 45, 0, 0, time.UTC), time.UTC, 1},
+		{"next_day", time.Date(2024, 3, 6, 0, 10, 0, 0, time.UTC), time.UTC, 0},
+		{"same_day_in_time_zone", time.Date(2024, 3, 4, 21, 30, 0, 0, time.UTC), yerevan, 1},
+		{"previous_day_in_time_zone", time.Date(2024, 3, 4, 19, 30, 0, 0, time.UTC), yerevan, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			newTransaction := func(source *TransactionsSource, date time.Time) Transaction {
+				return Transaction{
+					Date:            date,
+					FromAccount:     "111",
+					IsExpense:       true,
+					Amount:          Money{int: 4500},
+					Details:         "Coffee",
+					Source:          source,
+					AccountCurrency: "AMD",
+				}
+			}
+			dateOnlySource := &TransactionsSource{Tag: "AcbaCardExcel", FilePath: "card.xls", AccountNumber: "111"}
+			timedSource := &TransactionsSource{Tag: "MyAmeriaExcel", FilePath: "statement.xls", AccountNumber: "111"}
+			transactions := []Transaction{
+				newTransaction(dateOnlySource, startOfDay(time.Date(2024, 3, 5, 12, 0, 0, 0, tt.timeZone), tt.timeZone)),
+				newTransaction(timedSource, tt.timedDate),
+			}
+
+			// Act
+			_, dropped := deduplicateTransactions(transactions, &DeduplicationConfig{Enabled: true}, tt.timeZone)
+
+			// Assert
+			if len(dropped) != tt.expectedDropped {
+				t.Errorf("expected %d dropped, got %+v", tt.expectedDropped, dropped)
+			}
+		})
+	}
+}
+
+func TestDeduplicateTransactions_OverlappingOfxFiles(t *testing.T) {
+	// Arrange
+	var transactions []Transaction
+	for _, fileName := range []string{"checking_sgml.ofx", "checking_sgml_overlap.ofx"} {
+		fileTransactions, err := OfxFileParser{}.ParseRawTransactionsFromFile(filepath.Join("testdata", "ofx", fileName))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for i := range fileTransactions {
+			fileTransactions[i].Source.Tag = "Ofx"
+		}
+		transactions = append(transactions, fileTransactions...)
+	}
+
+	// Act
+	kept, dropped := deduplicateTransactions(transactions, &DeduplicationConfig{Enabled: true}, time.UTC)
+
+	// Assert
+	keptIDs := make([]string, 0, len(kept))
+	for _, transaction := range kept {
+		keptIDs = append(keptIDs, transaction.ID)
+	}
+	expectedIDs := []string{"TRANSFER-1001", "CARD-2002", "CARD-2003", "CARD-2004"}
+	if !slices.Equal(keptIDs, expectedIDs) {
+		t.Errorf("Expected kept %v, got %v", expectedIDs, keptIDs)
+	}
+	if len(dropped) != 1 || dropped[0].Transaction.ID != "CARD-2003" ||
+		filepath.Base(dropped[0].Transaction.Source.FilePath) != "checking_sgml_overlap.ofx" {
+		t.Errorf("Expected CARD-2003 dropped from the second file, got %+v", dropped)
+	}
+}
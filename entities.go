@@ -2,97 +2,60 @@ package main
 
 import (
 	"encoding/json"
-	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/tealeg/xlsx"
 )
 
-// MoneyWith2DecimalPlaces is a wrapper to parse money from "1,500.00" or "1,500" to 150000.
-type MoneyWith2DecimalPlaces struct {
+// Money is an exact decimal amount stored in thousandths of the currency unit, see `moneyDecimalPlaces`.
+// For example "1,500.00" and "1,500" are parsed into 1500000.
+type Money struct {
 	int
 }
 
-// ParseString removes commas and parses string as float.
-func (m *MoneyWith2DecimalPlaces) ParseString(s string) error {
-	sanitizedText := strings.Replace(s, ",", "", -1)
-	floatVal, err := strconv.ParseFloat(sanitizedText, 64)
+// ParseString parses amount like "1,234.56" or "1 234,56", see `AmountFormat`.
+func (m *Money) ParseString(s string) error {
+	value, err := ParseAmount(s)
 	if err != nil {
 		return err
 	}
-	m.int = int(floatVal * 100)
+	*m = value
 	return nil
 }
 
-func (m *MoneyWith2DecimalPlaces) ParseAmountWithoutLettersFromString(value string) error {
-	value = strings.TrimSpace(value)
-
-	// Find the first consecutive [0-9.-] characters
-	var numberStr strings.Builder
-	for _, char := range value {
-		if (char >= '0' && char <= '9') || char == '.' || char == '-' {
-			numberStr.WriteRune(char)
-		}
-	}
-
-	if numberStr.Len() == 0 {
-		return fmt.Errorf("invalid money format: '%s'", value)
-	}
-
-	// Parse the amount from the extracted number string
-	err := m.ParseString(numberStr.String())
+// ParseAmountWithoutLettersFromString parses amount surrounded by currency codes or symbols like "99500.25  USD".
+func (m *Money) ParseAmountWithoutLettersFromString(value string) error {
+	amount, err := AmountFormat{}.ParseWithoutLetters(value)
 	if err != nil {
-		return fmt.Errorf("failed to parse amount: %w", err)
+		return err
 	}
-
+	*m = amount
 	return nil
 }
 
-// UnmarshalText removes commas and parses string as float.
-func (m *MoneyWith2DecimalPlaces) UnmarshalText(text []byte) error {
+// UnmarshalText parses amount like "1,234.56" or "1 234,56".
+func (m *Money) UnmarshalText(text []byte) error {
 	return m.ParseString(string(text))
 }
 
-// UnmarshalFromExcelCell removes commas and parses cell's string value as float.
-func (m *MoneyWith2DecimalPlaces) UnmarshalFromExcelCell(cell *xlsx.Cell) error {
+// UnmarshalFromExcelCell parses cell's string value as amount, empty cell is skipped.
+func (m *Money) UnmarshalFromExcelCell(cell *xlsx.Cell) error {
 	if len(cell.Value) < 1 {
 		return nil
 	}
 	return m.ParseString(cell.Value)
 }
 
-// MarshalJSON implements the json.Marshaler interface.
-func (m MoneyWith2DecimalPlaces) MarshalJSON() ([]byte, error) {
-	intPart := m.int / 100
-	fracPart := m.int % 100
-
-	intStr := strconv.Itoa(intPart)
-	fracStr := fmt.Sprintf("%02d", fracPart)
-
-	// Add thousands separator
-	var parts []string
-	for i := len(intStr); i > 0; i -= 3 {
-		if i-3 > 0 {
-			parts = append([]string{intStr[i-3 : i]}, parts...)
-		} else {
-			parts = append([]string{intStr[:i]}, parts...)
-		}
-	}
-
-	formattedValue := strings.Join(parts, " ") + "." + fracStr
-	return json.Marshal(formattedValue)
+// MarshalJSON implements the json.Marshaler interface. Amount doesn't know its currency,
+// so it is always formatted with `displayDecimalPlaces` digits, like "1 234.57" for 1234.567 KWD.
+// Use `StringInCurrency` or API types to keep minor units of the currency.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.format(displayDecimalPlaces, " "))
 }
 
-func (m MoneyWith2DecimalPlaces) StringNoIndent() string {
-	dollars := m.int / 100
-	cents := m.int % 100
-	dollarString := strconv.Itoa(dollars)
-	for i := len(dollarString) - 3; i > 0; i -= 3 {
-		dollarString = dollarString[:i] + "," + dollarString[i:]
-	}
-	return fmt.Sprintf("%s.%02d", dollarString, cents)
+// StringNoIndent returns amount with `displayDecimalPlaces` digits like "1,234.57", see `MarshalJSON`.
+func (m Money) StringNoIndent() string {
+	return m.format(displayDecimalPlaces, ",")
 }
 
 // RuleType represents the type of rule which categorized a transaction.
@@ -107,6 +70,12 @@ const (
 	RuleTypeToAccount RuleType = "ToAccount"
 	// RuleTypeSubstring is a type of rule that matched by substring in "details".
 	RuleTypeSubstring RuleType = "Substring"
+	// RuleTypeRule is a type of rule that matched by all conditions of `RuleConfig`.
+	RuleTypeRule RuleType = "Rule"
+	// RuleTypeManual is a type of rule that matched by manual category override of the transaction.
+	RuleTypeManual RuleType = "Manual"
+	// RuleTypeSplit is a type of rule that matched by manual split of the transaction across several categories.
+	RuleTypeSplit RuleType = "Split"
 	// ConstantExchangeRatePrecision is a precision for constant exchange rates.
 	ConstantExchangeRatePrecision int = 100500
 	// ConstantExchangeRateSourceName is a name of the source for constant exchange rates.
@@ -127,6 +96,19 @@ type TransactionsSource struct {
 	AccountNumber string
 	// AccountCurrency is a currency of the account. ISO 3-character code.
 	AccountCurrency string
+	// OpeningBalance is a balance of the account at the start of the statement period, nil if file doesn't provide it.
+	OpeningBalance *BalanceRecord
+	// ClosingBalance is a balance of the account at the end of the statement period, nil if file doesn't provide it.
+	ClosingBalance *BalanceRecord
+}
+
+// BalanceRecord is a balance of the account provided by a statement file.
+type BalanceRecord struct {
+	// Date is a day at the start of which the account has the balance.
+	// I.e. closing balance of the statement which ends on 31st of January has 1st of February date.
+	Date time.Time
+	// Amount is a balance in the account currency.
+	Amount Money
 }
 
 func (s *TransactionsSource) String() string {
@@ -144,7 +126,7 @@ type Transaction struct {
 	// IsExpense is true if transaction is an expense, false if it is an income.
 	IsExpense bool
 	// Amount in account currency.
-	Amount MoneyWith2DecimalPlaces
+	Amount Money
 	// Details is a description of the transaction.
 	Details string
 	// Source explanation
@@ -156,7 +138,10 @@ type Transaction struct {
 	OriginCurrency string
 	// OriginCurrencyAmount is an amount in origin currency.
 	// Can be empty if transaction is in account currency.
-	OriginCurrencyAmount MoneyWith2DecimalPlaces
+	OriginCurrencyAmount Money
+	// ID is an identifier of the transaction assigned by the bank, like FITID in OFX files.
+	// Stays the same in all exports of the transaction. Empty if file doesn't provide it.
+	ID string
 }
 
 // AccountStatistics is a struct representing data about an account found in transactions.
@@ -180,7 +165,7 @@ type AccountStatistics struct {
 
 // AmountInCurrency is an amount in a specific currency with marks of origin and account currencies.
 type AmountInCurrency struct {
-	Amount MoneyWith2DecimalPlaces
+	Amount Money
 	// Currency name (as in source file but verified by Beancount rules).
 	Currency string
 	// ConversionPrecision is a number representing how precise conversion was.
@@ -207,6 +192,8 @@ type JournalEntry struct {
 	Details string
 	// Category is a user-defined and evaluated category of the transaction.
 	Category string
+	// CategoryPath is a path of the category in categories hierarchy, like ["Food", "Groceries"].
+	CategoryPath []string
 	// FromAccount is an account which pays the transaction, amount is decreasing here.
 	FromAccount string
 	// ToAccount is an account which receives the transaction, amount is increasing here.
@@ -214,17 +201,24 @@ type JournalEntry struct {
 	// AccountCurrency is a currency of the account.
 	AccountCurrency string
 	// AccountCurrencyAmount is an amount in account currency.
-	AccountCurrencyAmount MoneyWith2DecimalPlaces
+	AccountCurrencyAmount Money
 	// OriginCurrency is a currency of the transaction before conversion.
 	OriginCurrency string
 	// OriginCurrencyAmount is an amount in origin currency.
-	OriginCurrencyAmount MoneyWith2DecimalPlaces
+	OriginCurrencyAmount Money
 	// Amounts contains "converted" amounts in given currencies.
 	Amounts map[string]AmountInCurrency
-	// RuleType is a type of rule that matched (FromAccount, ToAccount, or Substring).
+	// RuleType is a type of rule that matched (FromAccount, ToAccount, Substring, Rule, Manual or Split).
 	RuleType RuleType
-	// RuleValue is the actual value that matched (account name or substring).
+	// RuleValue is the actual value that matched (account name, substring or rule description).
 	RuleValue string
+	// Fingerprint identifies the original transaction, see `TransactionFingerprint`.
+	Fingerprint string
+	// IsTransfer is true if entry is a transfer between my own accounts.
+	// In this case `FromAccount` and `ToAccount` are my accounts and `IsExpense` is false.
+	IsTransfer bool
+	// IncomingLeg is an incoming part of the transfer. Set only for transfers.
+	IncomingLeg *TransferLeg
 }
 
 // FileParser is an interface to parse raw/unified transactions from a file.
@@ -243,7 +237,7 @@ type Group struct {
 	Name string
 	// Total is a total amount of the group.
 	// May be lower than sum of amounts in journal entries if some entries are not included.
-	Total MoneyWith2DecimalPlaces
+	Total Money
 	// JournalEntries is a list of all journal entries in the group.
 	JournalEntries []JournalEntry
 }
@@ -252,15 +246,19 @@ type Group struct {
 type CategoryMatch struct {
 	// Name is a name of the group.
 	Name string
-	// RuleType is a type of rule that matched (FromAccount, ToAccount, or Substring).
+	// RuleType is a type of rule that matched (FromAccount, ToAccount, Substring, Rule, Manual or Split).
 	RuleType RuleType
-	// RuleValue is the actual value that matched (account name or substring).
+	// RuleValue is the actual value that matched (account name, substring or rule description).
 	RuleValue string
+	// Path is a path of the group in categories hierarchy, the last element is the group itself.
+	Path []string
 }
 
 // IntervalStatistics is a struct representing a list of journal entries for time interval, usually month.
 // Contains "income" and "expense" groups of journal entries for one currency.
 type IntervalStatistic struct {
+	// Name is a short name of the interval like "2024-07" for month or "2024-Q3" for quarter.
+	Name string
 	// Currency is a currency of the interval.
 	Currency string
 	// Start is a start date of the interval.
@@ -271,4 +269,31 @@ type IntervalStatistic struct {
 	Income map[string]*Group
 	// Expense is a map of "expense" type `Group`-s.
 	Expense map[string]*Group
+	// Transfers is a map of `Group`-s with transfers between my accounts, per pair of accounts.
+	Transfers map[string]*Group
+	// IncomeTree is a hierarchy of "income" categories with rolled up totals.
+	IncomeTree []*CategoryNode
+	// ExpenseTree is a hierarchy of "expense" categories with rolled up totals.
+	ExpenseTree []*CategoryNode
+	// Budgets is a progress of configured budgets sorted by name.
+	Budgets []*BudgetStatistic
+	// Anomalies are expense groups with unusual totals comparing with previous months, most unusual first.
+	Anomalies []*CategoryAnomaly
+	// TransactionAnomalies are expenses unusually large for their groups, most unusual first.
+	TransactionAnomalies []*TransactionAnomaly
+}
+
+// CategoryNode is a node in hierarchy of categories. Total includes totals of all children.
+type CategoryNode struct {
+	// Name is the last part of the path.
+	Name string
+	// Path is a full path of the node joined with `CategorySeparator`.
+	Path string
+	// Group is a name of `Group` with journal entries categorized directly into this node.
+	// Empty for nodes which only aggregate children.
+	Group string
+	// Total is a total amount of the own group and all children.
+	Total Money
+	// Children are nested categories sorted by total descending.
+	Children []*CategoryNode
 }
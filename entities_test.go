@@ -4,7 +4,7 @@ import (
 	"testing"
 )
 
-func TestMoneyWith2DecimalPlaces_UnmarshalText(t *testing.T) {
+func TestMoney_UnmarshalText(t *testing.T) {
 	tests := []struct {
 		name    string
 		input   string
@@ -14,19 +14,19 @@ func TestMoneyWith2DecimalPlaces_UnmarshalText(t *testing.T) {
 		{
 			name:    "valid input",
 			input:   "123.45",
-			wantInt: 12345,
+			wantInt: 123450,
 			wantErr: false,
 		},
 		{
 			name:    "input with decimal places",
 			input:   "123.456",
-			wantInt: 12345,
+			wantInt: 123456,
 			wantErr: false,
 		},
 		{
 			name:    "input with negative value",
 			input:   "-123.45",
-			wantInt: -12345,
+			wantInt: -123450,
 			wantErr: false,
 		},
 		{
@@ -39,7 +39,7 @@ func TestMoneyWith2DecimalPlaces_UnmarshalText(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var m MoneyWith2DecimalPlaces
+			var m Money
 			err := m.UnmarshalText([]byte(tt.input))
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("unexpected error: got %v, wantErr %v", err, tt.wantErr)
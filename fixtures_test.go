@@ -0,0 +1,139 @@
+package main
+
+import (
+	"time"
+)
+
+// utcDate returns midnight of the day in UTC.
+func utcDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func intPtr(value int) *int {
+	return &value
+}
+
+// newTestSource returns statement of the account with balances, nil amount means no balance.
+func newTestSource(account, currency string, from, to time.Time, opening, closing *int) *TransactionsSource {
+	source := &TransactionsSource{
+		TypeName:        "Test",
+		Tag:             "Test",
+		FilePath:        account + ".csv",
+		AccountNumber:   account,
+		AccountCurrency: currency,
+	}
+	if opening != nil {
+		source.OpeningBalance = &BalanceRecord{Date: from, Amount: Money{int: *opening}}
+	}
+	if closing != nil {
+		source.ClosingBalance = &BalanceRecord{Date: to.AddDate(0, 0, 1), Amount: Money{int: *closing}}
+	}
+	return source
+}
+
+// newTestFileInfo returns info about file of the account in AMD with transactions from-to.
+// Balances are set for the period between transactions if provided.
+func newTestFileInfo(path, account string, from, to time.Time, opening, closing *int) FileInfo {
+	source := newTestSource(account, "AMD", from, to, opening, closing)
+	source.FilePath = path
+	return FileInfo{
+		Path:              path,
+		Source:            source,
+		TransactionsCount: 1,
+		AccountNumber:     account,
+		FromDate:          from.Add(10 * time.Hour),
+		ToDate:            to.Add(10 * time.Hour),
+	}
+}
+
+// newTestTransaction returns transaction of the source between own account and counterparty,
+// negative amount is an expense. Transaction without source is in AMD of "my" account.
+func newTestTransaction(source *TransactionsSource, date time.Time, amount int, counterparty, details string) Transaction {
+	account, currency := "my", "AMD"
+	if source != nil {
+		account, currency = source.AccountNumber, source.AccountCurrency
+	}
+	isExpense := amount < 0
+	if isExpense {
+		amount = -amount
+	}
+	transaction := Transaction{
+		Date:            date,
+		FromAccount:     counterparty,
+		ToAccount:       account,
+		IsExpense:       isExpense,
+		Amount:          Money{int: amount},
+		Details:         details,
+		Source:          source,
+		AccountCurrency: currency,
+	}
+	if isExpense {
+		transaction.FromAccount, transaction.ToAccount = account, counterparty
+	}
+	return transaction
+}
+
+// newTestTransactions returns transactions without source on dates with the same amount and details.
+func newTestTransactions(dates []time.Time, amount int, details string) []Transaction {
+	result := make([]Transaction, 0, len(dates))
+	for _, date := range dates {
+		result = append(result, newTestTransaction(nil, date, amount, "", details))
+	}
+	return result
+}
+
+// newTestJournalEntry returns journal entry of the transaction categorized by the match,
+// with amount in account currency and provided amounts in other currencies.
+func newTestJournalEntry(transaction Transaction, match *CategoryMatch, otherAmounts ...AmountInCurrency) JournalEntry {
+	entry := JournalEntry{
+		Date:                  transaction.Date,
+		IsExpense:             transaction.IsExpense,
+		Source:                transaction.Source,
+		Details:               transaction.Details,
+		Category:              match.Name,
+		CategoryPath:          match.Path,
+		FromAccount:           transaction.FromAccount,
+		ToAccount:             transaction.ToAccount,
+		AccountCurrency:       transaction.AccountCurrency,
+		AccountCurrencyAmount: transaction.Amount,
+		Amounts: map[string]AmountInCurrency{
+			transaction.AccountCurrency: {Currency: transaction.AccountCurrency, Amount: transaction.Amount},
+		},
+		RuleType:    match.RuleType,
+		RuleValue:   match.RuleValue,
+		Fingerprint: TransactionFingerprint(&transaction),
+	}
+	for _, amount := range otherAmounts {
+		entry.Amounts[amount.Currency] = amount
+	}
+	return entry
+}
+
+// newTestMonth returns statistics for the month of 2024 with expense groups per currency.
+// Each group consists of journal entries with provided amounts in units of `Money`, one per day.
+func newTestMonth(month time.Month, expenses map[string]map[string][]int) map[string]*IntervalStatistic {
+	start := utcDate(2024, month, 1)
+	result := make(map[string]*IntervalStatistic, len(expenses))
+	for currency, groups := range expenses {
+		source := newTestSource("my", currency, start, start, nil, nil)
+		stat := &IntervalStatistic{
+			Currency: currency,
+			Start:    start,
+			End:      start.AddDate(0, 1, 0).Add(-time.Nanosecond),
+			Expense:  make(map[string]*Group),
+		}
+		for name, amounts := range groups {
+			group := &Group{Name: name}
+			match := &CategoryMatch{Name: name, Path: splitCategoryPath(name)}
+			for i, amount := range amounts {
+				transaction := newTestTransaction(source, start.AddDate(0, 0, i), -amount, "", name)
+				group.JournalEntries = append(group.JournalEntries, newTestJournalEntry(transaction, match))
+				group.Total.int += amount
+			}
+			stat.Expense[name] = group
+		}
+		stat.ExpenseTree = buildCategoryTree(stat.Expense)
+		result[currency] = stat
+	}
+	return result
+}
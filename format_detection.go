@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shakinm/xlsReader/xls"
+	"github.com/tealeg/xlsx"
+)
+
+// maxSniffedRows is a number of first rows to read from each sheet to detect file format.
+const maxSniffedRows = 40
+
+// maxSniffedCells is a number of first cells to read from each row of XLS file.
+const maxSniffedCells = 30
+
+// FileContainer is a type of file "container" detected by content, not by extension.
+type FileContainer string
+
+const (
+	FileContainerUnknown FileContainer = ""
+	FileContainerXml     FileContainer = "xml"
+	// FileContainerXlsx is Office Open XML spreadsheet. Note that some banks give it ".xls" extension.
+	FileContainerXlsx FileContainer = "xlsx"
+	// FileContainerXls is legacy binary (BIFF) spreadsheet.
+	FileContainerXls  FileContainer = "xls"
+	FileContainerText FileContainer = "text"
+)
+
+var (
+	zipSignature = []byte{0x50, 0x4B, 0x03, 0x04}
+	oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+	utf16LEBOM   = []byte{0xFF, 0xFE}
+	utf8BOM      = []byte{0xEF, 0xBB, 0xBF}
+	// sniffedExtensions are extensions of files which may contain transactions.
+	sniffedExtensions = map[string]struct{}{
+		".xml": {}, ".csv": {}, ".txt": {}, ".xls": {}, ".xlsx": {}, ".ofx": {}, ".qfx": {},
+	}
+)
+
+// SniffedSheet contains first rows of one sheet. Text files have one sheet without name.
+type SniffedSheet struct {
+	Name string
+	// Rows contains trimmed values of cells.
+	Rows [][]string
+}
+
+// FileSniff contains information about file content which is enough to detect its format.
+type FileSniff struct {
+	Path string
+	// Extension is lowercased file extension with dot.
+	Extension string
+	Container FileContainer
+	// IsUTF16 is true if text file is in UTF-16 encoding (with or without BOM).
+	IsUTF16 bool
+	// XmlRoot is a name of the root element for XML files.
+	XmlRoot string
+	Sheets  []SniffedSheet
+}
+
+// HasRow returns true if some row concatenated without separators is equal to the specified string.
+func (s *FileSniff) HasRow(merged string) bool {
+	return s.findRow(func(row []string) bool {
+		return strings.Join(row, "") == merged
+	})
+}
+
+// HasRowWithPrefix returns true if some row concatenated without separators starts with the specified string.
+func (s *FileSniff) HasRowWithPrefix(prefix string) bool {
+	return s.findRow(func(row []string) bool {
+		return strings.HasPrefix(strings.Join(row, ""), prefix)
+	})
+}
+
+// HasRowStartingWith returns true if some row starts with the specified cells.
+func (s *FileSniff) HasRowStartingWith(cells ...string) bool {
+	return s.findRow(func(row []string) bool {
+		if len(row) < len(cells) {
+			return false
+		}
+		for i, cell := range cells {
+			if row[i] != cell {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (s *FileSniff) findRow(isMatch func(row []string) bool) bool {
+	for _, sheet := range s.Sheets {
+		for _, row := range sheet.Rows {
+			if isMatch(row) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sniffFile reads beginning of the file to find out its container, encoding and first rows.
+// Files with not supported extensions are not read and get FileContainerUnknown container.
+func sniffFile(filePath string) (*FileSniff, error) {
+	sniff := &FileSniff{
+		Path:      filePath,
+		Extension: strings.ToLower(filepath.Ext(filePath)),
+	}
+	if _, ok := sniffedExtensions[sniff.Extension]; !ok {
+		return sniff, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(data, zipSignature):
+		sniff.Container = FileContainerXlsx
+		err = sniffXlsx(sniff, data)
+	case bytes.HasPrefix(data, oleSignature):
+		sniff.Container = FileContainerXls
+		err = sniffXls(sniff)
+	default:
+		err = sniffText(sniff, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sniff, nil
+}
+
+func sniffXlsx(sniff *FileSniff, data []byte) error {
+	f, err := xlsx.OpenBinary(data)
+	if err != nil {
+		return fmt.Errorf("failed to open XLSX file: %w", err)
+	}
+	for _, sheet := range f.Sheets {
+		sniffedSheet := SniffedSheet{Name: sheet.Name}
+		for i, row := range sheet.Rows {
+			if i >= maxSniffedRows {
+				break
+			}
+			cells := make([]string, 0, len(row.Cells))
+			for _, cell := range row.Cells {
+				cells = append(cells, strings.TrimSpace(cell.Value))
+			}
+			sniffedSheet.Rows = append(sniffedSheet.Rows, cells)
+		}
+		sniff.Sheets = append(sniff.Sheets, sniffedSheet)
+	}
+	return nil
+}
+
+func sniffXls(sniff *FileSniff) error {
+	f, err := xls.OpenFile(sniff.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open XLS file: %w", err)
+	}
+	for sheetIndex := 0; sheetIndex < f.GetNumberSheets(); sheetIndex++ {
+		sheet, err := f.GetSheet(sheetIndex)
+		if err != nil {
+			return fmt.Errorf("failed to get %d sheet: %w", sheetIndex, err)
+		}
+		sniffedSheet := SniffedSheet{Name: sheet.GetName()}
+		for i := 0; i <= sheet.GetNumberRows() && i < maxSniffedRows; i++ {
+			cells := make([]string, 0)
+			if row, err := sheet.GetRow(i); err == nil && row != nil {
+				for j, cell := range row.GetCols() {
+					if j >= maxSniffedCells {
+						break
+					}
+					cells = append(cells, strings.TrimSpace(cell.GetString()))
+				}
+			}
+			sniffedSheet.Rows = append(sniffedSheet.Rows, cells)
+		}
+		sniff.Sheets = append(sniff.Sheets, sniffedSheet)
+	}
+	return nil
+}
+
+func sniffText(sniff *FileSniff, data []byte) error {
+	if isUTF16LE(data) {
+		sniff.IsUTF16 = true
+		if len(data)%2 != 0 {
+			data = data[:len(data)-1]
+		}
+		decoded, err := decodeUTF16ToUTF8(data)
+		if err != nil {
+			return err
+		}
+		data = decoded
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		sniff.Container = FileContainerXml
+		decoder := xml.NewDecoder(bytes.NewReader(data))
+		// Root element is enough, so don't fail on not UTF-8 encodings declared in prolog.
+		decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+			return input, nil
+		}
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return fmt.Errorf("failed to find XML root element: %w", err)
+			}
+			if start, ok := token.(xml.StartElement); ok {
+				sniff.XmlRoot = start.Name.Local
+				return nil
+			}
+		}
+	}
+
+	sniff.Container = FileContainerText
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = guessCsvDelimiter(data)
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	sniffedSheet := SniffedSheet{}
+	for i := 0; i < maxSniffedRows; i++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Not a CSV file, just stop on rows which were read.
+			break
+		}
+		cells := make([]string, 0, len(record))
+		for _, cell := range record {
+			cells = append(cells, strings.TrimSpace(strings.Trim(cell, `"`)))
+		}
+		sniffedSheet.Rows = append(sniffedSheet.Rows, cells)
+	}
+	sniff.Sheets = append(sniff.Sheets, sniffedSheet)
+	return nil
+}
+
+// isUTF16LE checks BOM or, if there is no BOM, zero bytes in the first ASCII-like characters.
+func isUTF16LE(data []byte) bool {
+	if bytes.HasPrefix(data, utf16LEBOM) {
+		return true
+	}
+	if len(data) < 4 {
+		return false
+	}
+	return data[0] != 0 && data[1] == 0 && data[2] != 0 && data[3] == 0
+}
+
+// guessCsvDelimiter returns delimiter which is used in the first line of the text.
+func guessCsvDelimiter(data []byte) rune {
+	firstLine := data
+	if index := bytes.IndexByte(data, '\n'); index >= 0 {
+		firstLine = data[:index]
+	}
+	switch {
+	case bytes.Contains(firstLine, []byte("\t")):
+		return '\t'
+	case bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")):
+		return ';'
+	default:
+		return ','
+	}
+}
+
+// detectParsers returns all registered parsers which recognize the file.
+func detectParsers(sniff *FileSniff) []*ParserRegistration {
+	result := make([]*ParserRegistration, 0)
+	for _, registration := range getRegisteredParsers() {
+		if registration.Detect != nil && registration.Detect(sniff) {
+			result = append(result, registration)
+		}
+	}
+	return result
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestDetectParsers(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFile := func(name string, content []byte) string {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+		return path
+	}
+	encodeUTF16LE := func(text string) []byte {
+		result := make([]byte, 0, len(text)*2)
+		for _, r := range utf16.Encode([]rune(text)) {
+			result = append(result, byte(r), byte(r>>8))
+		}
+		return result
+	}
+
+	tests := []struct {
+		name              string
+		filePath          string
+		expectedContainer FileContainer
+		expectedParsers   []string
+	}{
+		{
+			name:              "Ineco XML",
+			filePath:          "demo/Statement_SALARY_EUR_365_.xml",
+			expectedContainer: FileContainerXml,
+			expectedParsers:   []string{InecoXmlParserName},
+		},
+		{
+			name:              "Ineco XLSX",
+			filePath:          "testdata/ineco/valid_card.xlsx",
+			expectedContainer: FileContainerXlsx,
+			expectedParsers:   []string{InecoXlsxParserName},
+		},
+		{
+			name:              "Ameria CSV in UTF-16 with BOM",
+			filePath:          "testdata/ameria/with_bom_header.csv",
+			expectedContainer: FileContainerText,
+			expectedParsers:   []string{AmeriaCsvParserName},
+		},
+		{
+			name:              "MyAmeria History XLSX with XLS extension",
+			filePath:          "testdata/ameria/valid_file.xls",
+			expectedContainer: FileContainerXlsx,
+			expectedParsers:   []string{MyAmeriaHistoryXlsParserName},
+		},
+		{
+			name:              "MyAmeria account statement XLS",
+			filePath:          "testdata/ameria/valid_statement.xls",
+			expectedContainer: FileContainerXls,
+			expectedParsers:   []string{MyAmeriaXlsParserName},
+		},
+		{
+			name:              "Ardshin XLSX",
+			filePath:          "testdata/ardshin/valid.xlsx",
+			expectedContainer: FileContainerXlsx,
+			expectedParsers:   []string{ArdshinXlsxParserName},
+		},
+		{
+			name:              "Acba regular account XLS",
+			filePath:          "testdata/acba/valid_account.xls",
+			expectedContainer: FileContainerXls,
+			expectedParsers:   []string{AcbaRegularAccountXlsParserName},
+		},
+		{
+			name:              "Acba card XLS",
+			filePath:          "testdata/acba/valid_card.xls",
+			expectedContainer: FileContainerXls,
+			expectedParsers:   []string{AcbaCardXlsParserName},
+		},
+		{
+			name:              "OFX 1.x SGML",
+			filePath:          "testdata/ofx/checking_sgml.ofx",
+			expectedContainer: FileContainerText,
+			expectedParsers:   []string{OfxParserName},
+		},
+		{
+			name:              "OFX 2.x XML",
+			filePath:          "testdata/ofx/accounts_xml.qfx",
+			expectedContainer: FileContainerXml,
+			expectedParsers:   []string{OfxParserName},
+		},
+		{
+			name: "Generic CSV",
+			filePath: writeFile("generic.csv", []byte(
+				"Date,FromAccount,ToAccount,IsExpense,Amount,Details,AccountCurrency,OriginCurrency,OriginCurrencyAmount\n"+
+					"2024-01-01,1,2,true,10.00,Coffee,AMD,AMD,10.00\n",
+			)),
+			expectedContainer: FileContainerText,
+			expectedParsers:   []string{GenericCsvParserName},
+		},
+		{
+			name:              "UTF-16 CSV without BOM and wrong header",
+			filePath:          writeFile("utf16.csv", encodeUTF16LE("Date\tAmount\n2024-01-01\t10\n")),
+			expectedContainer: FileContainerText,
+			expectedParsers:   []string{},
+		},
+		{
+			name:              "XML with unknown root",
+			filePath:          writeFile("other.xml", []byte(`<?xml version="1.0"?><Document></Document>`)),
+			expectedContainer: FileContainerXml,
+			expectedParsers:   []string{},
+		},
+		{
+			name:              "not supported extension",
+			filePath:          writeFile("statement.pdf", []byte("%PDF-1.4")),
+			expectedContainer: FileContainerUnknown,
+			expectedParsers:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			sniff, err := sniffFile(tt.filePath)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			parsers := make([]string, 0)
+			for _, registration := range detectParsers(sniff) {
+				parsers = append(parsers, registration.Name)
+			}
+
+			// Assert
+			if sniff.Container != tt.expectedContainer {
+				t.Errorf("Expected container '%s', got '%s'", tt.expectedContainer, sniff.Container)
+			}
+			if !reflect.DeepEqual(parsers, tt.expectedParsers) {
+				t.Errorf("Expected parsers %v, got %v", tt.expectedParsers, parsers)
+			}
+		})
+	}
+}
+
+func TestGuessCsvDelimiter(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected rune
+	}{
+		{"a,b,c\n1;2;3", ','},
+		{"a;b;c\n", ';'},
+		{"a\tb,c\n", '\t'},
+		{"single", ','},
+	}
+	for _, tt := range tests {
+		if actual := guessCsvDelimiter([]byte(tt.data)); actual != tt.expected {
+			t.Errorf("For %q expected %q, got %q", tt.data, tt.expected, actual)
+		}
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"time"
 )
 
 // Expected headers in the exact order matching Transaction struct fields
@@ -24,6 +23,28 @@ var expectedHeaders = []string{
 	"OriginCurrencyAmount",
 }
 
+const (
+	// GenericCsvParserName is a name of parser to use in `sources` configuration.
+	GenericCsvParserName = "genericCsv"
+	genericCsvTypeName   = "Generic CSV with transactions"
+)
+
+func init() {
+	RegisterParser(ParserRegistration{
+		Name:        GenericCsvParserName,
+		TypeName:    genericCsvTypeName,
+		Tag:         "GenericCsv",
+		Version:     2,
+		DefaultGlob: "generic*.csv",
+		NewParser:   newParserWithoutOptions(GenericCsvFileParser{}),
+		Detect: func(sniff *FileSniff) bool {
+			return sniff.Container == FileContainerText &&
+				len(sniff.Sheets) > 0 && len(sniff.Sheets[0].Rows) > 0 &&
+				strings.Join(sniff.Sheets[0].Rows[0], ",") == strings.Join(expectedHeaders, ",")
+		},
+	})
+}
+
 type GenericCsvFileParser struct{}
 
 func (p GenericCsvFileParser) ParseRawTransactionsFromFile(
@@ -80,7 +101,7 @@ func (p GenericCsvFileParser) ParseRawTransactionsFromFile(
 	}
 
 	sourceType := TransactionsSource{
-		TypeName: "Generic CSV with transactions",
+		TypeName: genericCsvTypeName,
 		FilePath: filePath,
 	}
 
@@ -112,7 +133,7 @@ func (p GenericCsvFileParser) ParseRawTransactionsFromFile(
 		}
 
 		// Date (time.Time)
-		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+		date, err := parseDate(record[0], OutputDateFormat)
 		if err != nil {
 			return nil, fmt.Errorf(
 				"line %d: invalid Date format '%s', expected YYYY-MM-DD: %w",
@@ -143,8 +164,8 @@ func (p GenericCsvFileParser) ParseRawTransactionsFromFile(
 			}
 		}
 
-		// Amount (MoneyWith2DecimalPlaces)
-		var amount MoneyWith2DecimalPlaces
+		// Amount (Money)
+		var amount Money
 		if err := amount.UnmarshalText([]byte(strings.TrimSpace(record[4]))); err != nil {
 			return nil, fmt.Errorf("line %d: invalid Amount value '%s': %w", lineNum, record[4], err)
 		}
@@ -166,9 +187,9 @@ func (p GenericCsvFileParser) ParseRawTransactionsFromFile(
 		// OriginCurrency (string)
 		transaction.OriginCurrency = strings.TrimSpace(record[7])
 
-		// OriginCurrencyAmount (MoneyWith2DecimalPlaces)
+		// OriginCurrencyAmount (Money)
 		if originAmount := strings.TrimSpace(record[8]); originAmount != "" {
-			var originCurrencyAmount MoneyWith2DecimalPlaces
+			var originCurrencyAmount Money
 			if err := originCurrencyAmount.UnmarshalText([]byte(originAmount)); err != nil {
 				return nil, fmt.Errorf(
 					"line %d: invalid OriginCurrencyAmount value '%s': %w",
@@ -56,7 +56,7 @@ func TestGenericCsvFileParser_ParseRawTransactionsFromFile_Success(t *testing.T)
 			FromAccount:     "123456",
 			ToAccount:       "789012",
 			IsExpense:       true,
-			Amount:          MoneyWith2DecimalPlaces{int: 250},
+			Amount:          Money{int: 2500},
 			Details:         "Coffee purchase",
 			AccountCurrency: "USD",
 			Source:          source,
@@ -66,11 +66,11 @@ func TestGenericCsvFileParser_ParseRawTransactionsFromFile_Success(t *testing.T)
 			FromAccount:          "987654",
 			ToAccount:            "123456",
 			IsExpense:            false,
-			Amount:               MoneyWith2DecimalPlaces{int: 100000},
+			Amount:               Money{int: 1000000},
 			Details:              "Salary deposit",
 			AccountCurrency:      "EUR",
 			OriginCurrency:       "USD",
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 110000},
+			OriginCurrencyAmount: Money{int: 1100000},
 			Source:               source,
 		},
 	}
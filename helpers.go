@@ -8,7 +8,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
-	"time"
+	"sync"
 )
 
 // getFilesByGlob retrieves files matching the glob pattern.
@@ -59,92 +59,207 @@ func writeAndOpenFile(resultFilePath, content string, openFile bool) {
 	}
 }
 
-// parseTransactionsOfOneType parses transactions from files of one type by one glob pattern.
+// sourceFiles contains files found for one source and the parser to use for them.
+type sourceFiles struct {
+	registration *ParserRegistration
+	parser       FileParser
+	files        []string
+	// firstJob is an index of the first file in the list of all parsing jobs.
+	firstJob int
+}
+
+// parsingJob is one file to parse.
+type parsingJob struct {
+	file   string
+	parser FileParser
+}
+
+// parsingResult is a result of parsing one file.
+type parsingResult struct {
+	transactions  []Transaction
+	notFatalError string
+	fileInfos     []FileInfo
+	err           error
+}
+
+// parseTransactionsOfSources parses transactions from files of all sources.
+// Files are parsed concurrently with `workers` goroutines, uses cache to don't parse not changed files (cache may be nil).
+// Result doesn't depend on number of workers: transactions go in order of sources and then in order of files.
 // Updates parsingWarnings slice with warnings were found.
 // Returns list of transactions, list of file infos and error if it is fatal.
-func parseTransactionsOfOneType(
-	glob string,
-	nameOfFilesUnderGlob string,
-	parser FileParser,
+func parseTransactionsOfSources(
+	sources []SourceConfig,
+	config *Config,
+	cache *TransactionsCache,
+	workers int,
 	parsingWarnings *[]string,
 ) ([]Transaction, []FileInfo, error) {
-	transactions, warning, fileInfos, err := parseTransactionFiles(glob, parser)
-	if err != nil {
-		return nil, nil, errors.New(i18n.T("error on parsing transactions from name files", "name", nameOfFilesUnderGlob, "err", err))
+	// Find files for all sources to make one list of jobs.
+	allSourceFiles := make([]*sourceFiles, 0, len(sources))
+	jobs := make([]parsingJob, 0)
+	for _, source := range sources {
+		registration, parser, err := newParserForSource(source, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		parser = cache.wrapParser(parser, registration)
+		files, warning, err := findFilesOfSource(source.Glob)
+		if err != nil {
+			return nil, nil, errors.New(i18n.T("error on parsing transactions from name files", "name", registration.TypeName, "err", err))
+		}
+		if warning != "" {
+			*parsingWarnings = append(*parsingWarnings, i18n.T("Can't parse all n files", "n", registration.TypeName, "warning", warning))
+		}
+		allSourceFiles = append(allSourceFiles, &sourceFiles{
+			registration: registration,
+			parser:       parser,
+			files:        files,
+			firstJob:     len(jobs),
+		})
+		for _, file := range files {
+			jobs = append(jobs, parsingJob{file: file, parser: parser})
+		}
 	}
-	if warning != "" {
-		*parsingWarnings = append(*parsingWarnings, i18n.T("Can't parse all n files", "n", nameOfFilesUnderGlob, "warning", warning))
+
+	// Parse all files concurrently and save results by index of job to keep order.
+	results := make([]parsingResult, len(jobs))
+	forEachInParallel(len(jobs), workers, func(i int) {
+		result := &results[i]
+		result.transactions, result.notFatalError, result.fileInfos, result.err = parseTransactionFile(jobs[i].file, jobs[i].parser)
+	})
+
+	// Assemble results in order of sources.
+	transactions := make([]Transaction, 0)
+	fileInfos := make([]FileInfo, 0, len(jobs))
+	for _, sourceFiles := range allSourceFiles {
+		typeName := sourceFiles.registration.TypeName
+		for i := range sourceFiles.files {
+			result := results[sourceFiles.firstJob+i]
+			if result.err != nil {
+				return nil, nil, errors.New(i18n.T("error on parsing transactions from name files", "name", typeName, "err", result.err))
+			}
+			if result.notFatalError != "" {
+				*parsingWarnings = append(*parsingWarnings, i18n.T("Can't parse all n files", "n", typeName, "warning", result.notFatalError))
+			}
+			setDefaultTag(result.transactions, sourceFiles.registration)
+			transactions = append(transactions, result.transactions...)
+			fileInfos = append(fileInfos, result.fileInfos...)
+		}
 	}
 	return transactions, fileInfos, nil
 }
 
-// parseTransactionFiles parses transactions from files by glob pattern.
-// Returns list of transactions, not fatal error message and error if it is fatal.
-func parseTransactionFiles(glob string, parser FileParser) ([]Transaction, string, []FileInfo, error) {
+// forEachInParallel calls `fn` for each index from 0 to n-1 in no more than `workers` goroutines.
+// Waits until all calls are finished. To keep results ordered `fn` should save them by index.
+func forEachInParallel(n int, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// setDefaultTag sets registered tag for sources which parser doesn't tag by itself.
+func setDefaultTag(transactions []Transaction, registration *ParserRegistration) {
+	for _, transaction := range transactions {
+		if transaction.Source != nil && transaction.Source.Tag == "" {
+			transaction.Source.Tag = registration.Tag
+		}
+	}
+}
+
+// findFilesOfSource returns files matching glob pattern.
+// Returns list of files, not fatal error message if there are no files and error if it is fatal.
+func findFilesOfSource(glob string) ([]string, string, error) {
 	files, err := getFilesByGlob(glob)
 	if err != nil {
-		return nil, "", nil, err
+		return nil, "", err
 	}
 	if len(files) < 1 {
 		workingDir, err := os.Getwd()
 		if err != nil {
-			return nil, "", nil, errors.New(i18n.T("can't get working directory", "err", err))
+			return nil, "", errors.New(i18n.T("can't get working directory", "err", err))
 		}
-		return nil, i18n.T("there are no files in d matching p pattern", "d", workingDir, "p", glob), nil, nil
+		return nil, i18n.T("there are no files in d matching p pattern", "d", workingDir, "p", glob), nil
 	}
+	return files, "", nil
+}
 
-	result := make([]Transaction, 0)
-	fileInfos := make([]FileInfo, 0)
+// parseTransactionFile parses transactions from one file.
+// Returns list of transactions, not fatal error message, file infos and error if it is fatal.
+// File has one info per source of transactions (like account) because file may contain several statements,
+// file without transactions has one info without source.
+func parseTransactionFile(file string, parser FileParser) ([]Transaction, string, []FileInfo, error) {
 	notFatalError := ""
-
-	for _, file := range files {
-		log.Println(i18n.T("Parsing file with parser", "file", file, "parser", parser))
-		rawTransactions, err := parser.ParseRawTransactionsFromFile(file)
-		if err != nil {
-			notFatalError = i18n.T("can't parse transactions from file f", "f", file, "err", err)
-			if len(rawTransactions) < 1 {
-				// If both error and no transactions then treat error as fatal.
-				return result, "", nil, errors.New(i18n.T("can't parse transactions from file f", "f", file, "err", err))
-			} else {
-				// Otherwise just log.
-				log.Println(notFatalError)
-			}
-		}
+	log.Println(i18n.T("Parsing file with parser", "file", file, "parser", parser))
+	rawTransactions, err := parser.ParseRawTransactionsFromFile(file)
+	if err != nil {
+		notFatalError = i18n.T("can't parse transactions from file f", "f", file, "err", err)
 		if len(rawTransactions) < 1 {
-			notFatalError = i18n.T("Can't find transactions in f file", "f", file)
+			// If both error and no transactions then treat error as fatal.
+			return nil, "", nil, errors.New(notFatalError)
+		} else {
+			// Otherwise just log.
 			log.Println(notFatalError)
 		}
-		log.Println(i18n.T("Found n transactions in f file", "n", len(rawTransactions), "f", file))
-		result = append(result, rawTransactions...)
+	}
+	if len(rawTransactions) < 1 {
+		notFatalError = i18n.T("Can't find transactions in f file", "f", file)
+		log.Println(notFatalError)
+	}
+	log.Println(i18n.T("Found n transactions in f file", "n", len(rawTransactions), "f", file))
 
-		// Get file info.
-		fileInfo, err := os.Stat(file)
-		if err != nil {
-			return result, "", nil, errors.New(i18n.T("can't get file info for '%s': %v", file, err))
-		}
-		var fileFromDate, fileToDate time.Time
-		if len(rawTransactions) > 0 {
-			fileFromDate = rawTransactions[0].Date
-			fileToDate = rawTransactions[0].Date
-
-			for _, transaction := range rawTransactions {
-				if transaction.Date.Before(fileFromDate) {
-					fileFromDate = transaction.Date
-				}
-				if transaction.Date.After(fileToDate) {
-					fileToDate = transaction.Date
-				}
+	// Get file info.
+	fileInfo, err := os.Stat(file)
+	if err != nil {
+		return rawTransactions, "", nil, errors.New(i18n.T("can't get file info for f", "f", file, "err", err))
+	}
+	if len(rawTransactions) < 1 {
+		return rawTransactions, notFatalError, []FileInfo{{Path: file, ModifiedTime: fileInfo.ModTime()}}, nil
+	}
+	result := make([]FileInfo, 0, 1)
+	sourceIndexes := make(map[*TransactionsSource]int)
+	for _, transaction := range rawTransactions {
+		index, ok := sourceIndexes[transaction.Source]
+		if !ok {
+			index = len(result)
+			sourceIndexes[transaction.Source] = index
+			result = append(result, FileInfo{
+				Path:         file,
+				Source:       transaction.Source,
+				ModifiedTime: fileInfo.ModTime(),
+				FromDate:     transaction.Date,
+				ToDate:       transaction.Date,
+			})
+			if transaction.Source != nil {
+				result[index].AccountNumber = transaction.Source.AccountNumber
 			}
 		}
-		fileInfos = append(fileInfos, FileInfo{
-			Path:              file,
-			Source:            rawTransactions[0].Source,
-			TransactionsCount: len(rawTransactions),
-			ModifiedTime:      fileInfo.ModTime(),
-			FromDate:          fileFromDate,
-			ToDate:            fileToDate,
-		})
+		info := &result[index]
+		info.TransactionsCount++
+		if transaction.Date.Before(info.FromDate) {
+			info.FromDate = transaction.Date
+		}
+		if transaction.Date.After(info.ToDate) {
+			info.ToDate = transaction.Date
+		}
 	}
-
-	return result, notFatalError, fileInfos, nil
+	return rawTransactions, notFatalError, result, nil
 }
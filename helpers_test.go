@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestForEachInParallel(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		workers int
+	}{
+		{"no_items", 0, 4},
+		{"one_worker", 10, 1},
+		{"wrong_workers", 10, 0},
+		{"more_workers_than_items", 3, 10},
+		{"many_items", 1000, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Arrange
+			results := make([]int, tt.n)
+			var calls atomic.Int32
+
+			// Act
+			forEachInParallel(tt.n, tt.workers, func(i int) {
+				calls.Add(1)
+				results[i] = i * i
+			})
+
+			// Assert
+			if int(calls.Load()) != tt.n {
+				t.Errorf("expected %d calls, got %d", tt.n, calls.Load())
+			}
+			for i, result := range results {
+				if result != i*i {
+					t.Errorf("wrong result %d for %d index", result, i)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTransactionsOfSources_DeterministicOrder(t *testing.T) {
+	// Arrange
+	sources := []SourceConfig{
+		{Parser: ArdshinXlsxParserName, Glob: "testdata/ardshin/valid.xlsx"},
+		{Parser: InecoXlsxParserName, Glob: "testdata/ineco/valid_*.xlsx"},
+		{Parser: AcbaRegularAccountXlsParserName, Glob: "testdata/acba/valid_account.xls"},
+		{Parser: AcbaCardXlsParserName, Glob: "testdata/acba/valid_card.xls"},
+		{Parser: GenericCsvParserName, Glob: "testdata/not_existing/*.csv"},
+	}
+	config := &Config{}
+	expectedWarnings := []string{"there are no files"}
+	expectedFiles := []string{
+		"testdata/ardshin/valid.xlsx",
+		"testdata/ineco/valid_card.xlsx",
+		"testdata/ineco/valid_regular.xlsx",
+		"testdata/acba/valid_account.xls",
+		"testdata/acba/valid_card.xls",
+	}
+	sequentialWarnings := []string{}
+	sequentialTransactions, _, err := parseTransactionsOfSources(sources, config, nil, 1, &sequentialWarnings)
+	if err != nil {
+		t.Fatalf("sequential parsing failed: %v", err)
+	}
+
+	// Act
+	parallelWarnings := []string{}
+	parallelTransactions, fileInfos, err := parseTransactionsOfSources(sources, config, nil, 8, &parallelWarnings)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("parallel parsing failed: %v", err)
+	}
+	if diff := cmp.Diff(sequentialTransactions, parallelTransactions, cmp.AllowUnexported(Money{})); diff != "" {
+		t.Errorf("transactions depend on number of workers (-sequential +parallel):\n%s", diff)
+	}
+	actualFiles := make([]string, 0, len(fileInfos))
+	for _, fileInfo := range fileInfos {
+		actualFiles = append(actualFiles, fileInfo.Path)
+	}
+	if diff := cmp.Diff(expectedFiles, actualFiles); diff != "" {
+		t.Errorf("wrong order of files (-expected +actual):\n%s", diff)
+	}
+	if len(parallelWarnings) != len(expectedWarnings) || !strings.Contains(parallelWarnings[0], expectedWarnings[0]) {
+		t.Errorf("expected warnings %v, got %v", expectedWarnings, parallelWarnings)
+	}
+}
+
+func TestParseTransactionsOfSources_FatalError(t *testing.T) {
+	// Arrange
+	sources := []SourceConfig{
+		{Parser: ArdshinXlsxParserName, Glob: "testdata/ardshin/valid.xlsx"},
+		{Parser: ArdshinXlsxParserName, Glob: "testdata/ardshin/no_header_row.xlsx"},
+	}
+	parsingWarnings := []string{}
+
+	// Act
+	_, _, err := parseTransactionsOfSources(sources, &Config{}, nil, 4, &parsingWarnings)
+
+	// Assert
+	checkErrorContainsSubstring(t, err, "no_header_row.xlsx")
+}
+
+func TestParseTransactionFile_SeveralAccounts(t *testing.T) {
+	// Arrange
+	filePath := "testdata/ofx/accounts_xml.qfx"
+
+	// Act
+	_, _, fileInfos, err := parseTransactionFile(filePath, OfxFileParser{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual := make([]string, 0, len(fileInfos))
+	for _, fileInfo := range fileInfos {
+		actual = append(actual, fmt.Sprintf("%s %s %d %s..%s", fileInfo.Path, fileInfo.AccountNumber,
+			fileInfo.TransactionsCount, fileInfo.FromDate.Format(OutputDateFormat), fileInfo.ToDate.Format(OutputDateFormat)))
+	}
+	expected := []string{
+		filePath + " 8310012345 2 2024-03-02..2024-03-15",
+		filePath + " 4111111111111111 1 2024-03-20..2024-03-20",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("file infos mismatch (-expected +actual):\n%s", diff)
+	}
+	coverage := BuildCoverage(fileInfos)
+	if len(coverage) != 2 || coverage[0].Account != "4111111111111111" || coverage[1].Account != "8310012345" {
+		t.Errorf("expected coverage of both accounts, got %+v", coverage)
+	}
+}
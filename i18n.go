@@ -59,7 +59,7 @@ func parseCommaSeparatedWithQuotes(s string) []string {
 // - Default fallback for any `T` issue is `[fall reason] key, %s` where `%s` is a comma-separated list of "%+v" of arguments.
 // Supported built-in formatting functions:
 // - number (signDisplay, maximumSignificantDigits, minimumSignificantDigits, maximumFractionDigits, minimumFractionDigits, minimumIntegerDigits),
-// - currency (from `MoneyWith2DecimalPlaces`),
+// - currency (from `Money`),
 // - date (Golang `time.Format`, default is `I18N_DATE_FORMAT`),
 // - list (not https://tc39.es/ecma402/#listformat-objects, only 'separator' property is supported, ', ' by-default).
 // - indent (rightIndent, leftIndent),
@@ -182,19 +182,19 @@ func (i18n *I18n) buildDefaultFormatters() map[string]func(val interface{}, prop
 	// Number format - subset of https://developer.mozilla.org/en-US/docs/Web/JavaScript/Reference/Global_Objects/Intl/NumberFormat/NumberFormat
 	result["number"] = i18n.number
 
-	// Currency format - based on MoneyWith2DecimalPlaces.
+	// Currency format - based on Money.
 	result["currency"] = func(val interface{}, props map[string]interface{}) string {
-		var amount MoneyWith2DecimalPlaces
+		var amount Money
 		switch v := val.(type) {
-		case MoneyWith2DecimalPlaces:
+		case Money:
 			amount = v
 		case float64:
-			amount = MoneyWith2DecimalPlaces{int(v * 100)}
+			amount = moneyFromFloat(v)
 		default:
 			return fmt.Sprintf("%+v", val)
 		}
 		if currency, ok := props["currency"]; ok {
-			return fmt.Sprintf("%s %s", amount.StringNoIndent(), currency)
+			return fmt.Sprintf("%s %s", amount.StringInCurrency(fmt.Sprint(currency)), currency)
 		}
 		return amount.StringNoIndent()
 	}
@@ -446,12 +446,12 @@ func (i18n *I18n) Tfallback(reason, key string, args ...interface{}) string {
 
 // number formats a number based on the specified options.
 func (i18n *I18n) number(value interface{}, props map[string]interface{}) string {
-	var val MoneyWith2DecimalPlaces
+	var val Money
 	switch v := value.(type) {
-	case MoneyWith2DecimalPlaces:
+	case Money:
 		val = v
 	case float64:
-		val = MoneyWith2DecimalPlaces{int(v * 100)}
+		val = moneyFromFloat(v)
 	default:
 		return fmt.Sprintf("%+v", value)
 	}
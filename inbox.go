@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+// InboxFileStatus is a result of processing one file from inbox.
+type InboxFileStatus string
+
+const (
+	InboxFileParsed    InboxFileStatus = "parsed"
+	InboxFileUnknown   InboxFileStatus = "unknown"
+	InboxFileAmbiguous InboxFileStatus = "ambiguous"
+	InboxFileFailed    InboxFileStatus = "failed"
+	// InboxFileSkipped is for files which are already parsed as part of `sources`.
+	InboxFileSkipped InboxFileStatus = "skipped"
+)
+
+// InboxFileReport describes how file from `inboxGlob` was processed.
+type InboxFileReport struct {
+	Path   string          `json:"path"`
+	Status InboxFileStatus `json:"status"`
+	// Parser is a name of detected parser if it is only one.
+	Parser string `json:"parser"`
+	// Candidates are names of all parsers which recognized file.
+	Candidates []string `json:"candidates"`
+	// Message is a localized explanation of the status.
+	Message string `json:"message"`
+	// Warning is a localized problem with parsed file, like not parsed part of transactions.
+	Warning string `json:"warning,omitempty"`
+}
+
+// LocalizedStatus returns status in the current language.
+func (r InboxFileReport) LocalizedStatus() string {
+	return i18n.T("inbox file status " + string(r.Status))
+}
+
+// parseInboxFiles detects format of each file matching `inboxGlob` and parses it with the detected parser.
+// Files are processed concurrently with `workers` goroutines. Files from alreadyParsedFiles are skipped.
+// Unknown, ambiguous or broken files don't stop parsing, they are reported in result and in parsingWarnings.
+// Returns transactions, file infos, per-file reports and error if it is fatal.
+func parseInboxFiles(
+	config *Config,
+	cache *TransactionsCache,
+	workers int,
+	alreadyParsedFiles []FileInfo,
+	parsingWarnings *[]string,
+) ([]Transaction, []FileInfo, []InboxFileReport, error) {
+	files, err := getFilesByGlob(config.InboxGlob)
+	if err != nil {
+		return nil, nil, nil, errors.New(i18n.T("can't find files by inboxGlob", "glob", config.InboxGlob, "err", err))
+	}
+	parsedPaths := make(map[string]struct{}, len(alreadyParsedFiles))
+	for _, fileInfo := range alreadyParsedFiles {
+		parsedPaths[absolutePath(fileInfo.Path)] = struct{}{}
+	}
+
+	// Detect formats and parse files concurrently, save results by index to keep order of files.
+	reports := make([]InboxFileReport, len(files))
+	filesTransactions := make([][]Transaction, len(files))
+	filesInfos := make([][]FileInfo, len(files))
+	forEachInParallel(len(files), workers, func(i int) {
+		reports[i] = InboxFileReport{Path: files[i]}
+		if _, ok := parsedPaths[absolutePath(files[i])]; ok {
+			reports[i].Status = InboxFileSkipped
+			reports[i].Message = i18n.T("File is already parsed as part of sources")
+			return
+		}
+		filesTransactions[i], filesInfos[i] = parseInboxFile(files[i], config, cache, &reports[i])
+	})
+
+	transactions := make([]Transaction, 0)
+	fileInfos := make([]FileInfo, 0)
+	for i, report := range reports {
+		switch report.Status {
+		case InboxFileParsed:
+			transactions = append(transactions, filesTransactions[i]...)
+			fileInfos = append(fileInfos, filesInfos[i]...)
+			if report.Warning != "" {
+				log.Println(report.Warning)
+				*parsingWarnings = append(*parsingWarnings, report.Warning)
+			}
+		case InboxFileSkipped:
+		default:
+			log.Println(report.Message)
+			*parsingWarnings = append(*parsingWarnings, report.Message)
+		}
+	}
+	return transactions, fileInfos, reports, nil
+}
+
+// parseInboxFile detects format of the file and parses it. Fills report with results.
+func parseInboxFile(file string, config *Config, cache *TransactionsCache, report *InboxFileReport) ([]Transaction, []FileInfo) {
+	sniff, err := sniffFile(file)
+	if err != nil {
+		report.Status = InboxFileFailed
+		report.Message = i18n.T("Can't read f file to detect format", "f", file, "err", err)
+		return nil, nil
+	}
+	candidates := detectParsers(sniff)
+	for _, candidate := range candidates {
+		report.Candidates = append(report.Candidates, candidate.Name)
+	}
+	switch len(candidates) {
+	case 0:
+		report.Status = InboxFileUnknown
+		report.Message = i18n.T("Format of f file is unknown", "f", file)
+		return nil, nil
+	case 1:
+		report.Parser = candidates[0].Name
+	default:
+		report.Status = InboxFileAmbiguous
+		report.Message = i18n.T("Format of f file is ambiguous", "f", file, "parsers", strings.Join(report.Candidates, ", "))
+		return nil, nil
+	}
+
+	registration := candidates[0]
+	parser, err := registration.NewParser(nil, config)
+	if err == nil {
+		var transactions []Transaction
+		var notFatalError string
+		var fileInfos []FileInfo
+		transactions, notFatalError, fileInfos, err = parseTransactionFile(file, cache.wrapParser(parser, registration))
+		if err == nil && len(transactions) > 0 {
+			setDefaultTag(transactions, registration)
+			report.Status = InboxFileParsed
+			report.Message = i18n.T("Parsed as t", "t", registration.TypeName)
+			if notFatalError != "" {
+				report.Warning = i18n.T("Can't parse all n files", "n", registration.TypeName, "warning", notFatalError)
+			}
+			return transactions, fileInfos
+		}
+	}
+	report.Status = InboxFileFailed
+	if err != nil {
+		report.Message = i18n.T("Can't parse f file detected as t", "f", file, "t", registration.TypeName, "err", err)
+	} else {
+		report.Message = i18n.T("Can't find transactions in f file", "f", file)
+	}
+	return nil, nil
+}
+
+// absolutePath returns absolute path or the path itself if it can't be resolved.
+func absolutePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
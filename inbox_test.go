@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func copyTestFile(t *testing.T, from, to string) {
+	content, err := os.ReadFile(from)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", from, err)
+	}
+	if err := os.WriteFile(to, content, 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", to, err)
+	}
+}
+
+func TestParseInboxFiles(t *testing.T) {
+	// Arrange
+	inboxDir := t.TempDir()
+	ardshinFile := filepath.Join(inboxDir, "a_ardshin.xlsx")
+	copyTestFile(t, "testdata/ardshin/valid.xlsx", ardshinFile)
+	acbaFile := filepath.Join(inboxDir, "b_acba.xls")
+	copyTestFile(t, "testdata/acba/valid_account.xls", acbaFile)
+	brokenFile := filepath.Join(inboxDir, "c_broken.xlsx")
+	copyTestFile(t, "testdata/ardshin/no_account_currency.xlsx", brokenFile)
+	unknownFile := filepath.Join(inboxDir, "d_unknown.csv")
+	if err := os.WriteFile(unknownFile, []byte("Some,Other,Headers\n1,2,3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	alreadyParsedFile := filepath.Join(inboxDir, "e_parsed.xls")
+	copyTestFile(t, "testdata/acba/valid_card.xls", alreadyParsedFile)
+	config := &Config{InboxGlob: filepath.Join(inboxDir, "*")}
+	parsingWarnings := []string{}
+
+	// Act
+	transactions, fileInfos, reports, err := parseInboxFiles(
+		config,
+		nil,
+		2,
+		[]FileInfo{{Path: alreadyParsedFile}},
+		&parsingWarnings,
+	)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedStatuses := []struct {
+		path   string
+		status InboxFileStatus
+		parser string
+	}{
+		{ardshinFile, InboxFileParsed, ArdshinXlsxParserName},
+		{acbaFile, InboxFileParsed, AcbaRegularAccountXlsParserName},
+		{brokenFile, InboxFileFailed, ArdshinXlsxParserName},
+		{unknownFile, InboxFileUnknown, ""},
+		{alreadyParsedFile, InboxFileSkipped, ""},
+	}
+	if len(reports) != len(expectedStatuses) {
+		t.Fatalf("Expected %d reports, got %d: %+v", len(expectedStatuses), len(reports), reports)
+	}
+	for i, expected := range expectedStatuses {
+		report := reports[i]
+		if report.Path != expected.path || report.Status != expected.status || report.Parser != expected.parser {
+			t.Errorf("Expected report %d to be %+v, got %+v", i, expected, report)
+		}
+		if report.Message == "" {
+			t.Errorf("Expected report %d to have message", i)
+		}
+	}
+	if len(fileInfos) != 2 {
+		t.Errorf("Expected 2 file infos, got %d", len(fileInfos))
+	}
+	if len(transactions) == 0 {
+		t.Error("Expected transactions from parsed files")
+	}
+	for _, transaction := range transactions {
+		if transaction.Source.Tag == "" {
+			t.Errorf("Expected tag to be set for %+v", transaction.Source)
+		}
+	}
+	if len(parsingWarnings) != 2 {
+		t.Errorf("Expected 2 warnings for broken and unknown files, got %v", parsingWarnings)
+	}
+}
+
+func TestParseInboxFiles_Ambiguous(t *testing.T) {
+	// Arrange
+	inboxDir := t.TempDir()
+	file := filepath.Join(inboxDir, "statement.xlsx")
+	copyTestFile(t, "testdata/ardshin/valid.xlsx", file)
+	parserRegistry["testArdshinClone"] = &ParserRegistration{
+		Name:      "testArdshinClone",
+		NewParser: newParserWithoutOptions(ArdshinXlsxFileParser{}),
+		Detect:    parserRegistry[ArdshinXlsxParserName].Detect,
+	}
+	defer delete(parserRegistry, "testArdshinClone")
+	parsingWarnings := []string{}
+
+	// Act
+	transactions, _, reports, err := parseInboxFiles(&Config{InboxGlob: file}, nil, 1, nil, &parsingWarnings)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(transactions) != 0 {
+		t.Errorf("Expected no transactions from ambiguous file, got %d", len(transactions))
+	}
+	if len(reports) != 1 || reports[0].Status != InboxFileAmbiguous {
+		t.Fatalf("Expected one ambiguous report, got %+v", reports)
+	}
+	if len(reports[0].Candidates) != 2 {
+		t.Errorf("Expected 2 candidates, got %v", reports[0].Candidates)
+	}
+	if !strings.Contains(reports[0].Message, "ardshinXlsx, testArdshinClone") {
+		t.Errorf("Expected message to list candidates, got '%s'", reports[0].Message)
+	}
+}
+
+func TestParseInboxFiles_PartiallyParsed(t *testing.T) {
+	// Arrange
+	inboxDir := t.TempDir()
+	file := filepath.Join(inboxDir, "statement.csv")
+	if err := os.WriteFile(file, []byte("Partial,Statement\n1,2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	parser := countingFileParser{
+		calls:        &calls,
+		transactions: newCacheTestTransactions(file),
+		err:          errors.New("row 3 is broken"),
+	}
+	parserRegistry["testPartial"] = &ParserRegistration{
+		Name:      "testPartial",
+		TypeName:  "Partial statement",
+		NewParser: newParserWithoutOptions(parser),
+		Detect: func(sniff *FileSniff) bool {
+			return sniff.HasRowStartingWith("Partial", "Statement")
+		},
+	}
+	defer delete(parserRegistry, "testPartial")
+	parsingWarnings := []string{}
+
+	// Act
+	transactions, _, reports, err := parseInboxFiles(&Config{InboxGlob: file}, nil, 1, nil, &parsingWarnings)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(transactions) != 2 {
+		t.Errorf("Expected 2 transactions from partially parsed file, got %d", len(transactions))
+	}
+	if len(reports) != 1 || reports[0].Status != InboxFileParsed {
+		t.Fatalf("Expected one parsed report, got %+v", reports)
+	}
+	if !strings.Contains(reports[0].Warning, "row 3 is broken") {
+		t.Errorf("Expected warning with parsing error in report, got '%s'", reports[0].Warning)
+	}
+	if len(parsingWarnings) != 1 || parsingWarnings[0] != reports[0].Warning {
+		t.Errorf("Expected warning of the report in parsing warnings, got %v", parsingWarnings)
+	}
+}
@@ -30,16 +30,36 @@ var (
 
 type InecoXlsxTransaction struct {
 	Date               time.Time
-	AmountOrigCur      MoneyWith2DecimalPlaces
+	AmountOrigCur      Money
 	Currency           string
-	NotNormalizedEntry MoneyWith2DecimalPlaces
-	Income             MoneyWith2DecimalPlaces
-	Expense            MoneyWith2DecimalPlaces
-	ExchangeRate       MoneyWith2DecimalPlaces
+	NotNormalizedEntry Money
+	Income             Money
+	Expense            Money
+	ExchangeRate       Money
 	DateWhenApplied    time.Time
 	Details            string
 }
 
+const (
+	// InecoXlsxParserName is a name of parser to use in `sources` configuration.
+	InecoXlsxParserName = "inecoXlsx"
+	inecoXlsxTypeName   = "Inecobank XLSX statement"
+)
+
+func init() {
+	RegisterParser(ParserRegistration{
+		Name:        InecoXlsxParserName,
+		TypeName:    inecoXlsxTypeName,
+		Tag:         "InecoExcel",
+		Version:     2,
+		DefaultGlob: "statement*.xlsx",
+		NewParser:   newParserWithoutOptions(InecoExcelFileParser{}),
+		Detect: func(sniff *FileSniff) bool {
+			return sniff.Container == FileContainerXlsx && sniff.HasRowWithPrefix(inecoXlsxHeadersBeforeTransactions)
+		},
+	})
+}
+
 type InecoExcelFileParser struct {
 }
 
@@ -158,7 +178,7 @@ func (p InecoExcelFileParser) ParseRawTransactionsFromFile(
 		}
 
 		// Parse date which is always 1st. Note that it has extra quotes.
-		date, err := time.Parse(InecoDateFormat, firstCell)
+		date, err := parseDate(firstCell, InecoDateFormat)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse date from 1st cell of %d row: %w", i, err)
 		}
@@ -193,7 +213,7 @@ func (p InecoExcelFileParser) ParseRawTransactionsFromFile(
 				Details:         cells[17].String(),
 			}
 		} else {
-			dateApplied, err := time.Parse(InecoDateFormat, cells[10].String())
+			dateApplied, err := parseDate(cells[10].String(), InecoDateFormat)
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse 'date when applied' from 6th cell of %d row: %w", i, err)
 			}
@@ -229,7 +249,7 @@ func (p InecoExcelFileParser) ParseRawTransactionsFromFile(
 	}
 
 	source := TransactionsSource{
-		TypeName:        "Inecobank XLSX statement",
+		TypeName:        inecoXlsxTypeName,
 		Tag:             tag,
 		FilePath:        filePath,
 		AccountNumber:   accountNumber,
@@ -254,8 +274,8 @@ func (p InecoExcelFileParser) ParseRawTransactionsFromFile(
 			IsExpense:            isExpense,
 			Date:                 t.Date,
 			Details:              t.Details,
-			Amount:               MoneyWith2DecimalPlaces{accountAmount},
-			OriginCurrencyAmount: MoneyWith2DecimalPlaces{t.AmountOrigCur.int},
+			Amount:               Money{accountAmount},
+			OriginCurrencyAmount: Money{t.AmountOrigCur.int},
 			Source:               &source,
 			AccountCurrency:      accountCurrency,
 			FromAccount:          from,
@@ -278,8 +298,8 @@ func mergeCellsToString(cells []*xlsx.Cell) string {
 	return builder.String()
 }
 
-func parseAmount(rowIndex int, cells []*xlsx.Cell, cellIndex int, name string) (MoneyWith2DecimalPlaces, error) {
-	var result MoneyWith2DecimalPlaces
+func parseAmount(rowIndex int, cells []*xlsx.Cell, cellIndex int, name string) (Money, error) {
+	var result Money
 	if err := result.UnmarshalFromExcelCell(cells[cellIndex]); err != nil {
 		return result, fmt.Errorf(
 			"failed to parse amount as '%s' from %d cell of %d row: %w",
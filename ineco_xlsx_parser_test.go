@@ -45,9 +45,9 @@ func TestInecoExcelFileParserParseRawTransactionsFromFile(t *testing.T) {
 					Details:              "Միջբանկային փոխանցում",
 					Source:               sourceRegular,
 					AccountCurrency:      "AMD",
-					Amount:               MoneyWith2DecimalPlaces{int: 400000},
+					Amount:               Money{int: 4000000},
 					OriginCurrency:       "",
-					OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 400000},
+					OriginCurrencyAmount: Money{int: 4000000},
 					FromAccount:          "2050205020502050",
 					ToAccount:            "UnknownAccount",
 				},
@@ -57,9 +57,9 @@ func TestInecoExcelFileParserParseRawTransactionsFromFile(t *testing.T) {
 					Details:              "Փոխանցում իմ հաշիվների միջև, Account replenishment, InecoOnline, 07/06/2023 11:38:58",
 					Source:               sourceRegular,
 					AccountCurrency:      "AMD",
-					Amount:               MoneyWith2DecimalPlaces{int: 7800010},
+					Amount:               Money{int: 78000100},
 					OriginCurrency:       "",
-					OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 7800010},
+					OriginCurrencyAmount: Money{int: 78000100},
 					FromAccount:          "UnknownAccount",
 					ToAccount:            "2050205020502050",
 				},
@@ -77,9 +77,9 @@ func TestInecoExcelFileParserParseRawTransactionsFromFile(t *testing.T) {
 					Details:              "Անկանխիկ գործարք - WILDBERRIES - YEREVAN",
 					Source:               sourceCard,
 					AccountCurrency:      "AMD",
-					Amount:               MoneyWith2DecimalPlaces{int: 35000},
+					Amount:               Money{int: 350000},
 					OriginCurrency:       "",
-					OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 35000},
+					OriginCurrencyAmount: Money{int: 350000},
 					FromAccount:          "1234567890121234",
 					ToAccount:            "UnknownAccount",
 				},
@@ -89,9 +89,9 @@ func TestInecoExcelFileParserParseRawTransactionsFromFile(t *testing.T) {
 					Details:              "Անկանխիկ գործարք – CLOUD",
 					Source:               sourceCard,
 					AccountCurrency:      "AMD",
-					Amount:               MoneyWith2DecimalPlaces{int: 784},
+					Amount:               Money{int: 7840},
 					OriginCurrency:       "USD",
-					OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 2},
+					OriginCurrencyAmount: Money{int: 20},
 					FromAccount:          "1234567890121234",
 					ToAccount:            "UnknownAccount",
 				},
@@ -101,9 +101,9 @@ func TestInecoExcelFileParserParseRawTransactionsFromFile(t *testing.T) {
 					Details:              "Գումարի ետ վերադարձ քարտապանին",
 					Source:               sourceCard,
 					AccountCurrency:      "AMD",
-					Amount:               MoneyWith2DecimalPlaces{int: 99999999999},
+					Amount:               Money{int: 999999999990},
 					OriginCurrency:       "",
-					OriginCurrencyAmount: MoneyWith2DecimalPlaces{int: 99999999999},
+					OriginCurrencyAmount: Money{int: 999999999990},
 					FromAccount:          "UnknownAccount",
 					ToAccount:            "1234567890121234",
 				},
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
@@ -19,15 +18,15 @@ type XmlDate struct {
 }
 
 type InecoTransaction struct {
-	NN                   string                  `xml:"n-n"`
-	Number               string                  `xml:"Number"`
-	Date                 XmlDate                 `xml:"Date"`
-	Currency             string                  `xml:"Currency"`
-	Income               MoneyWith2DecimalPlaces `xml:"Income"`
-	Expense              MoneyWith2DecimalPlaces `xml:"Expense"`
-	ReceiverPayerAccount string                  `xml:"Receiver-PayerAccount"`
-	ReceiverPayer        string                  `xml:"Receiver-Payer"`
-	Details              string                  `xml:"Details"`
+	NN                   string  `xml:"n-n"`
+	Number               string  `xml:"Number"`
+	Date                 XmlDate `xml:"Date"`
+	Currency             string  `xml:"Currency"`
+	Income               Money   `xml:"Income"`
+	Expense              Money   `xml:"Expense"`
+	ReceiverPayerAccount string  `xml:"Receiver-PayerAccount"`
+	ReceiverPayer        string  `xml:"Receiver-Payer"`
+	Details              string  `xml:"Details"`
 }
 
 type Operations struct {
@@ -44,23 +43,17 @@ type Statement struct {
 	Operations     Operations `xml:"Operations" validate:"required"`
 }
 
-func (m *MoneyWith2DecimalPlaces) UnmarshalFromXml(d *xml.Decoder, start xml.StartElement) error {
+func (m *Money) UnmarshalFromXml(d *xml.Decoder, start xml.StartElement) error {
 	var v string
 	d.DecodeElement(&v, &start)
-	v = strings.Replace(v, ",", "", -1)
-	floatVal, err := strconv.ParseFloat(v, 64)
-	if err != nil {
-		return err
-	}
-	m.int = int(floatVal * 100)
-	return nil
+	return m.ParseString(v)
 }
 
 func (xd *XmlDate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	var v string
 	d.DecodeElement(&v, &start)
 
-	parse, err := time.Parse(InecoDateFormat, v)
+	parse, err := parseDate(v, InecoDateFormat)
 	if err != nil {
 		return err
 	}
@@ -69,6 +62,26 @@ func (xd *XmlDate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	return nil
 }
 
+const (
+	// InecoXmlParserName is a name of parser to use in `sources` configuration.
+	InecoXmlParserName = "inecoXml"
+	inecoXmlTypeName   = "Inecobank XML statement"
+)
+
+func init() {
+	RegisterParser(ParserRegistration{
+		Name:        InecoXmlParserName,
+		TypeName:    inecoXmlTypeName,
+		Tag:         "InecoXml",
+		Version:     3,
+		DefaultGlob: "Statement*.xml",
+		NewParser:   newParserWithoutOptions(InecoXmlParser{}),
+		Detect: func(sniff *FileSniff) bool {
+			return sniff.Container == FileContainerXml && sniff.XmlRoot == "Statement"
+		},
+	})
+}
+
 type InecoXmlParser struct {
 }
 
@@ -107,13 +120,26 @@ func (InecoXmlParser) ParseRawTransactionsFromFile(
 
 	// Create source.
 	source := TransactionsSource{
-		TypeName:        "Inecobank XML statement",
+		TypeName:        inecoXmlTypeName,
 		Tag:             fmt.Sprintf("InecoXml:%s", stmt.Currency),
 		FilePath:        filePath,
 		AccountNumber:   stmt.AccountNumber,
 		AccountCurrency: stmt.Currency,
 	}
 
+	// Add balances if period is known. Closing balance is at the end of the last day of the period.
+	if from, to, ok := parseInecoXmlPeriod(stmt.Period); ok {
+		var opening, closing Money
+		if err := opening.ParseString(stmt.OpeningBalance); err != nil {
+			return nil, fmt.Errorf("failed to parse opening balance '%s': %w", stmt.OpeningBalance, err)
+		}
+		if err := closing.ParseString(stmt.ClosingBalance); err != nil {
+			return nil, fmt.Errorf("failed to parse closing balance '%s': %w", stmt.ClosingBalance, err)
+		}
+		source.OpeningBalance = &BalanceRecord{Date: from, Amount: opening}
+		source.ClosingBalance = &BalanceRecord{Date: to.AddDate(0, 0, 1), Amount: closing}
+	}
+
 	// Conver Inecobank rows to unified transactions.
 	transactions := make([]Transaction, 0, len(stmt.Operations.Transactions))
 	for _, t := range stmt.Operations.Transactions {
@@ -134,7 +160,7 @@ func (InecoXmlParser) ParseRawTransactionsFromFile(
 			Date:      t.Date.Time,
 			Details:   t.Details,
 			// Ineco XML shows amounts only in account currency.
-			Amount:          MoneyWith2DecimalPlaces{amount},
+			Amount:          Money{amount},
 			Source:          &source,
 			AccountCurrency: t.Currency,
 			FromAccount:     from,
@@ -144,4 +170,21 @@ func (InecoXmlParser) ParseRawTransactionsFromFile(
 	return transactions, nil
 }
 
+// parseInecoXmlPeriod parses period like "[10/07/2024 - 02/07/2025]" into the first and the last days.
+func parseInecoXmlPeriod(period string) (time.Time, time.Time, bool) {
+	parts := strings.Split(strings.Trim(strings.TrimSpace(period), "[]"), " - ")
+	if len(parts) != 2 {
+		return time.Time{}, time.Time{}, false
+	}
+	from, err := parseDate(parts[0], InecoDateFormat)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := parseDate(parts[1], InecoDateFormat)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
 var _ FileParser = InecoXmlParser{}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInecoXmlParser_ParseRawTransactionsFromFile(t *testing.T) {
+	// Arrange
+	filePath := filepath.Join("testdata", "ineco", "valid.xml")
+	source := &TransactionsSource{
+		TypeName:        "Inecobank XML statement",
+		Tag:             "InecoXml:AMD",
+		FilePath:        filePath,
+		AccountNumber:   "2050205020502050",
+		AccountCurrency: "AMD",
+		OpeningBalance:  &BalanceRecord{Date: time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC), Amount: Money{int: 1000000}},
+		ClosingBalance:  &BalanceRecord{Date: time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC), Amount: Money{int: 150500500}},
+	}
+	expected := []Transaction{
+		{
+			IsExpense:       false,
+			Date:            time.Date(2024, time.July, 5, 0, 0, 0, 0, time.UTC),
+			Details:         "Salary",
+			Amount:          Money{int: 200000000},
+			Source:          source,
+			AccountCurrency: "AMD",
+			FromAccount:     "1234567890123456",
+			ToAccount:       "2050205020502050",
+		},
+		{
+			IsExpense:       true,
+			Date:            time.Date(2024, time.July, 20, 0, 0, 0, 0, time.UTC),
+			Details:         "SAS SUPERMARKET",
+			Amount:          Money{int: 50499500},
+			Source:          source,
+			AccountCurrency: "AMD",
+			FromAccount:     "2050205020502050",
+			ToAccount:       "6543210987654321",
+		},
+	}
+
+	// Act
+	actual, err := InecoXmlParser{}.ParseRawTransactionsFromFile(filePath)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, actual, cmp.AllowUnexported(Money{})); diff != "" {
+		t.Errorf("transactions mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestParseInecoXmlPeriod(t *testing.T) {
+	tests := []struct {
+		period       string
+		expectedFrom time.Time
+		expectedTo   time.Time
+		expectedOk   bool
+	}{
+		{"[10/07/2024 - 02/07/2025]", time.Date(2024, time.July, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, time.July, 2, 0, 0, 0, 0, time.UTC), true},
+		{" 10/07/2024 - 02/07/2025 ", time.Date(2024, time.July, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, time.July, 2, 0, 0, 0, 0, time.UTC), true},
+		{"[10/07/2024]", time.Time{}, time.Time{}, false},
+		{"[2024-07-10 - 2025-07-02]", time.Time{}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+
+			// Act
+			from, to, ok := parseInecoXmlPeriod(tt.period)
+
+			// Assert
+			if ok != tt.expectedOk || !from.Equal(tt.expectedFrom) || !to.Equal(tt.expectedTo) {
+				t.Errorf("expected %v..%v (%v), got %v..%v (%v)", tt.expectedFrom, tt.expectedTo, tt.expectedOk, from, to, ok)
+			}
+		})
+	}
+}
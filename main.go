@@ -5,8 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"os"
+	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alexflint/go-arg"
@@ -39,6 +43,7 @@ type Args struct {
 	ResultMode           string `arg:"-o" default:"web" help:"Specify how to open the result: 'none' for print into STDOUT only, 'web' for web server to see in browser, 'file' for opening result file in OS." enum:"none,web,file"`
 	DontBuildBeanconFile bool   `arg:"--no-beancount" help:"Flag to don't build Beancount file."`
 	DontBuildTextReport  bool   `arg:"--no-txt-report" help:"Flag to don't build TXT file report."`
+	Period               string `arg:"--period" help:"Reporting period: 'month', 'week', 'quarter', 'year', 'custom' (ranges from configuration) or 'salary' (started by incomes of 'period.salaryGroup'). By default is used 'period.type' from configuration or 'month'."`
 }
 
 // Version is application version string and should be updated with `go build -ldflags`.
@@ -109,6 +114,12 @@ func runApplication(args Args) error {
 		return fmt.Errorf("invalid ResultMode '%s', supported only: %s, %s, %s", args.ResultMode, OPEN_MODE_NONE, OPEN_MODE_WEB, OPEN_MODE_FILE)
 	}
 
+	// Validate Period.
+	periodType, err := ParsePeriodType(args.Period)
+	if err != nil {
+		return err
+	}
+
 	// Prepare flags for writing to file and opening file with result.
 	isWriteToFile := !args.DontBuildTextReport
 	isOpenFileWithResult := args.ResultMode == OPEN_MODE_FILE
@@ -147,12 +158,9 @@ func runApplication(args Args) error {
 	log.Println(i18n.T("Using configuration", "config", config))
 
 	// Create data handler and parse files.
-	dataHandler := &DataHandler{
-		ConfigPath: args.ConfigPath,
-		Config:     config,
-		TimeZone:   timeZone,
-	}
-	transactions, fileInfos, parsingWarnings, categorization, err := dataHandler.parseAllFiles()
+	dataHandler := NewDataHandler(args.ConfigPath, nil)
+	dataHandler.PeriodType = periodType
+	transactions, fileInfos, inboxReports, parsingWarnings, categorization, err := dataHandler.parseAllFiles(config)
 	if err != nil {
 		return handleError(err, isWriteToFile, isOpenFileWithResult)
 	}
@@ -177,10 +185,15 @@ func runApplication(args Args) error {
 	}
 
 	// Complete the DataHandler setup.
-	dataHandler.DataMart = dataMart
-	dataHandler.StatisticBuilderFactory = statisticBuilderFactory
-	dataHandler.Categorization = categorization
-	dataHandler.FileInfos = fileInfos
+	dataHandler.setSnapshot(&DataSnapshot{
+		Config:                  config,
+		TimeZone:                timeZone,
+		DataMart:                dataMart,
+		StatisticBuilderFactory: statisticBuilderFactory,
+		Categorization:          categorization,
+		FileInfos:               fileInfos,
+		InboxReports:            inboxReports,
+	})
 
 	// Build journal entries.
 	journalEntries, err := dataHandler.GetJournalEntries()
@@ -205,7 +218,7 @@ func runApplication(args Args) error {
 			log.Println(i18n.T("can't build Beancount report, transactions from following sources don't have Reciever/Payer account number: sources", "sources", strings.Join(sourceNames, ", ")))
 		} else {
 			// Build Beancount file.
-			transLen, err := buildBeancountFile(journalEntries, dataMart.AllCurrencies, dataMart.Accounts, RESULT_BEANCOUNT_FILE_PATH)
+			transLen, err := buildBeancountFile(journalEntries, dataMart.AllCurrencies, dataMart.Accounts, dataHandler.GetBalances().Accounts, RESULT_BEANCOUNT_FILE_PATH)
 			if err != nil {
 				return handleError(errors.New(i18n.T("can't build Beancount report", "err", err)), isWriteToFile, isOpenFileWithResult)
 			}
@@ -247,7 +260,14 @@ func runApplication(args Args) error {
 				return handleError(errors.New(i18n.T("can't dump interval statistics", "err", err)), isWriteToFile, isOpenFileWithResult)
 			}
 		}
-		fmt.Fprintf(&reportStringBuilder, "\n%s", i18n.T("Total n months", "n", len(monthlyStatistics)))
+		reportStringBuilder.WriteString(recurringPaymentsToString(dataHandler.GetRecurringPayments()))
+		reportStringBuilder.WriteString(balancesToString(dataHandler.GetBalances()))
+		reportStringBuilder.WriteString(coverageToString(BuildCoverage(dataHandler.GetSnapshot().FileInfos)))
+		if periodType == PeriodMonth || (periodType == "" && config.GetPeriodType() == PeriodMonth) {
+			fmt.Fprintf(&reportStringBuilder, "\n%s", i18n.T("Total n months", "n", len(monthlyStatistics)))
+		} else {
+			fmt.Fprintf(&reportStringBuilder, "\n%s", i18n.T("Total n periods", "n", len(monthlyStatistics)))
+		}
 		result := reportStringBuilder.String()
 
 		// Always print result into logs and conditionally into the file which open through the OS.
@@ -259,7 +279,7 @@ func runApplication(args Args) error {
 
 	// Start web server if needed.
 	if args.ResultMode == OPEN_MODE_WEB {
-		url := fmt.Sprintf("http://localhost:%d", dataHandler.Config.UIPort)
+		url := fmt.Sprintf("http://localhost:%d", config.UIPort)
 		go func() {
 			time.Sleep(100 * time.Millisecond) // Give the server a moment to start.
 			err := openInOS(url)
@@ -291,7 +311,8 @@ func handleError(err error, inFile bool, openFile bool) error {
 	return errors.New(errMsg)
 }
 
-// FileInfo represents information about a parsed transaction file.
+// FileInfo represents information about transactions of one source (like account) in a parsed transaction file.
+// File with statements of several accounts has several infos.
 type FileInfo struct {
 	Path              string              `json:"path"`
 	Source            *TransactionsSource `json:"source"`
@@ -302,11 +323,10 @@ type FileInfo struct {
 	ToDate            time.Time           `json:"toDate"`
 }
 
-// DataHandler is a handler for data.
-// Contians methods to recalculate, cache, persist data.
-type DataHandler struct {
-	// ConfigPath is a path to the configuration file.
-	ConfigPath string
+// DataSnapshot is a consistent set of data built from the same configuration and files.
+// Exported fields are not changed after creation, changes create a new snapshot.
+// Journal entries and statistics are built lazily on first request.
+type DataSnapshot struct {
 	// Config is a configuration.
 	Config *Config
 	// TimeZone is a time zone.
@@ -315,267 +335,407 @@ type DataHandler struct {
 	DataMart *DataMart
 	// StatisticBuilderFactory is a factory to create statistic builders by categories.
 	StatisticBuilderFactory StatisticBuilderFactory
-	// Categorization is a cached struct to categorize transactions.
+	// Categorization is a struct to categorize transactions, built from Config if nil.
 	Categorization *Categorization
+	// FileInfos is a list of file information.
+	FileInfos []FileInfo
+	// InboxReports is a list of results of format detection for files from `inboxGlob`.
+	InboxReports []InboxFileReport
+	// mutex guards lazily built fields below.
+	mutex sync.Mutex
 	// journalEntries is a list of cached journal entries.
 	journalEntries []JournalEntry
 	// uncategorizedTransactions is a list of cached uncategorized transactions.
 	uncategorizedTransactions []Transaction
-	// monthlyStatistics is a list of cached monthly statistics.
-	monthlyStatistics []map[string]*IntervalStatistic
-	// FileInfos is a list of cached file information.
-	FileInfos []FileInfo
+	// periodStatistics are cached statistics per type of periods.
+	periodStatistics map[PeriodType][]map[string]*IntervalStatistic
+	// recurringPayments is a list of cached recurring payments.
+	recurringPayments []*RecurringPayment
+	// balances are cached balances of accounts and net worth.
+	balances *Balances
 }
 
-func NewDataHandler(configPath string, initialConfig *Config, timeZone *time.Location, dataMart *DataMart, groupExtractorFactory StatisticBuilderFactory, initialCategorization *Categorization, fileInfos []FileInfo) *DataHandler {
-	return &DataHandler{
-		ConfigPath:              configPath,
-		Config:                  initialConfig,
-		TimeZone:                timeZone,
-		DataMart:                dataMart,
-		StatisticBuilderFactory: groupExtractorFactory,
-		Categorization:          initialCategorization,
-		FileInfos:               fileInfos,
+// buildJournalEntries builds journal entries and uncategorized transactions if they are not built yet.
+// Should be called under the mutex.
+func (s *DataSnapshot) buildJournalEntries() error {
+	if s.journalEntries != nil {
+		return nil
 	}
-}
-
-func (dh *DataHandler) rebuildJournalEntriesAndUncategorizedTransactions() error {
-	var err error
-	if dh.Categorization == nil {
-		dh.Categorization, err = NewCategorization(dh.Config)
+	categorization := s.Categorization
+	if categorization == nil {
+		var err error
+		categorization, err = NewCategorization(s.Config)
 		if err != nil {
 			return err
 		}
 	}
-	dh.journalEntries, dh.uncategorizedTransactions, err = buildJournalEntries(dh.DataMart, dh.Categorization)
+	journalEntries, uncategorizedTransactions, err := buildJournalEntries(s.DataMart, categorization)
 	if err != nil {
 		return err
 	}
+	journalEntries, transferLegs := matchTransfers(journalEntries, s.Config.Transfers)
+	if len(transferLegs) > 0 {
+		log.Println(i18n.T("Matched n transfers between my accounts", "n", len(transferLegs)/2))
+	}
+	s.journalEntries = journalEntries
+	s.uncategorizedTransactions = removeTransferLegs(uncategorizedTransactions, transferLegs)
 	return nil
 }
 
-// GetJournalEntries returns journal entries.
-// If isReadFromCache is true and journalEntries are already built, returns them from cache.
-// Otherwise builds journal entries and returns them.
-// Note that it also builds accounts, currencies and uncategorized transactions.
-func (dh *DataHandler) GetJournalEntries() ([]JournalEntry, error) {
-	if dh.journalEntries == nil {
-		err := dh.rebuildJournalEntriesAndUncategorizedTransactions()
-		if err != nil {
-			return nil, err
-		}
+// GetJournalEntries returns journal entries. Builds them on first call.
+// Note that it also builds uncategorized transactions.
+func (s *DataSnapshot) GetJournalEntries() ([]JournalEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.buildJournalEntries(); err != nil {
+		return nil, err
 	}
-	return dh.journalEntries, nil
+	return s.journalEntries, nil
 }
 
-func (dh *DataHandler) GetUncategorizedTransactions() ([]Transaction, error) {
-	if dh.uncategorizedTransactions == nil {
-		err := dh.rebuildJournalEntriesAndUncategorizedTransactions()
-		if err != nil {
-			return nil, err
-		}
+// GetUncategorizedTransactions returns transactions which don't match any group. Builds them on first call.
+func (s *DataSnapshot) GetUncategorizedTransactions() ([]Transaction, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.buildJournalEntries(); err != nil {
+		return nil, err
 	}
-	return dh.uncategorizedTransactions, nil
+	return s.uncategorizedTransactions, nil
 }
 
-func (dh *DataHandler) rebuildMonthlyStatistics() error {
-	var err error
-	journalEntries, err := dh.GetJournalEntries()
-	if err != nil {
-		return err
+// GetMonthlyStatistics returns statistics per period of type from configuration and currency.
+// Builds them on first call.
+func (s *DataSnapshot) GetMonthlyStatistics() ([]map[string]*IntervalStatistic, error) {
+	return s.GetPeriodStatistics("")
+}
+
+// GetPeriodStatistics returns statistics per period of the type and currency. Builds them on first call.
+// Empty type means type from configuration.
+func (s *DataSnapshot) GetPeriodStatistics(periodType PeriodType) ([]map[string]*IntervalStatistic, error) {
+	if periodType == "" {
+		periodType = s.Config.GetPeriodType()
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if statistics, ok := s.periodStatistics[periodType]; ok {
+		return statistics, nil
+	}
+	if err := s.buildJournalEntries(); err != nil {
+		return nil, err
 	}
-	dh.monthlyStatistics, err = BuildMonthlyStatistics(
-		journalEntries,
-		dh.StatisticBuilderFactory,
-		dh.Config.MonthStartDayNumber,
-		dh.TimeZone,
+	period, err := NewPeriod(periodType, s.Config, s.journalEntries, s.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	// Budgets have monthly limits, salary periods are about month long so limits are used per salary period.
+	// Anomalies are disabled for other periods too because their baseline is set in months.
+	var budgets map[string]*BudgetConfig
+	anomalies := &AnomalyDetectionConfig{Disabled: true}
+	if periodType == PeriodMonth || periodType == PeriodSalary {
+		budgets = s.Config.Budgets
+		anomalies = s.Config.Anomalies
+	}
+	statistics, err := BuildPeriodStatistics(
+		s.journalEntries,
+		s.StatisticBuilderFactory,
+		period,
+		budgets,
+		anomalies,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	if s.periodStatistics == nil {
+		s.periodStatistics = make(map[PeriodType][]map[string]*IntervalStatistic)
+	}
+	s.periodStatistics[periodType] = statistics
+	return statistics, nil
 }
 
-func (dh *DataHandler) GetMonthlyStatistics() ([]map[string]*IntervalStatistic, error) {
-	if dh.monthlyStatistics == nil {
-		err := dh.rebuildMonthlyStatistics()
-		if err != nil {
-			return nil, err
-		}
+// GetRecurringPayments returns regular payments like subscriptions. Detects them on first call.
+func (s *DataSnapshot) GetRecurringPayments() []*RecurringPayment {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.recurringPayments == nil && s.DataMart != nil {
+		s.recurringPayments = DetectRecurringPayments(s.DataMart.SortedTransactions, s.DataMart.Accounts)
 	}
-	return dh.monthlyStatistics, nil
+	return s.recurringPayments
 }
 
-func (dh *DataHandler) UpdateGroups(groups map[string]*GroupConfig) error {
-	dh.Config.Groups = groups
-	err := dh.Config.writeToFile(dh.ConfigPath)
-	if err != nil {
-		return err
+// GetBalances returns balances of accounts and net worth. Builds them on first call.
+func (s *DataSnapshot) GetBalances() *Balances {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.balances == nil && s.DataMart != nil {
+		s.balances = BuildBalances(s.DataMart)
 	}
-	// Clear caches.
-	dh.Categorization = nil
-	dh.journalEntries = nil
-	dh.uncategorizedTransactions = nil
-	dh.monthlyStatistics = nil
-	return nil
+	return s.balances
 }
 
-// parseAllFiles parses all transaction files from the current configuration.
-// Doesn't update DataHandler fields.
-// Returns transactions, file infos, parsing warnings, categorization, and error.
-func (dh *DataHandler) parseAllFiles() ([]Transaction, []FileInfo, []string, *Categorization, error) {
-	var allFileInfos []FileInfo
-	transactions := make([]Transaction, 0)
-	parsingWarnings := []string{}
+// DataHandler is a handler for data.
+// Contians methods to recalculate, cache, persist data.
+// Safe for concurrent use: readers get the current DataSnapshot, writers build a new one and swap it.
+type DataHandler struct {
+	// ConfigPath is a path to the configuration file.
+	ConfigPath string
+	// PeriodType is a default type of periods for statistics, overrides one from configuration if not empty.
+	PeriodType PeriodType
+	// snapshotMutex guards snapshot.
+	snapshotMutex sync.RWMutex
+	// snapshot is the current data.
+	snapshot *DataSnapshot
+	// transactionsCache is a cache of transactions parsed from files.
+	transactionsCache *TransactionsCache
+	// updateMutex serializes changes of data: rebuild from files and update of groups.
+	// Files watcher, "refresh" button and categorization page may request them at the same time.
+	updateMutex sync.Mutex
+	// ownWritesMutex guards ownWrites.
+	ownWritesMutex sync.Mutex
+	// ownWrites are states of files written by the application by absolute paths, see `recordOwnWrite`.
+	ownWrites map[string]watchedFileState
+}
 
-	// Parse files to unified Transaction-s.
-	// Ineco XML
-	inecoXmlTransactions, fileInfos, err := parseTransactionsOfOneType(
-		dh.Config.InecobankStatementXmlFilesGlob,
-		"Inecobank XML statement",
-		InecoXmlParser{},
-		&parsingWarnings,
-	)
-	if err != nil {
-		return nil, nil, nil, nil, err
+func NewDataHandler(configPath string, snapshot *DataSnapshot) *DataHandler {
+	return &DataHandler{
+		ConfigPath: configPath,
+		snapshot:   snapshot,
 	}
-	transactions = append(transactions, inecoXmlTransactions...)
-	allFileInfos = append(allFileInfos, fileInfos...)
+}
 
-	// Ineco XLSX
-	inecoXlsxTransactions, fileInfos, err := parseTransactionsOfOneType(
-		dh.Config.InecobankStatementXlsxFilesGlob,
-		"Inecobank XLSX statement",
-		InecoExcelFileParser{},
-		&parsingWarnings,
-	)
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
-	transactions = append(transactions, inecoXlsxTransactions...)
-	allFileInfos = append(allFileInfos, fileInfos...)
+// GetSnapshot returns the current data. Returned snapshot stays consistent even if data is rebuilt meanwhile.
+func (dh *DataHandler) GetSnapshot() *DataSnapshot {
+	dh.snapshotMutex.RLock()
+	defer dh.snapshotMutex.RUnlock()
+	return dh.snapshot
+}
 
-	// MyAmeria Excel account statements and history.
-	myAmeriaStatementsXlsTransactions, fileInfos, err := parseTransactionsOfOneType(
-		dh.Config.MyAmeriaAccountStatementXlsFilesGlob,
-		"MyAmeria XLS statement",
-		MyAmeriaExcelStmtFileParser{},
-		&parsingWarnings,
-	)
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
-	transactions = append(transactions, myAmeriaStatementsXlsTransactions...)
-	allFileInfos = append(allFileInfos, fileInfos...)
-	myAmeriaHistoryXlsTransactions, fileInfos, err := parseTransactionsOfOneType(
-		dh.Config.MyAmeriaHistoryXlsFilesGlob,
-		"MyAmeria History XLS",
-		MyAmeriaExcelFileParser{
-			MyAccounts: dh.Config.MyAmeriaMyAccounts,
-		},
-		&parsingWarnings,
-	)
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
-	transactions = append(transactions, myAmeriaHistoryXlsTransactions...)
-	allFileInfos = append(allFileInfos, fileInfos...)
+// setSnapshot atomically replaces the current data.
+func (dh *DataHandler) setSnapshot(snapshot *DataSnapshot) {
+	dh.snapshotMutex.Lock()
+	defer dh.snapshotMutex.Unlock()
+	dh.snapshot = snapshot
+}
 
-	// Ameria CSV
-	ameriaCsvTransactions, fileInfos, err := parseTransactionsOfOneType(
-		dh.Config.AmeriaCsvFilesGlob,
-		"AmeriaBank CSV statement",
-		AmeriaCsvFileParser{},
-		&parsingWarnings,
-	)
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
-	transactions = append(transactions, ameriaCsvTransactions...)
-	allFileInfos = append(allFileInfos, fileInfos...)
+// GetJournalEntries returns journal entries of the current snapshot.
+func (dh *DataHandler) GetJournalEntries() ([]JournalEntry, error) {
+	return dh.GetSnapshot().GetJournalEntries()
+}
 
-	// Arshinbank XLSX
-	ardshinbankXlsxTransactions, fileInfos, err := parseTransactionsOfOneType(
-		dh.Config.ArdshinbankXlsxFilesGlob,
-		"Ardshinbank XLSX statement",
-		ArdshinXlsxFileParser{},
-		&parsingWarnings,
-	)
-	if err != nil {
-		return nil, nil, nil, nil, err
+// GetUncategorizedTransactions returns uncategorized transactions of the current snapshot.
+func (dh *DataHandler) GetUncategorizedTransactions() ([]Transaction, error) {
+	return dh.GetSnapshot().GetUncategorizedTransactions()
+}
+
+// GetMonthlyStatistics returns statistics of the current snapshot per default type of periods.
+func (dh *DataHandler) GetMonthlyStatistics() ([]map[string]*IntervalStatistic, error) {
+	return dh.GetSnapshot().GetPeriodStatistics(dh.PeriodType)
+}
+
+// GetRecurringPayments returns recurring payments of the current snapshot.
+func (dh *DataHandler) GetRecurringPayments() []*RecurringPayment {
+	return dh.GetSnapshot().GetRecurringPayments()
+}
+
+// GetBalances returns balances of accounts and net worth of the current snapshot.
+func (dh *DataHandler) GetBalances() *Balances {
+	return dh.GetSnapshot().GetBalances()
+}
+
+// UpdateGroups changes groups with the `update` function, saves them into the configuration file
+// and swaps in a snapshot with the new configuration.
+// `update` receives a copy of groups so it can't affect readers of the current snapshot.
+func (dh *DataHandler) UpdateGroups(update func(groups map[string]*GroupConfig) error) error {
+	return dh.updateConfig(func(config *Config) error {
+		return update(config.Groups)
+	})
+}
+
+// RenameGroup renames group together with its subgroups, see `renameGroup`, and rewrites references
+// to renamed groups in budgets, category overrides, splits and salary periods, so they don't point to missing groups.
+func (dh *DataHandler) RenameGroup(oldName, newName string) error {
+	return dh.updateConfig(func(config *Config) error {
+		if _, exists := config.Groups[newName]; exists {
+			return errGroupAlreadyExists
+		}
+		if err := renameGroup(config.Groups, oldName, newName); err != nil {
+			return err
+		}
+		rename := groupRenamer(oldName, newName)
+		budgets := make(map[string]*BudgetConfig, len(config.Budgets))
+		for name, budget := range config.Budgets {
+			budgets[rename(name)] = budget
+		}
+		config.Budgets = budgets
+		for _, override := range config.CategoryOverrides {
+			override.Group = rename(override.Group)
+		}
+		for _, split := range config.TransactionSplits {
+			for i := range split.Parts {
+				split.Parts[i].Group = rename(split.Parts[i].Group)
+			}
+		}
+		if config.Period != nil {
+			config.Period.SalaryGroup = rename(config.Period.SalaryGroup)
+		}
+		return nil
+	})
+}
+
+// DeleteGroup deletes group. Group which starts salary periods can't be deleted
+// because configuration without it is invalid.
+func (dh *DataHandler) DeleteGroup(name string) error {
+	return dh.updateConfig(func(config *Config) error {
+		if config.Period != nil && config.Period.SalaryGroup == name {
+			return fmt.Errorf("%w: '%s'", errGroupIsSalaryGroup, name)
+		}
+		delete(config.Groups, name)
+		return nil
+	})
+}
+
+// updateConfig changes the configuration with the `update` function, saves it into the configuration file
+// (and into the category overrides file if overrides or splits are changed) and swaps in a snapshot
+// with the new configuration. `update` receives copies of groups, budgets, overrides, splits and periods
+// so it can't affect readers of the current snapshot.
+func (dh *DataHandler) updateConfig(update func(config *Config) error) error {
+	dh.updateMutex.Lock()
+	defer dh.updateMutex.Unlock()
+
+	current := dh.GetSnapshot()
+	config := *current.Config
+	config.Groups = make(map[string]*GroupConfig, len(current.Config.Groups))
+	for name, group := range current.Config.Groups {
+		groupCopy := *group
+		config.Groups[name] = &groupCopy
+	}
+	config.Budgets = maps.Clone(current.Config.Budgets)
+	if current.Config.Period != nil {
+		period := *current.Config.Period
+		config.Period = &period
+	}
+	config.CategoryOverrides = maps.Clone(current.Config.CategoryOverrides)
+	for fingerprint, override := range config.CategoryOverrides {
+		overrideCopy := *override
+		config.CategoryOverrides[fingerprint] = &overrideCopy
+	}
+	config.TransactionSplits = maps.Clone(current.Config.TransactionSplits)
+	for fingerprint, split := range config.TransactionSplits {
+		splitCopy := *split
+		splitCopy.Parts = slices.Clone(split.Parts)
+		config.TransactionSplits[fingerprint] = &splitCopy
+	}
+	if err := update(&config); err != nil {
+		return err
 	}
-	transactions = append(transactions, ardshinbankXlsxTransactions...)
-	allFileInfos = append(allFileInfos, fileInfos...)
+	if err := config.writeToFile(dh.ConfigPath); err != nil {
+		return err
+	}
+	dh.recordOwnWrite(dh.ConfigPath)
+	if !reflect.DeepEqual(config.CategoryOverrides, current.Config.CategoryOverrides) ||
+		!reflect.DeepEqual(config.TransactionSplits, current.Config.TransactionSplits) {
+		overridesPath := getCategoryOverridesPath(dh.ConfigPath)
+		file := &categoryOverridesFile{Overrides: config.CategoryOverrides, Splits: config.TransactionSplits}
+		if err := writeCategoryOverrides(overridesPath, file); err != nil {
+			return err
+		}
+		dh.recordOwnWrite(overridesPath)
+	}
+
+	// Categorization and everything built from it would be rebuilt in the new snapshot.
+	dh.setSnapshot(&DataSnapshot{
+		Config:                  &config,
+		TimeZone:                current.TimeZone,
+		DataMart:                current.DataMart,
+		StatisticBuilderFactory: current.StatisticBuilderFactory,
+		FileInfos:               current.FileInfos,
+		InboxReports:            current.InboxReports,
+	})
+	return nil
+}
 
-	// Acba Regular Account XLS
-	acbaRegularAccountXlsTransactions, fileInfos, err := parseTransactionsOfOneType(
-		dh.Config.AcbaRegularAccountXlsFilesGlob,
-		"Acba Regular Account XLS statement",
-		AcbaRegularAccountExcelFileParser{},
-		&parsingWarnings,
-	)
+// parseAllFiles parses all transaction files from the provided configuration.
+// Doesn't update DataHandler fields except of transactions cache.
+// Returns transactions, file infos, inbox reports, parsing warnings, categorization, and error.
+func (dh *DataHandler) parseAllFiles(config *Config) ([]Transaction, []FileInfo, []InboxFileReport, []string, *Categorization, error) {
+	var allFileInfos []FileInfo
+	transactions := make([]Transaction, 0)
+	parsingWarnings := []string{}
+
+	// Parse files to unified Transaction-s.
+	cache := dh.getTransactionsCache(config)
+	workers := config.GetParsingWorkers()
+	sourcesTransactions, fileInfos, err := parseTransactionsOfSources(config.GetSources(), config, cache, workers, &parsingWarnings)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, err
 	}
-	transactions = append(transactions, acbaRegularAccountXlsTransactions...)
+	transactions = append(transactions, sourcesTransactions...)
 	allFileInfos = append(allFileInfos, fileInfos...)
 
-	// Acba Card XLS
-	acbaCardXlsTransactions, fileInfos, err := parseTransactionsOfOneType(
-		dh.Config.AcbaCardXlsFilesGlob,
-		"Acba Card XLS statement",
-		AcbaCardExcelFileParser{},
-		&parsingWarnings,
-	)
-	if err != nil {
-		return nil, nil, nil, nil, err
+	// Parse files with automatically detected format.
+	var inboxReports []InboxFileReport
+	if config.InboxGlob != "" {
+		inboxTransactions, fileInfos, reports, err := parseInboxFiles(config, cache, workers, allFileInfos, &parsingWarnings)
+		if err != nil {
+			return nil, nil, nil, nil, nil, err
+		}
+		transactions = append(transactions, inboxTransactions...)
+		allFileInfos = append(allFileInfos, fileInfos...)
+		inboxReports = reports
 	}
-	transactions = append(transactions, acbaCardXlsTransactions...)
-	allFileInfos = append(allFileInfos, fileInfos...)
 
-	// Generic CSV
-	genericCsvTransactions, fileInfos, err := parseTransactionsOfOneType(
-		dh.Config.GenericCsvFilesGlob,
-		"Generic CSV with transactions",
-		GenericCsvFileParser{},
-		&parsingWarnings,
-	)
-	if err != nil {
-		return nil, nil, nil, nil, err
+	// Cache is an optimization so don't fail if it can't be saved.
+	if err := cache.Save(); err != nil {
+		log.Println(i18n.T("Can't save transactions cache into f file", "f", cache.path, "err", err))
 	}
-	transactions = append(transactions, genericCsvTransactions...)
-	allFileInfos = append(allFileInfos, fileInfos...)
 
 	if len(transactions) < 1 {
-		return nil, nil, nil, nil, errors.New(
+		return nil, nil, nil, nil, nil, errors.New(
 			i18n.T("can't find transactions, parsing warnings w", "w", parsingWarnings),
 		)
 	}
 	log.Println(i18n.T("Total found n transactions", "n", len(transactions)))
 
 	// Create initial Categorization.
-	categorization, err := NewCategorization(dh.Config)
+	categorization, err := NewCategorization(config)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, err
 	}
 
-	return transactions, allFileInfos, parsingWarnings, categorization, nil
+	return transactions, allFileInfos, inboxReports, parsingWarnings, categorization, nil
+}
+
+// getTransactionsCache returns cache of parsed transactions or nil if it is disabled.
+// Cache is loaded from the file only once and then is kept in memory.
+// Should be called from one goroutine at a time, i.e. under updateMutex after the start.
+func (dh *DataHandler) getTransactionsCache(config *Config) *TransactionsCache {
+	if config.DisableTransactionsCache {
+		return nil
+	}
+	if dh.transactionsCache == nil {
+		dh.transactionsCache = loadTransactionsCache(getTransactionsCachePath(dh.ConfigPath))
+	}
+	return dh.transactionsCache
 }
 
 // RebuildFromFiles rebuilds the DataHandler by re-reading the config file and re-parsing all transaction files.
 // This method is useful for the UI to refresh all data when files or config have been updated.
+// New data is built aside and then swapped in, so pages are served with the old data meanwhile.
 func (dh *DataHandler) RebuildFromFiles() error {
+	dh.updateMutex.Lock()
+	defer dh.updateMutex.Unlock()
+
 	// Re-read configuration file to catch any user changes.
 	config, err := readConfig(dh.ConfigPath)
 	if err != nil {
 		return fmt.Errorf("configuration file '%s' is wrong: %w", dh.ConfigPath, err)
 	}
-
-	// Update stored config
-	dh.Config = config
+	timeZone, err := time.LoadLocation(config.TimeZoneLocation)
+	if err != nil {
+		return fmt.Errorf("unknown TimeZoneLocation: %s", config.TimeZoneLocation)
+	}
 
 	// Re-parse all files using the updated config
-	transactions, fileInfos, parsingWarnings, categorization, err := dh.parseAllFiles()
+	transactions, fileInfos, inboxReports, parsingWarnings, categorization, err := dh.parseAllFiles(config)
 	if err != nil {
 		return err
 	}
@@ -593,22 +753,21 @@ func (dh *DataHandler) RebuildFromFiles() error {
 		return err
 	}
 
-	// Update DataHandler with new data
-	dh.DataMart = newDataMart
-	dh.Categorization = categorization
-	dh.FileInfos = fileInfos
-
-	// Clear cached data to force recalculation
-	dh.journalEntries = nil
-	dh.uncategorizedTransactions = nil
-	dh.monthlyStatistics = nil
-
 	// Rebuild GroupExtractorFactory with new accounts
-	groupExtractorFactory, err := NewStatisticBuilderByCategories(dh.DataMart.Accounts, dh.Config)
+	groupExtractorFactory, err := NewStatisticBuilderByCategories(newDataMart.Accounts, config)
 	if err != nil {
 		return err
 	}
-	dh.StatisticBuilderFactory = groupExtractorFactory
 
+	// Swap in new data, journal entries and statistics would be built on demand.
+	dh.setSnapshot(&DataSnapshot{
+		Config:                  config,
+		TimeZone:                timeZone,
+		DataMart:                newDataMart,
+		StatisticBuilderFactory: groupExtractorFactory,
+		Categorization:          categorization,
+		FileInfos:               fileInfos,
+		InboxReports:            inboxReports,
+	})
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 // TestHelper provides utilities for testing
@@ -296,3 +297,63 @@ func TestHandleError(t *testing.T) {
 		t.Error("Expected error file to be created")
 	}
 }
+
+func TestDataSnapshot_GetPeriodStatistics_AnomaliesOnlyForMonths(t *testing.T) {
+	// Arrange
+	config := &Config{
+		MyAccounts: []string{"my"},
+		Groups:     map[string]*GroupConfig{"Food": {}},
+	}
+	factory, err := NewStatisticBuilderByCategories(map[string]*AccountStatistics{}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newEntry := func(date time.Time, amount int) JournalEntry {
+		return JournalEntry{
+			Date:                  date,
+			IsExpense:             true,
+			Category:              "Food",
+			FromAccount:           "my",
+			ToAccount:             "shop",
+			AccountCurrency:       "AMD",
+			AccountCurrencyAmount: Money{int: amount},
+			Amounts:               map[string]AmountInCurrency{"AMD": {Currency: "AMD", Amount: Money{int: amount}}},
+		}
+	}
+	// Mondays of consecutive months, so each entry is in own month and own week.
+	journalEntries := []JournalEntry{
+		newEntry(utcDate(2024, 1, 1), 10000),
+		newEntry(utcDate(2024, 2, 5), 12000),
+		newEntry(utcDate(2024, 3, 4), 9000),
+		newEntry(utcDate(2024, 4, 1), 50000),
+	}
+	tests := []struct {
+		periodType        PeriodType
+		expectedAnomalies int
+	}{
+		{PeriodMonth, 1},
+		{PeriodWeek, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.periodType), func(t *testing.T) {
+			snapshot := &DataSnapshot{
+				Config:                  config,
+				TimeZone:                time.UTC,
+				StatisticBuilderFactory: factory,
+				journalEntries:          journalEntries,
+			}
+
+			// Act
+			statistics, err := snapshot.GetPeriodStatistics(tt.periodType)
+
+			// Assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			last := statistics[len(statistics)-1]["AMD"]
+			if len(last.Anomalies) != tt.expectedAnomalies {
+				t.Errorf("expected %d anomalies, got %+v", tt.expectedAnomalies, last.Anomalies)
+			}
+		})
+	}
+}
@@ -0,0 +1,570 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// MappedCsvParserName is a name of parser to use in `sources` configuration.
+	MappedCsvParserName = "mappedCsv"
+	mappedCsvTypeName   = "CSV with configured columns"
+)
+
+func init() {
+	RegisterParser(ParserRegistration{
+		Name:        MappedCsvParserName,
+		TypeName:    mappedCsvTypeName,
+		Tag:         "Csv",
+		Version:     2,
+		DefaultGlob: "transactions*.csv",
+		NewParser:   newMappedCsvFileParser,
+	})
+}
+
+// CsvColumn is a column of CSV file referenced by header text or by number starting from 1.
+type CsvColumn struct {
+	// Header is a text of the column header, case-insensitive.
+	Header string
+	// Number is a number of the column starting from 1.
+	Number int
+	// Pattern is a regular expression to extract value from the cell. The first group is used if any.
+	// Cells which don't match it are treated as empty.
+	Pattern string
+}
+
+// UnmarshalYAML reads column from YAML number or string, or from mapping with one of them and pattern.
+func (c *CsvColumn) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		var fields struct {
+			Header  string `yaml:"header"`
+			Number  int    `yaml:"number"`
+			Pattern string `yaml:"pattern"`
+		}
+		if err := value.Decode(&fields); err != nil {
+			return err
+		}
+		if (fields.Header == "") == (fields.Number < 1) {
+			return fmt.Errorf("line %d: column should have either header or number starting from 1", value.Line)
+		}
+		*c = CsvColumn{Header: fields.Header, Number: fields.Number, Pattern: fields.Pattern}
+		return nil
+	}
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: column should be a header text or a number", value.Line)
+	}
+	if value.Tag == "!!int" {
+		number, err := strconv.Atoi(value.Value)
+		if err != nil || number < 1 {
+			return fmt.Errorf("line %d: column number should start from 1, got '%s'", value.Line, value.Value)
+		}
+		*c = CsvColumn{Number: number}
+		return nil
+	}
+	*c = CsvColumn{Header: value.Value}
+	return nil
+}
+
+// IsSet returns true if column is configured.
+func (c CsvColumn) IsSet() bool {
+	return c.Header != "" || c.Number > 0
+}
+
+func (c CsvColumn) String() string {
+	if c.Header != "" {
+		return fmt.Sprintf("'%s'", c.Header)
+	}
+	return fmt.Sprintf("#%d", c.Number)
+}
+
+// CsvColumns is a list of columns which values are joined with space. May be set as a single column in YAML.
+type CsvColumns []CsvColumn
+
+// UnmarshalYAML reads list of columns from YAML sequence or a single column.
+func (c *CsvColumns) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.SequenceNode {
+		var columns []CsvColumn
+		if err := value.Decode(&columns); err != nil {
+			return err
+		}
+		*c = columns
+		return nil
+	}
+	var column CsvColumn
+	if err := value.Decode(&column); err != nil {
+		return err
+	}
+	*c = CsvColumns{column}
+	return nil
+}
+
+// MappedCsvColumns maps columns of CSV file to fields of transactions.
+type MappedCsvColumns struct {
+	// Date is a column with date of the transaction. "Date" by default.
+	Date CsvColumn `yaml:"date"`
+	// Amount is a column with signed amount in account currency, negative amounts are expenses.
+	// "Amount" by default if Debit and Credit are not set.
+	Amount CsvColumn `yaml:"amount"`
+	// Debit is a column with amounts of expenses, used together with Credit instead of Amount.
+	Debit CsvColumn `yaml:"debit"`
+	// Credit is a column with amounts of incomes, used together with Debit instead of Amount.
+	Credit CsvColumn `yaml:"credit"`
+	// Details are columns joined into details of the transaction. "Details" by default if file has header.
+	Details CsvColumns `yaml:"details"`
+	// AccountNumber is a column with own account number. "Account" by default if constant is not set.
+	AccountNumber CsvColumn `yaml:"accountNumber"`
+	// Currency is a column with currency of the account. "Currency" by default if constant is not set.
+	Currency CsvColumn `yaml:"currency"`
+	// Counterparty is a column with account number of the other side of the transaction.
+	// Pattern of the column helps to extract number from cells with names.
+	Counterparty CsvColumn `yaml:"counterparty"`
+	// OriginCurrency is a column with currency of the transaction before conversion.
+	OriginCurrency CsvColumn `yaml:"originCurrency"`
+	// OriginAmount is a column with amount of the transaction before conversion.
+	OriginAmount CsvColumn `yaml:"originAmount"`
+}
+
+// MappedCsvFileParserOptions are options of "mappedCsv" source.
+type MappedCsvFileParserOptions struct {
+	// Delimiter is a single character between values, "," by default. "tab" is a synonym for tab character.
+	Delimiter string `yaml:"delimiter"`
+	// Encoding is a name of the file encoding like "windows-1251" or "utf-16le", "utf-8" by default.
+	Encoding string `yaml:"encoding"`
+	// SkipRows is a number of rows before the header row (or before transactions if there is no header).
+	SkipRows int `yaml:"skipRows" validate:"min=0"`
+	// NoHeader is a flag that file doesn't have header row, so columns should be set by numbers.
+	NoHeader bool `yaml:"noHeader"`
+	// DateFormat is a layout of dates in Go format like "02.01.2006", "2006-01-02" by default.
+	DateFormat string `yaml:"dateFormat"`
+	// TimeZone is an IANA name of time zone for dates without offset like "Asia/Yerevan", UTC by default.
+	TimeZone string `yaml:"timeZone"`
+	// DecimalSeparator is "." or ",", by default it is guessed for each amount.
+	DecimalSeparator string `yaml:"decimalSeparator"`
+	// AccountNumber is an own account number for all transactions of the file.
+	AccountNumber string `yaml:"accountNumber"`
+	// Currency is a currency of the account for all transactions of the file.
+	Currency string `yaml:"currency"`
+	// Columns maps columns to fields of transactions.
+	Columns MappedCsvColumns `yaml:"columns"`
+}
+
+// MappedCsvFileParser parses CSV files with columns mapped in options of the source.
+type MappedCsvFileParser struct {
+	Options   MappedCsvFileParserOptions
+	delimiter rune
+}
+
+func newMappedCsvFileParser(options map[string]any, _ *Config) (FileParser, error) {
+	parserOptions := MappedCsvFileParserOptions{}
+	if err := decodeParserOptions(options, &parserOptions); err != nil {
+		return nil, err
+	}
+	if parserOptions.Delimiter == "" {
+		parserOptions.Delimiter = ","
+	}
+	if parserOptions.Encoding == "" {
+		parserOptions.Encoding = "utf-8"
+	}
+	if parserOptions.DateFormat == "" {
+		parserOptions.DateFormat = OutputDateFormat
+	}
+	columns := &parserOptions.Columns
+	if err := columns.applyDefaults(!parserOptions.NoHeader, parserOptions.AccountNumber != "", parserOptions.Currency != ""); err != nil {
+		return nil, err
+	}
+	if parserOptions.NoHeader {
+		for _, column := range columns.all() {
+			if column.Header != "" {
+				return nil, fmt.Errorf("column %s should be set by number because file has no header", column)
+			}
+		}
+	}
+
+	delimiter, size := utf8.DecodeRuneInString(parserOptions.Delimiter)
+	if parserOptions.Delimiter == "tab" {
+		delimiter = '\t'
+	} else if size != len(parserOptions.Delimiter) {
+		return nil, fmt.Errorf("delimiter should be a single character, got '%s'", parserOptions.Delimiter)
+	}
+	if _, err := newDateFormat(parserOptions.TimeZone, parserOptions.DateFormat); err != nil {
+		return nil, err
+	}
+	if err := checkDecimalSeparator(parserOptions.DecimalSeparator); err != nil {
+		return nil, err
+	}
+	if _, err := htmlindex.Get(parserOptions.Encoding); err != nil {
+		return nil, fmt.Errorf("unknown encoding '%s': %w", parserOptions.Encoding, err)
+	}
+	return MappedCsvFileParser{Options: parserOptions, delimiter: delimiter}, nil
+}
+
+// applyDefaults sets default headers for not configured columns and checks that columns don't conflict
+// and have valid patterns. Account number and currency columns are not needed if their values are known
+// for the whole file.
+func (c *MappedCsvColumns) applyDefaults(hasHeader, hasAccountNumber, hasCurrency bool) error {
+	if !c.Date.IsSet() {
+		c.Date = CsvColumn{Header: "Date"}
+	}
+	if c.Amount.IsSet() && (c.Debit.IsSet() || c.Credit.IsSet()) {
+		return errors.New("either 'amount' or 'debit' and 'credit' columns should be set, not both")
+	}
+	if !c.Amount.IsSet() && !c.Debit.IsSet() && !c.Credit.IsSet() {
+		c.Amount = CsvColumn{Header: "Amount"}
+	}
+	if len(c.Details) == 0 && hasHeader {
+		c.Details = CsvColumns{{Header: "Details"}}
+	}
+	if !hasAccountNumber && !c.AccountNumber.IsSet() {
+		c.AccountNumber = CsvColumn{Header: "Account"}
+	}
+	if !hasCurrency && !c.Currency.IsSet() {
+		c.Currency = CsvColumn{Header: "Currency"}
+	}
+	for _, column := range c.all() {
+		if _, err := regexp.Compile(column.Pattern); err != nil {
+			return fmt.Errorf("wrong pattern of column %s: %w", column, err)
+		}
+	}
+	return nil
+}
+
+// checkDecimalSeparator returns error if decimal separator from options is not supported.
+func checkDecimalSeparator(separator string) error {
+	if separator != "" && separator != "." && separator != "," {
+		return fmt.Errorf("decimal separator should be '.' or ',', got '%s'", separator)
+	}
+	return nil
+}
+
+// amountFormat returns format of amounts with decimal separator from options.
+func amountFormat(decimalSeparator string) AmountFormat {
+	if decimalSeparator == "" {
+		return AmountFormat{}
+	}
+	return AmountFormat{DecimalSeparator: rune(decimalSeparator[0])}
+}
+
+// all returns all set columns.
+func (c MappedCsvColumns) all() []CsvColumn {
+	result := make([]CsvColumn, 0)
+	for _, column := range append([]CsvColumn{
+		c.Date, c.Amount, c.Debit, c.Credit, c.AccountNumber, c.Currency, c.Counterparty, c.OriginCurrency, c.OriginAmount,
+	}, c.Details...) {
+		if column.IsSet() {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+// resolvedColumn is an index of column in the file, -1 for not set column, with compiled pattern if any.
+type resolvedColumn struct {
+	index   int
+	pattern *regexp.Regexp
+}
+
+// mappedCsvIndexes are resolved columns of the file.
+type mappedCsvIndexes struct {
+	date, amount, debit, credit           resolvedColumn
+	accountNumber, currency, counterparty resolvedColumn
+	originCurrency, originAmount          resolvedColumn
+	details                               []resolvedColumn
+}
+
+// resolveColumns finds indexes of columns by header rows, there are no rows for files without header.
+// Multi-row headers are searched from top to bottom, so texts of upper rows take precedence.
+func (c MappedCsvColumns) resolveColumns(headers ...[]string) (*mappedCsvIndexes, error) {
+	var err error
+	find := func(column CsvColumn) int {
+		switch {
+		case err != nil || !column.IsSet():
+			return -1
+		case column.Number > 0:
+			return column.Number - 1
+		}
+		for _, header := range headers {
+			for i, text := range header {
+				if strings.EqualFold(strings.TrimSpace(text), column.Header) {
+					return i
+				}
+			}
+		}
+		headerTexts := make([]string, 0, len(headers))
+		for _, header := range headers {
+			headerTexts = append(headerTexts, fmt.Sprint(header))
+		}
+		err = fmt.Errorf("can't find column %s in header %s", column, strings.Join(headerTexts, " "))
+		return -1
+	}
+	resolve := func(column CsvColumn) resolvedColumn {
+		result := resolvedColumn{index: find(column)}
+		if column.Pattern != "" {
+			// Patterns are checked when options are read.
+			result.pattern = regexp.MustCompile(column.Pattern)
+		}
+		return result
+	}
+	result := &mappedCsvIndexes{
+		date:           resolve(c.Date),
+		amount:         resolve(c.Amount),
+		debit:          resolve(c.Debit),
+		credit:         resolve(c.Credit),
+		accountNumber:  resolve(c.AccountNumber),
+		currency:       resolve(c.Currency),
+		counterparty:   resolve(c.Counterparty),
+		originCurrency: resolve(c.OriginCurrency),
+		originAmount:   resolve(c.OriginAmount),
+	}
+	for _, column := range c.Details {
+		result.details = append(result.details, resolve(column))
+	}
+	return result, err
+}
+
+// mappedRowParser converts rows with mapped columns into transactions. Shared by CSV and spreadsheet parsers.
+type mappedRowParser struct {
+	typeName    string
+	filePath    string
+	indexes     *mappedCsvIndexes
+	parseDate   func(string) (time.Time, error)
+	parseAmount func(string) (Money, error)
+	// accountNumber and currency are values for all rows, columns are used if they are empty.
+	accountNumber string
+	currency      string
+	// counterparty is a value for rows with empty counterparty column.
+	counterparty string
+	// keepOriginAmount is a flag to keep origin amount of rows in account currency too.
+	keepOriginAmount bool
+	// swapAccounts is a flag to swap own account and counterparty of rows.
+	swapAccounts bool
+	// Files may contain several accounts, keep one source per account and currency.
+	sources map[string]*TransactionsSource
+}
+
+// parseRow returns transaction from the row or nil for rows without date or amount.
+func (p *mappedRowParser) parseRow(record []string) (*Transaction, error) {
+	cell := func(column resolvedColumn) string {
+		if column.index < 0 || column.index >= len(record) {
+			return ""
+		}
+		value := strings.TrimSpace(record[column.index])
+		if column.pattern != nil {
+			matches := column.pattern.FindStringSubmatch(value)
+			switch {
+			case matches == nil:
+				return ""
+			case len(matches) > 1:
+				return strings.TrimSpace(matches[1])
+			}
+			return strings.TrimSpace(matches[0])
+		}
+		return value
+	}
+
+	// Skip empty rows and rows without date like totals.
+	dateText := cell(p.indexes.date)
+	if dateText == "" {
+		return nil, nil
+	}
+	date, err := p.parseDate(dateText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse date '%s': %w", dateText, err)
+	}
+
+	// Parse amount from signed amount or from debit and credit columns.
+	var amount, debit, credit Money
+	for _, field := range []struct {
+		column resolvedColumn
+		name   string
+		target *Money
+	}{
+		{p.indexes.amount, "amount", &amount},
+		{p.indexes.debit, "debit", &debit},
+		{p.indexes.credit, "credit", &credit},
+	} {
+		if value := cell(field.column); value != "" {
+			if *field.target, err = p.parseAmount(value); err != nil {
+				return nil, fmt.Errorf("failed to parse %s '%s': %w", field.name, value, err)
+			}
+		}
+	}
+	isExpense := amount.int < 0
+	if debit.int != 0 && credit.int != 0 {
+		return nil, fmt.Errorf("both debit '%s' and credit '%s' are set", cell(p.indexes.debit), cell(p.indexes.credit))
+	} else if debit.int != 0 {
+		amount, isExpense = debit, true
+	} else if credit.int != 0 {
+		amount = credit
+	}
+	if amount.int < 0 {
+		amount.int = -amount.int
+	}
+	// Skip rows without amount like declined or informational ones.
+	if amount.int == 0 {
+		return nil, nil
+	}
+
+	accountNumber := p.accountNumber
+	if accountNumber == "" {
+		accountNumber = cell(p.indexes.accountNumber)
+	}
+	if accountNumber == "" {
+		return nil, errors.New("account number is empty")
+	}
+	currency := p.currency
+	if currency == "" {
+		currency = cell(p.indexes.currency)
+	}
+	if currency == "" {
+		return nil, errors.New("currency is empty")
+	}
+	sourceKey := accountNumber + ":" + currency
+	source, ok := p.sources[sourceKey]
+	if !ok {
+		source = &TransactionsSource{
+			TypeName:        p.typeName,
+			FilePath:        p.filePath,
+			AccountNumber:   accountNumber,
+			AccountCurrency: currency,
+		}
+		if p.sources == nil {
+			p.sources = make(map[string]*TransactionsSource)
+		}
+		p.sources[sourceKey] = source
+	}
+
+	// Parts are joined with spaces, so commas at their ends are noise.
+	details := make([]string, 0, len(p.indexes.details))
+	for _, column := range p.indexes.details {
+		if value := strings.Trim(cell(column), ", \n"); value != "" {
+			details = append(details, value)
+		}
+	}
+	transaction := &Transaction{
+		Date:            date,
+		IsExpense:       isExpense,
+		Amount:          amount,
+		Details:         strings.Join(details, " "),
+		Source:          source,
+		AccountCurrency: currency,
+	}
+	counterparty := cell(p.indexes.counterparty)
+	if counterparty == "" {
+		counterparty = p.counterparty
+	}
+	if isExpense != p.swapAccounts {
+		transaction.FromAccount, transaction.ToAccount = accountNumber, counterparty
+	} else {
+		transaction.FromAccount, transaction.ToAccount = counterparty, accountNumber
+	}
+	originCurrency := cell(p.indexes.originCurrency)
+	isOtherCurrency := originCurrency != "" && originCurrency != currency
+	if value := cell(p.indexes.originAmount); isOtherCurrency || (p.keepOriginAmount && value != "") {
+		originAmount, err := p.parseAmount(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse origin amount '%s': %w", value, err)
+		}
+		if originAmount.int < 0 {
+			originAmount.int = -originAmount.int
+		}
+		transaction.OriginCurrencyAmount = originAmount
+	}
+	if isOtherCurrency {
+		transaction.OriginCurrency = originCurrency
+	}
+	return transaction, nil
+}
+
+// decodeText converts text from the encoding with WHATWG name (like "windows-1251") into UTF-8 without BOM.
+func decodeText(data []byte, encodingName string) ([]byte, error) {
+	encoding, err := htmlindex.Get(encodingName)
+	if err != nil {
+		return nil, fmt.Errorf("unknown encoding '%s': %w", encodingName, err)
+	}
+	decoded, err := encoding.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode file from '%s': %w", encodingName, err)
+	}
+	return bytes.TrimPrefix(decoded, []byte("\ufeff")), nil
+}
+
+func (p MappedCsvFileParser) ParseRawTransactionsFromFile(filePath string) ([]Transaction, error) {
+	fileData, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	text, err := decodeText(fileData, p.Options.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(text))
+	reader.Comma = p.delimiter
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	for i := 0; i < p.Options.SkipRows; i++ {
+		if _, err := reader.Read(); err != nil {
+			return nil, fmt.Errorf("failed to skip %d rows: %w", p.Options.SkipRows, err)
+		}
+	}
+	var headers [][]string
+	if !p.Options.NoHeader {
+		header, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read header: %w", err)
+		}
+		headers = append(headers, header)
+	}
+	indexes, err := p.Options.Columns.resolveColumns(headers...)
+	if err != nil {
+		return nil, err
+	}
+
+	dateFormat, err := newDateFormat(p.Options.TimeZone, p.Options.DateFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	rowParser := &mappedRowParser{
+		typeName:      mappedCsvTypeName,
+		filePath:      filePath,
+		indexes:       indexes,
+		parseDate:     dateFormat.Parse,
+		parseAmount:   amountFormat(p.Options.DecimalSeparator).Parse,
+		accountNumber: p.Options.AccountNumber,
+		currency:      p.Options.Currency,
+	}
+	transactions := make([]Transaction, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		transaction, err := rowParser.parseRow(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if transaction != nil {
+			transactions = append(transactions, *transaction)
+		}
+	}
+	return transactions, nil
+}
+
+var _ FileParser = MappedCsvFileParser{}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newTestMappedCsvParser creates parser from options like they are written in configuration.
+func newTestMappedCsvParser(t *testing.T, options map[string]any) FileParser {
+	t.Helper()
+	parser, err := newMappedCsvFileParser(options, &Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return parser
+}
+
+func TestMappedCsvFileParser_DebitCreditWindows1251(t *testing.T) {
+	// Arrange
+	filePath := filepath.Join("testdata", "csv", "debit_credit_windows1251.csv")
+	parser := newTestMappedCsvParser(t, map[string]any{
+		"delimiter":        ";",
+		"encoding":         "windows-1251",
+		"skipRows":         2,
+		"dateFormat":       "02.01.2006",
+		"decimalSeparator": ",",
+		"accountNumber":    "40817810000000000001",
+		"currency":         "RUB",
+		"columns": map[string]any{
+			"date":         "Дата",
+			"debit":        "расход",
+			"credit":       4,
+			"details":      "Описание",
+			"counterparty": "Счёт контрагента",
+		},
+	})
+	source := &TransactionsSource{
+		TypeName:        mappedCsvTypeName,
+		FilePath:        filePath,
+		AccountNumber:   "40817810000000000001",
+		AccountCurrency: "RUB",
+	}
+
+	// Act
+	actual, err := parser.ParseRawTransactionsFromFile(filePath)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Transaction{
+		{
+			Date:            time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+			FromAccount:     "40702810000000000099",
+			ToAccount:       "40817810000000000001",
+			Amount:          Money{int: 150000000},
+			Details:         "Зарплата за февраль",
+			Source:          source,
+			AccountCurrency: "RUB",
+		},
+		{
+			Date:            time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC),
+			FromAccount:     "40817810000000000001",
+			IsExpense:       true,
+			Amount:          Money{int: 2345670},
+			Details:         "Супермаркет; продукты",
+			Source:          source,
+			AccountCurrency: "RUB",
+		},
+		{
+			Date:            time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC),
+			FromAccount:     "40817810000000000001",
+			ToAccount:       "40817810000000000002",
+			IsExpense:       true,
+			Amount:          Money{int: 10000000},
+			Details:         "Перевод на вклад",
+			Source:          source,
+			AccountCurrency: "RUB",
+		},
+	}
+	if diff := cmp.Diff(expected, actual, moneyComparer); diff != "" {
+		t.Errorf("transactions mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestMappedCsvFileParser_SignedAmountWithAccountColumns(t *testing.T) {
+	// Arrange
+	filePath := filepath.Join("testdata", "csv", "signed_amount.csv")
+	parser := newTestMappedCsvParser(t, map[string]any{
+		"columns": map[string]any{
+			"date":           "Started Date",
+			"details":        []any{"Description", 5},
+			"originCurrency": "Original Currency",
+			"originAmount":   "Original Amount",
+		},
+	})
+	eurSource := &TransactionsSource{TypeName: mappedCsvTypeName, FilePath: filePath, AccountNumber: "LT001", AccountCurrency: "EUR"}
+	usdSource := &TransactionsSource{TypeName: mappedCsvTypeName, FilePath: filePath, AccountNumber: "LT002", AccountCurrency: "USD"}
+
+	// Act
+	actual, err := parser.ParseRawTransactionsFromFile(filePath)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Transaction{
+		{
+			Date:            time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC),
+			FromAccount:     "LT001",
+			IsExpense:       true,
+			Amount:          Money{int: 3500},
+			Details:         "Coffee shop -3.50",
+			Source:          eurSource,
+			AccountCurrency: "EUR",
+		},
+		{
+			Date:                 time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC),
+			FromAccount:          "LT001",
+			IsExpense:            true,
+			Amount:               Money{int: 1234560},
+			Details:              "Hotel in Yerevan -1,234.56",
+			Source:               eurSource,
+			AccountCurrency:      "EUR",
+			OriginCurrency:       "AMD",
+			OriginCurrencyAmount: Money{int: 530000000},
+		},
+		{
+			Date:            time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC),
+			ToAccount:       "LT002",
+			Amount:          Money{int: 100000},
+			Details:         "Top-up 100",
+			Source:          usdSource,
+			AccountCurrency: "USD",
+		},
+	}
+	if diff := cmp.Diff(expected, actual, moneyComparer); diff != "" {
+		t.Errorf("transactions mismatch (-expected +actual):\n%s", diff)
+	}
+	if actual[0].Source != actual[1].Source {
+		t.Error("expected the same source for transactions of the same account")
+	}
+}
+
+func TestMappedCsvFileParser_NoHeader(t *testing.T) {
+	// Arrange
+	filePath := filepath.Join(t.TempDir(), "no_header.csv")
+	content := "15/03/2024\tTaxi\t1.234,50\n\n16/03/2024\tRefund\t-10\n"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	parser := newTestMappedCsvParser(t, map[string]any{
+		"delimiter":        "tab",
+		"noHeader":         true,
+		"dateFormat":       "02/01/2006",
+		"decimalSeparator": ",",
+		"accountNumber":    "card",
+		"currency":         "AMD",
+		"columns":          map[string]any{"date": 1, "details": 2, "amount": 3},
+	})
+
+	// Act
+	actual, err := parser.ParseRawTransactionsFromFile(filePath)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(actual) != 2 {
+		t.Fatalf("expected 2 transactions, got %+v", actual)
+	}
+	if actual[0].IsExpense || actual[0].Amount.int != 1234500 || actual[0].ToAccount != "card" {
+		t.Errorf("expected income of 1234.50 to 'card', got %+v", actual[0])
+	}
+	if !actual[1].IsExpense || actual[1].Amount.int != 10000 || actual[1].FromAccount != "card" {
+		t.Errorf("expected expense of 10.00 from 'card', got %+v", actual[1])
+	}
+}
+
+func TestMappedCsvFileParser_TimeZone(t *testing.T) {
+	// Arrange
+	filePath := filepath.Join(t.TempDir(), "time_zone.csv")
+	content := "Date,Details,Amount\n2024-03-01 02:30,Taxi,-5\n"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	parser := newTestMappedCsvParser(t, map[string]any{
+		"dateFormat":    "2006-01-02 15:04",
+		"timeZone":      "Asia/Yerevan",
+		"accountNumber": "card",
+		"currency":      "AMD",
+	})
+
+	// Act
+	actual, err := parser.ParseRawTransactionsFromFile(filePath)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(actual) != 1 {
+		t.Fatalf("expected 1 transaction, got %+v", actual)
+	}
+	expected := time.Date(2024, 2, 29, 22, 30, 0, 0, time.UTC)
+	if !actual[0].Date.Equal(expected) || actual[0].Date.Location().String() != "Asia/Yerevan" {
+		t.Errorf("expected %v in Asia/Yerevan, got %v", expected, actual[0].Date)
+	}
+}
+
+func TestMappedCsvFileParser_ParseErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		options      map[string]any
+		errorMessage string
+	}{
+		{
+			name:         "missing_column",
+			content:      "Date,Sum,Details,Account,Currency\n2024-03-01,1,Test,acc,AMD\n",
+			errorMessage: "can't find column 'Amount' in header [Date Sum Details Account Currency]",
+		},
+		{
+			name:         "invalid_date",
+			content:      "Date,Amount,Details,Account,Currency\n2024-03-01,1,Test,acc,AMD\n01.03.2024,1,Test,acc,AMD\n",
+			errorMessage: "line 3: failed to parse date '01.03.2024'",
+		},
+		{
+			name:         "invalid_amount",
+			content:      "Date,Amount,Details,Account,Currency\n2024-03-01,1.2.3,Test,acc,AMD\n",
+			errorMessage: "line 2: failed to parse amount '1.2.3'",
+		},
+		{
+			name:         "empty_account",
+			content:      "Date,Amount,Details,Account,Currency\n2024-03-01,1,Test,,AMD\n",
+			errorMessage: "line 2: account number is empty",
+		},
+		{
+			name:         "both_debit_and_credit",
+			content:      "Date,Out,In,Details\n2024-03-01,1,2,Test\n",
+			options:      map[string]any{"accountNumber": "acc", "currency": "AMD", "columns": map[string]any{"debit": "Out", "credit": "In"}},
+			errorMessage: "line 2: both debit '1' and credit '2' are set",
+		},
+		{
+			name:         "not_enough_rows_to_skip",
+			content:      "Date,Amount,Details,Account,Currency\n",
+			options:      map[string]any{"skipRows": 2},
+			errorMessage: "failed to skip 2 rows: EOF",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			filePath := filepath.Join(t.TempDir(), tt.name+".csv")
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			parser := newTestMappedCsvParser(t, tt.options)
+
+			// Act
+			_, err := parser.ParseRawTransactionsFromFile(filePath)
+
+			// Assert
+			checkErrorContainsSubstring(t, err, tt.errorMessage)
+		})
+	}
+}
+
+func TestNewMappedCsvFileParser_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		options      map[string]any
+		errorMessage string
+	}{
+		{
+			name:         "amount_with_debit",
+			options:      map[string]any{"columns": map[string]any{"amount": "Sum", "debit": "Out"}},
+			errorMessage: "either 'amount' or 'debit' and 'credit' columns should be set, not both",
+		},
+		{
+			name:         "header_without_header_row",
+			options:      map[string]any{"noHeader": true, "columns": map[string]any{"date": 1, "amount": "Sum"}},
+			errorMessage: "column 'Sum' should be set by number because file has no header",
+		},
+		{
+			name:         "long_delimiter",
+			options:      map[string]any{"delimiter": ";;"},
+			errorMessage: "delimiter should be a single character, got ';;'",
+		},
+		{
+			name:         "unknown_encoding",
+			options:      map[string]any{"encoding": "klingon"},
+			errorMessage: "unknown encoding 'klingon'",
+		},
+		{
+			name:         "wrong_column_number",
+			options:      map[string]any{"columns": map[string]any{"date": 0}},
+			errorMessage: "column number should start from 1, got '0'",
+		},
+		{
+			name:         "column_with_header_and_number",
+			options:      map[string]any{"columns": map[string]any{"date": map[string]any{"header": "Date", "number": 1}}},
+			errorMessage: "column should have either header or number starting from 1",
+		},
+		{
+			name:         "wrong_column_pattern",
+			options:      map[string]any{"columns": map[string]any{"counterparty": map[string]any{"header": "Payee", "pattern": "("}}},
+			errorMessage: "wrong pattern of column 'Payee'",
+		},
+		{
+			name:         "wrong_decimal_separator",
+			options:      map[string]any{"decimalSeparator": "_"},
+			errorMessage: "decimal separator should be '.' or ',', got '_'",
+		},
+		{
+			name:         "unknown_time_zone",
+			options:      map[string]any{"timeZone": "Mars/Olympus"},
+			errorMessage: "invalid timezone location 'Mars/Olympus'",
+		},
+		{
+			name:         "unknown_option",
+			options:      map[string]any{"columns": map[string]any{"sum": "Sum"}},
+			errorMessage: "field sum not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			_, err := newMappedCsvFileParser(tt.options, &Config{})
+
+			// Assert
+			checkErrorContainsSubstring(t, err, tt.errorMessage)
+		})
+	}
+}
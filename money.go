@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// moneyDecimalPlaces is a number of digits after the decimal point stored in Money.
+// It is 3 to keep amounts in currencies like KWD exactly. Amounts without currency are shown with 2 digits.
+const moneyDecimalPlaces = 3
+
+// moneyUnitsInOne is a number of stored units in one whole unit of the currency, 10^moneyDecimalPlaces.
+const moneyUnitsInOne = 1000
+
+// displayDecimalPlaces is a number of digits after the decimal point in amounts shown without currency.
+const displayDecimalPlaces = 2
+
+// maxDecimalDigits is a maximal number of significant digits which fit into int64 without overflow.
+const maxDecimalDigits = 18
+
+// currencyMinorUnits are numbers of digits after the decimal point by ISO 4217
+// for currencies which don't use 2 digits. Amounts in currencies with 4 digits (CLF, UYW) are stored,
+// rounded and shown with `moneyDecimalPlaces` digits only, i.e. the 4th digit is rounded on parsing.
+var currencyMinorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// CurrencyMinorUnits returns number of digits after the decimal point of the currency by ISO 4217.
+// Returns 2 for unknown currencies.
+func CurrencyMinorUnits(currency string) int {
+	if units, ok := currencyMinorUnits[currency]; ok {
+		return units
+	}
+	return 2
+}
+
+// pow10 returns 10 in power n for small non-negative n.
+func pow10(n int) int {
+	result := 1
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
+
+// parseDecimal parses decimal number like "-1234.565" or "1.5E+3" without float rounding errors.
+// Returns the number as integer count of 10^-scale units, rounded half away from zero.
+// Errors are the same as ones of `strconv.ParseFloat`.
+func parseDecimal(s string, scale int) (int, error) {
+	syntaxError := &strconv.NumError{Func: "ParseFloat", Num: s, Err: strconv.ErrSyntax}
+	text := strings.TrimSpace(s)
+	isNegative := false
+	if len(text) > 0 && (text[0] == '-' || text[0] == '+') {
+		isNegative = text[0] == '-'
+		text = text[1:]
+	}
+	mantissa, exponentText, hasExponent := strings.Cut(strings.ToLower(text), "e")
+	exponent := 0
+	if hasExponent {
+		var err error
+		exponent, err = strconv.Atoi(exponentText)
+		if err != nil {
+			return 0, syntaxError
+		}
+	}
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	if len(intPart)+len(fracPart) == 0 {
+		return 0, syntaxError
+	}
+	for _, part := range []string{intPart, fracPart} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return 0, syntaxError
+			}
+		}
+	}
+
+	// Value is digits * 10^shift units.
+	digits := strings.TrimLeft(intPart+fracPart, "0")
+	shift := exponent - len(fracPart) + scale
+	if digits == "" {
+		return 0, nil
+	}
+	rangeError := &strconv.NumError{Func: "ParseFloat", Num: s, Err: strconv.ErrRange}
+	roundUp := false
+	if shift < 0 {
+		// Drop extra digits and remember if the first dropped digit requires rounding up.
+		if -shift <= len(digits) {
+			roundUp = digits[len(digits)+shift] >= '5'
+			digits = digits[:len(digits)+shift]
+		} else {
+			digits = ""
+		}
+		shift = 0
+	}
+	if len(digits)+shift > maxDecimalDigits {
+		return 0, rangeError
+	}
+	result := 0
+	if digits != "" {
+		value, err := strconv.Atoi(digits)
+		if err != nil {
+			return 0, rangeError
+		}
+		result = value * pow10(shift)
+	}
+	if roundUp {
+		result++
+	}
+	if isNegative {
+		result = -result
+	}
+	return result, nil
+}
+
+// roundHalfAwayFromZero rounds number of money units calculated with floats, like after currency conversion.
+func roundHalfAwayFromZero(value float64) int {
+	return int(math.Round(value))
+}
+
+// minorUnitSize returns number of stored money units in one minor unit of the currency, like 10 for USD cents.
+// It is 1 for currencies with more digits than stored.
+func minorUnitSize(currency string) int {
+	return pow10(max(moneyDecimalPlaces-CurrencyMinorUnits(currency), 0))
+}
+
+// roundToMinorUnits rounds number of money units calculated with floats to minor units of the currency at once,
+// so there is no double rounding like 28.5049 -> 28.505 -> 28.51 for USD.
+func roundToMinorUnits(value float64, currency string) int {
+	minorUnit := minorUnitSize(currency)
+	return roundHalfAwayFromZero(value/float64(minorUnit)) * minorUnit
+}
+
+// moneyFromFloat returns amount for number of whole currency units, like 12.5 from configuration.
+func moneyFromFloat(value float64) Money {
+	return Money{int: roundHalfAwayFromZero(value * moneyUnitsInOne)}
+}
+
+// Float returns amount as number of whole currency units, like 12.5. For charts and statistics only.
+func (m Money) Float() float64 {
+	return float64(m.int) / moneyUnitsInOne
+}
+
+// roundToDecimalPlaces returns amount rounded half away from zero to the number of digits after the decimal point.
+func (m Money) roundToDecimalPlaces(decimalPlaces int) Money {
+	if decimalPlaces >= moneyDecimalPlaces {
+		return m
+	}
+	step := pow10(moneyDecimalPlaces - decimalPlaces)
+	remainder := m.int % step
+	result := m.int - remainder
+	if remainder*2 >= step {
+		result += step
+	} else if remainder*2 <= -step {
+		result -= step
+	}
+	return Money{int: result}
+}
+
+// format returns amount like "-1,234.56" rounded to the number of digits after the decimal point
+// with digits of the integer part grouped by the thousands separator.
+func (m Money) format(decimalPlaces int, thousandsSeparator string) string {
+	decimalPlaces = min(decimalPlaces, moneyDecimalPlaces)
+	amount := m.roundToDecimalPlaces(decimalPlaces).int
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	intString := strconv.Itoa(amount / moneyUnitsInOne)
+	for i := len(intString) - 3; i > 0; i -= 3 {
+		intString = intString[:i] + thousandsSeparator + intString[i:]
+	}
+	if decimalPlaces == 0 {
+		return sign + intString
+	}
+	fracString := fmt.Sprintf("%0*d", moneyDecimalPlaces, amount%moneyUnitsInOne)
+	return sign + intString + "." + fracString[:decimalPlaces]
+}
+
+// RoundToCurrency rounds amount to minor units of the currency, i.e. to whole yens for JPY and to cents for USD.
+func (m Money) RoundToCurrency(currency string) Money {
+	return m.roundToDecimalPlaces(CurrencyMinorUnits(currency))
+}
+
+// StringInCurrency returns amount with thousands separators and number of digits after the decimal point
+// as ISO 4217 defines for the currency, like "1,000" for JPY or "1.250" for KWD.
+func (m Money) StringInCurrency(currency string) string {
+	return m.format(CurrencyMinorUnits(currency), ",")
+}
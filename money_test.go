@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"testing/quick"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input    string
+		scale    int
+		expected int
+		err      error
+	}{
+		{"0.29", 2, 29, nil},
+		{"1.005", 2, 101, nil},
+		{"-1.005", 2, -101, nil},
+		{"1.004", 2, 100, nil},
+		{"  +123.4 ", 2, 12340, nil},
+		{".5", 0, 1, nil},
+		{"5.", 2, 500, nil},
+		{"0.0049", 2, 0, nil},
+		{"1.5E+3", 2, 150000, nil},
+		{"1.2345e2", 2, 12345, nil},
+		{"12345e-5", 3, 123, nil},
+		{"1e-400", 2, 0, nil},
+		{"000000000000000000000001.00", 2, 100, nil},
+		{"1234.5678", 4, 12345678, nil},
+		{"1e400", 2, 0, strconv.ErrRange},
+		{"", 2, 0, strconv.ErrSyntax},
+		{".", 2, 0, strconv.ErrSyntax},
+		{"abc", 2, 0, strconv.ErrSyntax},
+		{"1.2.3", 2, 0, strconv.ErrSyntax},
+		{"1e", 2, 0, strconv.ErrSyntax},
+		{"1 000", 2, 0, strconv.ErrSyntax},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s_%d", tt.input, tt.scale), func(t *testing.T) {
+
+			// Act
+			actual, err := parseDecimal(tt.input, tt.scale)
+
+			// Assert
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseDecimal_Properties(t *testing.T) {
+	// formatUnits returns units as decimal string with `scale` digits after the point.
+	formatUnits := func(units int64, scale int) string {
+		sign := ""
+		if units < 0 {
+			sign = "-"
+			units = -units
+		}
+		divisor := int64(pow10(scale))
+		if scale == 0 {
+			return fmt.Sprintf("%s%d", sign, units)
+		}
+		return fmt.Sprintf("%s%d.%0*d", sign, units/divisor, scale, units%divisor)
+	}
+	properties := map[string]interface{}{
+		"round_trip": func(units int32, scale uint8) bool {
+			s := int(scale % 5)
+			actual, err := parseDecimal(formatUnits(int64(units), s), s)
+			return err == nil && actual == int(units)
+		},
+		"half_away_from_zero": func(units int32, digit uint8) bool {
+			extra := int64(digit % 10)
+			value := int64(units)*10 + extra
+			if units < 0 {
+				value = int64(units)*10 - extra
+			}
+			expected := int(units)
+			if extra >= 5 {
+				if value < 0 {
+					expected--
+				} else {
+					expected++
+				}
+			}
+			actual, err := parseDecimal(formatUnits(value, 3), 2)
+			return err == nil && actual == expected
+		},
+		"exponent_moves_point": func(units int32, exponent uint8) bool {
+			e := int(exponent % 8)
+			expected, _ := parseDecimal(strconv.Itoa(int(units)), 2)
+			actual, err := parseDecimal(fmt.Sprintf("%de-%d", int64(units)*int64(pow10(e)), e), 2)
+			return err == nil && actual == expected
+		},
+		"money_string_round_trip": func(cents int32) bool {
+			var actual Money
+			err := actual.ParseString(Money{int: int(cents) * 10}.StringNoIndent())
+			return err == nil && actual.int == int(cents)*10
+		},
+		"three_digits_currency_string_round_trip": func(units int32) bool {
+			var actual Money
+			err := actual.ParseString(Money{int: int(units)}.StringInCurrency("KWD"))
+			return err == nil && actual.int == int(units)
+		},
+	}
+	for name, property := range properties {
+		t.Run(name, func(t *testing.T) {
+			if err := quick.Check(property, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestRoundToCurrency(t *testing.T) {
+	tests := []struct {
+		amount   int
+		currency string
+		expected int
+		asString string
+	}{
+		{1234560, "AMD", 1234560, "1,234.56"},
+		{1234565, "AMD", 1234570, "1,234.57"},
+		{-1234565, "USD", -1234570, "-1,234.57"},
+		{1234560, "JPY", 1235000, "1,235"},
+		{1234490, "JPY", 1234000, "1,234"},
+		{-1234500, "JPY", -1235000, "-1,235"},
+		{-500, "KRW", -1000, "-1"},
+		{490, "KRW", 0, "0"},
+		{1234567, "KWD", 1234567, "1,234.567"},
+		{-5, "BHD", -5, "-0.005"},
+		{1234567, "CLF", 1234567, "1,234.567"},
+		{-5, "UYW", -5, "-0.005"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d_%s", tt.amount, tt.currency), func(t *testing.T) {
+			// Arrange
+			amount := Money{int: tt.amount}
+
+			// Act
+			actual := amount.RoundToCurrency(tt.currency)
+			actualString := amount.StringInCurrency(tt.currency)
+
+			// Assert
+			if actual.int != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, actual.int)
+			}
+			if actualString != tt.asString {
+				t.Errorf("expected %q, got %q", tt.asString, actualString)
+			}
+		})
+	}
+}
+
+func TestMoney_StringWithoutCurrency(t *testing.T) {
+	tests := []struct {
+		amount       int
+		expected     string
+		expectedJSON string
+	}{
+		{1234567, "1,234.57", `"1 234.57"`},
+		{-1234565, "-1,234.57", `"-1 234.57"`},
+		{1000000, "1,000.00", `"1 000.00"`},
+		{-5, "-0.01", `"-0.01"`},
+		{0, "0.00", `"0.00"`},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.amount), func(t *testing.T) {
+			// Arrange
+			amount := Money{int: tt.amount}
+
+			// Act
+			actual := amount.StringNoIndent()
+			actualJSON, err := json.Marshal(amount)
+
+			// Assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+			if string(actualJSON) != tt.expectedJSON {
+				t.Errorf("expected JSON %s, got %s", tt.expectedJSON, actualJSON)
+			}
+		})
+	}
+}
+
+func TestRoundToCurrency_Properties(t *testing.T) {
+	properties := map[string]interface{}{
+		"idempotent": func(cents int32) bool {
+			once := Money{int: int(cents)}.RoundToCurrency("JPY")
+			return once.RoundToCurrency("JPY") == once
+		},
+		"whole_units_and_close": func(cents int32) bool {
+			rounded := Money{int: int(cents)}.RoundToCurrency("JPY")
+			difference := rounded.int - int(cents)
+			return rounded.int%1000 == 0 && difference <= 500 && difference >= -500
+		},
+		"symmetric": func(cents int32) bool {
+			positive := Money{int: int(cents)}.RoundToCurrency("JPY")
+			negative := Money{int: -int(cents)}.RoundToCurrency("JPY")
+			return positive.int == -negative.int
+		},
+	}
+	for name, property := range properties {
+		t.Run(name, func(t *testing.T) {
+			if err := quick.Check(property, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestAtLeast1MinorUnit(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		expected int
+	}{
+		{28504.9, "USD", 28500},
+		{-28505, "USD", -28510},
+		{0.4, "USD", 10},
+		{12345600, "JPY", 12346000},
+		{10, "JPY", 1000},
+		{1234.5, "KWD", 1235},
+		{0.4, "KWD", 1},
+		{0.4, "CLF", 1},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v_%s", tt.amount, tt.currency), func(t *testing.T) {
+
+			// Act
+			actual := atLeast1MinorUnit(tt.amount, tt.currency)
+
+			// Assert
+			if actual.int != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, actual.int)
+			}
+		})
+	}
+}
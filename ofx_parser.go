@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// OfxParserName is a name of parser to use in `sources` configuration.
+	OfxParserName = "ofx"
+	ofxTypeName   = "OFX/QFX statement"
+	// ofxDateFormat is a layout of the date part of OFX dates like "20240131120000.000[-5:EST]".
+	ofxDateFormat = "20060102"
+)
+
+func init() {
+	RegisterParser(ParserRegistration{
+		Name:        OfxParserName,
+		TypeName:    ofxTypeName,
+		Tag:         "Ofx",
+		Version:     2,
+		DefaultGlob: "*.[oq]fx",
+		NewParser:   newParserWithoutOptions(OfxFileParser{}),
+		Detect: func(sniff *FileSniff) bool {
+			return sniff.XmlRoot == "OFX" || sniff.HasRowWithPrefix("OFXHEADER:")
+		},
+	})
+}
+
+// OfxFileParser parses OFX 1.x (SGML) and OFX 2.x (XML) files, QFX files are OFX files too.
+// Each bank or credit card statement of the file becomes a separate source.
+type OfxFileParser struct {
+}
+
+// ofxElement is an element of OFX file. Aggregates have children, elements with value don't.
+type ofxElement struct {
+	name     string
+	value    string
+	children []*ofxElement
+}
+
+// child returns the first child element by path of names or nil.
+func (e *ofxElement) child(path ...string) *ofxElement {
+	current := e
+	for _, name := range path {
+		var found *ofxElement
+		for _, child := range current.children {
+			if child.name == name {
+				found = child
+				break
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		current = found
+	}
+	return current
+}
+
+// text returns value of the child element by path of names or empty string.
+func (e *ofxElement) text(path ...string) string {
+	if child := e.child(path...); child != nil {
+		return child.value
+	}
+	return ""
+}
+
+// findAll returns all descendant elements with one of names in document order.
+func (e *ofxElement) findAll(names ...string) []*ofxElement {
+	result := make([]*ofxElement, 0)
+	for _, child := range e.children {
+		for _, name := range names {
+			if child.name == name {
+				result = append(result, child)
+			}
+		}
+		result = append(result, child.findAll(names...)...)
+	}
+	return result
+}
+
+var (
+	ofxSgmlCharsetRegexp = regexp.MustCompile(`(?m)^CHARSET:\s*(\S+)`)
+	ofxXmlEncodingRegexp = regexp.MustCompile(`<\?xml[^>]*encoding="([^"]+)"`)
+)
+
+// ofxEncoding returns name of encoding from OFX header, "utf-8" if it is not specified.
+// OFX 1.x header has codepage number like "CHARSET:1252", OFX 2.x has XML declaration.
+func ofxEncoding(header []byte) string {
+	if matches := ofxXmlEncodingRegexp.FindSubmatch(header); matches != nil {
+		return string(matches[1])
+	}
+	if matches := ofxSgmlCharsetRegexp.FindSubmatch(header); matches != nil {
+		charset := string(matches[1])
+		if _, err := strconv.Atoi(charset); err == nil {
+			return "windows-" + charset
+		}
+		if !strings.EqualFold(charset, "NONE") {
+			return charset
+		}
+	}
+	return "utf-8"
+}
+
+// parseOfx parses body of OFX file into tree of elements with root "OFX" element.
+// Supports both SGML with not closed elements and XML. Values are unescaped and trimmed.
+func parseOfx(data []byte) (*ofxElement, error) {
+	start := bytes.Index(bytes.ToUpper(data), []byte("<OFX>"))
+	if start < 0 {
+		return nil, errors.New("can't find <OFX> element")
+	}
+	body := string(data[start:])
+	root := &ofxElement{}
+	stack := []*ofxElement{root}
+	for len(body) > 0 {
+		tagStart := strings.IndexByte(body, '<')
+		if tagStart < 0 {
+			break
+		}
+		tagEnd := strings.IndexByte(body[tagStart:], '>')
+		if tagEnd < 0 {
+			return nil, fmt.Errorf("not closed tag '%s'", body[tagStart:])
+		}
+		tag := body[tagStart+1 : tagStart+tagEnd]
+		body = body[tagStart+tagEnd+1:]
+		switch {
+		case strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!"):
+			// Skip processing instructions and comments.
+			continue
+		case strings.HasPrefix(tag, "/"):
+			// Close aggregate and all not closed elements inside it.
+			name := strings.ToUpper(strings.TrimSpace(tag[1:]))
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+			continue
+		}
+
+		parent := stack[len(stack)-1]
+		isEmpty := strings.HasSuffix(tag, "/")
+		element := &ofxElement{name: strings.ToUpper(strings.TrimSpace(strings.TrimSuffix(tag, "/")))}
+		parent.children = append(parent.children, element)
+		if isEmpty {
+			continue
+		}
+		valueEnd := strings.IndexByte(body, '<')
+		if valueEnd < 0 {
+			valueEnd = len(body)
+		}
+		value := strings.TrimSpace(body[:valueEnd])
+		closingTag := "</" + element.name + ">"
+		switch {
+		case value != "":
+			// Element with value, closed in XML and not closed in SGML.
+			element.value = html.UnescapeString(value)
+			body = body[valueEnd:]
+			if len(body) >= len(closingTag) && strings.EqualFold(body[:len(closingTag)], closingTag) {
+				body = body[len(closingTag):]
+			}
+		case len(body[valueEnd:]) >= len(closingTag) && strings.EqualFold(body[valueEnd:valueEnd+len(closingTag)], closingTag):
+			// Element with empty value.
+			body = body[valueEnd+len(closingTag):]
+		default:
+			stack = append(stack, element)
+		}
+	}
+	ofx := root.child("OFX")
+	if ofx == nil {
+		return nil, errors.New("can't find <OFX> element")
+	}
+	return ofx, nil
+}
+
+// parseOfxDate parses date like "20240131", "20240131120000" or "20240131120000.000[-5:EST]".
+// Time and time zone are ignored because the date is already in the time zone of the bank.
+func parseOfxDate(s string) (time.Time, error) {
+	if len(s) < len(ofxDateFormat) {
+		return time.Time{}, fmt.Errorf("date '%s' is too short", s)
+	}
+	return parseDate(s[:len(ofxDateFormat)], ofxDateFormat)
+}
+
+// parseOfxAmount parses amount like "-12.50" or "-12,50". OFX amounts shouldn't have thousands separators
+// and may use '.' or ',' as decimal separator, so single ',' or '.' is always the decimal point.
+// Amounts with several separators, like "-1,234.56" from some banks, are parsed with guessed separators.
+func parseOfxAmount(s string) (Money, error) {
+	switch {
+	case strings.Count(s, ",")+strings.Count(s, ".") > 1:
+		return ParseAmount(s)
+	case strings.Contains(s, ","):
+		return AmountFormat{DecimalSeparator: ','}.Parse(s)
+	default:
+		return AmountFormat{DecimalSeparator: '.'}.Parse(s)
+	}
+}
+
+// parseOfxRate parses currency rate like "1.0837" or "1,0837".
+func parseOfxRate(s string) (float64, error) {
+	return strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+}
+
+func (p OfxFileParser) ParseRawTransactionsFromFile(filePath string) ([]Transaction, error) {
+	fileData, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	header := fileData
+	if index := bytes.Index(bytes.ToUpper(fileData), []byte("<OFX>")); index >= 0 {
+		header = fileData[:index]
+	}
+	text, err := decodeText(fileData, ofxEncoding(header))
+	if err != nil {
+		return nil, err
+	}
+	ofx, err := parseOfx(text)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]Transaction, 0)
+	for _, statement := range ofx.findAll("STMTRS", "CCSTMTRS") {
+		statementTransactions, err := parseOfxStatement(statement, filePath)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, statementTransactions...)
+	}
+	return transactions, nil
+}
+
+// parseOfxStatement parses bank (STMTRS) or credit card (CCSTMTRS) statement.
+func parseOfxStatement(statement *ofxElement, filePath string) ([]Transaction, error) {
+	accountFrom := "BANKACCTFROM"
+	if statement.name == "CCSTMTRS" {
+		accountFrom = "CCACCTFROM"
+	}
+	accountNumber := statement.text(accountFrom, "ACCTID")
+	if accountNumber == "" {
+		return nil, fmt.Errorf("%s doesn't have %s.ACCTID", statement.name, accountFrom)
+	}
+	currency := statement.text("CURDEF")
+	if currency == "" {
+		return nil, fmt.Errorf("%s of '%s' account doesn't have CURDEF", statement.name, accountNumber)
+	}
+	source := &TransactionsSource{
+		TypeName:        ofxTypeName,
+		FilePath:        filePath,
+		AccountNumber:   accountNumber,
+		AccountCurrency: currency,
+	}
+
+	// Ledger balance is as of the end of the day.
+	if ledgerBalance := statement.child("LEDGERBAL"); ledgerBalance != nil {
+		amount, err := parseOfxAmount(ledgerBalance.text("BALAMT"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse LEDGERBAL.BALAMT of '%s' account: %w", accountNumber, err)
+		}
+		date, err := parseOfxDate(ledgerBalance.text("DTASOF"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse LEDGERBAL.DTASOF of '%s' account: %w", accountNumber, err)
+		}
+		source.ClosingBalance = &BalanceRecord{Date: date.AddDate(0, 0, 1), Amount: amount}
+	}
+
+	transactions := make([]Transaction, 0)
+	for _, record := range statement.findAll("STMTTRN") {
+		transaction, err := parseOfxTransaction(record, source)
+		if err != nil {
+			id := record.text("FITID")
+			return nil, fmt.Errorf("failed to parse '%s' transaction of '%s' account: %w", id, accountNumber, err)
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, nil
+}
+
+// parseOfxTransaction converts STMTTRN element into transaction.
+func parseOfxTransaction(record *ofxElement, source *TransactionsSource) (Transaction, error) {
+	date, err := parseOfxDate(record.text("DTPOSTED"))
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to parse DTPOSTED: %w", err)
+	}
+	amount, err := parseOfxAmount(record.text("TRNAMT"))
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to parse TRNAMT: %w", err)
+	}
+	isExpense := amount.int < 0
+	if isExpense {
+		amount.int = -amount.int
+	}
+
+	// Amount may be in other currency with rate to account currency (CURRENCY),
+	// or may be converted into account currency from other currency (ORIGCURRENCY).
+	var originCurrency string
+	var originAmount Money
+	for _, name := range []string{"CURRENCY", "ORIGCURRENCY"} {
+		element := record.child(name)
+		if element == nil || element.text("CURSYM") == "" || element.text("CURSYM") == source.AccountCurrency {
+			continue
+		}
+		rate, err := parseOfxRate(element.text("CURRATE"))
+		if err != nil || rate <= 0 {
+			return Transaction{}, fmt.Errorf("wrong %s.CURRATE '%s'", name, element.text("CURRATE"))
+		}
+		originCurrency = element.text("CURSYM")
+		if name == "CURRENCY" {
+			originAmount = amount
+			amount = Money{int: roundToMinorUnits(float64(amount.int)*rate, source.AccountCurrency)}
+		} else {
+			originAmount = Money{int: roundToMinorUnits(float64(amount.int)/rate, originCurrency)}
+		}
+	}
+
+	details := make([]string, 0, 3)
+	for _, value := range []string{record.text("NAME"), record.text("PAYEE", "NAME"), record.text("MEMO")} {
+		if value != "" && (len(details) == 0 || details[len(details)-1] != value) {
+			details = append(details, value)
+		}
+	}
+	counterparty := record.text("BANKACCTTO", "ACCTID")
+	if counterparty == "" {
+		counterparty = record.text("CCACCTTO", "ACCTID")
+	}
+	transaction := Transaction{
+		Date:                 date,
+		IsExpense:            isExpense,
+		Amount:               amount,
+		Details:              strings.Join(details, " "),
+		Source:               source,
+		AccountCurrency:      source.AccountCurrency,
+		OriginCurrency:       originCurrency,
+		OriginCurrencyAmount: originAmount,
+		ID:                   record.text("FITID"),
+	}
+	if isExpense {
+		transaction.FromAccount, transaction.ToAccount = source.AccountNumber, counterparty
+	} else {
+		transaction.FromAccount, transaction.ToAccount = counterparty, source.AccountNumber
+	}
+	return transaction, nil
+}
+
+var _ FileParser = OfxFileParser{}
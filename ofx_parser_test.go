@@ -0,0 +1,272 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOfxFileParser_Sgml(t *testing.T) {
+	// Arrange
+	filePath := filepath.Join("testdata", "ofx", "checking_sgml.ofx")
+	source := &TransactionsSource{
+		TypeName:        ofxTypeName,
+		FilePath:        filePath,
+		AccountNumber:   "BE12 3456 7890 1234",
+		AccountCurrency: "EUR",
+		ClosingBalance:  &BalanceRecord{Date: utcDate(2024, 4, 1), Amount: Money{int: 12483160}},
+	}
+
+	// Act
+	actual, err := OfxFileParser{}.ParseRawTransactionsFromFile(filePath)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Transaction{
+		{
+			Date:            utcDate(2024, 3, 1),
+			FromAccount:     "BE98 7654 3210 9876",
+			ToAccount:       "BE12 3456 7890 1234",
+			Amount:          Money{int: 2500000},
+			Details:         "ACME Corp Salary March",
+			Source:          source,
+			AccountCurrency: "EUR",
+			ID:              "TRANSFER-1001",
+		},
+		{
+			Date:            utcDate(2024, 3, 5),
+			FromAccount:     "BE12 3456 7890 1234",
+			IsExpense:       true,
+			Amount:          Money{int: 4500},
+			Details:         "Café & Bar",
+			Source:          source,
+			AccountCurrency: "EUR",
+			ID:              "CARD-2002",
+		},
+		{
+			Date:                 utcDate(2024, 3, 10),
+			FromAccount:          "BE12 3456 7890 1234",
+			IsExpense:            true,
+			Amount:               Money{int: 12340},
+			Details:              "Hotel Yerevan 5,000.00 AMD",
+			Source:               source,
+			AccountCurrency:      "EUR",
+			OriginCurrency:       "AMD",
+			OriginCurrencyAmount: Money{int: 5000000},
+			ID:                   "CARD-2003",
+		},
+	}
+	if diff := cmp.Diff(expected, actual, moneyComparer); diff != "" {
+		t.Errorf("transactions mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestOfxFileParser_XmlWithSeveralStatements(t *testing.T) {
+	// Arrange
+	filePath := filepath.Join("testdata", "ofx", "accounts_xml.qfx")
+	checkingSource := &TransactionsSource{
+		TypeName:        ofxTypeName,
+		FilePath:        filePath,
+		AccountNumber:   "8310012345",
+		AccountCurrency: "USD",
+		ClosingBalance:  &BalanceRecord{Date: utcDate(2024, 4, 1), Amount: Money{int: -865440}},
+	}
+	cardSource := &TransactionsSource{
+		TypeName:        ofxTypeName,
+		FilePath:        filePath,
+		AccountNumber:   "4111111111111111",
+		AccountCurrency: "USD",
+	}
+
+	// Act
+	actual, err := OfxFileParser{}.ParseRawTransactionsFromFile(filePath)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Transaction{
+		{
+			Date:            utcDate(2024, 3, 2),
+			FromAccount:     "8310012345",
+			IsExpense:       true,
+			Amount:          Money{int: 1234560},
+			Details:         "Rent <March>",
+			Source:          checkingSource,
+			AccountCurrency: "USD",
+			ID:              "TRANSACTION-3001",
+		},
+		{
+			Date:                 utcDate(2024, 3, 15),
+			ToAccount:            "8310012345",
+			Amount:               Money{int: 108500},
+			Details:              "Mom Gift",
+			Source:               checkingSource,
+			AccountCurrency:      "USD",
+			OriginCurrency:       "EUR",
+			OriginCurrencyAmount: Money{int: 100000},
+			ID:                   "TRANSACTION-3002",
+		},
+		{
+			Date:            utcDate(2024, 3, 20),
+			FromAccount:     "4111111111111111",
+			IsExpense:       true,
+			Amount:          Money{int: 15000},
+			Details:         "Streaming service",
+			Source:          cardSource,
+			AccountCurrency: "USD",
+			ID:              "CC-4001",
+		},
+	}
+	if diff := cmp.Diff(expected, actual, moneyComparer); diff != "" {
+		t.Errorf("transactions mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestOfxFileParser_CommaDecimalSeparator(t *testing.T) {
+	// Arrange
+	filePath := filepath.Join("testdata", "ofx", "comma_decimal_sgml.ofx")
+	source := &TransactionsSource{
+		TypeName:        ofxTypeName,
+		FilePath:        filePath,
+		AccountNumber:   "5550001111",
+		AccountCurrency: "USD",
+		ClosingBalance:  &BalanceRecord{Date: utcDate(2024, 6, 1), Amount: Money{int: 1234500}},
+	}
+
+	// Act
+	actual, err := OfxFileParser{}.ParseRawTransactionsFromFile(filePath)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Transaction{
+		{
+			Date:            utcDate(2024, 5, 2),
+			FromAccount:     "5550001111",
+			IsExpense:       true,
+			Amount:          Money{int: 12500},
+			Details:         "Coffee",
+			Source:          source,
+			AccountCurrency: "USD",
+			ID:              "COMMA-5001",
+		},
+		{
+			// 10.00 EUR * 1.08371 = 10.8371 USD is rounded to cents.
+			Date:                 utcDate(2024, 5, 10),
+			FromAccount:          "5550001111",
+			IsExpense:            true,
+			Amount:               Money{int: 10840},
+			Details:              "Museum Paris",
+			Source:               source,
+			AccountCurrency:      "USD",
+			OriginCurrency:       "EUR",
+			OriginCurrencyAmount: Money{int: 10000},
+			ID:                   "COMMA-5002",
+		},
+		{
+			// 20.00 USD / 0.0026 = 7692.3077 AMD is rounded to luma.
+			Date:                 utcDate(2024, 5, 20),
+			FromAccount:          "5550001111",
+			IsExpense:            true,
+			Amount:               Money{int: 20000},
+			Details:              "Hotel Yerevan",
+			Source:               source,
+			AccountCurrency:      "USD",
+			OriginCurrency:       "AMD",
+			OriginCurrencyAmount: Money{int: 7692310},
+			ID:                   "COMMA-5003",
+		},
+	}
+	if diff := cmp.Diff(expected, actual, moneyComparer); diff != "" {
+		t.Errorf("transactions mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestOfxFileParser_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		errorMessage string
+	}{
+		{
+			name:         "not_ofx",
+			content:      "Date,Amount\n2024-03-01,1\n",
+			errorMessage: "can't find <OFX> element",
+		},
+		{
+			name:         "no_account",
+			content:      "<OFX><STMTRS><CURDEF>USD</STMTRS></OFX>",
+			errorMessage: "STMTRS doesn't have BANKACCTFROM.ACCTID",
+		},
+		{
+			name:         "no_currency",
+			content:      "<OFX><STMTRS><BANKACCTFROM><ACCTID>1</BANKACCTFROM></STMTRS></OFX>",
+			errorMessage: "STMTRS of '1' account doesn't have CURDEF",
+		},
+		{
+			name: "invalid_amount",
+			content: "<OFX><STMTRS><CURDEF>USD<BANKACCTFROM><ACCTID>1</BANKACCTFROM><BANKTRANLIST>" +
+				"<STMTTRN><DTPOSTED>20240301<TRNAMT>ten<FITID>F1</STMTTRN></BANKTRANLIST></STMTRS></OFX>",
+			errorMessage: "failed to parse 'F1' transaction of '1' account: failed to parse TRNAMT",
+		},
+		{
+			name: "invalid_date",
+			content: "<OFX><STMTRS><CURDEF>USD<BANKACCTFROM><ACCTID>1</BANKACCTFROM><BANKTRANLIST>" +
+				"<STMTTRN><DTPOSTED>2024<TRNAMT>1<FITID>F1</STMTTRN></BANKTRANLIST></STMTRS></OFX>",
+			errorMessage: "failed to parse DTPOSTED: date '2024' is too short",
+		},
+		{
+			name: "wrong_rate",
+			content: "<OFX><STMTRS><CURDEF>USD<BANKACCTFROM><ACCTID>1</BANKACCTFROM><BANKTRANLIST>" +
+				"<STMTTRN><DTPOSTED>20240301<TRNAMT>1<FITID>F1<CURRENCY><CURRATE>0<CURSYM>EUR</CURRENCY>" +
+				"</STMTTRN></BANKTRANLIST></STMTRS></OFX>",
+			errorMessage: "wrong CURRENCY.CURRATE '0'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			filePath := filepath.Join(t.TempDir(), tt.name+".ofx")
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			_, err := OfxFileParser{}.ParseRawTransactionsFromFile(filePath)
+
+			// Assert
+			checkErrorContainsSubstring(t, err, tt.errorMessage)
+		})
+	}
+}
+
+func TestOfxEncoding(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"OFXHEADER:100\r\nDATA:OFXSGML\r\nCHARSET:1252\r\n", "windows-1252"},
+		{"OFXHEADER:100\nCHARSET:ISO-8859-1\n", "ISO-8859-1"},
+		{"OFXHEADER:100\nCHARSET:NONE\n", "utf-8"},
+		{`<?xml version="1.0" encoding="US-ASCII"?>`, "US-ASCII"},
+		{"", "utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+
+			// Act
+			actual := ofxEncoding([]byte(tt.header))
+
+			// Assert
+			if actual != tt.expected {
+				t.Errorf("expected '%s', got '%s'", tt.expected, actual)
+			}
+		})
+	}
+}
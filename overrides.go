@@ -0,0 +1,166 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CategoryOverridesFileName is a name of file with manual categories of single transactions,
+// it is placed next to the configuration file.
+const CategoryOverridesFileName = "category_overrides.yaml"
+
+// CategoryOverride pins category of one transaction.
+type CategoryOverride struct {
+	// Group is a name of the group to put transaction into.
+	Group string `yaml:"group"`
+	// Transaction is a human-readable description of the transaction, only to simplify manual editing of the file.
+	Transaction string `yaml:"transaction,omitempty"`
+}
+
+// categoryOverridesFile is a structure of file with category overrides.
+type categoryOverridesFile struct {
+	// Overrides is a map from transaction fingerprint to the override.
+	Overrides map[string]*CategoryOverride `yaml:"overrides"`
+	// Splits is a map from transaction fingerprint to the split of transaction across categories.
+	Splits map[string]*TransactionSplit `yaml:"splits,omitempty"`
+}
+
+// TransactionFingerprint returns identifier of the transaction which doesn't depend on categorization
+// and on the file name, so stays the same after re-export of statements.
+// It is built from source type, account, date, direction, amount and hash of details.
+// Note that identical transactions in the same day have the same fingerprint
+// unless the bank provides transaction ID which is used instead of other fields.
+func TransactionFingerprint(tr *Transaction) string {
+	source := ""
+	if tr.Source != nil {
+		source = tr.Source.Tag
+	}
+	account := tr.ToAccount
+	if tr.IsExpense {
+		account = tr.FromAccount
+	}
+	if tr.ID != "" {
+		hash := sha256.Sum256([]byte(strings.Join([]string{source, account, tr.ID}, "|")))
+		return hex.EncodeToString(hash[:12])
+	}
+	detailsHash := sha256.Sum256([]byte(tr.Details))
+	key := strings.Join([]string{
+		source,
+		account,
+		tr.Date.Format(OutputDateFormat),
+		fmt.Sprint(tr.IsExpense),
+		fmt.Sprint(tr.Amount.int),
+		tr.AccountCurrency,
+		hex.EncodeToString(detailsHash[:]),
+	}, "|")
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:12])
+}
+
+// describeTransaction returns short description of transaction for the overrides file.
+func describeTransaction(tr *Transaction) string {
+	return fmt.Sprintf("%s %s %s %s",
+		tr.Date.Format(OutputDateFormat), tr.Amount.StringNoIndent(), tr.AccountCurrency, tr.Details)
+}
+
+// getCategoryOverridesPath returns path to the category overrides file for the configuration file.
+func getCategoryOverridesPath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), CategoryOverridesFileName)
+}
+
+// readCategoryOverrides reads category overrides and splits from the file.
+// Missing file means no overrides and splits.
+func readCategoryOverrides(path string) (*categoryOverridesFile, error) {
+	file := &categoryOverridesFile{}
+	buf, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(buf, file); err != nil {
+			return nil, fmt.Errorf("can't decode YAML from category overrides file '%s': %w", path, err)
+		}
+	}
+	if file.Overrides == nil {
+		file.Overrides = map[string]*CategoryOverride{}
+	}
+	if file.Splits == nil {
+		file.Splits = map[string]*TransactionSplit{}
+	}
+	for fingerprint, override := range file.Overrides {
+		if override == nil || override.Group == "" {
+			return nil, fmt.Errorf("category override '%s' in file '%s' has no group", fingerprint, path)
+		}
+	}
+	for fingerprint, split := range file.Splits {
+		if err := validateTransactionSplit(split); err != nil {
+			return nil, fmt.Errorf("split '%s' in file '%s' is wrong: %w", fingerprint, path, err)
+		}
+	}
+	return file, nil
+}
+
+// writeCategoryOverrides writes category overrides and splits to the file.
+func writeCategoryOverrides(path string, file *categoryOverridesFile) error {
+	buf, err := yaml.Marshal(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf, 0644)
+}
+
+// SetCategoryOverride pins group of the transaction with given fingerprint, empty group removes override.
+// Group should be configured one.
+// Updates overrides file and swaps in a new snapshot with not built yet categorization.
+func (dh *DataHandler) SetCategoryOverride(fingerprint, group string) error {
+	dh.updateMutex.Lock()
+	defer dh.updateMutex.Unlock()
+
+	current := dh.GetSnapshot()
+	if _, ok := current.Config.Groups[group]; group != "" && !ok {
+		return fmt.Errorf("%w: '%s'", errGroupNotFound, group)
+	}
+	overrides := make(map[string]*CategoryOverride, len(current.Config.CategoryOverrides)+1)
+	for key, override := range current.Config.CategoryOverrides {
+		overrides[key] = override
+	}
+	if group == "" {
+		delete(overrides, fingerprint)
+	} else {
+		override := &CategoryOverride{Group: group}
+		if current.DataMart != nil {
+			for _, transaction := range current.DataMart.SortedTransactions {
+				if TransactionFingerprint(&transaction) == fingerprint {
+					override.Transaction = describeTransaction(&transaction)
+					break
+				}
+			}
+		}
+		overrides[fingerprint] = override
+	}
+	file := &categoryOverridesFile{Overrides: overrides, Splits: current.Config.TransactionSplits}
+	overridesPath := getCategoryOverridesPath(dh.ConfigPath)
+	if err := writeCategoryOverrides(overridesPath, file); err != nil {
+		return err
+	}
+	dh.recordOwnWrite(overridesPath)
+
+	config := *current.Config
+	config.CategoryOverrides = overrides
+	dh.setSnapshot(&DataSnapshot{
+		Config:                  &config,
+		TimeZone:                current.TimeZone,
+		DataMart:                current.DataMart,
+		StatisticBuilderFactory: current.StatisticBuilderFactory,
+		FileInfos:               current.FileInfos,
+		InboxReports:            current.InboxReports,
+	})
+	return nil
+}
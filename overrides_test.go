@@ -0,0 +1,207 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTransactionFingerprint(t *testing.T) {
+	transaction := Transaction{
+		Date:            testDate,
+		FromAccount:     "my",
+		ToAccount:       "shop",
+		IsExpense:       true,
+		Amount:          Money{int: 1000000},
+		Details:         "SHOP",
+		Source:          &TransactionsSource{Tag: "InecoXml", FilePath: "a.xml"},
+		AccountCurrency: "AMD",
+	}
+	otherFile := transaction
+	otherFile.Source = &TransactionsSource{Tag: "InecoXml", FilePath: "b.xml"}
+	otherAmount := transaction
+	otherAmount.Amount = Money{int: 1000010}
+	otherDetails := transaction
+	otherDetails.Details = "SHOP 2"
+	otherSource := transaction
+	otherSource.Source = &TransactionsSource{Tag: "AmeriaCsv", FilePath: "a.xml"}
+	withID := transaction
+	withID.ID = "FIT1"
+	withIDOtherDetails := withID
+	withIDOtherDetails.Details = "SHOP 2"
+	withOtherID := withID
+	withOtherID.ID = "FIT2"
+	tests := []struct {
+		name          string
+		base          Transaction
+		transaction   Transaction
+		expectedEqual bool
+	}{
+		{"other_file", transaction, otherFile, true},
+		{"other_amount", transaction, otherAmount, false},
+		{"other_details", transaction, otherDetails, false},
+		{"other_source", transaction, otherSource, false},
+		{"id_instead_of_fields", transaction, withID, false},
+		{"same_id_other_details", withID, withIDOtherDetails, true},
+		{"other_id", withID, withOtherID, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			expected := TransactionFingerprint(&tt.base)
+			actual := TransactionFingerprint(&tt.transaction)
+
+			// Assert
+			if (expected == actual) != tt.expectedEqual {
+				t.Errorf("expected fingerprints equality %v, got %s and %s", tt.expectedEqual, expected, actual)
+			}
+		})
+	}
+}
+
+func TestCategoryOverrides_WriteAndRead(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), CategoryOverridesFileName)
+	file := &categoryOverridesFile{
+		Overrides: map[string]*CategoryOverride{
+			"abc": {Group: "Gifts", Transaction: "2024-06-01 1,000.00 AMD SHOP"},
+			"def": {Group: "Food"},
+		},
+		Splits: map[string]*TransactionSplit{
+			"ghi": {Parts: []SplitPart{{Group: "Food", Percent: floatPtr(70)}, {Group: "Household"}}},
+		},
+	}
+
+	// Act
+	err := writeCategoryOverrides(path, file)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := readCategoryOverrides(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(file, actual); diff != "" {
+		t.Errorf("overrides mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestReadCategoryOverrides_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{"no_group", "overrides:\n  abc:\n    transaction: something\n", "category override 'abc'"},
+		{"wrong_yaml", "overrides: [", "can't decode YAML"},
+		{"wrong_split", "splits:\n  abc:\n    parts:\n      - group: Food\n", "split 'abc'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Arrange
+			file := createTempFileWithContent(tt.content)
+			defer os.Remove(file.Name())
+
+			// Act
+			_, err := readCategoryOverrides(file.Name())
+
+			// Assert
+			checkErrorContainsSubstring(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestCategorization_CategorizeTransaction_Manual(t *testing.T) {
+	// Arrange
+	transaction := Transaction{Date: testDate, Details: "Yandex Taxi ride", IsExpense: true}
+	fingerprint := TransactionFingerprint(&transaction)
+	config := &Config{
+		Groups: map[string]*GroupConfig{
+			"Taxi": {Substrings: []string{"Taxi"}, Rules: []RuleConfig{{Substring: "Yandex", Priority: 100}}},
+		},
+		CategoryOverrides: map[string]*CategoryOverride{fingerprint: {Group: "Gifts"}},
+	}
+	categorization, err := NewCategorization(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := transaction
+	other.Amount = Money{int: 10}
+
+	// Act
+	actual, isUncategorized, err := categorization.CategorizeTransaction(&transaction)
+	actualOther, _, errOther := categorization.CategorizeTransaction(&other)
+
+	// Assert
+	if err != nil || errOther != nil {
+		t.Fatal(err, errOther)
+	}
+	expected := &CategoryMatch{Name: "Gifts", RuleType: RuleTypeManual, RuleValue: fingerprint, Path: []string{"Gifts"}}
+	if diff := cmp.Diff(expected, actual); diff != "" || isUncategorized {
+		t.Errorf("match mismatch (-expected +actual):\n%s", diff)
+	}
+	if actualOther.Name != "Taxi" || actualOther.RuleType != RuleTypeRule {
+		t.Errorf("override shouldn't affect other transactions, got %+v", actualOther)
+	}
+}
+
+func TestDataHandler_SetCategoryOverride(t *testing.T) {
+	// Arrange
+	dataHandler := newTestDataHandler(t)
+	journalEntries, err := dataHandler.GetJournalEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := journalEntries[0].Fingerprint
+
+	// Act
+	err = dataHandler.SetCategoryOverride(fingerprint, "Food")
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := readConfig(dataHandler.ConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	override := config.CategoryOverrides[fingerprint]
+	if override == nil || override.Group != "Food" || override.Transaction == "" {
+		t.Errorf("expected override in file, got %+v", config.CategoryOverrides)
+	}
+	journalEntries, err = dataHandler.GetJournalEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range journalEntries {
+		if entry.Fingerprint == fingerprint && (entry.Category != "Food" || entry.RuleType != RuleTypeManual) {
+			t.Errorf("expected entry in 'Food' category, got %+v", entry)
+		}
+	}
+
+	// Act - override with not configured group.
+	err = dataHandler.SetCategoryOverride(fingerprint, "Gifts")
+
+	// Assert
+	checkErrorContainsSubstring(t, err, "Group with this name doesn't exist: 'Gifts'")
+	if dataHandler.GetSnapshot().Config.CategoryOverrides[fingerprint].Group != "Food" {
+		t.Errorf("expected override to stay in 'Food', got %+v", dataHandler.GetSnapshot().Config.CategoryOverrides)
+	}
+
+	// Act - remove override.
+	err = dataHandler.SetCategoryOverride(fingerprint, "")
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataHandler.GetSnapshot().Config.CategoryOverrides) != 0 {
+		t.Errorf("expected no overrides, got %+v", dataHandler.GetSnapshot().Config.CategoryOverrides)
+	}
+}
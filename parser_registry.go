@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParserFactory creates a FileParser from source options and the whole configuration.
+// Options are taken "as is" from `sources[].options` configuration section.
+type ParserFactory func(options map[string]any, config *Config) (FileParser, error)
+
+// ParserRegistration describes one supported type of transactions files.
+type ParserRegistration struct {
+	// Name is a unique parser name used in `sources[].parser` configuration field.
+	Name string
+	// TypeName is a human readable name of files type, used in logs and warnings.
+	TypeName string
+	// Tag is a prefix for Beancount account names. Used if parser doesn't set own tag.
+	Tag string
+	// Version of the parser. Should be increased when parser starts to produce different transactions
+	// from the same file, because it invalidates cached results of parsing.
+	Version int
+	// DefaultGlob is a glob pattern of files exported by the bank with default names.
+	// Used for sources without glob.
+	DefaultGlob string
+	// NewParser creates parser for the specific source.
+	NewParser ParserFactory
+	// Detect returns true if file looks like supported by parser. Used for files from `inboxGlob`.
+	// May be nil if parser can't recognize own files.
+	Detect func(sniff *FileSniff) bool
+}
+
+var parserRegistry = map[string]*ParserRegistration{}
+
+// RegisterParser adds parser into registry. Expected to be called from `init` functions.
+// Panics on wrong or duplicated registration because it is a programming error.
+func RegisterParser(registration ParserRegistration) {
+	if registration.Name == "" || registration.NewParser == nil {
+		panic(fmt.Sprintf("parser registration %+v should have name and factory", registration))
+	}
+	if _, ok := parserRegistry[registration.Name]; ok {
+		panic(fmt.Sprintf("parser '%s' is already registered", registration.Name))
+	}
+	parserRegistry[registration.Name] = &registration
+}
+
+// getParserRegistration returns registration by parser name.
+func getParserRegistration(name string) (*ParserRegistration, bool) {
+	registration, ok := parserRegistry[name]
+	return registration, ok
+}
+
+// getRegisteredParsers returns all registered parsers sorted by name.
+func getRegisteredParsers() []*ParserRegistration {
+	result := make([]*ParserRegistration, 0, len(parserRegistry))
+	for _, registration := range parserRegistry {
+		result = append(result, registration)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// getRegisteredParserNames returns names of all registered parsers sorted.
+func getRegisteredParserNames() []string {
+	registrations := getRegisteredParsers()
+	result := make([]string, 0, len(registrations))
+	for _, registration := range registrations {
+		result = append(result, registration.Name)
+	}
+	return result
+}
+
+// newParserWithoutOptions returns factory for parsers which don't support any options.
+func newParserWithoutOptions(parser FileParser) ParserFactory {
+	return func(options map[string]any, _ *Config) (FileParser, error) {
+		if len(options) > 0 {
+			return nil, fmt.Errorf("parser doesn't support options, got %v", options)
+		}
+		return parser, nil
+	}
+}
+
+// decodeParserOptions decodes source options into the specified struct with `yaml` tags.
+// Returns error on unknown options to catch typos in configuration.
+func decodeParserOptions(options map[string]any, target any) error {
+	if len(options) > 0 {
+		buf, err := yaml.Marshal(options)
+		if err != nil {
+			return err
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(buf))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(target); err != nil {
+			return fmt.Errorf("wrong options: %w", err)
+		}
+	}
+	// Empty options are validated too, they may miss required fields.
+	return validate.Struct(target)
+}
+
+// newParserForSource creates parser for the source configuration.
+func newParserForSource(source SourceConfig, config *Config) (*ParserRegistration, FileParser, error) {
+	registration, ok := getParserRegistration(source.Parser)
+	if !ok {
+		return nil, nil, fmt.Errorf(
+			"unknown parser '%s', supported parsers: %v",
+			source.Parser,
+			getRegisteredParserNames(),
+		)
+	}
+	parser, err := registration.NewParser(source.Options, config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't create '%s' parser: %w", source.Parser, err)
+	}
+	return registration, parser, nil
+}
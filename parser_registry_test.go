@@ -0,0 +1,188 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegisteredParsers(t *testing.T) {
+	// Arrange
+	expectedNames := []string{
+		AcbaCardXlsParserName,
+		AcbaRegularAccountXlsParserName,
+		AmeriaCsvParserName,
+		ArdshinXlsxParserName,
+		GenericCsvParserName,
+		InecoXlsxParserName,
+		InecoXmlParserName,
+		MappedCsvParserName,
+		MyAmeriaHistoryXlsParserName,
+		MyAmeriaXlsParserName,
+		OfxParserName,
+		SpreadsheetParserName,
+	}
+
+	// Act
+	names := getRegisteredParserNames()
+
+	// Assert
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("Expected parsers %v, got %v", expectedNames, names)
+	}
+	for _, registration := range getRegisteredParsers() {
+		if registration.TypeName == "" || registration.Tag == "" || registration.DefaultGlob == "" {
+			t.Errorf("Parser '%s' registration is incomplete: %+v", registration.Name, registration)
+		}
+		if _, err := registration.NewParser(nil, &Config{}); err != nil {
+			t.Errorf("Parser '%s' can't be created without options: %v", registration.Name, err)
+		}
+	}
+}
+
+func TestRegisterParser_Duplicate(t *testing.T) {
+	// Arrange
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic on duplicated registration")
+		}
+	}()
+
+	// Act
+	RegisterParser(ParserRegistration{
+		Name:      InecoXmlParserName,
+		NewParser: newParserWithoutOptions(InecoXmlParser{}),
+	})
+}
+
+func TestNewParserForSource(t *testing.T) {
+	config := &Config{
+		MyAmeriaMyAccounts: map[string]string{"1234567890123456": "AMD"},
+	}
+	tests := []struct {
+		name           string
+		source         SourceConfig
+		expectedParser FileParser
+		expectedError  string
+	}{
+		{
+			name:           "parser without options",
+			source:         SourceConfig{Parser: InecoXmlParserName, Glob: "*.xml"},
+			expectedParser: InecoXmlParser{},
+		},
+		{
+			name: "options are not supported",
+			source: SourceConfig{
+				Parser:  InecoXmlParserName,
+				Glob:    "*.xml",
+				Options: map[string]any{"foo": "bar"},
+			},
+			expectedError: "parser doesn't support options",
+		},
+		{
+			name:          "unknown parser",
+			source:        SourceConfig{Parser: "unknownBank", Glob: "*.xml"},
+			expectedError: "unknown parser 'unknownBank'",
+		},
+		{
+			name:           "default options from config",
+			source:         SourceConfig{Parser: MyAmeriaHistoryXlsParserName, Glob: "*.xls"},
+			expectedParser: MyAmeriaExcelFileParser{MyAccounts: config.MyAmeriaMyAccounts},
+		},
+		{
+			name: "options override config",
+			source: SourceConfig{
+				Parser: MyAmeriaHistoryXlsParserName,
+				Glob:   "*.xls",
+				Options: map[string]any{
+					"myAccounts": map[string]any{"6543210987654321": "USD"},
+				},
+			},
+			expectedParser: MyAmeriaExcelFileParser{MyAccounts: map[string]string{"6543210987654321": "USD"}},
+		},
+		{
+			name: "unknown option",
+			source: SourceConfig{
+				Parser:  MyAmeriaHistoryXlsParserName,
+				Glob:    "*.xls",
+				Options: map[string]any{"myAcounts": map[string]any{}},
+			},
+			expectedError: "field myAcounts not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, parser, err := newParserForSource(tt.source, config)
+
+			// Assert
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Fatalf("Expected error containing '%s', got nil", tt.expectedError)
+				}
+				checkErrorContainsSubstring(t, err, tt.expectedError)
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(parser, tt.expectedParser) {
+				t.Errorf("Expected parser %#v, got %#v", tt.expectedParser, parser)
+			}
+		})
+	}
+}
+
+func TestDecodeParserOptions(t *testing.T) {
+	type options struct {
+		Name  string `yaml:"name" validate:"required"`
+		Count int    `yaml:"count" validate:"min=0"`
+	}
+	tests := []struct {
+		name          string
+		options       map[string]any
+		expected      options
+		expectedError string
+	}{
+		{
+			name:     "valid options",
+			options:  map[string]any{"name": "bank", "count": 2},
+			expected: options{Name: "bank", Count: 2},
+		},
+		{
+			name:          "empty options are validated",
+			options:       nil,
+			expectedError: "Error:Field validation for 'Name' failed on the 'required' tag",
+		},
+		{
+			name:          "invalid value",
+			options:       map[string]any{"name": "bank", "count": -1},
+			expectedError: "Error:Field validation for 'Count' failed on the 'min' tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var actual options
+
+			// Act
+			err := decodeParserOptions(tt.options, &actual)
+
+			// Assert
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Fatalf("Expected error containing '%s', got nil", tt.expectedError)
+				}
+				checkErrorContainsSubstring(t, err, tt.expectedError)
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if actual != tt.expected {
+				t.Errorf("Expected options %+v, got %+v", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// AmountFormat describes how amounts are written in files.
+// Besides of decimal separator it understands:
+// - thousands separators: spaces (including non-breaking ones), apostrophes and the other of ',' and '.',
+// - signs '+', '-' and Unicode minus '−' before or after the number, also separated by spaces,
+// - parentheses around the number for negative amounts, like "(1,234.56)",
+// - exponent like in "1.5E+3", as Excel may write numbers.
+type AmountFormat struct {
+	// DecimalSeparator is a separator of the fractional part: '.' or ','.
+	// Zero value means to guess it, see `guessDecimalSeparator`.
+	DecimalSeparator rune
+}
+
+// ParseAmount parses amount with guessed decimal separator.
+func ParseAmount(s string) (Money, error) {
+	return AmountFormat{}.Parse(s)
+}
+
+// Parse parses amount like "1,234.56", "1 234,56", "-1'234.56" or "(1,234.56)".
+// Errors are the same as ones of `strconv.ParseFloat` for the original string.
+func (f AmountFormat) Parse(s string) (Money, error) {
+	value, err := parseDecimal(f.normalize(s), moneyDecimalPlaces)
+	if err != nil {
+		var numError *strconv.NumError
+		if errors.As(err, &numError) {
+			numError.Num = s
+		}
+		return Money{}, err
+	}
+	return Money{int: value}, nil
+}
+
+// ParseWithoutLetters parses amount surrounded by currency codes, symbols or other text,
+// like "99500.25  USD" or "+ 1,449.00 ֏".
+func (f AmountFormat) ParseWithoutLetters(s string) (Money, error) {
+	var number strings.Builder
+	hasDigits := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			hasDigits = true
+			number.WriteRune(r)
+		case strings.ContainsRune(amountSignsAndSeparators, r) || unicode.IsSpace(r):
+			number.WriteRune(r)
+		}
+	}
+	if !hasDigits {
+		return Money{}, fmt.Errorf("invalid money format: '%s'", s)
+	}
+	amount, err := f.Parse(number.String())
+	if err != nil {
+		return amount, fmt.Errorf("failed to parse amount: %w", err)
+	}
+	return amount, nil
+}
+
+// amountSignsAndSeparators are non-digit characters which may be a part of the amount.
+const amountSignsAndSeparators = "+-−().,'’"
+
+// amountThousandsSeparators are characters used only to group digits.
+const amountThousandsSeparators = "'’"
+
+// normalize returns amount as "-1234.56" or "1.5e3" string for `parseDecimal`.
+// Doesn't validate the string, so wrong characters are kept to fail on parsing.
+func (f AmountFormat) normalize(s string) string {
+	text := strings.TrimSpace(s)
+	isNegative := false
+	if len(text) > 1 && strings.HasPrefix(text, "(") && strings.HasSuffix(text, ")") {
+		isNegative = true
+		text = strings.TrimSpace(text[1 : len(text)-1])
+	}
+	// Sign may be before or after the number, like "- 1.60" or "1.60-".
+	for _, sign := range []string{"+", "-", "−"} {
+		if strings.HasPrefix(text, sign) {
+			isNegative = isNegative != (sign != "+")
+			text = strings.TrimSpace(strings.TrimPrefix(text, sign))
+			break
+		} else if strings.HasSuffix(text, sign) && len(text) > len(sign) {
+			isNegative = isNegative != (sign != "+")
+			text = strings.TrimSpace(strings.TrimSuffix(text, sign))
+			break
+		}
+	}
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || strings.ContainsRune(amountThousandsSeparators, r) {
+			return -1
+		}
+		return r
+	}, text)
+
+	mantissa, exponent, hasExponent := strings.Cut(strings.ToLower(text), "e")
+	decimalSeparator := f.DecimalSeparator
+	if decimalSeparator == 0 {
+		decimalSeparator = guessDecimalSeparator(mantissa)
+	}
+	thousandsSeparator := ","
+	if decimalSeparator == ',' {
+		thousandsSeparator = "."
+	}
+	intPart, fracPart, hasFraction := strings.Cut(mantissa, string(decimalSeparator))
+	if !isValidDigitsGrouping(strings.Split(intPart, thousandsSeparator)) {
+		// Keep wrong separators to fail on parsing.
+		return text
+	}
+	mantissa = strings.ReplaceAll(intPart, thousandsSeparator, "")
+	if hasFraction {
+		mantissa += "." + fracPart
+	}
+
+	var result strings.Builder
+	if isNegative {
+		result.WriteString("-")
+	}
+	result.WriteString(mantissa)
+	if hasExponent {
+		result.WriteString("e" + exponent)
+	}
+	return result.String()
+}
+
+// guessDecimalSeparator returns decimal separator of the number without spaces and signs:
+// - if both ',' and '.' are used then the last one is decimal separator, like in "1.234,56",
+// - several ',' or '.' are thousands separators, like in "1,234,567" or "1.234.567",
+// - single ',' is a thousands separator only if followed by exactly 3 digits, like in "1,500",
+// - single '.' is always decimal separator, like in "1.500".
+func guessDecimalSeparator(number string) rune {
+	lastComma := strings.LastIndex(number, ",")
+	lastDot := strings.LastIndex(number, ".")
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		if lastComma > lastDot {
+			return ','
+		}
+		return '.'
+	case lastComma >= 0:
+		if strings.Count(number, ",") == 1 && len(number)-lastComma-1 != 3 {
+			return ','
+		}
+		return '.'
+	case strings.Count(number, ".") > 1:
+		return ','
+	default:
+		return '.'
+	}
+}
+
+// isValidDigitsGrouping returns true if groups of digits split by thousands separator have 3 digits
+// except the first one which has 1-3 digits.
+func isValidDigitsGrouping(groups []string) bool {
+	if len(groups) == 1 {
+		return true
+	}
+	if len(groups[0]) < 1 || len(groups[0]) > 3 {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// DateFormat describes how dates are written in files.
+// Statements contain local dates of the bank, so dates without offset are parsed as UTC ones by default
+// to keep the same calendar day everywhere in the app.
+type DateFormat struct {
+	// Layouts are layouts of `time.Parse` to try one by one.
+	Layouts []string
+	// Location is a time zone for dates without offset. Dates with offset are converted into it.
+	// Nil means UTC and keeps offsets of dates as is.
+	Location *time.Location
+}
+
+// newDateFormat returns format with layouts and time zone by IANA name like "Asia/Yerevan".
+// Empty time zone means UTC.
+func newDateFormat(timeZone string, layouts ...string) (DateFormat, error) {
+	format := DateFormat{Layouts: layouts}
+	if timeZone == "" {
+		return format, nil
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return format, fmt.Errorf("invalid timezone location '%s': %w", timeZone, err)
+	}
+	format.Location = location
+	return format, nil
+}
+
+// parseDate parses date in UTC trying layouts one by one.
+func parseDate(s string, layouts ...string) (time.Time, error) {
+	return DateFormat{Layouts: layouts}.Parse(s)
+}
+
+// Parse parses date with surrounding spaces trying layouts one by one.
+// For single layout returns error of `time.Parse`.
+func (f DateFormat) Parse(s string) (time.Time, error) {
+	if len(f.Layouts) == 0 {
+		return time.Time{}, errors.New("no date layouts are specified")
+	}
+	text := strings.TrimSpace(s)
+	location := f.Location
+	if location == nil {
+		location = time.UTC
+	}
+	var firstErr error
+	for _, layout := range f.Layouts {
+		date, err := time.ParseInLocation(layout, text, location)
+		if err == nil {
+			if f.Location != nil {
+				date = date.In(f.Location)
+			}
+			return date, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if len(f.Layouts) == 1 {
+		return time.Time{}, firstErr
+	}
+	return time.Time{}, fmt.Errorf("date '%s' doesn't match any of layouts '%s'", s, strings.Join(f.Layouts, "', '"))
+}
+
+// withWallClock returns date with the same date and time of day in the location of the format.
+// Used for dates without offset stored not as text, like Excel serial numbers.
+func (f DateFormat) withWallClock(date time.Time) time.Time {
+	if f.Location == nil {
+		return date
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), f.Location)
+}
+
+// excelEpoch is a day before the 1st day of Excel serial dates with respect to 1900 leap year bug of Excel.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// excelSerialDate converts Excel serial number of days like 45292.5 into date and time in UTC.
+func excelSerialDate(serial float64) time.Time {
+	days := int(serial)
+	return excelEpoch.AddDate(0, 0, days).Add(time.Duration((serial - float64(days)) * float64(24*time.Hour)).Round(time.Second))
+}
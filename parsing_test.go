@@ -0,0 +1,307 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// amountParsingTests are amounts as they are written in files of supported banks and in other locales.
+// All parsers use `AmountFormat` so this table is shared by them.
+var amountParsingTests = []struct {
+	name     string
+	input    string
+	expected int
+}{
+	{"ameria_csv", "1,500.00", 1500000},
+	{"ameria_csv_no_fraction", "1,500", 1500000},
+	{"ineco", "123456.78", 123456780},
+	{"ardshin_debit", "-20,000.00", -20000000},
+	{"acba_credit", "+ 1,449.00", 1449000},
+	{"acba_debit", "- 1.60", -1600},
+	{"excel_exponent", "1.5E+3", 1500000},
+	{"millions", "1,234,567.89", 1234567890},
+	{"decimal_comma", "12,5", 12500},
+	{"decimal_comma_with_dots", "1.234.567,89", 1234567890},
+	{"spaces", "1 234 567,89", 1234567890},
+	{"non_breaking_spaces", "1 234 567,89", 1234567890},
+	{"apostrophes", "1'234'567.89", 1234567890},
+	{"unicode_minus", "−1,234.56", -1234560},
+	{"trailing_minus", "1,234.56-", -1234560},
+	{"parentheses", "(1,234.56)", -1234560},
+	{"parentheses_with_minus", "(-5)", 5000},
+	{"surrounding_spaces", "  7.25 ", 7250},
+	{"three_decimal_places", "0.125", 125},
+}
+
+func TestAmountFormat_Parse(t *testing.T) {
+	for _, tt := range amountParsingTests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			actual, err := ParseAmount(tt.input)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual.int != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, actual.int)
+			}
+		})
+	}
+}
+
+func TestAmountFormat_ParseWithoutLetters(t *testing.T) {
+	for _, tt := range amountParsingTests {
+		if tt.name == "excel_exponent" {
+			continue
+		}
+		for _, format := range []string{"%s USD", "AMD %s", "%s ֏"} {
+			input := fmt.Sprintf(format, tt.input)
+			t.Run(input, func(t *testing.T) {
+
+				// Act
+				actual, err := AmountFormat{}.ParseWithoutLetters(input)
+
+				// Assert
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if actual.int != tt.expected {
+					t.Errorf("expected %d, got %d", tt.expected, actual.int)
+				}
+			})
+		}
+	}
+}
+
+func TestAmountFormat_Parse_DecimalSeparator(t *testing.T) {
+	tests := []struct {
+		input            string
+		decimalSeparator rune
+		expected         int
+	}{
+		{"1,500", '.', 1500000},
+		{"1,500", ',', 1500},
+		{"1.500", '.', 1500},
+		{"1.500", ',', 1500000},
+		{"1.234,5", ',', 1234500},
+		{"-0,01", ',', -10},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s_%c", tt.input, tt.decimalSeparator), func(t *testing.T) {
+			// Arrange
+			format := AmountFormat{DecimalSeparator: tt.decimalSeparator}
+
+			// Act
+			actual, err := format.Parse(tt.input)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual.int != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, actual.int)
+			}
+		})
+	}
+}
+
+func TestAmountFormat_Parse_Errors(t *testing.T) {
+	tests := []struct {
+		input        string
+		errorMessage string
+	}{
+		{"", `strconv.ParseFloat: parsing "": invalid syntax`},
+		{"abc", `strconv.ParseFloat: parsing "abc": invalid syntax`},
+		{"2.5.0", `strconv.ParseFloat: parsing "2.5.0": invalid syntax`},
+		{"1,2,3", `strconv.ParseFloat: parsing "1,2,3": invalid syntax`},
+		{"1 2,34.5", `strconv.ParseFloat: parsing "1 2,34.5": invalid syntax`},
+		{"--5", `strconv.ParseFloat: parsing "--5": invalid syntax`},
+		{"-", `strconv.ParseFloat: parsing "-": invalid syntax`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+
+			// Act
+			_, err := ParseAmount(tt.input)
+
+			// Assert
+			if !errors.Is(err, strconv.ErrSyntax) {
+				t.Errorf("expected syntax error, got %v", err)
+			}
+			checkErrorContainsSubstring(t, err, tt.errorMessage)
+		})
+	}
+}
+
+func TestAmountFormat_ParseWithoutLetters_NoDigits(t *testing.T) {
+
+	// Act
+	_, err := AmountFormat{}.ParseWithoutLetters(" USD ")
+
+	// Assert
+	checkErrorContainsSubstring(t, err, "invalid money format: ' USD '")
+}
+
+func TestDateFormat_Parse(t *testing.T) {
+	yerevan := time.FixedZone("AMT", 4*60*60)
+	tests := []struct {
+		name     string
+		format   DateFormat
+		input    string
+		expected time.Time
+	}{
+		{
+			name:     "ineco",
+			format:   DateFormat{Layouts: []string{InecoDateFormat}},
+			input:    " 31/01/2024 ",
+			expected: utcDate(2024, 1, 31),
+		},
+		{
+			name:     "second_layout",
+			format:   DateFormat{Layouts: []string{MyAmeriaStmtDateFormat, MyAmeriaHistoryDateFormat}},
+			input:    "31-01-2024",
+			expected: utcDate(2024, 1, 31),
+		},
+		{
+			name:     "time_without_offset_in_utc",
+			format:   DateFormat{Layouts: []string{"2006-01-02 15:04"}},
+			input:    "2024-01-31 10:30",
+			expected: time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "offset_kept",
+			format:   DateFormat{Layouts: []string{time.RFC3339}},
+			input:    "2024-01-31T22:30:00-05:00",
+			expected: time.Date(2024, 2, 1, 3, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "location",
+			format:   DateFormat{Layouts: []string{"2006-01-02 15:04"}, Location: yerevan},
+			input:    "2024-01-31 10:30",
+			expected: time.Date(2024, 1, 31, 6, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "offset_converted_to_location",
+			format:   DateFormat{Layouts: []string{time.RFC3339}, Location: yerevan},
+			input:    "2024-01-31T22:30:00Z",
+			expected: time.Date(2024, 2, 1, 2, 30, 0, 0, yerevan),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			actual, err := tt.format.Parse(tt.input)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !actual.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+			if tt.format.Location != nil && actual.Location() != tt.format.Location {
+				t.Errorf("expected location %v, got %v", tt.format.Location, actual.Location())
+			}
+		})
+	}
+}
+
+func TestNewDateFormat(t *testing.T) {
+	tests := []struct {
+		name         string
+		timeZone     string
+		expected     string
+		errorMessage string
+	}{
+		{name: "empty_is_utc", timeZone: "", expected: "2024-01-31 10:30:00 +0000 UTC"},
+		{name: "iana_name", timeZone: "Asia/Yerevan", expected: "2024-01-31 10:30:00 +0400 +04"},
+		{name: "unknown", timeZone: "Mars/Olympus", errorMessage: "invalid timezone location 'Mars/Olympus'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			format, err := newDateFormat(tt.timeZone, "2006-01-02 15:04")
+
+			// Assert
+			if tt.errorMessage != "" {
+				checkErrorContainsSubstring(t, err, tt.errorMessage)
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actual, err := format.Parse("2024-01-31 10:30")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual.String() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDateFormat_Parse_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		format       DateFormat
+		errorMessage string
+	}{
+		{
+			name:         "single_layout",
+			format:       DateFormat{Layouts: []string{OutputDateFormat}},
+			errorMessage: `parsing time "2024/01/31" as "2006-01-02": cannot parse "/01/31" as "-"`,
+		},
+		{
+			name:         "several_layouts",
+			format:       DateFormat{Layouts: []string{OutputDateFormat, InecoDateFormat}},
+			errorMessage: "date '2024/01/31' doesn't match any of layouts '2006-01-02', '02/01/2006'",
+		},
+		{
+			name:         "no_layouts",
+			format:       DateFormat{},
+			errorMessage: "no date layouts are specified",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			_, err := tt.format.Parse("2024/01/31")
+
+			// Assert
+			checkErrorContainsSubstring(t, err, tt.errorMessage)
+		})
+	}
+}
+
+func TestExcelSerialDate(t *testing.T) {
+	tests := []struct {
+		serial   float64
+		expected time.Time
+	}{
+		{1, utcDate(1899, 12, 31)},
+		{61, utcDate(1900, 3, 1)},
+		{45292, utcDate(2024, 1, 1)},
+		{45292.75, time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.serial), func(t *testing.T) {
+
+			// Act
+			actual := excelSerialDate(tt.serial)
+
+			// Assert
+			if !actual.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
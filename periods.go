@@ -0,0 +1,346 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PeriodType is a kind of reporting periods.
+type PeriodType string
+
+const (
+	PeriodMonth   PeriodType = "month"
+	PeriodWeek    PeriodType = "week"
+	PeriodQuarter PeriodType = "quarter"
+	PeriodYear    PeriodType = "year"
+	PeriodCustom  PeriodType = "custom"
+	PeriodSalary  PeriodType = "salary"
+)
+
+// PeriodTypes lists all supported types of periods.
+var PeriodTypes = []PeriodType{PeriodMonth, PeriodWeek, PeriodQuarter, PeriodYear, PeriodCustom, PeriodSalary}
+
+const (
+	// defaultSalaryMaxDays is a default maximal length of salary period in days.
+	defaultSalaryMaxDays = 35
+	// salaryMinDays is a minimal length of salary period in days.
+	// Incomes of salary group closer to the start of the period (like salary paid in parts) don't start new period.
+	salaryMinDays = 20
+)
+
+// weekdays maps names of days in configuration to `time.Weekday`.
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// PeriodConfig configures reporting periods. Months are used by default.
+type PeriodConfig struct {
+	// Type is a type of periods.
+	Type PeriodType `yaml:"type,omitempty" validate:"omitempty,oneof=month week quarter year custom salary"`
+	// YearStartMonth is a number of the first month of the (fiscal) year for quarters and years. January by default.
+	YearStartMonth int `yaml:"yearStartMonth,omitempty" validate:"min=0,max=12"`
+	// WeekStartDay is a name of the first day of weeks, "monday" by default.
+	WeekStartDay string `yaml:"weekStartDay,omitempty" validate:"omitempty,oneof=monday tuesday wednesday thursday friday saturday sunday"`
+	// Ranges are arbitrary periods for "custom" type.
+	Ranges []PeriodRange `yaml:"ranges,omitempty" validate:"omitempty,dive"`
+	// SalaryGroup is a name of income group which transactions start "salary" periods.
+	SalaryGroup string `yaml:"salaryGroup,omitempty"`
+	// SalaryMaxDays is a maximal length of "salary" period in days. If there is no salary during it
+	// then next period starts one month after the last salary. 35 days by default.
+	SalaryMaxDays int `yaml:"salaryMaxDays,omitempty" validate:"omitempty,min=28,max=366"`
+}
+
+// PeriodRange is an arbitrary reporting period.
+type PeriodRange struct {
+	// Name is a name of the period, "from..to" by default.
+	Name string `yaml:"name,omitempty"`
+	// From is a first day of the period in YYYY-MM-DD format.
+	From string `yaml:"from" validate:"required"`
+	// To is a last day (inclusive) of the period in YYYY-MM-DD format.
+	To string `yaml:"to" validate:"required"`
+}
+
+// ParsePeriodType checks that value is a known type of periods. Empty value is allowed.
+func ParsePeriodType(value string) (PeriodType, error) {
+	for _, periodType := range PeriodTypes {
+		if string(periodType) == value {
+			return periodType, nil
+		}
+	}
+	if value == "" {
+		return "", nil
+	}
+	names := make([]string, len(PeriodTypes))
+	for i, periodType := range PeriodTypes {
+		names[i] = string(periodType)
+	}
+	return "", fmt.Errorf("unknown period '%s', supported only: %s", value, strings.Join(names, ", "))
+}
+
+// Period splits time into consecutive reporting periods.
+type Period interface {
+	// Bounds returns start and end (last nanosecond) of the period containing the date.
+	// Returns false if the date is not in any period.
+	Bounds(date time.Time) (time.Time, time.Time, bool)
+	// Name returns short name of the period which starts at the date.
+	Name(start time.Time) string
+}
+
+// calendarPeriod is a period of several months, i.e. month, quarter or year.
+type calendarPeriod struct {
+	months     int
+	startMonth time.Month
+	startDay   int
+	timeZone   *time.Location
+}
+
+func (p calendarPeriod) Bounds(date time.Time) (time.Time, time.Time, bool) {
+	date = date.In(p.timeZone)
+	offset := ((int(date.Month())-int(p.startMonth))%p.months + p.months) % p.months
+	start := time.Date(date.Year(), date.Month()-time.Month(offset), p.startDay, 0, 0, 0, 0, p.timeZone)
+	if start.After(date) {
+		start = time.Date(date.Year(), date.Month()-time.Month(offset+p.months), p.startDay, 0, 0, 0, 0, p.timeZone)
+	}
+	return start, start.AddDate(0, p.months, 0).Add(-time.Nanosecond), true
+}
+
+func (p calendarPeriod) Name(start time.Time) string {
+	// Year of the period is a year when the (fiscal) year starts.
+	year := start.Year()
+	if start.Month() < p.startMonth {
+		year--
+	}
+	switch p.months {
+	case 1:
+		return start.Format("2006-01")
+	case 3:
+		quarter := (int(start.Month())-int(p.startMonth)+12)%12/3 + 1
+		return fmt.Sprintf("%d-Q%d", year, quarter)
+	default:
+		if p.startMonth == time.January {
+			return fmt.Sprintf("%d", year)
+		}
+		return fmt.Sprintf("%d-%d", year, year+1)
+	}
+}
+
+// weekPeriod is a week starting from the specified day.
+type weekPeriod struct {
+	startDay time.Weekday
+	timeZone *time.Location
+}
+
+func (p weekPeriod) Bounds(date time.Time) (time.Time, time.Time, bool) {
+	date = date.In(p.timeZone)
+	offset := (int(date.Weekday()) - int(p.startDay) + 7) % 7
+	start := time.Date(date.Year(), date.Month(), date.Day()-offset, 0, 0, 0, 0, p.timeZone)
+	return start, start.AddDate(0, 0, 7).Add(-time.Nanosecond), true
+}
+
+func (p weekPeriod) Name(start time.Time) string {
+	return start.Format(OutputDateFormat)
+}
+
+// customPeriodRange is a parsed `PeriodRange`.
+type customPeriodRange struct {
+	name  string
+	start time.Time
+	end   time.Time
+}
+
+// customPeriod is a list of arbitrary not overlapping ranges sorted by start.
+type customPeriod struct {
+	ranges []customPeriodRange
+}
+
+func (p customPeriod) Bounds(date time.Time) (time.Time, time.Time, bool) {
+	i := sort.Search(len(p.ranges), func(i int) bool {
+		return !p.ranges[i].end.Before(date)
+	})
+	if i == len(p.ranges) || p.ranges[i].start.After(date) {
+		return time.Time{}, time.Time{}, false
+	}
+	return p.ranges[i].start, p.ranges[i].end, true
+}
+
+func (p customPeriod) Name(start time.Time) string {
+	for _, r := range p.ranges {
+		if r.start.Equal(start) {
+			return r.name
+		}
+	}
+	return start.Format(OutputDateFormat)
+}
+
+// newCustomPeriod parses and checks ranges. Ranges may go in any order but shouldn't overlap.
+func newCustomPeriod(ranges []PeriodRange, timeZone *time.Location) (customPeriod, error) {
+	if len(ranges) == 0 {
+		return customPeriod{}, errors.New("'custom' period requires 'period.ranges'")
+	}
+	result := customPeriod{ranges: make([]customPeriodRange, 0, len(ranges))}
+	for i, r := range ranges {
+		from, err := time.ParseInLocation(OutputDateFormat, r.From, timeZone)
+		if err != nil {
+			return customPeriod{}, fmt.Errorf("period range #%d has wrong 'from' date '%s', expected YYYY-MM-DD", i+1, r.From)
+		}
+		to, err := time.ParseInLocation(OutputDateFormat, r.To, timeZone)
+		if err != nil {
+			return customPeriod{}, fmt.Errorf("period range #%d has wrong 'to' date '%s', expected YYYY-MM-DD", i+1, r.To)
+		}
+		if to.Before(from) {
+			return customPeriod{}, fmt.Errorf("period range #%d ends before it starts", i+1)
+		}
+		name := r.Name
+		if name == "" {
+			name = r.From + ".." + r.To
+		}
+		result.ranges = append(result.ranges, customPeriodRange{
+			name:  name,
+			start: from,
+			end:   to.AddDate(0, 0, 1).Add(-time.Nanosecond),
+		})
+	}
+	sort.Slice(result.ranges, func(i, j int) bool {
+		return result.ranges[i].start.Before(result.ranges[j].start)
+	})
+	for i := 1; i < len(result.ranges); i++ {
+		if !result.ranges[i].start.After(result.ranges[i-1].end) {
+			return customPeriod{}, fmt.Errorf("period ranges '%s' and '%s' overlap",
+				result.ranges[i-1].name, result.ranges[i].name)
+		}
+	}
+	return result, nil
+}
+
+// startOfDay returns midnight of the date in the time zone.
+func startOfDay(date time.Time, timeZone *time.Location) time.Time {
+	date = date.In(timeZone)
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, timeZone)
+}
+
+// newSalaryPeriod builds periods starting at dates of incomes in the group.
+// Periods before the first salary and periods without salary (longer than `maxDays`)
+// are filled with periods starting on the same day of month as the last salary.
+// If there are no incomes in the group then calendar months are used.
+func newSalaryPeriod(journalEntries []JournalEntry, group string, maxDays int, timeZone *time.Location) Period {
+	var first, last time.Time
+	salaries := make([]time.Time, 0)
+	for i := range journalEntries {
+		je := &journalEntries[i]
+		day := startOfDay(je.Date, timeZone)
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+		if !je.IsExpense && isInCategory(je, group) {
+			salaries = append(salaries, day)
+		}
+	}
+	if len(salaries) == 0 {
+		log.Println(i18n.T("No incomes in group to start salary periods, calendar months are used", "group", group))
+		return calendarPeriod{months: 1, startMonth: time.January, startDay: 1, timeZone: timeZone}
+	}
+	sort.Slice(salaries, func(i, j int) bool {
+		return salaries[i].Before(salaries[j])
+	})
+	days := func(from, to time.Time) int {
+		return int(math.Round(to.Sub(from).Hours() / 24))
+	}
+
+	// Periods before the first salary.
+	starts := []time.Time{salaries[0]}
+	for months := 1; starts[0].After(first); months++ {
+		starts = append([]time.Time{salaries[0].AddDate(0, -months, 0)}, starts...)
+	}
+	base := salaries[0]
+	for _, salary := range salaries[1:] {
+		previous := starts[len(starts)-1]
+		if days(previous, salary) < salaryMinDays {
+			continue
+		}
+		// Fill gap without salaries.
+		for months := 1; days(previous, salary) > maxDays; months++ {
+			start := base.AddDate(0, months, 0)
+			if days(start, salary) < salaryMinDays {
+				break
+			}
+			starts = append(starts, start)
+			previous = start
+		}
+		starts = append(starts, salary)
+		base = salary
+	}
+	// Periods after the last salary, the last start is an end of the last period.
+	for months := 1; !starts[len(starts)-1].After(last); months++ {
+		starts = append(starts, base.AddDate(0, months, 0))
+	}
+
+	result := customPeriod{ranges: make([]customPeriodRange, 0, len(starts)-1)}
+	for i := 0; i+1 < len(starts); i++ {
+		result.ranges = append(result.ranges, customPeriodRange{
+			name:  starts[i].Format(OutputDateFormat),
+			start: starts[i],
+			end:   starts[i+1].Add(-time.Nanosecond),
+		})
+	}
+	return result
+}
+
+// NewPeriod creates reporting periods of the type. Empty type means type from configuration.
+// Journal entries are used only for "salary" periods.
+func NewPeriod(periodType PeriodType, config *Config, journalEntries []JournalEntry, timeZone *time.Location) (Period, error) {
+	if periodType == "" {
+		periodType = config.GetPeriodType()
+	}
+	periodConfig := config.Period
+	if periodConfig == nil {
+		periodConfig = &PeriodConfig{}
+	}
+	startMonth := time.January
+	if periodConfig.YearStartMonth > 0 {
+		startMonth = time.Month(periodConfig.YearStartMonth)
+	}
+	startDay := int(config.MonthStartDayNumber)
+	if startDay == 0 {
+		startDay = 1
+	}
+	switch periodType {
+	case PeriodMonth:
+		return calendarPeriod{months: 1, startMonth: time.January, startDay: startDay, timeZone: timeZone}, nil
+	case PeriodQuarter:
+		return calendarPeriod{months: 3, startMonth: startMonth, startDay: startDay, timeZone: timeZone}, nil
+	case PeriodYear:
+		return calendarPeriod{months: 12, startMonth: startMonth, startDay: startDay, timeZone: timeZone}, nil
+	case PeriodWeek:
+		startWeekday := time.Monday
+		if periodConfig.WeekStartDay != "" {
+			startWeekday = weekdays[periodConfig.WeekStartDay]
+		}
+		return weekPeriod{startDay: startWeekday, timeZone: timeZone}, nil
+	case PeriodCustom:
+		return newCustomPeriod(periodConfig.Ranges, timeZone)
+	case PeriodSalary:
+		if periodConfig.SalaryGroup == "" {
+			return nil, errors.New("'salary' period requires 'period.salaryGroup'")
+		}
+		maxDays := periodConfig.SalaryMaxDays
+		if maxDays == 0 {
+			maxDays = defaultSalaryMaxDays
+		}
+		return newSalaryPeriod(journalEntries, periodConfig.SalaryGroup, maxDays, timeZone), nil
+	}
+	return nil, fmt.Errorf("unknown period '%s'", periodType)
+}
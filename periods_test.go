@@ -0,0 +1,365 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePeriodType(t *testing.T) {
+	tests := []struct {
+		value         string
+		expected      PeriodType
+		expectedError string
+	}{
+		{"", "", ""},
+		{"quarter", PeriodQuarter, ""},
+		{"custom", PeriodCustom, ""},
+		{"decade", "", "unknown period 'decade', supported only: month, week, quarter, year, custom, salary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+
+			// Act
+			actual, err := ParsePeriodType(tt.value)
+
+			// Assert
+			if tt.expectedError != "" {
+				checkErrorContainsSubstring(t, err, tt.expectedError)
+				return
+			}
+			if err != nil || actual != tt.expected {
+				t.Errorf("expected %s, got %s (%v)", tt.expected, actual, err)
+			}
+		})
+	}
+}
+
+func TestNewPeriod_Bounds(t *testing.T) {
+	ranges := []PeriodRange{
+		{Name: "Vacation", From: "2024-08-01", To: "2024-08-14"},
+		{From: "2024-06-25", To: "2024-07-24"},
+	}
+	tests := []struct {
+		name          string
+		periodType    PeriodType
+		config        Config
+		date          time.Time
+		expectedStart time.Time
+		expectedEnd   time.Time
+		expectedName  string
+		expectedOk    bool
+	}{
+		{
+			name:          "month_from_config",
+			config:        Config{MonthStartDayNumber: 1},
+			date:          utcDate(2024, 7, 15),
+			expectedStart: utcDate(2024, 7, 1),
+			expectedEnd:   utcDate(2024, 8, 1),
+			expectedName:  "2024-07",
+			expectedOk:    true,
+		},
+		{
+			name:          "month_with_start_day_before_it",
+			periodType:    PeriodMonth,
+			config:        Config{MonthStartDayNumber: 25},
+			date:          utcDate(2024, 7, 10),
+			expectedStart: utcDate(2024, 6, 25),
+			expectedEnd:   utcDate(2024, 7, 25),
+			expectedName:  "2024-06",
+			expectedOk:    true,
+		},
+		{
+			name:          "week_from_monday",
+			periodType:    PeriodWeek,
+			date:          utcDate(2024, 7, 14), // Sunday.
+			expectedStart: utcDate(2024, 7, 8),
+			expectedEnd:   utcDate(2024, 7, 15),
+			expectedName:  "2024-07-08",
+			expectedOk:    true,
+		},
+		{
+			name:          "week_from_sunday",
+			periodType:    PeriodWeek,
+			config:        Config{Period: &PeriodConfig{WeekStartDay: "sunday"}},
+			date:          utcDate(2024, 7, 14),
+			expectedStart: utcDate(2024, 7, 14),
+			expectedEnd:   utcDate(2024, 7, 21),
+			expectedName:  "2024-07-14",
+			expectedOk:    true,
+		},
+		{
+			name:          "quarter",
+			config:        Config{Period: &PeriodConfig{Type: PeriodQuarter}},
+			date:          utcDate(2024, 8, 31),
+			expectedStart: utcDate(2024, 7, 1),
+			expectedEnd:   utcDate(2024, 10, 1),
+			expectedName:  "2024-Q3",
+			expectedOk:    true,
+		},
+		{
+			name:          "fiscal_quarter",
+			periodType:    PeriodQuarter,
+			config:        Config{Period: &PeriodConfig{YearStartMonth: 4}},
+			date:          utcDate(2024, 2, 10),
+			expectedStart: utcDate(2024, 1, 1),
+			expectedEnd:   utcDate(2024, 4, 1),
+			expectedName:  "2023-Q4",
+			expectedOk:    true,
+		},
+		{
+			name:          "year",
+			periodType:    PeriodYear,
+			date:          utcDate(2024, 2, 10),
+			expectedStart: utcDate(2024, 1, 1),
+			expectedEnd:   utcDate(2025, 1, 1),
+			expectedName:  "2024",
+			expectedOk:    true,
+		},
+		{
+			name:          "fiscal_year",
+			periodType:    PeriodYear,
+			config:        Config{Period: &PeriodConfig{YearStartMonth: 4}},
+			date:          utcDate(2024, 2, 10),
+			expectedStart: utcDate(2023, 4, 1),
+			expectedEnd:   utcDate(2024, 4, 1),
+			expectedName:  "2023-2024",
+			expectedOk:    true,
+		},
+		{
+			name:          "custom_named",
+			periodType:    PeriodCustom,
+			config:        Config{Period: &PeriodConfig{Ranges: ranges}},
+			date:          utcDate(2024, 8, 14).Add(23 * time.Hour),
+			expectedStart: utcDate(2024, 8, 1),
+			expectedEnd:   utcDate(2024, 8, 15),
+			expectedName:  "Vacation",
+			expectedOk:    true,
+		},
+		{
+			name:          "custom_not_named",
+			periodType:    PeriodCustom,
+			config:        Config{Period: &PeriodConfig{Ranges: ranges}},
+			date:          utcDate(2024, 6, 25),
+			expectedStart: utcDate(2024, 6, 25),
+			expectedEnd:   utcDate(2024, 7, 25),
+			expectedName:  "2024-06-25..2024-07-24",
+			expectedOk:    true,
+		},
+		{
+			name:       "custom_out_of_ranges",
+			periodType: PeriodCustom,
+			config:     Config{Period: &PeriodConfig{Ranges: ranges}},
+			date:       utcDate(2024, 7, 28),
+			expectedOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			period, err := NewPeriod(tt.periodType, &tt.config, nil, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			start, end, ok := period.Bounds(tt.date)
+
+			// Assert
+			if ok != tt.expectedOk {
+				t.Fatalf("expected ok=%v, got %v", tt.expectedOk, ok)
+			}
+			if !ok {
+				return
+			}
+			if !start.Equal(tt.expectedStart) || !end.Equal(tt.expectedEnd.Add(-time.Nanosecond)) {
+				t.Errorf("expected %v..%v, got %v..%v", tt.expectedStart, tt.expectedEnd, start, end)
+			}
+			if name := period.Name(start); name != tt.expectedName {
+				t.Errorf("expected name %s, got %s", tt.expectedName, name)
+			}
+		})
+	}
+}
+
+func TestNewPeriod_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		ranges        []PeriodRange
+		expectedError string
+	}{
+		{"no_ranges", nil, "requires 'period.ranges'"},
+		{"wrong_from", []PeriodRange{{From: "2024.01.01", To: "2024-02-01"}}, "range #1 has wrong 'from' date"},
+		{"wrong_to", []PeriodRange{{From: "2024-01-01", To: "tomorrow"}}, "range #1 has wrong 'to' date"},
+		{"reversed", []PeriodRange{{From: "2024-02-01", To: "2024-01-01"}}, "range #1 ends before it starts"},
+		{
+			"overlap",
+			[]PeriodRange{{Name: "B", From: "2024-01-31", To: "2024-02-28"}, {Name: "A", From: "2024-01-01", To: "2024-01-31"}},
+			"period ranges 'A' and 'B' overlap",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			config := &Config{Period: &PeriodConfig{Type: PeriodCustom, Ranges: tt.ranges}}
+
+			// Act
+			_, err := NewPeriod("", config, nil, time.UTC)
+
+			// Assert
+			checkErrorContainsSubstring(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestBuildPeriodStatistics(t *testing.T) {
+	// Arrange
+	newEntry := func(date time.Time, amount int) JournalEntry {
+		return JournalEntry{
+			Date:                  date,
+			IsExpense:             true,
+			Category:              "Food",
+			FromAccount:           "my",
+			ToAccount:             "shop",
+			AccountCurrency:       "AMD",
+			AccountCurrencyAmount: Money{int: amount},
+			Amounts:               map[string]AmountInCurrency{"AMD": {Currency: "AMD", Amount: Money{int: amount}}},
+		}
+	}
+	journalEntries := []JournalEntry{
+		newEntry(utcDate(2024, 1, 10), 100),
+		newEntry(utcDate(2024, 3, 31), 200),
+		newEntry(utcDate(2024, 4, 1), 400),
+		newEntry(utcDate(2024, 12, 31), 800),
+	}
+	config := &Config{
+		MyAccounts: []string{"my"},
+		Groups:     map[string]*GroupConfig{"Food": {}},
+		Period:     &PeriodConfig{Ranges: []PeriodRange{{Name: "Spring", From: "2024-03-01", To: "2024-05-31"}}},
+	}
+	factory, err := NewStatisticBuilderByCategories(map[string]*AccountStatistics{}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		periodType     PeriodType
+		expectedNames  []string
+		expectedTotals []int
+	}{
+		{PeriodQuarter, []string{"2024-Q1", "2024-Q2", "2024-Q4"}, []int{300, 400, 800}},
+		{PeriodYear, []string{"2024"}, []int{1500}},
+		{PeriodCustom, []string{"Spring"}, []int{600}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.periodType), func(t *testing.T) {
+			period, err := NewPeriod(tt.periodType, config, journalEntries, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			statistics, err := BuildPeriodStatistics(journalEntries, factory, period, nil, &AnomalyDetectionConfig{Disabled: true})
+
+			// Assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(statistics) != len(tt.expectedNames) {
+				t.Fatalf("expected %d periods, got %d", len(tt.expectedNames), len(statistics))
+			}
+			for i, statistic := range statistics {
+				stat := statistic["AMD"]
+				if stat.Name != tt.expectedNames[i] || stat.Expense["Food"].Total.int != tt.expectedTotals[i] {
+					t.Errorf("period #%d: expected %s with %d, got %s with %d", i+1,
+						tt.expectedNames[i], tt.expectedTotals[i], stat.Name, stat.Expense["Food"].Total.int)
+				}
+			}
+		})
+	}
+}
+
+func TestNewPeriod_Salary(t *testing.T) {
+	// Arrange
+	newEntry := func(date time.Time, isExpense bool, category string) JournalEntry {
+		return JournalEntry{Date: date.Add(10 * time.Hour), IsExpense: isExpense, Category: category}
+	}
+	tests := []struct {
+		name           string
+		journalEntries []JournalEntry
+		expectedStarts []time.Time
+		expectedEnd    time.Time
+	}{
+		{
+			name: "salaries_with_parts_and_gap",
+			journalEntries: []JournalEntry{
+				newEntry(utcDate(2024, 1, 10), true, "Food"),
+				newEntry(utcDate(2024, 1, 25), false, "Salary"),
+				newEntry(utcDate(2024, 2, 23), false, "Salary"),
+				newEntry(utcDate(2024, 2, 28), false, "Salary"),
+				newEntry(utcDate(2024, 3, 20), false, "Gifts"),
+				newEntry(utcDate(2024, 5, 2), false, "Salary"),
+				newEntry(utcDate(2024, 6, 10), true, "Food"),
+			},
+			expectedStarts: []time.Time{
+				utcDate(2023, 12, 25),
+				utcDate(2024, 1, 25),
+				utcDate(2024, 2, 23),
+				utcDate(2024, 3, 23),
+				utcDate(2024, 5, 2),
+				utcDate(2024, 6, 2),
+			},
+			expectedEnd: utcDate(2024, 7, 2),
+		},
+		{
+			name: "salary_on_first_day",
+			journalEntries: []JournalEntry{
+				newEntry(utcDate(2024, 1, 5), false, "Salary"),
+				newEntry(utcDate(2024, 2, 6), true, "Food"),
+			},
+			expectedStarts: []time.Time{utcDate(2024, 1, 5), utcDate(2024, 2, 5)},
+			expectedEnd:    utcDate(2024, 3, 5),
+		},
+		{
+			name:           "salary_is_expense",
+			journalEntries: []JournalEntry{newEntry(utcDate(2024, 1, 5), true, "Salary")},
+		},
+	}
+	config := &Config{Period: &PeriodConfig{Type: PeriodSalary, SalaryGroup: "Salary"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			period, err := NewPeriod("", config, tt.journalEntries, time.UTC)
+
+			// Assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.expectedStarts == nil {
+				if _, ok := period.(calendarPeriod); !ok {
+					t.Fatalf("expected fallback to calendar months, got %+v", period)
+				}
+				return
+			}
+			salaryPeriod, ok := period.(customPeriod)
+			if !ok {
+				t.Fatalf("expected list of periods, got %+v", period)
+			}
+			actualStarts := make([]time.Time, 0, len(salaryPeriod.ranges))
+			for _, r := range salaryPeriod.ranges {
+				actualStarts = append(actualStarts, r.start)
+			}
+			if diff := cmp.Diff(tt.expectedStarts, actualStarts); diff != "" {
+				t.Errorf("starts mismatch (-expected +actual):\n%s", diff)
+			}
+			lastEnd := salaryPeriod.ranges[len(salaryPeriod.ranges)-1].end
+			if !lastEnd.Equal(tt.expectedEnd.Add(-time.Nanosecond)) {
+				t.Errorf("expected end %v, got %v", tt.expectedEnd, lastEnd)
+			}
+			if name := period.Name(tt.expectedStarts[0]); name != tt.expectedStarts[0].Format(OutputDateFormat) {
+				t.Errorf("expected name as start date, got %s", name)
+			}
+		})
+	}
+}
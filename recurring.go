@@ -0,0 +1,329 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// RecurringPeriod is a regular interval between recurring payments.
+type RecurringPeriod string
+
+const (
+	RecurringPeriodWeekly  RecurringPeriod = "weekly"
+	RecurringPeriodMonthly RecurringPeriod = "monthly"
+	RecurringPeriodYearly  RecurringPeriod = "yearly"
+)
+
+// recurringPeriodSpec describes how to recognize a period by intervals between payments.
+type recurringPeriodSpec struct {
+	period RecurringPeriod
+	// days is an average length of the period.
+	days float64
+	// toleranceDays is an allowed deviation of an interval from the period.
+	toleranceDays float64
+	// minOccurrences is a minimal number of payments to consider them recurring.
+	minOccurrences int
+}
+
+var recurringPeriodSpecs = []recurringPeriodSpec{
+	{RecurringPeriodWeekly, 7, 1, 4},
+	{RecurringPeriodMonthly, 30.44, 4, 3},
+	{RecurringPeriodYearly, 365.25, 15, 2},
+}
+
+// recurringAmountTolerance is a maximal relative change of amount between consecutive payments
+// to consider them as the same recurring payment.
+const recurringAmountTolerance = 0.3
+
+// recurringMinRegularShare is a minimal share of intervals which should match the period.
+const recurringMinRegularShare = 0.75
+
+// next returns expected date of the next payment after the date.
+func (p RecurringPeriod) next(date time.Time) time.Time {
+	switch p {
+	case RecurringPeriodWeekly:
+		return date.AddDate(0, 0, 7)
+	case RecurringPeriodYearly:
+		return date.AddDate(1, 0, 0)
+	default:
+		return date.AddDate(0, 1, 0)
+	}
+}
+
+// PriceChange is a change of amount of the recurring payment.
+type PriceChange struct {
+	// Date is a date of the first payment with the new amount.
+	Date time.Time
+	// From is a previous amount.
+	From Money
+	// To is a new amount.
+	To Money
+}
+
+// RecurringPayment is a series of regular payments to the same counterparty, i.e. a subscription.
+type RecurringPayment struct {
+	// Name is details of the last payment.
+	Name string
+	// Counterparty is an account which receives payments, may be empty.
+	Counterparty string
+	// Currency is a currency of payments. Origin currency is used for payments in foreign currency.
+	Currency string
+	// Period is a regular interval between payments.
+	Period RecurringPeriod
+	// Occurrences is a number of found payments.
+	Occurrences int
+	// FirstDate is a date of the first payment.
+	FirstDate time.Time
+	// LastDate is a date of the last payment.
+	LastDate time.Time
+	// NextDate is an expected date of the next payment.
+	NextDate time.Time
+	// AverageAmount is an average amount of payments.
+	AverageAmount Money
+	// LastAmount is an amount of the last payment.
+	LastAmount Money
+	// PriceChanges are changes of amount in chronological order.
+	PriceChanges []PriceChange
+	// MissedDates are expected dates between the first and the last payments without payment.
+	MissedDates []time.Time
+	// IsActive is false if the next payment is overdue comparing with the last date of all transactions,
+	// i.e. the subscription looks cancelled.
+	IsActive bool
+}
+
+// recurringCandidate is a payment considered as a part of recurring series.
+type recurringCandidate struct {
+	date    time.Time
+	amount  int
+	details string
+}
+
+// normalizeRecurringDetails returns details in lower case without digits and punctuation,
+// so payments with different dates, card numbers or order IDs look the same.
+func normalizeRecurringDetails(details string) string {
+	fields := strings.FieldsFunc(strings.ToLower(details), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// getPaymentAmount returns currency and amount of the payment as it was charged by the counterparty.
+// Origin currency is preferred because conversion to the account currency changes amount every time.
+func getPaymentAmount(transaction *Transaction) (string, int) {
+	if transaction.OriginCurrency != "" && transaction.OriginCurrencyAmount.int != 0 {
+		return transaction.OriginCurrency, transaction.OriginCurrencyAmount.int
+	}
+	return transaction.AccountCurrency, transaction.Amount.int
+}
+
+// isSimilarAmount checks that amounts differ not more than on `recurringAmountTolerance`.
+func isSimilarAmount(a, b int) bool {
+	return math.Abs(float64(a-b)) <= recurringAmountTolerance*math.Max(math.Abs(float64(a)), math.Abs(float64(b)))
+}
+
+// DetectRecurringPayments finds series of regular expenses with similar amounts to the same counterparty
+// and with the same normalized details. Transfers to own accounts are ignored.
+// Transactions should be sorted by date. Result is sorted by activity and then by average amount.
+func DetectRecurringPayments(transactions []Transaction, accounts map[string]*AccountStatistics) []*RecurringPayment {
+	if len(transactions) == 0 {
+		return nil
+	}
+	lastDate := transactions[0].Date
+	type seriesKey struct {
+		counterparty, details, currency string
+	}
+	keys := make([]seriesKey, 0)
+	series := make(map[seriesKey][][]recurringCandidate)
+	for i := range transactions {
+		transaction := &transactions[i]
+		if transaction.Date.After(lastDate) {
+			lastDate = transaction.Date
+		}
+		if !transaction.IsExpense {
+			continue
+		}
+		if account, ok := accounts[transaction.ToAccount]; ok && account.IsTransactionAccount {
+			continue
+		}
+		currency, amount := getPaymentAmount(transaction)
+		// Details are always a part of the key because some banks set placeholder or card processing
+		// account as the counterparty of all purchases.
+		key := seriesKey{
+			counterparty: transaction.ToAccount,
+			details:      normalizeRecurringDetails(transaction.Details),
+			currency:     currency,
+		}
+		if key.counterparty == "" && key.details == "" {
+			continue
+		}
+		candidate := recurringCandidate{date: transaction.Date, amount: amount, details: transaction.Details}
+
+		// Put into series with similar last amount, so occasional purchases don't break subscriptions.
+		keySeries, ok := series[key]
+		if !ok {
+			keys = append(keys, key)
+		}
+		found := false
+		for j, s := range keySeries {
+			if isSimilarAmount(s[len(s)-1].amount, amount) {
+				keySeries[j] = append(s, candidate)
+				found = true
+				break
+			}
+		}
+		if !found {
+			keySeries = append(keySeries, []recurringCandidate{candidate})
+		}
+		series[key] = keySeries
+	}
+
+	result := make([]*RecurringPayment, 0)
+	for _, key := range keys {
+		for _, candidates := range series[key] {
+			payment := detectRecurringPayment(candidates, lastDate)
+			if payment == nil {
+				continue
+			}
+			payment.Counterparty = key.counterparty
+			payment.Currency = key.currency
+			result = append(result, payment)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].IsActive != result[j].IsActive {
+			return result[i].IsActive
+		}
+		if result[i].Currency != result[j].Currency {
+			return result[i].Currency < result[j].Currency
+		}
+		return result[i].AverageAmount.int > result[j].AverageAmount.int
+	})
+	return result
+}
+
+// daysBetween returns number of calendar days between dates.
+func daysBetween(from, to time.Time) float64 {
+	return math.Round(to.Sub(from).Hours() / 24)
+}
+
+// detectRecurringPayment checks that chronologically sorted payments are regular.
+// Returns nil if they are not.
+func detectRecurringPayment(candidates []recurringCandidate, lastDate time.Time) *RecurringPayment {
+	if len(candidates) < 2 {
+		return nil
+	}
+	intervals := make([]float64, len(candidates)-1)
+	for i := 1; i < len(candidates); i++ {
+		intervals[i-1] = daysBetween(candidates[i-1].date, candidates[i].date)
+	}
+	sortedIntervals := append([]float64{}, intervals...)
+	sort.Float64s(sortedIntervals)
+	median := sortedIntervals[len(sortedIntervals)/2]
+	if len(sortedIntervals)%2 == 0 {
+		median = (median + sortedIntervals[len(sortedIntervals)/2-1]) / 2
+	}
+
+	var spec *recurringPeriodSpec
+	for i := range recurringPeriodSpecs {
+		if math.Abs(median-recurringPeriodSpecs[i].days) <= recurringPeriodSpecs[i].toleranceDays {
+			spec = &recurringPeriodSpecs[i]
+			break
+		}
+	}
+	if spec == nil || len(candidates) < spec.minOccurrences {
+		return nil
+	}
+
+	// Check intervals. Interval close to a multiple of the period means missed payments.
+	regular := 0
+	missedDates := make([]time.Time, 0)
+	for i, interval := range intervals {
+		periods := math.Round(interval / spec.days)
+		if periods < 1 || math.Abs(interval-periods*spec.days) > spec.toleranceDays*periods {
+			continue
+		}
+		regular++
+		expected := candidates[i].date
+		for n := 1; n < int(periods); n++ {
+			expected = spec.period.next(expected)
+			missedDates = append(missedDates, expected)
+		}
+	}
+	if float64(regular) < recurringMinRegularShare*float64(len(intervals)) || len(missedDates) >= len(candidates) {
+		return nil
+	}
+
+	last := candidates[len(candidates)-1]
+	payment := &RecurringPayment{
+		Name:        last.details,
+		Period:      spec.period,
+		Occurrences: len(candidates),
+		FirstDate:   candidates[0].date,
+		LastDate:    last.date,
+		NextDate:    spec.period.next(last.date),
+		LastAmount:  Money{int: last.amount},
+		MissedDates: missedDates,
+	}
+	payment.IsActive = daysBetween(payment.NextDate, lastDate) <= spec.toleranceDays
+	sum := 0
+	for i, candidate := range candidates {
+		sum += candidate.amount
+		if i > 0 && candidate.amount != candidates[i-1].amount {
+			payment.PriceChanges = append(payment.PriceChanges, PriceChange{
+				Date: candidate.date,
+				From: Money{int: candidates[i-1].amount},
+				To:   Money{int: candidate.amount},
+			})
+		}
+	}
+	payment.AverageAmount = Money{int: int(math.Round(float64(sum) / float64(len(candidates))))}
+	return payment
+}
+
+// LocalizedPeriod returns localized name of the period.
+func (p *RecurringPayment) LocalizedPeriod() string {
+	return i18n.T(string(p.Period))
+}
+
+// recurringPaymentsToString returns human readable list of recurring payments.
+func recurringPaymentsToString(payments []*RecurringPayment) string {
+	if len(payments) == 0 {
+		return ""
+	}
+	details := make([]string, 0, len(payments))
+	nActive := 0
+	for _, payment := range payments {
+		line := i18n.T("paymentName period amount currency last next",
+			"paymentName", payment.Name,
+			"period", payment.LocalizedPeriod(),
+			"amount", payment.AverageAmount,
+			"currency", payment.Currency,
+			"last", payment.LastDate.Format(OutputDateFormat),
+			"next", payment.NextDate.Format(OutputDateFormat),
+		)
+		if payment.IsActive {
+			nActive++
+		} else {
+			line += i18n.T(" - looks cancelled")
+		}
+		for _, change := range payment.PriceChanges {
+			line += i18n.T(", price changed from to on date",
+				"from", change.From.StringNoIndent(),
+				"to", change.To.StringNoIndent(),
+				"date", change.Date.Format(OutputDateFormat),
+			)
+		}
+		if len(payment.MissedDates) > 0 {
+			line += i18n.T(", missed n", "n", len(payment.MissedDates))
+		}
+		details = append(details, line)
+	}
+	return i18n.T("RecurringPayments_format",
+		"nPayments", len(payments),
+		"nActive", nActive,
+		"detailsPayments", details,
+	)
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestNormalizeRecurringDetails(t *testing.T) {
+	tests := []struct {
+		details  string
+		expected string
+	}{
+		{"NETFLIX.COM 12/05 card *1234", "netflix com card"},
+		{"  Yandex  Plus, order #555 ", "yandex plus order"},
+		{"12345", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.details, func(t *testing.T) {
+
+			// Act
+			actual := normalizeRecurringDetails(tt.details)
+
+			// Assert
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDetectRecurringPayments(t *testing.T) {
+	// Last transaction in data to check activity of subscriptions.
+	lastTransaction := newTestTransaction(nil, utcDate(2024, 7, 20), 1000, "", "SHOP")
+	tests := []struct {
+		name         string
+		transactions []Transaction
+		accounts     map[string]*AccountStatistics
+		expected     []*RecurringPayment
+	}{
+		{
+			name: "monthly_with_price_change_and_missed",
+			transactions: []Transaction{
+				newTestTransaction(nil, utcDate(2024, 1, 5), -3990000, "", "NETFLIX.COM 05/01"),
+				newTestTransaction(nil, utcDate(2024, 2, 5), -3990000, "", "NETFLIX.COM 05/02"),
+				newTestTransaction(nil, utcDate(2024, 4, 4), -3990000, "", "NETFLIX.COM 04/04"),
+				newTestTransaction(nil, utcDate(2024, 5, 6), -4490000, "", "NETFLIX.COM 06/05"),
+				newTestTransaction(nil, utcDate(2024, 6, 5), -4490000, "", "NETFLIX.COM 05/06"),
+				newTestTransaction(nil, utcDate(2024, 7, 5), -4490000, "", "NETFLIX.COM 05/07"),
+			},
+			expected: []*RecurringPayment{{
+				Name:          "NETFLIX.COM 05/07",
+				Currency:      "AMD",
+				Period:        RecurringPeriodMonthly,
+				Occurrences:   6,
+				FirstDate:     utcDate(2024, 1, 5),
+				LastDate:      utcDate(2024, 7, 5),
+				NextDate:      utcDate(2024, 8, 5),
+				AverageAmount: Money{int: 4240000},
+				LastAmount:    Money{int: 4490000},
+				PriceChanges: []PriceChange{
+					{Date: utcDate(2024, 5, 6), From: Money{int: 3990000}, To: Money{int: 4490000}},
+				},
+				MissedDates: []time.Time{utcDate(2024, 3, 5)},
+				IsActive:    true,
+			}},
+		},
+		{
+			name: "weekly",
+			transactions: newTestTransactions(
+				[]time.Time{utcDate(2024, 6, 22), utcDate(2024, 6, 29), utcDate(2024, 7, 6), utcDate(2024, 7, 13), utcDate(2024, 7, 20)},
+				-2000000, "Tennis club",
+			),
+			expected: []*RecurringPayment{{
+				Name:          "Tennis club",
+				Currency:      "AMD",
+				Period:        RecurringPeriodWeekly,
+				Occurrences:   5,
+				FirstDate:     utcDate(2024, 6, 22),
+				LastDate:      utcDate(2024, 7, 20),
+				NextDate:      utcDate(2024, 7, 27),
+				AverageAmount: Money{int: 2000000},
+				LastAmount:    Money{int: 2000000},
+				MissedDates:   []time.Time{},
+				IsActive:      true,
+			}},
+		},
+		{
+			name:         "yearly_cancelled",
+			transactions: newTestTransactions([]time.Time{utcDate(2022, 3, 1), utcDate(2023, 3, 3)}, -10000000, "Domain renewal"),
+			expected: []*RecurringPayment{{
+				Name:          "Domain renewal",
+				Currency:      "AMD",
+				Period:        RecurringPeriodYearly,
+				Occurrences:   2,
+				FirstDate:     utcDate(2022, 3, 1),
+				LastDate:      utcDate(2023, 3, 3),
+				NextDate:      utcDate(2024, 3, 3),
+				AverageAmount: Money{int: 10000000},
+				LastAmount:    Money{int: 10000000},
+				MissedDates:   []time.Time{},
+				IsActive:      false,
+			}},
+		},
+		{
+			name: "occasional_purchase_at_the_same_counterparty",
+			transactions: []Transaction{
+				newTestTransaction(nil, utcDate(2024, 4, 10), -990000, "apple", "APPLE.COM/BILL"),
+				newTestTransaction(nil, utcDate(2024, 5, 10), -990000, "apple", "APPLE.COM/BILL"),
+				newTestTransaction(nil, utcDate(2024, 5, 15), -15000000, "apple", "APPLE.COM/BILL"),
+				newTestTransaction(nil, utcDate(2024, 6, 10), -990000, "apple", "APPLE.COM/BILL"),
+				newTestTransaction(nil, utcDate(2024, 7, 10), -990000, "apple", "APPLE.COM/BILL"),
+			},
+			expected: []*RecurringPayment{{
+				Name:          "APPLE.COM/BILL",
+				Counterparty:  "apple",
+				Currency:      "AMD",
+				Period:        RecurringPeriodMonthly,
+				Occurrences:   4,
+				FirstDate:     utcDate(2024, 4, 10),
+				LastDate:      utcDate(2024, 7, 10),
+				NextDate:      utcDate(2024, 8, 10),
+				AverageAmount: Money{int: 990000},
+				LastAmount:    Money{int: 990000},
+				MissedDates:   []time.Time{},
+				IsActive:      true,
+			}},
+		},
+		{
+			name: "irregular",
+			transactions: newTestTransactions(
+				[]time.Time{utcDate(2024, 1, 3), utcDate(2024, 1, 20), utcDate(2024, 3, 1), utcDate(2024, 3, 5), utcDate(2024, 6, 1)},
+				-5000000, "SUPERMARKET",
+			),
+			expected: []*RecurringPayment{},
+		},
+		{
+			name: "different_amounts",
+			transactions: []Transaction{
+				newTestTransaction(nil, utcDate(2024, 4, 1), -1000000, "", "SUPERMARKET"),
+				newTestTransaction(nil, utcDate(2024, 5, 1), -3000000, "", "SUPERMARKET"),
+				newTestTransaction(nil, utcDate(2024, 6, 1), -1500000, "", "SUPERMARKET"),
+				newTestTransaction(nil, utcDate(2024, 7, 1), -5000000, "", "SUPERMARKET"),
+			},
+			expected: []*RecurringPayment{},
+		},
+		{
+			name: "different_merchants_with_placeholder_counterparty",
+			transactions: []Transaction{
+				newTestTransaction(nil, utcDate(2024, 4, 1), -1000000, "UnknownAccount", "CAFE CENTRAL"),
+				newTestTransaction(nil, utcDate(2024, 5, 1), -1000000, "UnknownAccount", "BOOKSTORE"),
+				newTestTransaction(nil, utcDate(2024, 6, 1), -1000000, "UnknownAccount", "PHARMACY"),
+				newTestTransaction(nil, utcDate(2024, 7, 1), -1000000, "UnknownAccount", "CINEMA"),
+			},
+			expected: []*RecurringPayment{},
+		},
+		{
+			name: "transfers_to_own_account",
+			transactions: []Transaction{
+				newTestTransaction(nil, utcDate(2024, 5, 1), -1000000, "savings", "To savings"),
+				newTestTransaction(nil, utcDate(2024, 6, 1), -1000000, "savings", "To savings"),
+				newTestTransaction(nil, utcDate(2024, 7, 1), -1000000, "savings", "To savings"),
+			},
+			accounts: map[string]*AccountStatistics{"savings": {Number: "savings", IsTransactionAccount: true}},
+			expected: []*RecurringPayment{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			transactions := append(tt.transactions, lastTransaction)
+
+			// Act
+			actual := DetectRecurringPayments(transactions, tt.accounts)
+
+			// Assert
+			if diff := cmp.Diff(tt.expected, actual, cmp.AllowUnexported(Money{}), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("recurring payments mismatch (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRecurringPaymentsToString(t *testing.T) {
+	// Arrange
+	payments := []*RecurringPayment{
+		{
+			Name:          "NETFLIX",
+			Currency:      "AMD",
+			Period:        RecurringPeriodMonthly,
+			LastDate:      utcDate(2024, 7, 5),
+			NextDate:      utcDate(2024, 8, 5),
+			AverageAmount: Money{int: 4240000},
+			PriceChanges: []PriceChange{
+				{Date: utcDate(2024, 5, 6), From: Money{int: 3990000}, To: Money{int: 4490000}},
+			},
+			MissedDates: []time.Time{utcDate(2024, 3, 5)},
+			IsActive:    true,
+		},
+		{
+			Name:          "Domain renewal",
+			Currency:      "AMD",
+			Period:        RecurringPeriodYearly,
+			LastDate:      utcDate(2023, 3, 3),
+			NextDate:      utcDate(2024, 3, 3),
+			AverageAmount: Money{int: 10000000},
+		},
+	}
+
+	// Act
+	actual := recurringPaymentsToString(payments)
+
+	// Assert
+	for _, expected := range []string{
+		"Recurring payments (total 2, active 1)",
+		"NETFLIX",
+		"monthly",
+		"next 2024-08-05",
+		"price changed from 3,990.00 to 4,490.00 on 2024-05-06",
+		"missed 1",
+		"Domain renewal",
+		"looks cancelled",
+	} {
+		if !strings.Contains(actual, expected) {
+			t.Errorf("expected %q in:\n%s", expected, actual)
+		}
+	}
+	if recurringPaymentsToString(nil) != "" {
+		t.Error("expected empty string for no payments")
+	}
+}
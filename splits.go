@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// TransactionSplit splits one transaction across several categories.
+type TransactionSplit struct {
+	// Transaction is a human-readable description of the transaction, only to simplify manual editing of the file.
+	Transaction string `yaml:"transaction,omitempty"`
+	// Parts are parts of transaction amount with categories.
+	Parts []SplitPart `yaml:"parts"`
+}
+
+// SplitPart is a part of transaction amount in one category.
+// Either `Amount` or `Percent` may be set. Part without both of them gets the rest of transaction amount.
+type SplitPart struct {
+	// Group is a name of the group to put this part into.
+	Group string `yaml:"group"`
+	// Amount is an amount in the account currency of the transaction.
+	Amount *float64 `yaml:"amount,omitempty"`
+	// Percent is a percent of transaction amount.
+	Percent *float64 `yaml:"percent,omitempty"`
+}
+
+// validateTransactionSplit checks parts of the split which don't depend on the transaction.
+func validateTransactionSplit(split *TransactionSplit) error {
+	if split == nil || len(split.Parts) < 2 {
+		return errors.New("split should have at least 2 parts")
+	}
+	restParts := 0
+	for i, part := range split.Parts {
+		switch {
+		case part.Group == "":
+			return fmt.Errorf("part #%d has no group", i+1)
+		case part.Amount != nil && part.Percent != nil:
+			return fmt.Errorf("part #%d has both 'amount' and 'percent'", i+1)
+		case part.Amount != nil && *part.Amount <= 0:
+			return fmt.Errorf("part #%d has not positive amount", i+1)
+		case part.Percent != nil && (*part.Percent <= 0 || *part.Percent > 100):
+			return fmt.Errorf("part #%d has percent out of (0, 100] range", i+1)
+		case part.Amount == nil && part.Percent == nil:
+			restParts++
+		}
+	}
+	if restParts > 1 {
+		return errors.New("only one part may be without 'amount' and 'percent'")
+	}
+	return nil
+}
+
+// computeSplitAmounts returns amounts of parts in money units for the transaction amount `total` in money units.
+// Amounts are rounded to minor units of the currency of the transaction.
+// Part without amount and percent gets the rest. If all parts are set in percents which sum to 100
+// then rounding difference goes into the last part. Otherwise amounts should sum to the total exactly.
+func computeSplitAmounts(total int, parts []SplitPart, currency string) ([]int, error) {
+	result := make([]int, len(parts))
+	restIndex := -1
+	sum := 0
+	percentsSum := 0.0
+	for i, part := range parts {
+		switch {
+		case part.Amount != nil:
+			result[i] = moneyFromFloat(*part.Amount).RoundToCurrency(currency).int
+		case part.Percent != nil:
+			result[i] = roundToMinorUnits(float64(total)**part.Percent/100, currency)
+			percentsSum += *part.Percent
+		default:
+			restIndex = i
+		}
+		sum += result[i]
+	}
+	difference := total - sum
+	switch {
+	case restIndex >= 0:
+		result[restIndex] = difference
+	case math.Abs(percentsSum-100) < 1e-9:
+		result[len(result)-1] += difference
+	case difference != 0:
+		return nil, fmt.Errorf("parts sum to %s instead of %s",
+			Money{int: sum}.StringNoIndent(), Money{int: total}.StringNoIndent())
+	}
+	for i, amount := range result {
+		if amount <= 0 {
+			return nil, fmt.Errorf("part #%d gets not positive amount %s of %s",
+				i+1, Money{int: amount}.StringNoIndent(), Money{int: total}.StringNoIndent())
+		}
+	}
+	return result, nil
+}
+
+// distributeProportionally splits value in the currency into parts proportional to `shares`.
+// Rounding difference goes into the last part so parts always sum to the value.
+func distributeProportionally(value int, currency string, shares []int, sharesTotal int) []int {
+	result := make([]int, len(shares))
+	rest := value
+	for i, share := range shares[:len(shares)-1] {
+		result[i] = roundToMinorUnits(float64(value)*float64(share)/float64(sharesTotal), currency)
+		rest -= result[i]
+	}
+	result[len(result)-1] = rest
+	return result
+}
+
+// SplitJournalEntry splits journal entry into entries per part of the transaction split.
+// Entries not categorized by split are returned as is.
+func (c *Categorization) SplitJournalEntry(entry JournalEntry) ([]JournalEntry, error) {
+	split, ok := c.splits[entry.Fingerprint]
+	if !ok || entry.RuleType != RuleTypeSplit {
+		return []JournalEntry{entry}, nil
+	}
+	total := entry.AccountCurrencyAmount.int
+	amounts, err := computeSplitAmounts(total, split.Parts, entry.AccountCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("split '%s' can't be applied to transaction '%s': %w",
+			entry.Fingerprint, entry.Details, err)
+	}
+	originAmounts := distributeProportionally(entry.OriginCurrencyAmount.int, entry.OriginCurrency, amounts, total)
+	convertedAmounts := make(map[string][]int, len(entry.Amounts))
+	for currency, amount := range entry.Amounts {
+		convertedAmounts[currency] = distributeProportionally(amount.Amount.int, currency, amounts, total)
+	}
+
+	result := make([]JournalEntry, len(split.Parts))
+	for i, part := range split.Parts {
+		partEntry := entry
+		partEntry.Category = part.Group
+		partEntry.CategoryPath = c.getCategoryPath(part.Group)
+		partEntry.AccountCurrencyAmount = Money{int: amounts[i]}
+		partEntry.OriginCurrencyAmount = Money{int: originAmounts[i]}
+		partEntry.Amounts = make(map[string]AmountInCurrency, len(entry.Amounts))
+		for currency, amount := range entry.Amounts {
+			amount.Amount = Money{int: convertedAmounts[currency][i]}
+			partEntry.Amounts[currency] = amount
+		}
+		result[i] = partEntry
+	}
+	return result, nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateTransactionSplit(t *testing.T) {
+	tests := []struct {
+		name          string
+		parts         []SplitPart
+		expectedError string
+	}{
+		{"valid", []SplitPart{{Group: "A", Amount: floatPtr(10)}, {Group: "B", Percent: floatPtr(50)}, {Group: "C"}}, ""},
+		{"one_part", []SplitPart{{Group: "A"}}, "at least 2 parts"},
+		{"no_group", []SplitPart{{Group: "A"}, {Amount: floatPtr(10)}}, "part #2 has no group"},
+		{"amount_and_percent", []SplitPart{{Group: "A", Amount: floatPtr(10), Percent: floatPtr(10)}, {Group: "B"}}, "both 'amount' and 'percent'"},
+		{"negative_amount", []SplitPart{{Group: "A", Amount: floatPtr(-10)}, {Group: "B"}}, "not positive amount"},
+		{"big_percent", []SplitPart{{Group: "A", Percent: floatPtr(101)}, {Group: "B"}}, "out of (0, 100] range"},
+		{"two_rest_parts", []SplitPart{{Group: "A"}, {Group: "B"}}, "only one part"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			err := validateTransactionSplit(&TransactionSplit{Parts: tt.parts})
+
+			// Assert
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			checkErrorContainsSubstring(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestComputeSplitAmounts(t *testing.T) {
+	tests := []struct {
+		name          string
+		total         int
+		parts         []SplitPart
+		expected      []int
+		expectedError string
+	}{
+		{
+			name:     "amount_and_rest",
+			total:    100000,
+			parts:    []SplitPart{{Group: "A", Amount: floatPtr(30.5)}, {Group: "B"}},
+			expected: []int{30500, 69500},
+		},
+		{
+			name:     "percents_with_rounding",
+			total:    10000,
+			parts:    []SplitPart{{Group: "A", Percent: floatPtr(33.33)}, {Group: "B", Percent: floatPtr(33.33)}, {Group: "C", Percent: floatPtr(33.34)}},
+			expected: []int{3330, 3330, 3340},
+		},
+		{
+			name:     "percent_and_rest",
+			total:    9990,
+			parts:    []SplitPart{{Group: "A"}, {Group: "B", Percent: floatPtr(50)}},
+			expected: []int{4990, 5000},
+		},
+		{
+			name:     "exact_amounts",
+			total:    100000,
+			parts:    []SplitPart{{Group: "A", Amount: floatPtr(40)}, {Group: "B", Amount: floatPtr(60)}},
+			expected: []int{40000, 60000},
+		},
+		{
+			name:          "amounts_dont_sum",
+			total:         100000,
+			parts:         []SplitPart{{Group: "A", Amount: floatPtr(40)}, {Group: "B", Amount: floatPtr(50)}},
+			expectedError: "parts sum to 90.00 instead of 100.00",
+		},
+		{
+			name:          "nothing_left_for_rest",
+			total:         100000,
+			parts:         []SplitPart{{Group: "A", Amount: floatPtr(100)}, {Group: "B"}},
+			expectedError: "part #2 gets not positive amount",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			actual, err := computeSplitAmounts(tt.total, tt.parts, "AMD")
+
+			// Assert
+			if tt.expectedError != "" {
+				checkErrorContainsSubstring(t, err, tt.expectedError)
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Errorf("amounts mismatch (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+// newTestSplitEntry returns categorization with split of supermarket payment and journal entry of the payment.
+func newTestSplitEntry(t *testing.T) (*Categorization, JournalEntry) {
+	t.Helper()
+	transaction := newTestTransaction(newTestSource("my", "AMD", testDate, testDate, nil, nil), testDate, -10000000, "shop", "SUPERMARKET")
+	config := &Config{
+		Groups: map[string]*GroupConfig{
+			"Food":      {Substrings: []string{"SUPERMARKET"}},
+			"Household": {Parent: "Home"},
+		},
+		TransactionSplits: map[string]*TransactionSplit{
+			TransactionFingerprint(&transaction): {Parts: []SplitPart{
+				{Group: "Food", Percent: floatPtr(70)},
+				{Group: "Household"},
+			}},
+		},
+	}
+	categorization, err := NewCategorization(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	match, _, err := categorization.CategorizeTransaction(&transaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return categorization, newTestJournalEntry(transaction, match, AmountInCurrency{Currency: "USD", Amount: Money{int: 25010}})
+}
+
+func TestCategorization_SplitJournalEntry(t *testing.T) {
+	// Arrange
+	categorization, entry := newTestSplitEntry(t)
+
+	// Act
+	actual, err := categorization.SplitJournalEntry(entry)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", actual)
+	}
+	expected := []struct {
+		category string
+		path     []string
+		amd      int
+		usd      int
+	}{
+		{"Food", []string{"Food"}, 7000000, 17510},
+		{"Household", []string{"Home", "Household"}, 3000000, 7500},
+	}
+	for i, e := range expected {
+		part := actual[i]
+		if part.Category != e.category || !cmp.Equal(part.CategoryPath, e.path) || part.RuleType != RuleTypeSplit {
+			t.Errorf("part #%d: expected category %s %v, got %s %v %s", i+1, e.category, e.path,
+				part.Category, part.CategoryPath, part.RuleType)
+		}
+		if part.AccountCurrencyAmount.int != e.amd || part.Amounts["AMD"].Amount.int != e.amd {
+			t.Errorf("part #%d: expected %d AMD, got %+v", i+1, e.amd, part)
+		}
+		if part.Amounts["USD"].Amount.int != e.usd {
+			t.Errorf("part #%d: expected %d USD, got %+v", i+1, e.usd, part.Amounts["USD"])
+		}
+	}
+	if entry.Amounts["USD"].Amount.int != 25010 {
+		t.Errorf("original entry is changed: %+v", entry)
+	}
+}
+
+func TestBuildBeancountFile_Split(t *testing.T) {
+	// Arrange
+	categorization, entry := newTestSplitEntry(t)
+	entries, err := categorization.SplitJournalEntry(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts := map[string]*AccountStatistics{
+		"my": {Number: "my", Source: entry.Source, From: testDate},
+	}
+	path := filepath.Join(t.TempDir(), "test.beancount")
+
+	// Act
+	_, err = buildBeancountFile(entries, nil, accounts, nil, path)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(content)
+	if strings.Count(text, "\"SUPERMARKET\"") != 1 {
+		t.Errorf("expected one Beancount transaction, got:\n%s", text)
+	}
+	for _, posting := range []string{
+		"Expenses:Food:shop    7,000.00 AMD",
+		"Expenses:Home:Household:shop    3,000.00 AMD",
+	} {
+		if !strings.Contains(text, posting) {
+			t.Errorf("expected posting %q, got:\n%s", posting, text)
+		}
+	}
+}
+
+func TestBuildJournalEntries_WrongSplitIsIgnored(t *testing.T) {
+	// Arrange
+	transaction := newTestTransaction(newTestSource("my", "AMD", testDate, testDate, nil, nil), testDate, -10000000, "shop", "SUPERMARKET")
+	config := &Config{
+		ConvertToCurrencies: []string{"AMD"},
+		Groups: map[string]*GroupConfig{
+			"Food":      {Substrings: []string{"SUPERMARKET"}},
+			"Household": {},
+		},
+		TransactionSplits: map[string]*TransactionSplit{
+			// Fixed amounts don't sum to the transaction amount.
+			TransactionFingerprint(&transaction): {Parts: []SplitPart{
+				{Group: "Food", Amount: floatPtr(7000)},
+				{Group: "Household", Amount: floatPtr(2000)},
+			}},
+		},
+	}
+	dataMart, err := BuildDataMart([]Transaction{transaction}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	categorization, err := NewCategorization(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	actual, _, err := buildJournalEntries(dataMart, categorization)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(actual) != 1 || actual[0].Category != "Food" || actual[0].AccountCurrencyAmount.int != 10000000 {
+		t.Errorf("expected one not split entry in 'Food', got %+v", actual)
+	}
+}
@@ -0,0 +1,444 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shakinm/xlsReader/xls"
+	"github.com/tealeg/xlsx"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// SpreadsheetParserName is a name of parser to use in `sources` configuration.
+	SpreadsheetParserName = "spreadsheet"
+	spreadsheetTypeName   = "Spreadsheet with configured layout"
+)
+
+func init() {
+	RegisterParser(ParserRegistration{
+		Name:        SpreadsheetParserName,
+		TypeName:    spreadsheetTypeName,
+		Tag:         "Excel",
+		Version:     2,
+		DefaultGlob: "statement*.xls*",
+		NewParser:   newSpreadsheetFileParser,
+	})
+}
+
+// SpreadsheetHeader describes how to find header row of transactions.
+type SpreadsheetHeader struct {
+	// Contains are texts which all should be values of cells of the header row, case-insensitive.
+	// Header of the date column by default.
+	Contains []string `yaml:"contains"`
+	// RowsAbove is a number of rows above the found row which are parts of the header too.
+	// Useful when headers of some columns are in cells merged over several rows.
+	RowsAbove int `yaml:"rowsAbove" validate:"min=0"`
+}
+
+// SpreadsheetValue is a value for the whole file, like account number, set as constant or found by label.
+type SpreadsheetValue struct {
+	// Value is a constant value. Label is not searched if it is set.
+	Value string `yaml:"value"`
+	// Label is a text at the start of a cell above the header row. Value is the rest of the cell text,
+	// or the next non-empty cell to the right if the rest is empty, or the cell below if there is no such cell.
+	Label string `yaml:"label"`
+	// Pattern is a regular expression to extract value from the found text. The first group is used if any.
+	Pattern string `yaml:"pattern"`
+	// Remove are characters to remove from the value, like "-" in "2050-2050".
+	Remove string `yaml:"remove"`
+}
+
+// IsSet returns true if value is configured.
+func (v SpreadsheetValue) IsSet() bool {
+	return v.Value != "" || v.Label != ""
+}
+
+// SpreadsheetEnd describes row after the last transaction. Without it all rows till the end of sheet are parsed.
+type SpreadsheetEnd struct {
+	// Contains are texts one of which should be at the start of some cell of the row, case-insensitive.
+	Contains []string `yaml:"contains"`
+	// EmptyRow is a flag that transactions end on the first empty row.
+	EmptyRow bool `yaml:"emptyRow"`
+}
+
+// SpreadsheetSpec describes layout of XLS or XLSX statements of some bank.
+type SpreadsheetSpec struct {
+	// Name is a human readable name of files type, used in logs and warnings.
+	Name string `yaml:"name"`
+	// Sheet is a name of the sheet with transactions, the first sheet by default.
+	Sheet string `yaml:"sheet"`
+	// Header describes how to find header row of transactions.
+	Header SpreadsheetHeader `yaml:"header"`
+	// AccountNumber is an own account number for all transactions of the file. Column is used if it is not set.
+	AccountNumber SpreadsheetValue `yaml:"accountNumber"`
+	// Currency is a currency of the account for all transactions of the file. Column is used if it is not set.
+	Currency SpreadsheetValue `yaml:"currency"`
+	// Counterparty is an account number of the other side for transactions with empty counterparty column,
+	// like a placeholder for statements without counterparties.
+	Counterparty SpreadsheetValue `yaml:"counterparty"`
+	// DateFormat is a layout of dates in Go format like "02.01.2006", "2006-01-02" by default.
+	// Dates stored as Excel numbers are supported without it.
+	DateFormat string `yaml:"dateFormat"`
+	// TimeZone is an IANA name of time zone for dates without offset like "Asia/Yerevan", UTC by default.
+	TimeZone string `yaml:"timeZone"`
+	// DecimalSeparator is "." or ",", by default it is guessed for each amount.
+	DecimalSeparator string `yaml:"decimalSeparator"`
+	// Columns maps columns to fields of transactions, the same way as for "mappedCsv" parser.
+	Columns MappedCsvColumns `yaml:"columns"`
+	// KeepOriginAmount is a flag to keep origin amount of transactions in account currency too.
+	// By default origin amount is kept only for transactions in other currencies.
+	KeepOriginAmount bool `yaml:"keepOriginAmount"`
+	// SwapAccounts is a flag to send expenses from counterparty to own account and incomes back,
+	// the same way as "acbaRegularAccountXls" parser does.
+	SwapAccounts bool `yaml:"swapAccounts"`
+	// End describes row after the last transaction.
+	End SpreadsheetEnd `yaml:"end"`
+}
+
+// SpreadsheetFileParserOptions are options of "spreadsheet" source.
+type SpreadsheetFileParserOptions struct {
+	// SpecFile is a path to YAML file with spec. Spec may be set inline instead.
+	SpecFile        string `yaml:"specFile"`
+	SpreadsheetSpec `yaml:",inline"`
+}
+
+// SpreadsheetFileParser parses XLS and XLSX files with layout described by spec.
+type SpreadsheetFileParser struct {
+	Spec SpreadsheetSpec
+}
+
+func newSpreadsheetFileParser(options map[string]any, _ *Config) (FileParser, error) {
+	parserOptions := SpreadsheetFileParserOptions{}
+	if err := decodeParserOptions(options, &parserOptions); err != nil {
+		return nil, err
+	}
+	spec := parserOptions.SpreadsheetSpec
+	if parserOptions.SpecFile != "" {
+		if !reflect.DeepEqual(spec, SpreadsheetSpec{}) {
+			return nil, errors.New("either 'specFile' or spec fields should be set, not both")
+		}
+		var err error
+		if spec, err = readSpreadsheetSpec(parserOptions.SpecFile); err != nil {
+			return nil, err
+		}
+	}
+	return newSpreadsheetFileParserFromSpec(spec)
+}
+
+// readSpreadsheetSpec reads spec from YAML file.
+func readSpreadsheetSpec(filePath string) (SpreadsheetSpec, error) {
+	var spec SpreadsheetSpec
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return spec, fmt.Errorf("failed to read spec file: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return spec, fmt.Errorf("wrong spec in '%s': %w", filePath, err)
+	}
+	return spec, validate.Struct(spec)
+}
+
+func newSpreadsheetFileParserFromSpec(spec SpreadsheetSpec) (FileParser, error) {
+	if spec.Name == "" {
+		spec.Name = spreadsheetTypeName
+	}
+	if spec.DateFormat == "" {
+		spec.DateFormat = OutputDateFormat
+	}
+	columns := &spec.Columns
+	if err := columns.applyDefaults(true, spec.AccountNumber.IsSet(), spec.Currency.IsSet()); err != nil {
+		return nil, err
+	}
+	if len(spec.Header.Contains) == 0 {
+		if columns.Date.Header == "" {
+			return nil, errors.New("header texts should be set if date column is set by number")
+		}
+		spec.Header.Contains = []string{columns.Date.Header}
+	}
+	for name, value := range map[string]SpreadsheetValue{
+		"accountNumber": spec.AccountNumber,
+		"currency":      spec.Currency,
+		"counterparty":  spec.Counterparty,
+	} {
+		if _, err := regexp.Compile(value.Pattern); err != nil {
+			return nil, fmt.Errorf("wrong pattern of '%s': %w", name, err)
+		}
+	}
+	if _, err := newDateFormat(spec.TimeZone, spec.DateFormat); err != nil {
+		return nil, err
+	}
+	if err := checkDecimalSeparator(spec.DecimalSeparator); err != nil {
+		return nil, err
+	}
+	return SpreadsheetFileParser{Spec: spec}, nil
+}
+
+// readSpreadsheetRows reads values of cells from the sheet with the specified name or from the first sheet.
+// Container is detected by content because some banks give ".xls" extension to XLSX files.
+// Rows and cells keep positions, so empty ones are represented by empty values.
+func readSpreadsheetRows(filePath, sheetName string) ([][]string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	switch {
+	case bytes.HasPrefix(data, zipSignature):
+		return readXlsxRows(data, sheetName)
+	case bytes.HasPrefix(data, oleSignature):
+		return readXlsRows(filePath, sheetName)
+	default:
+		return nil, errors.New("file is neither XLSX nor XLS spreadsheet")
+	}
+}
+
+func readXlsxRows(data []byte, sheetName string) ([][]string, error) {
+	f, err := xlsx.OpenBinary(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XLSX file: %w", err)
+	}
+	if len(f.Sheets) == 0 {
+		return nil, errors.New("file doesn't have sheets")
+	}
+	sheet := f.Sheets[0]
+	if sheetName != "" {
+		var ok bool
+		if sheet, ok = f.Sheet[sheetName]; !ok {
+			return nil, fmt.Errorf("can't find sheet '%s'", sheetName)
+		}
+	}
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		cells := make([]string, 0)
+		if row != nil {
+			for _, cell := range row.Cells {
+				cells = append(cells, strings.TrimSpace(cell.Value))
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+func readXlsRows(filePath, sheetName string) ([][]string, error) {
+	f, err := xls.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XLS file: %w", err)
+	}
+	var sheet *xls.Sheet
+	for i := 0; i < f.GetNumberSheets(); i++ {
+		candidate, err := f.GetSheet(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %d sheet: %w", i, err)
+		}
+		if sheetName == "" || candidate.GetName() == sheetName {
+			sheet = candidate
+			break
+		}
+	}
+	if sheet == nil {
+		return nil, fmt.Errorf("can't find sheet '%s'", sheetName)
+	}
+	rows := make([][]string, 0, sheet.GetNumberRows())
+	for i := 0; i <= sheet.GetNumberRows(); i++ {
+		cells := make([]string, 0)
+		if row, err := sheet.GetRow(i); err == nil && row != nil {
+			for _, cell := range row.GetCols() {
+				cells = append(cells, strings.TrimSpace(cell.GetString()))
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+// hasCells returns true if all texts are values of some cells of the row, case-insensitive.
+func hasCells(row []string, texts []string) bool {
+	for _, text := range texts {
+		found := false
+		for _, cell := range row {
+			if strings.EqualFold(cell, text) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// hasCellWithPrefix returns true if some cell of the row starts with one of texts, case-insensitive.
+func hasCellWithPrefix(row []string, texts []string) bool {
+	for _, cell := range row {
+		for _, text := range texts {
+			if strings.HasPrefix(strings.ToLower(cell), strings.ToLower(text)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isEmptyRow returns true if all cells of the row are empty.
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if cell != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// find returns value for the whole file from rows above the header.
+func (v SpreadsheetValue) find(rows [][]string) (string, error) {
+	if v.Value != "" || v.Label == "" {
+		return v.Value, nil
+	}
+	text := ""
+	found := false
+	for i := 0; i < len(rows) && !found; i++ {
+		for j, cell := range rows[i] {
+			if !strings.HasPrefix(cell, v.Label) {
+				continue
+			}
+			found = true
+			text = strings.TrimSpace(strings.TrimPrefix(cell, v.Label))
+			for k := j + 1; k < len(rows[i]) && text == ""; k++ {
+				text = rows[i][k]
+			}
+			if text == "" && i+1 < len(rows) && j < len(rows[i+1]) {
+				text = rows[i+1][j]
+			}
+			break
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("can't find label '%s' above header", v.Label)
+	}
+	if v.Pattern != "" {
+		matches := regexp.MustCompile(v.Pattern).FindStringSubmatch(text)
+		if matches == nil {
+			return "", fmt.Errorf("text '%s' after label '%s' doesn't match pattern '%s'", text, v.Label, v.Pattern)
+		}
+		text = matches[0]
+		if len(matches) > 1 {
+			text = matches[1]
+		}
+	}
+	text = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(v.Remove, r) {
+			return -1
+		}
+		return r
+	}, text))
+	if text == "" {
+		return "", fmt.Errorf("value after label '%s' is empty", v.Label)
+	}
+	return text, nil
+}
+
+// dateParser returns parser of dates with layout and time zone from spec or from Excel serial numbers.
+func (s SpreadsheetSpec) dateParser() (func(string) (time.Time, error), error) {
+	format, err := newDateFormat(s.TimeZone, s.DateFormat)
+	if err != nil {
+		return nil, err
+	}
+	return func(text string) (time.Time, error) {
+		date, err := format.Parse(text)
+		if err == nil {
+			return date, nil
+		}
+		if serial, floatErr := strconv.ParseFloat(text, 64); floatErr == nil && serial > 0 {
+			return format.withWallClock(excelSerialDate(serial)), nil
+		}
+		return date, err
+	}, nil
+}
+
+func (p SpreadsheetFileParser) ParseRawTransactionsFromFile(filePath string) ([]Transaction, error) {
+	rows, err := readSpreadsheetRows(filePath, p.Spec.Sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	headerIndex := -1
+	for i, row := range rows {
+		if hasCells(row, p.Spec.Header.Contains) {
+			headerIndex = i
+			break
+		}
+	}
+	if headerIndex < 0 {
+		return nil, fmt.Errorf("can't find header row with %v", p.Spec.Header.Contains)
+	}
+	accountNumber, err := p.Spec.AccountNumber.find(rows[:headerIndex])
+	if err != nil {
+		return nil, fmt.Errorf("failed to find account number: %w", err)
+	}
+	currency, err := p.Spec.Currency.find(rows[:headerIndex])
+	if err != nil {
+		return nil, fmt.Errorf("failed to find currency: %w", err)
+	}
+	counterparty, err := p.Spec.Counterparty.find(rows[:headerIndex])
+	if err != nil {
+		return nil, fmt.Errorf("failed to find counterparty: %w", err)
+	}
+	indexes, err := p.Spec.Columns.resolveColumns(rows[max(0, headerIndex-p.Spec.Header.RowsAbove) : headerIndex+1]...)
+	if err != nil {
+		return nil, err
+	}
+
+	parseDate, err := p.Spec.dateParser()
+	if err != nil {
+		return nil, err
+	}
+
+	amountFormat := amountFormat(p.Spec.DecimalSeparator)
+	rowParser := &mappedRowParser{
+		typeName:  p.Spec.Name,
+		filePath:  filePath,
+		indexes:   indexes,
+		parseDate: parseDate,
+		parseAmount: func(s string) (Money, error) {
+			// Cells may contain currency next to the amount.
+			amount, err := amountFormat.Parse(s)
+			if err != nil {
+				return amountFormat.ParseWithoutLetters(s)
+			}
+			return amount, nil
+		},
+		accountNumber:    accountNumber,
+		currency:         currency,
+		counterparty:     counterparty,
+		keepOriginAmount: p.Spec.KeepOriginAmount,
+		swapAccounts:     p.Spec.SwapAccounts,
+	}
+	transactions := make([]Transaction, 0)
+	for i := headerIndex + 1; i < len(rows); i++ {
+		row := rows[i]
+		if (p.Spec.End.EmptyRow && isEmptyRow(row)) || hasCellWithPrefix(row, p.Spec.End.Contains) {
+			break
+		}
+		transaction, err := rowParser.parseRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		if transaction != nil {
+			transactions = append(transactions, *transaction)
+		}
+	}
+	return transactions, nil
+}
+
+var _ FileParser = SpreadsheetFileParser{}
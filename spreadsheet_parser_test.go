@@ -0,0 +1,440 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tealeg/xlsx"
+)
+
+// specTransaction contains fields of transaction which specs should parse the same way as hand-written parsers.
+type specTransaction struct {
+	Date                 time.Time
+	IsExpense            bool
+	Amount               int
+	AccountNumber        string
+	AccountCurrency      string
+	OriginCurrency       string
+	OriginCurrencyAmount int
+	FromAccount          string
+	ToAccount            string
+	Details              string
+}
+
+// toSpecTransactions converts transactions for comparison. Dates, signs and details are kept as is,
+// except that sequences of spaces in details are collapsed if `collapseDetailsSpaces` is set.
+func toSpecTransactions(transactions []Transaction, collapseDetailsSpaces bool) []specTransaction {
+	result := make([]specTransaction, 0, len(transactions))
+	for _, t := range transactions {
+		details := t.Details
+		if collapseDetailsSpaces {
+			details = strings.Join(strings.Fields(details), " ")
+		}
+		result = append(result, specTransaction{
+			Date:                 t.Date,
+			IsExpense:            t.IsExpense,
+			Amount:               t.Amount.int,
+			AccountNumber:        t.Source.AccountNumber,
+			AccountCurrency:      t.AccountCurrency,
+			OriginCurrency:       t.OriginCurrency,
+			OriginCurrencyAmount: t.OriginCurrencyAmount.int,
+			FromAccount:          t.FromAccount,
+			ToAccount:            t.ToAccount,
+			Details:              details,
+		})
+	}
+	return result
+}
+
+func TestSpreadsheetFileParser_SpecsOfSupportedBanks(t *testing.T) {
+	tests := []struct {
+		specFile              string
+		filePath              string
+		parser                FileParser
+		collapseDetailsSpaces bool
+	}{
+		{"ineco_xlsx.yaml", "ineco/valid_regular.xlsx", InecoExcelFileParser{}, false},
+		{"ineco_xlsx.yaml", "ineco/valid_card.xlsx", InecoExcelFileParser{}, false},
+		// Hand-written parser joins untrimmed description and place cells, spec joins trimmed cells.
+		{"acba_card_xls.yaml", "acba/valid_card.xls", AcbaCardExcelFileParser{}, true},
+		{"acba_account_xls.yaml", "acba/valid_account.xls", AcbaRegularAccountExcelFileParser{}, false},
+		{"ardshin_xlsx.yaml", "ardshin/valid.xlsx", ArdshinXlsxFileParser{}, false},
+		{"myameria_xls.yaml", "ameria/valid_statement.xls", MyAmeriaExcelStmtFileParser{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filePath, func(t *testing.T) {
+			// Arrange
+			filePath := filepath.Join("testdata", tt.filePath)
+			parser, err := newSpreadsheetFileParser(map[string]any{
+				"specFile": filepath.Join("testdata", "specs", tt.specFile),
+			}, &Config{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected, err := tt.parser.ParseRawTransactionsFromFile(filePath)
+			if err != nil {
+				t.Fatalf("unexpected error of hand-written parser: %v", err)
+			}
+
+			// Act
+			actual, err := parser.ParseRawTransactionsFromFile(filePath)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(actual) == 0 {
+				t.Fatal("expected transactions, got none")
+			}
+			expectedSpec := toSpecTransactions(expected, tt.collapseDetailsSpaces)
+			if diff := cmp.Diff(expectedSpec, toSpecTransactions(actual, tt.collapseDetailsSpaces)); diff != "" {
+				t.Errorf("transactions mismatch (-hand-written +spec):\n%s", diff)
+			}
+			if actual[0].Source.TypeName != expected[0].Source.TypeName {
+				t.Errorf("expected type name '%s', got '%s'", expected[0].Source.TypeName, actual[0].Source.TypeName)
+			}
+		})
+	}
+}
+
+func TestSpreadsheetFileParser_MyAmeriaSpec(t *testing.T) {
+	// Arrange
+	filePath := filepath.Join("testdata", "ameria", "valid_statement.xls")
+	parser, err := newSpreadsheetFileParser(map[string]any{
+		"specFile": filepath.Join("testdata", "specs", "myameria_xls.yaml"),
+	}, &Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	source := &TransactionsSource{
+		TypeName:        "MyAmeria XLS statement",
+		FilePath:        filePath,
+		AccountNumber:   "1234567890123456",
+		AccountCurrency: "USD",
+	}
+
+	// Act
+	actual, err := parser.ParseRawTransactionsFromFile(filePath)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Transaction{
+		{
+			Date:            utcDate(2024, 4, 18),
+			FromAccount:     "9999999999999999",
+			ToAccount:       "1234567890123456",
+			Amount:          Money{int: 100500250},
+			Details:         "Transfer to myself",
+			Source:          source,
+			AccountCurrency: "USD",
+		},
+		{
+			Date:            utcDate(2024, 4, 18),
+			FromAccount:     "1234567890123456",
+			ToAccount:       "208181982",
+			IsExpense:       true,
+			Amount:          Money{int: 1000000},
+			Details:         "Payment for services",
+			Source:          source,
+			AccountCurrency: "USD",
+		},
+	}
+	if diff := cmp.Diff(expected, actual, moneyComparer); diff != "" {
+		t.Errorf("transactions mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+// writeTestXlsx writes rows into the first sheet of new XLSX file and returns path to it.
+func writeTestXlsx(t *testing.T, rows [][]string) string {
+	t.Helper()
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("Statement")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, values := range rows {
+		row := sheet.AddRow()
+		for _, value := range values {
+			row.AddCell().SetValue(value)
+		}
+	}
+	filePath := filepath.Join(t.TempDir(), "statement.xlsx")
+	if err := file.Save(filePath); err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+// testStatementRows are rows of statement with labels in different places and totals after transactions.
+var testStatementRows = [][]string{
+	{"Evocabank", "", "Account:", "", "2200-1234"},
+	{"Statement for", "", "Currency", ""},
+	{"", "", "USD (US dollar)"},
+	{},
+	{"Operation", "", "Amount", "", "Place"},
+	{"Date", "Description", "In", "Out"},
+	{"01.03.2024", "Salary", "1,500.00 USD", "", "Employer"},
+	{"", "Balance", "1,500.00", ""},
+	{"45355", "Coffee", "", "-3.50"},
+	{"Total", "", "1,500.00", "3.50"},
+	{"05.03.2024", "After totals", "1", ""},
+}
+
+func TestSpreadsheetFileParser_InlineSpec(t *testing.T) {
+	// Arrange
+	filePath := writeTestXlsx(t, testStatementRows)
+	parser, err := newSpreadsheetFileParser(map[string]any{
+		"name":          "Evocabank XLSX",
+		"header":        map[string]any{"contains": []any{"date", "in", "out"}, "rowsAbove": 1},
+		"accountNumber": map[string]any{"label": "Account:", "remove": "-"},
+		"currency":      map[string]any{"label": "Currency", "pattern": `^(\w+) \(`},
+		"dateFormat":    "02.01.2006",
+		"columns": map[string]any{
+			"date":    "Date",
+			"credit":  "In",
+			"debit":   "Out",
+			"details": []any{"Description", "Place"},
+		},
+		"end": map[string]any{"contains": []any{"total"}},
+	}, &Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	source := &TransactionsSource{
+		TypeName:        "Evocabank XLSX",
+		FilePath:        filePath,
+		AccountNumber:   "22001234",
+		AccountCurrency: "USD",
+	}
+
+	// Act
+	actual, err := parser.ParseRawTransactionsFromFile(filePath)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Transaction{
+		{
+			Date:            utcDate(2024, 3, 1),
+			ToAccount:       "22001234",
+			Amount:          Money{int: 1500000},
+			Details:         "Salary Employer",
+			Source:          source,
+			AccountCurrency: "USD",
+		},
+		{
+			Date:            utcDate(2024, 3, 4),
+			FromAccount:     "22001234",
+			IsExpense:       true,
+			Amount:          Money{int: 3500},
+			Details:         "Coffee",
+			Source:          source,
+			AccountCurrency: "USD",
+		},
+	}
+	if diff := cmp.Diff(expected, actual, moneyComparer); diff != "" {
+		t.Errorf("transactions mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestSpreadsheetSpec_DateParser(t *testing.T) {
+	yerevan, err := time.LoadLocation("Asia/Yerevan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		timeZone string
+		input    string
+		expected time.Time
+	}{
+		{"text_in_utc", "", "01.03.2024", utcDate(2024, 3, 1)},
+		{"serial_in_utc", "", "45352.75", time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)},
+		{"text_in_time_zone", "Asia/Yerevan", "01.03.2024", time.Date(2024, 3, 1, 0, 0, 0, 0, yerevan)},
+		{"serial_in_time_zone", "Asia/Yerevan", "45352.75", time.Date(2024, 3, 1, 18, 0, 0, 0, yerevan)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			spec := SpreadsheetSpec{DateFormat: "02.01.2006", TimeZone: tt.timeZone}
+			parseDate, err := spec.dateParser()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Act
+			actual, err := parseDate(tt.input)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !actual.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSpreadsheetValue_Find(t *testing.T) {
+	rows := [][]string{
+		{"Account:", "", "", "2200-1234", "Currency:"},
+		{"Owner: John Doe", "", "", "", "AMD"},
+	}
+	tests := []struct {
+		name     string
+		value    SpreadsheetValue
+		expected string
+	}{
+		{"constant", SpreadsheetValue{Value: "acc", Label: "Account:"}, "acc"},
+		{"next_cell", SpreadsheetValue{Label: "Account:"}, "2200-1234"},
+		{"rest_of_cell", SpreadsheetValue{Label: "Owner:"}, "John Doe"},
+		{"cell_below", SpreadsheetValue{Label: "Currency:"}, "AMD"},
+		{"pattern", SpreadsheetValue{Label: "Owner:", Pattern: `\w+$`}, "Doe"},
+		{"pattern_group", SpreadsheetValue{Label: "Account:", Pattern: `(\d+)-\d+`}, "2200"},
+		{"remove", SpreadsheetValue{Label: "Account:", Remove: "-"}, "22001234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			actual, err := tt.value.find(rows)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected '%s', got '%s'", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSpreadsheetFileParser_ParseErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		rows         [][]string
+		options      map[string]any
+		errorMessage string
+	}{
+		{
+			name:         "no_header",
+			rows:         [][]string{{"Account:", "acc"}, {"Day", "Amount"}},
+			options:      map[string]any{"accountNumber": map[string]any{"label": "Account:"}},
+			errorMessage: "can't find header row with [Date]",
+		},
+		{
+			name:         "no_label",
+			rows:         [][]string{{"Account", "acc"}, {"Date", "Amount"}},
+			options:      map[string]any{"accountNumber": map[string]any{"label": "Account:"}},
+			errorMessage: "failed to find account number: can't find label 'Account:' above header",
+		},
+		{
+			name:         "empty_value",
+			rows:         [][]string{{"Account:", ""}, {"Date", "Amount"}},
+			options:      map[string]any{"accountNumber": map[string]any{"label": "Account:"}},
+			errorMessage: "failed to find account number: value after label 'Account:' is empty",
+		},
+		{
+			name:         "pattern_mismatch",
+			rows:         [][]string{{"Account:", "acc"}, {"Date", "Amount"}},
+			options:      map[string]any{"accountNumber": map[string]any{"label": "Account:", "pattern": `\d+`}},
+			errorMessage: "text 'acc' after label 'Account:' doesn't match pattern '\\d+'",
+		},
+		{
+			name:         "missing_column",
+			rows:         [][]string{{"Date", "Sum"}},
+			options:      map[string]any{"accountNumber": map[string]any{"value": "acc"}, "currency": map[string]any{"value": "AMD"}},
+			errorMessage: "can't find column 'Amount' in header [Date Sum]",
+		},
+		{
+			name:         "invalid_amount",
+			rows:         [][]string{{"Date", "Amount", "Details"}, {"2024-03-01", "USD"}},
+			options:      map[string]any{"accountNumber": map[string]any{"value": "acc"}, "currency": map[string]any{"value": "AMD"}},
+			errorMessage: "row 2: failed to parse amount 'USD': invalid money format: 'USD'",
+		},
+		{
+			name:         "missing_sheet",
+			rows:         [][]string{{"Date", "Amount"}},
+			options:      map[string]any{"sheet": "Transactions"},
+			errorMessage: "can't find sheet 'Transactions'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			filePath := writeTestXlsx(t, tt.rows)
+			parser, err := newSpreadsheetFileParser(tt.options, &Config{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Act
+			_, err = parser.ParseRawTransactionsFromFile(filePath)
+
+			// Assert
+			checkErrorContainsSubstring(t, err, tt.errorMessage)
+		})
+	}
+}
+
+func TestNewSpreadsheetFileParser_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		options      map[string]any
+		errorMessage string
+	}{
+		{
+			name:         "spec_file_with_inline_spec",
+			options:      map[string]any{"specFile": "spec.yaml", "sheet": "Statement"},
+			errorMessage: "either 'specFile' or spec fields should be set, not both",
+		},
+		{
+			name:         "missing_spec_file",
+			options:      map[string]any{"specFile": filepath.Join("testdata", "specs", "missing.yaml")},
+			errorMessage: "failed to read spec file",
+		},
+		{
+			name:         "date_column_number_without_header",
+			options:      map[string]any{"columns": map[string]any{"date": 1}},
+			errorMessage: "header texts should be set if date column is set by number",
+		},
+		{
+			name:         "wrong_pattern",
+			options:      map[string]any{"currency": map[string]any{"label": "Currency", "pattern": "("}},
+			errorMessage: "wrong pattern of 'currency'",
+		},
+		{
+			name:         "amount_with_debit",
+			options:      map[string]any{"columns": map[string]any{"amount": "Sum", "debit": "Out"}},
+			errorMessage: "either 'amount' or 'debit' and 'credit' columns should be set, not both",
+		},
+		{
+			name:         "unknown_time_zone",
+			options:      map[string]any{"timeZone": "Mars/Olympus"},
+			errorMessage: "invalid timezone location 'Mars/Olympus'",
+		},
+		{
+			name:         "unknown_option",
+			options:      map[string]any{"header": map[string]any{"text": "Date"}},
+			errorMessage: "field text not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			_, err := newSpreadsheetFileParser(tt.options, &Config{})
+
+			// Assert
+			checkErrorContainsSubstring(t, err, tt.errorMessage)
+		})
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"io"
 	"log"
 	"sort"
-	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,7 +16,9 @@ func (t *Transaction) String() string {
 
 func (je *JournalEntry) String() string {
 	direction := i18n.T("Income")
-	if je.IsExpense {
+	if je.IsTransfer {
+		direction = i18n.T("Transfer")
+	} else if je.IsExpense {
 		direction = i18n.T("Expense")
 	}
 	amounts := ""
@@ -45,17 +47,11 @@ func (je *JournalEntry) String() string {
 	)
 }
 
-func (m MoneyWith2DecimalPlaces) String() string {
-	dollars := m.int / 100
-	cents := m.int % 100
-	dollarString := strconv.Itoa(dollars)
-	for i := len(dollarString) - 3; i > 0; i -= 3 {
-		dollarString = dollarString[:i] + "," + dollarString[i:]
-	}
-	return fmt.Sprintf("%9s.%02d", dollarString, cents)
+func (m Money) String() string {
+	return fmt.Sprintf("%12s", m.StringNoIndent())
 }
 
-// GroupList structure to sort groups by `MoneyWith2DecimalPlaces` descending.
+// GroupList structure to sort groups by `Money` descending.
 type GroupList []*Group
 
 func (g GroupList) Len() int {
@@ -134,25 +130,142 @@ func MapOfGroupsToString(mapOfGroups map[string]*Group) []string {
 	return MapOfGroupsToStringFull(mapOfGroups, false)
 }
 
+// buildCategoryTree builds hierarchy of categories with rolled up totals from groups.
+// Path of each group is taken from its journal entries.
+func buildCategoryTree(groups map[string]*Group) []*CategoryNode {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	root := &CategoryNode{}
+	for _, name := range names {
+		group := groups[name]
+		path := []string{name}
+		if len(group.JournalEntries) > 0 && len(group.JournalEntries[0].CategoryPath) > 0 {
+			path = group.JournalEntries[0].CategoryPath
+		}
+		node := root
+		for i, part := range path {
+			var child *CategoryNode
+			for _, existing := range node.Children {
+				if existing.Name == part {
+					child = existing
+					break
+				}
+			}
+			if child == nil {
+				child = &CategoryNode{Name: part, Path: strings.Join(path[:i+1], CategorySeparator)}
+				node.Children = append(node.Children, child)
+			}
+			child.Total.int += group.Total.int
+			node = child
+		}
+		node.Group = name
+	}
+	sortCategoryNodes(root.Children)
+	return root.Children
+}
+
+// sortCategoryNodes sorts nodes on all levels by total descending.
+func sortCategoryNodes(nodes []*CategoryNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Total.int > nodes[j].Total.int
+	})
+	for _, node := range nodes {
+		sortCategoryNodes(node.Children)
+	}
+}
+
+// isCategoryTreeFlat returns true if there is no hierarchy in categories.
+func isCategoryTreeFlat(nodes []*CategoryNode) bool {
+	for _, node := range nodes {
+		if len(node.Children) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// GroupsToStrings converts groups to human readable strings.
+// If categories are hierarchical then renders them as tree with rolled up totals on each level.
+func GroupsToStrings(groups map[string]*Group, tree []*CategoryNode, withJournalEntries bool) []string {
+	if isCategoryTreeFlat(tree) {
+		return MapOfGroupsToStringFull(groups, withJournalEntries)
+	}
+	result := []string{}
+	appendCategoryNodesStrings(&result, tree, groups, "", withJournalEntries)
+	return result
+}
+
+// appendCategoryNodesStrings appends strings for nodes and their children with increasing indent.
+func appendCategoryNodesStrings(result *[]string, nodes []*CategoryNode, groups map[string]*Group, indent string, withJournalEntries bool) {
+	for _, node := range nodes {
+		if node.Total.int == 0 {
+			continue
+		}
+		childIndent := indent
+		if len(node.Children) > 0 {
+			*result = append(*result, indent+i18n.T("groupName total",
+				"groupName", node.Name+CategorySeparator,
+				"total", node.Total,
+			))
+			childIndent = indent + "  "
+		}
+		if group, ok := groups[node.Group]; ok && node.Group != "" {
+			for _, line := range MapOfGroupsToStringFull(map[string]*Group{node.Group: group}, withJournalEntries) {
+				*result = append(*result, childIndent+line)
+			}
+		}
+		appendCategoryNodesStrings(result, node.Children, groups, childIndent, withJournalEntries)
+	}
+}
+
+// countNotEmptyGroups returns number of groups with not zero total.
+func countNotEmptyGroups(groups map[string]*Group) int {
+	count := 0
+	for _, group := range groups {
+		if group.Total.int != 0 {
+			count++
+		}
+	}
+	return count
+}
+
 func (s *IntervalStatistic) String() string {
-	income := MapOfGroupsToStringFull(s.Income, true)
-	expense := MapOfGroupsToStringFull(s.Expense, true)
+	income := GroupsToStrings(s.Income, s.IncomeTree, true)
+	expense := GroupsToStrings(s.Expense, s.ExpenseTree, true)
 	return i18n.T("Statistics_format",
 		"start", s.Start,
 		"end", s.End,
 		"currency", s.Currency,
-		"nIncome", len(income),
+		"nIncome", countNotEmptyGroups(s.Income),
 		"sumIncome", MapOfGroupsSum(s.Income),
 		"detailsIncome", income,
-		"nExpense", len(expense),
+		"nExpense", countNotEmptyGroups(s.Expense),
 		"sumExpense", MapOfGroupsSum(s.Expense),
 		"detailsExpense", expense,
+	) + transfersToString(s.Transfers, true) + budgetsToString(s.Budgets) +
+		anomaliesToString(s.Anomalies, s.TransactionAnomalies)
+}
+
+// transfersToString converts transfers groups to human readable string.
+// Returns empty string if there are no transfers.
+func transfersToString(transfers map[string]*Group, withJournalEntries bool) string {
+	details := MapOfGroupsToStringFull(transfers, withJournalEntries)
+	if len(details) == 0 {
+		return ""
+	}
+	return i18n.T("Transfers_format",
+		"nTransfers", len(details),
+		"sumTransfers", MapOfGroupsSum(transfers),
+		"detailsTransfers", details,
 	)
 }
 
 // MapOfGroupsSum returns sum from all groups.
-func MapOfGroupsSum(mapOfGroups map[string]*Group) MoneyWith2DecimalPlaces {
-	sum := MoneyWith2DecimalPlaces{}
+func MapOfGroupsSum(mapOfGroups map[string]*Group) Money {
+	sum := Money{}
 	for _, group := range mapOfGroups {
 		sum.int += group.Total.int
 	}
@@ -191,21 +304,22 @@ func DumpIntervalStatistic(intervalStatistic *IntervalStatistic, writer io.Write
 		fmt.Fprint(writer, i18n.T("c amounts\n stats\n", "c", currency, "stats", intervalStatistic))
 		return
 	}
-	// Otherwise use `MapOfGroupsToString` to dump income and expense.
-	income := MapOfGroupsToString(intervalStatistic.Income)
-	expense := MapOfGroupsToString(intervalStatistic.Expense)
+	// Otherwise dump only totals of income and expense groups.
+	income := GroupsToStrings(intervalStatistic.Income, intervalStatistic.IncomeTree, false)
+	expense := GroupsToStrings(intervalStatistic.Expense, intervalStatistic.ExpenseTree, false)
 	fmt.Fprintln(writer,
 		i18n.T("Statistics_format",
 			"start", intervalStatistic.Start,
 			"end", intervalStatistic.End,
 			"currency", currency,
-			"nIncome", len(income),
+			"nIncome", countNotEmptyGroups(intervalStatistic.Income),
 			"sumIncome", MapOfGroupsSum(intervalStatistic.Income),
 			"detailsIncome", income,
-			"nExpense", len(expense),
+			"nExpense", countNotEmptyGroups(intervalStatistic.Expense),
 			"sumExpense", MapOfGroupsSum(intervalStatistic.Expense),
 			"detailsExpense", expense,
-		),
+		)+transfersToString(intervalStatistic.Transfers, false)+budgetsToString(intervalStatistic.Budgets)+
+			anomaliesToString(intervalStatistic.Anomalies, intervalStatistic.TransactionAnomalies),
 	)
 }
 
@@ -235,20 +349,33 @@ func (s GroupExtractorByCategories) HandleJournalEntry(je JournalEntry, start, e
 		stat, ok := s.intervalStats[currency]
 		if !ok {
 			stat = &IntervalStatistic{
-				Currency: currency,
-				Start:    start,
-				End:      end,
-				Income:   make(map[string]*Group),
-				Expense:  make(map[string]*Group),
+				Currency:  currency,
+				Start:     start,
+				End:       end,
+				Income:    make(map[string]*Group),
+				Expense:   make(map[string]*Group),
+				Transfers: make(map[string]*Group),
 			}
 			s.intervalStats[currency] = stat
 		}
-		if je.IsExpense {
+		if je.IsTransfer {
+			// Transfers are not incomes or expenses, so keep them separately.
+			group, exists := stat.Transfers[je.Category]
+			if !exists {
+				group = &Group{
+					Name:  je.Category,
+					Total: Money{int: 0},
+				}
+				stat.Transfers[je.Category] = group
+			}
+			group.JournalEntries = append(group.JournalEntries, je)
+			group.Total.int += amount.Amount.int
+		} else if je.IsExpense {
 			group, exists := stat.Expense[je.Category]
 			if !exists {
 				group = &Group{
 					Name:  je.Category,
-					Total: MoneyWith2DecimalPlaces{int: 0},
+					Total: Money{int: 0},
 				}
 				stat.Expense[je.Category] = group
 			}
@@ -262,7 +389,7 @@ func (s GroupExtractorByCategories) HandleJournalEntry(je JournalEntry, start, e
 			if !exists {
 				group = &Group{
 					Name:  je.Category,
-					Total: MoneyWith2DecimalPlaces{int: 0},
+					Total: Money{int: 0},
 				}
 				stat.Income[je.Category] = group
 			}
@@ -278,6 +405,10 @@ func (s GroupExtractorByCategories) HandleJournalEntry(je JournalEntry, start, e
 }
 
 func (s GroupExtractorByCategories) GetIntervalStatistics() map[string]*IntervalStatistic {
+	for _, stat := range s.intervalStats {
+		stat.IncomeTree = buildCategoryTree(stat.Income)
+		stat.ExpenseTree = buildCategoryTree(stat.Expense)
+	}
 	return s.intervalStats
 }
 
@@ -316,37 +447,49 @@ func NewStatisticBuilderByCategories(accounts map[string]*AccountStatistics, con
 	}, nil
 }
 
-// BuildMonthlyStatistics builds list of
+// BuildPeriodStatistics builds list of
 // [github.com/AlexanderMakarov/am-budget-view.main.IntervalStatistic]
-// per each month from provided journal entries.
-func BuildMonthlyStatistics(
+// per each reporting period from provided journal entries sorted by date.
+// Periods without journal entries are skipped, as well as journal entries out of all periods.
+// Limits of budgets are applied to each period as is, so budgets should be provided only for monthly
+// and salary periods. For salary periods limits are per salary period.
+func BuildPeriodStatistics(
 	journalEntries []JournalEntry,
 	statisticBuilderFactory StatisticBuilderFactory,
-	monthStart uint,
-	timeZone *time.Location,
+	period Period,
+	budgets map[string]*BudgetConfig,
+	anomalies *AnomalyDetectionConfig,
 ) ([]map[string]*IntervalStatistic, error) {
 
 	result := make([]map[string]*IntervalStatistic, 0)
 	var statBuilder IntervalStatisticsBuilder
+	var start, end time.Time
 
-	// Get first month boundaries from the first transaction. Build first month statistics.
-	start := time.Date(journalEntries[0].Date.Year(), journalEntries[0].Date.Month(),
-		int(monthStart), 0, 0, 0, 0, timeZone)
-	end := start.AddDate(0, 1, 0).Add(-1 * time.Nanosecond)
-	statBuilder = statisticBuilderFactory(start, end)
+	// saveStatistics appends statistics of the current period if there is one.
+	saveStatistics := func() {
+		if statBuilder == nil {
+			return
+		}
+		statistics := statBuilder.GetIntervalStatistics()
+		for _, stat := range statistics {
+			stat.Name = period.Name(start)
+		}
+		result = append(result, statistics)
+	}
 
 	// Iterate through all the journal entries.
 	for _, je := range journalEntries {
 
-		// Check if this transaction is part of the new month.
-		if je.Date.After(end) {
-
-			// Save previous month statistic if there is one.
-			result = append(result, statBuilder.GetIntervalStatistics())
+		// Check if this transaction is part of the new period.
+		if statBuilder == nil || je.Date.Before(start) || je.Date.After(end) {
+			newStart, newEnd, ok := period.Bounds(je.Date)
+			if !ok {
+				continue
+			}
 
-			// Calculate start and end of the next month.
-			start = time.Date(je.Date.Year(), je.Date.Month(), int(monthStart), 0, 0, 0, 0, timeZone)
-			end = start.AddDate(0, 1, 0).Add(-1 * time.Nanosecond)
+			// Save previous period statistic and start the next one.
+			saveStatistics()
+			start, end = newStart, newEnd
 			statBuilder = statisticBuilderFactory(start, end)
 		}
 
@@ -357,7 +500,13 @@ func BuildMonthlyStatistics(
 	}
 
 	// Add last IntervalStatistics if need.
-	result = append(result, statBuilder.GetIntervalStatistics())
+	saveStatistics()
+
+	// Compare expenses with budgets.
+	buildBudgetStatistics(result, budgets)
+
+	// Compare expenses with previous periods.
+	buildAnomalies(result, anomalies)
 
 	return result, nil
 }
@@ -158,12 +158,12 @@ Statistics for        2024-10-27..2024-10-28 (in    USD):
 			FromAccount:           "a1",
 			ToAccount:             "a2",
 			AccountCurrency:       "USD",
-			AccountCurrencyAmount: MoneyWith2DecimalPlaces{1},
+			AccountCurrencyAmount: Money{10},
 			OriginCurrency:        "USD",
-			OriginCurrencyAmount:  MoneyWith2DecimalPlaces{1},
+			OriginCurrencyAmount:  Money{10},
 			Amounts: map[string]AmountInCurrency{
-				"USD": {Currency: "USD", Amount: MoneyWith2DecimalPlaces{1}},
-				"AMD": {Currency: "AMD", Amount: MoneyWith2DecimalPlaces{400}},
+				"USD": {Currency: "USD", Amount: Money{10}},
+				"AMD": {Currency: "AMD", Amount: Money{4000}},
 			},
 		},
 		{
@@ -175,12 +175,12 @@ Statistics for        2024-10-27..2024-10-28 (in    USD):
 			FromAccount:           "a1",
 			ToAccount:             "a2",
 			AccountCurrency:       "USD",
-			AccountCurrencyAmount: MoneyWith2DecimalPlaces{2},
+			AccountCurrencyAmount: Money{20},
 			OriginCurrency:        "AMD",
-			OriginCurrencyAmount:  MoneyWith2DecimalPlaces{800},
+			OriginCurrencyAmount:  Money{8000},
 			Amounts: map[string]AmountInCurrency{
-				"USD": {Currency: "USD", Amount: MoneyWith2DecimalPlaces{2}},
-				"AMD": {Currency: "AMD", Amount: MoneyWith2DecimalPlaces{800}},
+				"USD": {Currency: "USD", Amount: Money{20}},
+				"AMD": {Currency: "AMD", Amount: Money{8000}},
 			},
 		},
 		{
@@ -192,12 +192,12 @@ Statistics for        2024-10-27..2024-10-28 (in    USD):
 			FromAccount:           "a1",
 			ToAccount:             "a2",
 			AccountCurrency:       "AMD",
-			AccountCurrencyAmount: MoneyWith2DecimalPlaces{1200},
+			AccountCurrencyAmount: Money{12000},
 			OriginCurrency:        "USD",
-			OriginCurrencyAmount:  MoneyWith2DecimalPlaces{3},
+			OriginCurrencyAmount:  Money{30},
 			Amounts: map[string]AmountInCurrency{
-				"USD": {Currency: "USD", Amount: MoneyWith2DecimalPlaces{3}},
-				"AMD": {Currency: "AMD", Amount: MoneyWith2DecimalPlaces{1200}},
+				"USD": {Currency: "USD", Amount: Money{30}},
+				"AMD": {Currency: "AMD", Amount: Money{12000}},
 			},
 		},
 		{
@@ -209,10 +209,10 @@ Statistics for        2024-10-27..2024-10-28 (in    USD):
 			FromAccount:           "a1",
 			ToAccount:             "a2",
 			AccountCurrency:       "AMD",
-			AccountCurrencyAmount: MoneyWith2DecimalPlaces{1600},
+			AccountCurrencyAmount: Money{16000},
 			Amounts: map[string]AmountInCurrency{
-				"USD": {Currency: "USD", Amount: MoneyWith2DecimalPlaces{4}},
-				"AMD": {Currency: "AMD", Amount: MoneyWith2DecimalPlaces{1600}},
+				"USD": {Currency: "USD", Amount: Money{40}},
+				"AMD": {Currency: "AMD", Amount: Money{16000}},
 			},
 		},
 		{
@@ -224,12 +224,12 @@ Statistics for        2024-10-27..2024-10-28 (in    USD):
 			FromAccount:           "a1",
 			ToAccount:             "a2",
 			AccountCurrency:       "AMD",
-			AccountCurrencyAmount: MoneyWith2DecimalPlaces{2000},
+			AccountCurrencyAmount: Money{20000},
 			OriginCurrency:        "USD",
-			OriginCurrencyAmount:  MoneyWith2DecimalPlaces{5},
+			OriginCurrencyAmount:  Money{50},
 			Amounts: map[string]AmountInCurrency{
-				"USD": {Currency: "USD", Amount: MoneyWith2DecimalPlaces{5}},
-				"AMD": {Currency: "AMD", Amount: MoneyWith2DecimalPlaces{2000}},
+				"USD": {Currency: "USD", Amount: Money{50}},
+				"AMD": {Currency: "AMD", Amount: Money{20000}},
 			},
 		},
 	}
@@ -321,6 +321,7 @@ Statistics for        2024-10-27..2024-10-28 (in    USD):
 	}
 }
 
+// newUsdJE creates journal entry with amount in USD cents.
 func newUsdJE(amount int, isExpense bool, category, from, to string, source *TransactionsSource) JournalEntry {
 	sign := "+"
 	if isExpense {
@@ -332,9 +333,122 @@ func newUsdJE(amount int, isExpense bool, category, from, to string, source *Tra
 		Source:                source,
 		Category:              category,
 		Details:               fmt.Sprintf("%s%d%s", sign, amount, category),
-		AccountCurrencyAmount: MoneyWith2DecimalPlaces{amount},
+		AccountCurrencyAmount: Money{amount * 10},
 		FromAccount:           from,
 		ToAccount:             to,
-		Amounts:               map[string]AmountInCurrency{"USD": {Currency: "USD", Amount: MoneyWith2DecimalPlaces{amount}}},
+		Amounts:               map[string]AmountInCurrency{"USD": {Currency: "USD", Amount: Money{amount * 10}}},
+	}
+}
+
+func Test_groupExtractorByCategories_HandleJournalEntry_Transfers(t *testing.T) {
+	// Arrange
+	source := &TransactionsSource{TypeName: "t1", FilePath: "s1"}
+	transfer := newUsdJE(5, false, "a1 → a2", "a1", "a2", source)
+	transfer.IsTransfer = true
+	expense := newUsdJE(3, true, "b", "a1", "a3", source)
+	builder, _ := NewStatisticBuilderByCategories(map[string]*AccountStatistics{}, nil)
+	extractor := builder(date1, date2)
+
+	// Act
+	for _, je := range []JournalEntry{transfer, expense} {
+		if err := extractor.HandleJournalEntry(je, date1, date2); err != nil {
+			t.Fatalf("HandleJournalEntry failed: %v", err)
+		}
+	}
+	stat := extractor.GetIntervalStatistics()["USD"]
+
+	// Assert
+	if len(stat.Income) != 0 || len(stat.Expense) != 1 {
+		t.Errorf("transfer should not be counted as income or expense: %+v", stat)
+	}
+	group, ok := stat.Transfers["a1 → a2"]
+	if !ok || group.Total.int != 50 || len(group.JournalEntries) != 1 {
+		t.Errorf("wrong transfers group: %+v", stat.Transfers)
+	}
+	var sb strings.Builder
+	DumpIntervalStatistic(stat, &sb, "USD", false)
+	if !strings.Contains(sb.String(), "Transfers between my accounts") {
+		t.Errorf("dump doesn't contain transfers:\n%s", sb.String())
+	}
+}
+
+// newTestCategoryGroup creates group with total and one journal entry with given category path.
+func newTestCategoryGroup(name string, total int, path ...string) *Group {
+	return &Group{
+		Name:           name,
+		Total:          Money{int: total},
+		JournalEntries: []JournalEntry{{Category: name, CategoryPath: path}},
+	}
+}
+
+func Test_buildCategoryTree(t *testing.T) {
+	// Arrange
+	groups := map[string]*Group{
+		"Food":        newTestCategoryGroup("Food", 10000, "Food"),
+		"Restaurants": newTestCategoryGroup("Restaurants", 30000, "Food", "Restaurants"),
+		"Food:Coffee": newTestCategoryGroup("Food:Coffee", 5000, "Food", "Coffee"),
+		"Taxi":        newTestCategoryGroup("Taxi", 20000, "Taxi"),
+	}
+
+	// Act
+	actual := buildCategoryTree(groups)
+
+	// Assert
+	expected := []*CategoryNode{
+		{
+			Name: "Food", Path: "Food", Group: "Food", Total: Money{int: 45000},
+			Children: []*CategoryNode{
+				{Name: "Restaurants", Path: "Food:Restaurants", Group: "Restaurants", Total: Money{int: 30000}},
+				{Name: "Coffee", Path: "Food:Coffee", Group: "Food:Coffee", Total: Money{int: 5000}},
+			},
+		},
+		{Name: "Taxi", Path: "Taxi", Group: "Taxi", Total: Money{int: 20000}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("buildCategoryTree: expected=%+v, actual=%+v", expected, actual)
+	}
+}
+
+func Test_GroupsToStrings(t *testing.T) {
+	tests := []struct {
+		name     string
+		groups   map[string]*Group
+		expected []string
+	}{
+		{
+			name: "flat",
+			groups: map[string]*Group{
+				"Food": newTestCategoryGroup("Food", 10000, "Food"),
+				"Taxi": newTestCategoryGroup("Taxi", 20000, "Taxi"),
+			},
+			expected: []string{"Taxi", "Food"},
+		},
+		{
+			name: "tree",
+			groups: map[string]*Group{
+				"Food":        newTestCategoryGroup("Food", 10000, "Food"),
+				"Restaurants": newTestCategoryGroup("Restaurants", 30000, "Food", "Restaurants"),
+				"Pizza":       newTestCategoryGroup("Pizza", 20000, "Food", "Restaurants", "Pizza"),
+				"Taxi":        newTestCategoryGroup("Taxi", 20000, "Taxi"),
+			},
+			expected: []string{"Food:", "  Food", "  Restaurants:", "    Restaurants", "    Pizza", "Taxi"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			actual := GroupsToStrings(tt.groups, buildCategoryTree(tt.groups), false)
+
+			// Assert
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("expected %d lines, got %d: %q", len(tt.expected), len(actual), actual)
+			}
+			for i, prefix := range tt.expected {
+				if !strings.HasPrefix(actual[i], prefix) {
+					t.Errorf("line %d: expected prefix %q, got %q", i, prefix, actual[i])
+				}
+			}
+		})
 	}
 }
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// TransferMatchingConfig configures matching of transfers between my own accounts.
+// Outgoing transaction from one account and incoming transaction to other account
+// with the same amount and close dates are merged into one "transfer" journal entry.
+type TransferMatchingConfig struct {
+	// Enabled turns transfers matching on.
+	Enabled bool `yaml:"enabled"`
+	// DateWindowDays is a maximum difference in days between outgoing and incoming parts of a transfer.
+	DateWindowDays int `yaml:"dateWindowDays,omitempty" validate:"min=0,max=31"`
+	// AmountTolerancePercent is a maximum difference in percents between amounts of a transfer
+	// in different currencies. Amounts are compared after conversion so exchange rates of banks
+	// may differ from ones used for conversion. Amounts in the same currency should be equal.
+	AmountTolerancePercent float64 `yaml:"amountTolerancePercent,omitempty" validate:"min=0,max=10"`
+	// Categories are names of groups for transfers between my accounts, like "Transfer between my accounts".
+	// Used when counterparty accounts of transactions don't point to each other:
+	// both parts of such transfer should be in these groups (or in their subgroups).
+	Categories []string `yaml:"categories,omitempty"`
+}
+
+// TransferLeg contains information about incoming part of the transfer.
+// Outgoing part is described by journal entry fields itself.
+type TransferLeg struct {
+	Date                  time.Time
+	Source                *TransactionsSource
+	Details               string
+	Account               string
+	AccountCurrency       string
+	AccountCurrencyAmount Money
+	OriginCurrency        string
+	OriginCurrencyAmount  Money
+}
+
+// transferLegKey identifies transaction which became a part of transfer.
+type transferLegKey struct {
+	source    *TransactionsSource
+	date      time.Time
+	isExpense bool
+	amount    int
+	details   string
+}
+
+// transferGroupName returns name of statistics group for transfers between two accounts.
+func transferGroupName(fromAccount, toAccount string) string {
+	return fromAccount + " → " + toAccount
+}
+
+// matchTransfers finds pairs of outgoing and incoming journal entries which are transfers
+// between my own accounts and replaces each pair with one journal entry with `IsTransfer` flag.
+// Parts of a transfer should be from different files and different accounts.
+// Each outgoing entry is paired with the closest by date incoming entry.
+// Returns journal entries in the original order and keys of transactions which became transfers.
+func matchTransfers(
+	journalEntries []JournalEntry,
+	config *TransferMatchingConfig,
+) ([]JournalEntry, map[transferLegKey]struct{}) {
+	matchedLegs := make(map[transferLegKey]struct{})
+	if config == nil || !config.Enabled {
+		return journalEntries, matchedLegs
+	}
+
+	// Incomes are sorted by date to scan only ones in the date window of each outgoing entry.
+	incomes := make([]int, 0)
+	for i, je := range journalEntries {
+		if !je.IsExpense && !je.IsTransfer && je.RuleType != RuleTypeSplit {
+			incomes = append(incomes, i)
+		}
+	}
+	sort.SliceStable(incomes, func(a, b int) bool {
+		return journalEntries[incomes[a]].Date.Before(journalEntries[incomes[b]].Date)
+	})
+	window := time.Duration(config.DateWindowDays) * 24 * time.Hour
+	pairs := make(map[int]int)
+	isIncomeMatched := make(map[int]bool)
+	for i, outgoing := range journalEntries {
+		if !outgoing.IsExpense || outgoing.IsTransfer || outgoing.RuleType == RuleTypeSplit {
+			continue
+		}
+		windowStart := outgoing.Date.Add(-window)
+		windowEnd := outgoing.Date.Add(window)
+		first := sort.Search(len(incomes), func(k int) bool {
+			return !journalEntries[incomes[k]].Date.Before(windowStart)
+		})
+		bestIndex := -1
+		var bestDistance time.Duration
+		for _, j := range incomes[first:] {
+			incoming := &journalEntries[j]
+			if incoming.Date.After(windowEnd) {
+				break
+			}
+			if isIncomeMatched[j] || !isTransferPair(&outgoing, incoming, config) {
+				continue
+			}
+			distance := absDuration(incoming.Date.Sub(outgoing.Date))
+			if bestIndex == -1 || distance < bestDistance {
+				bestIndex = j
+				bestDistance = distance
+			}
+		}
+		if bestIndex != -1 {
+			pairs[i] = bestIndex
+			isIncomeMatched[bestIndex] = true
+		}
+	}
+	if len(pairs) == 0 {
+		return journalEntries, matchedLegs
+	}
+
+	result := make([]JournalEntry, 0, len(journalEntries)-len(pairs))
+	for i, je := range journalEntries {
+		if isIncomeMatched[i] {
+			continue
+		}
+		if j, ok := pairs[i]; ok {
+			incoming := journalEntries[j]
+			matchedLegs[journalEntryLegKey(&je)] = struct{}{}
+			matchedLegs[journalEntryLegKey(&incoming)] = struct{}{}
+			je = mergeTransferLegs(je, incoming)
+		}
+		result = append(result, je)
+	}
+	return result, matchedLegs
+}
+
+// absDuration returns absolute value of the duration.
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// isTransferPair checks that entries are parts of one transfer, i.e. move the same amount between my accounts.
+// Counterparty of at least one part should be the account of the other part. Otherwise, for example
+// if banks don't provide counterparties, both parts should be categorized as transfers.
+func isTransferPair(outgoing, incoming *JournalEntry, config *TransferMatchingConfig) bool {
+	outAccount := journalEntryOwnAccount(outgoing)
+	inAccount := journalEntryOwnAccount(incoming)
+	if outAccount == "" || inAccount == "" || outAccount == inAccount {
+		return false
+	}
+	if outgoing.Source == nil || incoming.Source == nil || outgoing.Source.FilePath == incoming.Source.FilePath {
+		return false
+	}
+	isLinked := outgoing.ToAccount == inAccount || incoming.FromAccount == outAccount
+	if !isLinked && !(isTransferCategory(outgoing, config.Categories) && isTransferCategory(incoming, config.Categories)) {
+		return false
+	}
+	if outgoing.AccountCurrency == incoming.AccountCurrency {
+		return outgoing.AccountCurrencyAmount.int == incoming.AccountCurrencyAmount.int
+	}
+	// Compare in any currency both amounts are converted to.
+	currencies := make([]string, 0, len(outgoing.Amounts))
+	for currency := range outgoing.Amounts {
+		if _, ok := incoming.Amounts[currency]; ok {
+			currencies = append(currencies, currency)
+		}
+	}
+	if len(currencies) == 0 {
+		return false
+	}
+	sort.Strings(currencies)
+	outAmount := float64(outgoing.Amounts[currencies[0]].Amount.int)
+	inAmount := float64(incoming.Amounts[currencies[0]].Amount.int)
+	if outAmount == 0 {
+		return false
+	}
+	return math.Abs(outAmount-inAmount)/outAmount*100 <= config.AmountTolerancePercent
+}
+
+// isTransferCategory returns true if the journal entry is in one of categories or in their subcategories.
+func isTransferCategory(je *JournalEntry, categories []string) bool {
+	for _, category := range categories {
+		if je.Category == category || slices.Contains(je.CategoryPath, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// journalEntryOwnAccount returns account of the statement owner for the journal entry.
+func journalEntryOwnAccount(je *JournalEntry) string {
+	if je.Source != nil && je.Source.AccountNumber != "" {
+		return je.Source.AccountNumber
+	}
+	if je.IsExpense {
+		return je.FromAccount
+	}
+	return je.ToAccount
+}
+
+// mergeTransferLegs builds transfer journal entry from outgoing and incoming parts.
+func mergeTransferLegs(outgoing, incoming JournalEntry) JournalEntry {
+	transfer := outgoing
+	transfer.IsExpense = false
+	transfer.IsTransfer = true
+	transfer.FromAccount = journalEntryOwnAccount(&outgoing)
+	transfer.ToAccount = journalEntryOwnAccount(&incoming)
+	transfer.Category = transferGroupName(transfer.FromAccount, transfer.ToAccount)
+	transfer.CategoryPath = []string{transfer.Category}
+	transfer.RuleType = ""
+	transfer.RuleValue = ""
+	transfer.IncomingLeg = &TransferLeg{
+		Date:                  incoming.Date,
+		Source:                incoming.Source,
+		Details:               incoming.Details,
+		Account:               transfer.ToAccount,
+		AccountCurrency:       incoming.AccountCurrency,
+		AccountCurrencyAmount: incoming.AccountCurrencyAmount,
+		OriginCurrency:        incoming.OriginCurrency,
+		OriginCurrencyAmount:  incoming.OriginCurrencyAmount,
+	}
+	return transfer
+}
+
+func journalEntryLegKey(je *JournalEntry) transferLegKey {
+	return transferLegKey{
+		source:    je.Source,
+		date:      je.Date,
+		isExpense: je.IsExpense,
+		amount:    je.AccountCurrencyAmount.int,
+		details:   je.Details,
+	}
+}
+
+// removeTransferLegs returns transactions which didn't become a part of a transfer.
+func removeTransferLegs(transactions []Transaction, matchedLegs map[transferLegKey]struct{}) []Transaction {
+	if len(matchedLegs) == 0 {
+		return transactions
+	}
+	return slices.DeleteFunc(slices.Clone(transactions), func(t Transaction) bool {
+		_, ok := matchedLegs[transferLegKey{
+			source:    t.Source,
+			date:      t.Date,
+			isExpense: t.IsExpense,
+			amount:    t.Amount.int,
+			details:   t.Details,
+		}]
+		return ok
+	})
+}
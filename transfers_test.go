@@ -0,0 +1,277 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchTransfers(t *testing.T) {
+	cardSource := &TransactionsSource{Tag: "AcbaCardExcel", FilePath: "CardStatement.xls", AccountNumber: "card"}
+	accountSource := &TransactionsSource{Tag: "InecoXml:AMD", FilePath: "Statement.xml", AccountNumber: "acc"}
+	usdSource := &TransactionsSource{Tag: "InecoXml:USD", FilePath: "StatementUSD.xml", AccountNumber: "usd"}
+	newEntry := func(source *TransactionsSource, isExpense bool, days int, currency string, amount int, amd int, counterparty string) JournalEntry {
+		from, to := source.AccountNumber, counterparty
+		if !isExpense {
+			from, to = counterparty, source.AccountNumber
+		}
+		return JournalEntry{
+			Date:                  testDate.AddDate(0, 0, days),
+			IsExpense:             isExpense,
+			Source:                source,
+			Details:               source.Tag,
+			Category:              "c",
+			FromAccount:           from,
+			ToAccount:             to,
+			AccountCurrency:       currency,
+			AccountCurrencyAmount: Money{amount},
+			Amounts: map[string]AmountInCurrency{
+				"AMD": {Currency: "AMD", Amount: Money{amd}},
+			},
+		}
+	}
+	withCategory := func(je JournalEntry, category string) JournalEntry {
+		je.Category = category
+		je.CategoryPath = []string{category}
+		return je
+	}
+
+	tests := []struct {
+		name              string
+		entries           []JournalEntry
+		config            *TransferMatchingConfig
+		expectedTransfers []string
+		expectedLen       int
+	}{
+		{
+			name: "disabled",
+			entries: []JournalEntry{
+				newEntry(cardSource, true, 0, "AMD", 10000, 10000, "acc"),
+				newEntry(accountSource, false, 0, "AMD", 10000, 10000, "other"),
+			},
+			config:      &TransferMatchingConfig{Enabled: false},
+			expectedLen: 2,
+		},
+		{
+			name: "same currency in date window",
+			entries: []JournalEntry{
+				newEntry(cardSource, true, 0, "AMD", 10000, 10000, "acc"),
+				newEntry(cardSource, true, 0, "AMD", 5000, 5000, "acc"),
+				newEntry(accountSource, false, 1, "AMD", 10000, 10000, "other"),
+			},
+			config:            &TransferMatchingConfig{Enabled: true, DateWindowDays: 2},
+			expectedTransfers: []string{"card → acc"},
+			expectedLen:       2,
+		},
+		{
+			name: "out of date window",
+			entries: []JournalEntry{
+				newEntry(cardSource, true, 0, "AMD", 10000, 10000, "acc"),
+				newEntry(accountSource, false, 3, "AMD", 10000, 10000, "other"),
+			},
+			config:      &TransferMatchingConfig{Enabled: true, DateWindowDays: 2},
+			expectedLen: 2,
+		},
+		{
+			name: "same account is not a transfer",
+			entries: []JournalEntry{
+				newEntry(cardSource, true, 0, "AMD", 10000, 10000, "acc"),
+				newEntry(&TransactionsSource{Tag: "AcbaCardExcel", FilePath: "CardStatement2.xls", AccountNumber: "card"}, false, 0, "AMD", 10000, 10000, "other"),
+			},
+			config:      &TransferMatchingConfig{Enabled: true},
+			expectedLen: 2,
+		},
+		{
+			name: "unrelated counterparties are not a transfer",
+			entries: []JournalEntry{
+				newEntry(cardSource, true, 0, "AMD", 10000, 10000, "shop"),
+				newEntry(accountSource, false, 0, "AMD", 10000, 10000, "employer"),
+			},
+			config:      &TransferMatchingConfig{Enabled: true},
+			expectedLen: 2,
+		},
+		{
+			name: "payer of incoming entry is outgoing account",
+			entries: []JournalEntry{
+				newEntry(cardSource, true, 0, "AMD", 10000, 10000, "UnknownAccount"),
+				newEntry(accountSource, false, 0, "AMD", 10000, 10000, "card"),
+			},
+			config:            &TransferMatchingConfig{Enabled: true},
+			expectedTransfers: []string{"card → acc"},
+			expectedLen:       1,
+		},
+		{
+			name: "unknown counterparties with transfer categories",
+			entries: []JournalEntry{
+				newEntry(cardSource, true, 0, "AMD", 10000, 10000, "UnknownAccount"),
+				withCategory(newEntry(accountSource, false, 0, "AMD", 10000, 10000, ""), "Own"),
+			},
+			config:            &TransferMatchingConfig{Enabled: true, Categories: []string{"c", "Own"}},
+			expectedTransfers: []string{"card → acc"},
+			expectedLen:       1,
+		},
+		{
+			name: "unknown counterparties with one transfer category",
+			entries: []JournalEntry{
+				newEntry(cardSource, true, 0, "AMD", 10000, 10000, "UnknownAccount"),
+				withCategory(newEntry(accountSource, false, 0, "AMD", 10000, 10000, ""), "Transfers"),
+			},
+			config:      &TransferMatchingConfig{Enabled: true, Categories: []string{"Transfers"}},
+			expectedLen: 2,
+		},
+		{
+			name: "closest incoming entry is chosen",
+			entries: []JournalEntry{
+				newEntry(accountSource, false, -2, "AMD", 10000, 10000, "other"),
+				newEntry(cardSource, true, 0, "AMD", 10000, 10000, "acc"),
+				newEntry(accountSource, false, 1, "AMD", 10000, 10000, "other"),
+			},
+			config:            &TransferMatchingConfig{Enabled: true, DateWindowDays: 3},
+			expectedTransfers: []string{"card → acc"},
+			expectedLen:       2,
+		},
+		{
+			name: "different currencies within tolerance",
+			entries: []JournalEntry{
+				newEntry(usdSource, true, 0, "USD", 100000, 39000000, "acc"),
+				newEntry(accountSource, false, 0, "AMD", 38800000, 38800000, "other"),
+			},
+			config:            &TransferMatchingConfig{Enabled: true, AmountTolerancePercent: 1},
+			expectedTransfers: []string{"usd → acc"},
+			expectedLen:       1,
+		},
+		{
+			name: "different currencies out of tolerance",
+			entries: []JournalEntry{
+				newEntry(usdSource, true, 0, "USD", 100000, 39000000, "acc"),
+				newEntry(accountSource, false, 0, "AMD", 38000000, 38000000, "other"),
+			},
+			config:      &TransferMatchingConfig{Enabled: true, AmountTolerancePercent: 1},
+			expectedLen: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Act
+			result, legs := matchTransfers(tt.entries, tt.config)
+
+			// Assert
+			if len(result) != tt.expectedLen {
+				t.Errorf("expected %d entries, got %d: %+v", tt.expectedLen, len(result), result)
+			}
+			transfers := make([]string, 0)
+			for _, je := range result {
+				if je.IsTransfer {
+					transfers = append(transfers, je.Category)
+					if je.IncomingLeg == nil || je.IncomingLeg.Account != je.ToAccount {
+						t.Errorf("wrong incoming leg %+v of %+v", je.IncomingLeg, je)
+					}
+				}
+			}
+			if strings.Join(transfers, ",") != strings.Join(tt.expectedTransfers, ",") {
+				t.Errorf("expected transfers %v, got %v", tt.expectedTransfers, transfers)
+			}
+			if len(legs) != 2*len(tt.expectedTransfers) {
+				t.Errorf("expected %d matched legs, got %d", 2*len(tt.expectedTransfers), len(legs))
+			}
+		})
+	}
+}
+
+func TestRemoveTransferLegs(t *testing.T) {
+	// Arrange
+	source := &TransactionsSource{FilePath: "Statement.xml", AccountNumber: "acc"}
+	transactions := []Transaction{
+		{Date: testDate, Source: source, Details: "to card", IsExpense: true, Amount: Money{10000}},
+		{Date: testDate, Source: source, Details: "coffee", IsExpense: true, Amount: Money{10000}},
+	}
+	legs := map[transferLegKey]struct{}{
+		{source: source, date: testDate, isExpense: true, amount: 10000, details: "to card"}: {},
+	}
+
+	// Act
+	result := removeTransferLegs(transactions, legs)
+
+	// Assert
+	if len(result) != 1 || result[0].Details != "coffee" {
+		t.Errorf("expected only 'coffee' transaction, got %+v", result)
+	}
+	if len(transactions) != 2 {
+		t.Errorf("original slice was modified: %+v", transactions)
+	}
+}
+
+func TestBuildBeancountFile_Transfer(t *testing.T) {
+	usdSource := &TransactionsSource{Tag: "InecoXml:USD", FilePath: "StatementUSD.xml", AccountNumber: "usd"}
+	amdSource := &TransactionsSource{Tag: "InecoXml:AMD", FilePath: "Statement.xml", AccountNumber: "amd"}
+	accounts := map[string]*AccountStatistics{
+		"usd": {Number: "usd", IsTransactionAccount: true, Source: usdSource, From: testDate},
+		"amd": {Number: "amd", IsTransactionAccount: true, Source: amdSource, From: testDate},
+	}
+	tests := []struct {
+		name             string
+		incomingCurrency string
+		incomingAmount   int
+		expectedPostings string
+	}{
+		{
+			name:             "same currency",
+			incomingCurrency: "USD",
+			incomingAmount:   100000,
+			expectedPostings: "  Assets:InecoXml:USD:usd    -100.00 USD\n  Assets:InecoXml:AMD:amd    100.00 USD\n",
+		},
+		{
+			name:             "different currencies",
+			incomingCurrency: "AMD",
+			incomingAmount:   39000000,
+			expectedPostings: "  Assets:InecoXml:USD:usd    -100.00 USD @@ 39,000.00 AMD\n  Assets:InecoXml:AMD:amd    39,000.00 AMD\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Arrange
+			je := JournalEntry{
+				Date:                  testDate,
+				IsTransfer:            true,
+				Source:                usdSource,
+				Details:               "Transfer",
+				FromAccount:           "usd",
+				ToAccount:             "amd",
+				AccountCurrency:       "USD",
+				AccountCurrencyAmount: Money{100000},
+				IncomingLeg: &TransferLeg{
+					Date:                  testDate,
+					Source:                amdSource,
+					Account:               "amd",
+					AccountCurrency:       tt.incomingCurrency,
+					AccountCurrencyAmount: Money{tt.incomingAmount},
+				},
+			}
+			outputPath := filepath.Join(t.TempDir(), "result.beancount")
+
+			// Act
+			n, err := buildBeancountFile([]JournalEntry{je}, map[string]*CurrencyStatistics{}, accounts, nil, outputPath)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if n != 1 {
+				t.Errorf("expected 1 journal entry, got %d", n)
+			}
+			content, err := os.ReadFile(outputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(content), tt.expectedPostings) {
+				t.Errorf("expected postings:\n%s\nin file:\n%s", tt.expectedPostings, content)
+			}
+			if strings.Contains(string(content), "Expenses:") || strings.Contains(string(content), "Income:") {
+				t.Errorf("transfer should have only assets postings:\n%s", content)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"log"
@@ -82,31 +84,24 @@ func ListenAndServe(dataHandler *DataHandler) error {
 		return fmt.Errorf("failed to initialize shared templates: %w", err)
 	}
 
-	// Set up HTTP handlers
-	http.HandleFunc("/", handleIndex(dataHandler))
-	http.HandleFunc("/transactions", handleTransactions(dataHandler))
-	http.HandleFunc("/categorization", handleCategorization(dataHandler))
-	http.HandleFunc("/groups", handleGroups(dataHandler))
-	http.HandleFunc("/files", handleFiles(dataHandler))
-	http.HandleFunc("/open-file", handleOpenFile())
-	http.HandleFunc("/refresh-files", handleRefreshFiles(dataHandler))
-
-	// Serve static files based on DEV_MODE
-	if devMode {
-		// In development mode, serve from filesystem
-		http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
-	} else {
-		// In production mode, serve from embedded FS
-		http.Handle("/static/", http.FileServer(http.FS(static)))
+	// Watch files to rebuild data and reload pages automatically.
+	broker := NewEventsBroker()
+	config := dataHandler.GetSnapshot().Config
+	if !config.DisableFilesWatcher {
+		watcher := NewFilesWatcher(dataHandler.getWatchedPatterns, dataHandler.isOwnWrite, func() {
+			rebuildOnFilesChange(dataHandler, broker)
+		})
+		go watcher.Run(context.Background())
 	}
 
 	// Wrap the entire http.ServeMux with a logging handler
+	mux := newServeMux(dataHandler, broker)
 	loggedMux := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
 		// Create a custom ResponseWriter to capture the status code.
 		lw := &logWriter{ResponseWriter: w}
-		http.DefaultServeMux.ServeHTTP(lw, r)
+		mux.ServeHTTP(lw, r)
 		duration := time.Since(start)
 		log.Printf("%s %s %d %dms", r.Method, r.URL.Path, lw.statusCode, duration.Milliseconds())
 	})
@@ -114,7 +109,35 @@ func ListenAndServe(dataHandler *DataHandler) error {
 	if devMode {
 		log.Println("Running in development mode - serving static files directly from filesystem")
 	}
-	return http.ListenAndServe(fmt.Sprintf(":%d", dataHandler.Config.UIPort), loggedMux)
+	return http.ListenAndServe(fmt.Sprintf(":%d", config.UIPort), loggedMux)
+}
+
+// newServeMux creates router with all pages and API of UI.
+func newServeMux(dataHandler *DataHandler, broker *EventsBroker) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	// Set up HTTP handlers
+	mux.HandleFunc("/", handleIndex(dataHandler))
+	mux.HandleFunc("/transactions", handleTransactions(dataHandler))
+	mux.HandleFunc("/categorization", handleCategorization(dataHandler))
+	mux.HandleFunc("/groups", handleGroups(dataHandler))
+	mux.HandleFunc("/files", handleFiles(dataHandler))
+	mux.HandleFunc("/recurring", handleRecurring(dataHandler))
+	mux.HandleFunc("/balances", handleBalances(dataHandler))
+	mux.HandleFunc("/open-file", handleOpenFile())
+	mux.HandleFunc("/refresh-files", handleRefreshFiles(dataHandler, broker))
+	mux.HandleFunc("/events", handleEvents(broker))
+	registerAPIHandlers(mux, dataHandler)
+
+	// Serve static files based on DEV_MODE
+	if devMode {
+		// In development mode, serve from filesystem
+		mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
+	} else {
+		// In production mode, serve from embedded FS
+		mux.Handle("/static/", http.FileServer(http.FS(static)))
+	}
+	return mux
 }
 
 func handleIndex(dataHandler *DataHandler) func(w http.ResponseWriter, r *http.Request) {
@@ -130,7 +153,13 @@ func handleIndex(dataHandler *DataHandler) func(w http.ResponseWriter, r *http.R
 		}
 
 		// Prepare JSON with statistics.
-		statistics, err := dataHandler.GetMonthlyStatistics()
+		snapshot := dataHandler.GetSnapshot()
+		periodType, err := getRequestPeriodType(r, dataHandler, snapshot.Config)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		statistics, err := snapshot.GetPeriodStatistics(periodType)
 		if err != nil {
 			logAndReturnError(w, err)
 			return
@@ -142,19 +171,25 @@ func handleIndex(dataHandler *DataHandler) func(w http.ResponseWriter, r *http.R
 		}
 
 		currencies := make([]string, 0)
-		for _, stat := range statistics[0] {
-			currencies = append(currencies, stat.Currency)
+		if len(statistics) > 0 {
+			for _, stat := range statistics[0] {
+				currencies = append(currencies, stat.Currency)
+			}
 		}
 		sort.Strings(currencies)
 
 		data := struct {
-			Currencies []string
-			Statistics template.JS
-			Locale     string
+			Currencies  []string
+			Statistics  template.JS
+			Locale      string
+			Period      PeriodType
+			PeriodTypes []PeriodType
 		}{
-			Currencies: currencies,
-			Statistics: template.JS(jsonData),
-			Locale:     i18n.locale,
+			Currencies:  currencies,
+			Statistics:  template.JS(jsonData),
+			Locale:      i18n.locale,
+			Period:      periodType,
+			PeriodTypes: snapshot.Config.GetAvailablePeriodTypes(),
 		}
 
 		err = parseAndExecuteTemplate("templates/index.html", w, data)
@@ -165,6 +200,22 @@ func handleIndex(dataHandler *DataHandler) func(w http.ResponseWriter, r *http.R
 	}
 }
 
+// getRequestPeriodType returns type of periods from "period" query parameter.
+// By default it is type from CLI arguments or from configuration.
+func getRequestPeriodType(r *http.Request, dataHandler *DataHandler, config *Config) (PeriodType, error) {
+	periodType, err := ParsePeriodType(r.URL.Query().Get("period"))
+	if err != nil {
+		return "", err
+	}
+	if periodType == "" {
+		periodType = dataHandler.PeriodType
+	}
+	if periodType == "" {
+		periodType = config.GetPeriodType()
+	}
+	return periodType, nil
+}
+
 func handleTransactions(dataHandler *DataHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		month := r.URL.Query().Get("month")
@@ -172,13 +223,20 @@ func handleTransactions(dataHandler *DataHandler) http.HandlerFunc {
 		txType := r.URL.Query().Get("type")
 		currency := r.URL.Query().Get("currency")
 
-		statistics, err := dataHandler.GetMonthlyStatistics()
+		// Use one snapshot to don't mix data if it is rebuilt meanwhile.
+		snapshot := dataHandler.GetSnapshot()
+		periodType, err := getRequestPeriodType(r, dataHandler, snapshot.Config)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		statistics, err := snapshot.GetPeriodStatistics(periodType)
 		if err != nil {
 			logAndReturnError(w, err)
 			return
 		}
 
-		// Find the statistics for the selected month
+		// Find the statistics for the selected period, "month" parameter contains name of the period.
 		var entries []JournalEntry
 		for _, stat := range statistics {
 			currStat := stat[currency]
@@ -186,13 +244,15 @@ func handleTransactions(dataHandler *DataHandler) http.HandlerFunc {
 				continue
 			}
 
-			// Break this into separate steps for debugging
-			formattedDate := i18n.T("date_format", "val", currStat.Start)[:7] // YYYY-MM
-			if formattedDate == month {
+			if currStat.Name == month {
 				if txType == "income" {
 					if groupData, ok := currStat.Income[group]; ok {
 						entries = groupData.JournalEntries
 					}
+				} else if txType == "transfer" {
+					if groupData, ok := currStat.Transfers[group]; ok {
+						entries = groupData.JournalEntries
+					}
 				} else {
 					if groupData, ok := currStat.Expense[group]; ok {
 						entries = groupData.JournalEntries
@@ -203,7 +263,7 @@ func handleTransactions(dataHandler *DataHandler) http.HandlerFunc {
 		}
 
 		// JSON encode the accounts
-		jsonAccounts, err := json.Marshal(dataHandler.DataMart.Accounts)
+		jsonAccounts, err := json.Marshal(snapshot.DataMart.Accounts)
 		if err != nil {
 			logAndReturnError(w, err)
 			return
@@ -221,8 +281,8 @@ func handleTransactions(dataHandler *DataHandler) http.HandlerFunc {
 		var templateEntries []TemplateEntry
 
 		for _, entry := range entries {
-			fromAccount := dataHandler.DataMart.Accounts[entry.FromAccount]
-			toAccount := dataHandler.DataMart.Accounts[entry.ToAccount]
+			fromAccount := snapshot.DataMart.Accounts[entry.FromAccount]
+			toAccount := snapshot.DataMart.Accounts[entry.ToAccount]
 			isCounted := fromAccount != nil &&
 				toAccount != nil &&
 				fromAccount.IsTransactionAccount &&
@@ -238,7 +298,7 @@ func handleTransactions(dataHandler *DataHandler) http.HandlerFunc {
 		}
 
 		// Get sorted groups for the template
-		sortedGroups := getSortedGroups(dataHandler.Config.Groups)
+		sortedGroups := getSortedGroups(snapshot.Config.Groups)
 
 		// JSON encode the groups
 		jsonGroups, err := json.Marshal(sortedGroups)
@@ -273,6 +333,15 @@ func handleTransactions(dataHandler *DataHandler) http.HandlerFunc {
 	}
 }
 
+var (
+	// errGroupAlreadyExists is returned when group is renamed to the name of other group.
+	errGroupAlreadyExists = errors.New("Group with this name already exists")
+	// errGroupNotFound is returned when changed group or group for transaction is not configured.
+	errGroupNotFound = errors.New("Group with this name doesn't exist")
+	// errGroupIsSalaryGroup is returned when deleted group starts salary periods.
+	errGroupIsSalaryGroup = errors.New("Group starts salary periods, change 'period.salaryGroup' first")
+)
+
 func handleCategorization(dataHandler *DataHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "POST" {
@@ -283,6 +352,7 @@ func handleCategorization(dataHandler *DataHandler) http.HandlerFunc {
 				Substrings   []string `json:"substrings,omitempty"`
 				FromAccounts []string `json:"fromAccounts,omitempty"`
 				ToAccounts   []string `json:"toAccounts,omitempty"`
+				Fingerprint  string   `json:"fingerprint,omitempty"`
 			}
 
 			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -290,48 +360,57 @@ func handleCategorization(dataHandler *DataHandler) http.HandlerFunc {
 				return
 			}
 
-			switch request.Action {
-			case "upsertGroup":
-				if request.GroupName == "" {
-					logAndReturnError(w, fmt.Errorf("for action 'upsertGroup' value in 'groupName' should be provided"))
-					return
-				}
-				if group, ok := dataHandler.Config.Groups[request.GroupName]; ok {
-					group.Substrings = request.Substrings
-					group.FromAccounts = request.FromAccounts
-					group.ToAccounts = request.ToAccounts
-				} else {
-					group = &GroupConfig{
-						Substrings:   request.Substrings,
-						FromAccounts: request.FromAccounts,
-						ToAccounts:   request.ToAccounts,
-					}
-					dataHandler.Config.Groups[request.GroupName] = group
-				}
-
-			case "deleteGroup":
-				if request.GroupName == "" {
-					logAndReturnError(w, fmt.Errorf("for 'deleteGroup' action 'groupName' is required"))
+			// Manual category of single transaction is stored separately from groups.
+			var err error
+			if request.Action == "setTransactionCategory" {
+				if request.Fingerprint == "" {
+					http.Error(w, "for action 'setTransactionCategory' value in 'fingerprint' should be provided", http.StatusBadRequest)
 					return
 				}
-				delete(dataHandler.Config.Groups, request.GroupName)
-
-			case "renameGroup":
+				err = dataHandler.SetCategoryOverride(request.Fingerprint, request.GroupName)
+			} else if request.Action == "renameGroup" {
+				// Renaming changes references to the group in budgets, overrides and splits too.
 				if request.NewGroupName == "" {
-					logAndReturnError(w, fmt.Errorf("newGroupName is required"))
+					http.Error(w, "for action 'renameGroup' value in 'newGroupName' should be provided", http.StatusBadRequest)
 					return
 				}
-				if _, exists := dataHandler.Config.Groups[request.NewGroupName]; exists {
-					http.Error(w, "Group with this name already exists", http.StatusBadRequest)
+				err = dataHandler.RenameGroup(request.GroupName, request.NewGroupName)
+			} else if request.Action == "deleteGroup" {
+				// Group which starts salary periods is kept to not break the configuration.
+				if request.GroupName == "" {
+					http.Error(w, "for 'deleteGroup' action 'groupName' is required", http.StatusBadRequest)
 					return
 				}
-				group := dataHandler.Config.Groups[request.GroupName]
-				delete(dataHandler.Config.Groups, request.GroupName)
-				dataHandler.Config.Groups[request.NewGroupName] = group
+				err = dataHandler.DeleteGroup(request.GroupName)
+			} else {
+				// After any modification update groups in memory and on disk.
+				err = dataHandler.UpdateGroups(func(groups map[string]*GroupConfig) error {
+					switch request.Action {
+					case "upsertGroup":
+						if request.GroupName == "" {
+							return fmt.Errorf("for action 'upsertGroup' value in 'groupName' should be provided")
+						}
+						if group, ok := groups[request.GroupName]; ok {
+							group.Substrings = request.Substrings
+							group.FromAccounts = request.FromAccounts
+							group.ToAccounts = request.ToAccounts
+						} else {
+							groups[request.GroupName] = &GroupConfig{
+								Substrings:   request.Substrings,
+								FromAccounts: request.FromAccounts,
+								ToAccounts:   request.ToAccounts,
+							}
+						}
+					}
+					return nil
+				})
 			}
-
-			// After any modification update groups in memory and on disk.
-			if err := dataHandler.UpdateGroups(dataHandler.Config.Groups); err != nil {
+			if errors.Is(err, errGroupAlreadyExists) || errors.Is(err, errGroupNotFound) ||
+				errors.Is(err, errGroupIsSalaryGroup) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err != nil {
 				logAndReturnError(w, err)
 				return
 			}
@@ -340,7 +419,8 @@ func handleCategorization(dataHandler *DataHandler) http.HandlerFunc {
 		}
 
 		// Show the categorization page.
-		transactions, err := dataHandler.GetUncategorizedTransactions()
+		snapshot := dataHandler.GetSnapshot()
+		transactions, err := snapshot.GetUncategorizedTransactions()
 		if err != nil {
 			logAndReturnError(w, err)
 			return
@@ -351,8 +431,8 @@ func handleCategorization(dataHandler *DataHandler) http.HandlerFunc {
 			Accounts     template.JS
 		}{
 			Transactions: transactions,
-			Groups:       template.JS(mustEncodeJSON(getSortedGroups(dataHandler.Config.Groups))),
-			Accounts:     template.JS(mustEncodeJSON(dataHandler.DataMart.Accounts)),
+			Groups:       template.JS(mustEncodeJSON(getSortedGroups(snapshot.Config.Groups))),
+			Accounts:     template.JS(mustEncodeJSON(snapshot.DataMart.Accounts)),
 		}
 		err = parseAndExecuteTemplate("templates/categorization.html", w, data)
 		if err != nil {
@@ -367,7 +447,7 @@ func handleGroups(dataHandler *DataHandler) http.HandlerFunc {
 		data := struct {
 			Groups map[string]*GroupConfig
 		}{
-			Groups: getSortedGroups(dataHandler.Config.Groups),
+			Groups: getSortedGroups(dataHandler.GetSnapshot().Config.Groups),
 		}
 
 		err := parseAndExecuteTemplate("templates/groups.html", w, data)
@@ -378,19 +458,120 @@ func handleGroups(dataHandler *DataHandler) http.HandlerFunc {
 	}
 }
 
+// SourceInfo represents configured source of transactions files.
+type SourceInfo struct {
+	Parser     string
+	TypeName   string
+	Glob       string
+	FilesCount int
+}
+
+// getSourceInfos returns information about all configured sources.
+func getSourceInfos(config *Config) []SourceInfo {
+	sources := config.GetSources()
+	result := make([]SourceInfo, 0, len(sources))
+	for _, source := range sources {
+		info := SourceInfo{
+			Parser: source.Parser,
+			Glob:   source.Glob,
+		}
+		if registration, ok := getParserRegistration(source.Parser); ok {
+			info.TypeName = registration.TypeName
+		}
+		if files, err := getFilesByGlob(source.Glob); err == nil {
+			info.FilesCount = len(files)
+		}
+		result = append(result, info)
+	}
+	return result
+}
+
+// TimelineSegment is a part of the account timeline on the files page.
+// Position and width are in percents of the whole timeline.
+type TimelineSegment struct {
+	Left  string
+	Width string
+	Class string
+	Title string
+}
+
+// AccountTimeline is a timeline of statements of one account.
+type AccountTimeline struct {
+	Coverage *AccountCoverage
+	Segments []TimelineSegment
+}
+
+// buildCoverageTimelines places periods of statements and issues of all accounts on the same timeline.
+// Returns timelines and first and last days of the timeline.
+func buildCoverageTimelines(accounts []*AccountCoverage) ([]AccountTimeline, time.Time, time.Time) {
+	result := make([]AccountTimeline, 0, len(accounts))
+	if len(accounts) == 0 {
+		return result, time.Time{}, time.Time{}
+	}
+	from, to := accounts[0].From, accounts[0].To
+	for _, account := range accounts[1:] {
+		if account.From.Before(from) {
+			from = account.From
+		}
+		if account.To.After(to) {
+			to = account.To
+		}
+	}
+	totalDays := to.Sub(from).Hours()/24 + 1
+	segment := func(start, end time.Time, class, title string) TimelineSegment {
+		return TimelineSegment{
+			Left:  fmt.Sprintf("%.2f", start.Sub(from).Hours()/24/totalDays*100),
+			Width: fmt.Sprintf("%.2f", (end.Sub(start).Hours()/24+1)/totalDays*100),
+			Class: class,
+			Title: title,
+		}
+	}
+	for _, account := range accounts {
+		timeline := AccountTimeline{Coverage: account}
+		for _, period := range account.Periods {
+			timeline.Segments = append(timeline.Segments, segment(period.From, period.To, "coverage-file",
+				fmt.Sprintf("%s: %s - %s", period.Path, period.From.Format(OutputDateFormat), period.To.Format(OutputDateFormat))))
+		}
+		for _, issue := range account.Issues {
+			timeline.Segments = append(timeline.Segments, segment(issue.From, issue.To, "coverage-"+string(issue.Type),
+				fmt.Sprintf("%s: %s - %s", issue.LocalizedType(), issue.From.Format(OutputDateFormat), issue.To.Format(OutputDateFormat))))
+		}
+		result = append(result, timeline)
+	}
+	return result, from, to
+}
+
 func handleFiles(dataHandler *DataHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		workingDir, err := os.Getwd()
 		if err != nil {
 			workingDir = i18n.T("Unable to determine working directory")
 		}
+		snapshot := dataHandler.GetSnapshot()
 
 		data := struct {
-			WorkingDir string
-			Files      []FileInfo
+			WorkingDir        string
+			Sources           []SourceInfo
+			InboxReports      []InboxFileReport
+			Files             []FileInfo
+			DroppedDuplicates []DroppedDuplicate
+			Coverage          []AccountTimeline
+			CoverageFrom      time.Time
+			CoverageTo        time.Time
+			CoverageIssues    int
 		}{
-			WorkingDir: workingDir,
-			Files:      dataHandler.FileInfos,
+			WorkingDir:   workingDir,
+			Sources:      getSourceInfos(snapshot.Config),
+			InboxReports: snapshot.InboxReports,
+			Files:        snapshot.FileInfos,
+		}
+		coverage := BuildCoverage(snapshot.FileInfos)
+		data.Coverage, data.CoverageFrom, data.CoverageTo = buildCoverageTimelines(coverage)
+		for _, account := range coverage {
+			data.CoverageIssues += len(account.Issues)
+		}
+		if snapshot.DataMart != nil {
+			data.DroppedDuplicates = snapshot.DataMart.DroppedDuplicates
 		}
 
 		err = parseAndExecuteTemplate("templates/files.html", w, data)
@@ -401,6 +582,80 @@ func handleFiles(dataHandler *DataHandler) http.HandlerFunc {
 	}
 }
 
+func handleRecurring(dataHandler *DataHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data := struct {
+			Payments []*RecurringPayment
+		}{
+			Payments: dataHandler.GetRecurringPayments(),
+		}
+
+		err := parseAndExecuteTemplate("templates/recurring.html", w, data)
+		if err != nil {
+			logAndReturnError(w, err)
+			return
+		}
+	}
+}
+
+func handleBalances(dataHandler *DataHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snapshot := dataHandler.GetSnapshot()
+		balances := snapshot.GetBalances()
+		if balances == nil {
+			balances = &Balances{}
+		}
+
+		// Prepare net worth timeline for chart, amounts per currency.
+		// Days without net worth in the currency are nulls to show gaps.
+		netWorth := struct {
+			Dates   []string
+			Amounts map[string][]*float64
+		}{
+			Dates:   make([]string, 0, len(balances.NetWorth)),
+			Amounts: make(map[string][]*float64, len(balances.Currencies)),
+		}
+		for _, point := range balances.NetWorth {
+			netWorth.Dates = append(netWorth.Dates, point.Date.Format(OutputDateFormat))
+			for _, currency := range balances.Currencies {
+				var amount *float64
+				if money, ok := point.Amounts[currency]; ok {
+					value := money.Float()
+					amount = &value
+				}
+				netWorth.Amounts[currency] = append(netWorth.Amounts[currency], amount)
+			}
+		}
+		jsonData, err := json.Marshal(netWorth)
+		if err != nil {
+			logAndReturnError(w, err)
+			return
+		}
+		currency := r.URL.Query().Get("currency")
+		if currency == "" && len(snapshot.Config.ConvertToCurrencies) > 0 {
+			currency = snapshot.Config.ConvertToCurrencies[0]
+		}
+
+		data := struct {
+			Accounts   []*AccountBalances
+			Currencies []string
+			Currency   string
+			NetWorth   template.JS
+		}{
+			Accounts:   balances.Accounts,
+			Currencies: balances.Currencies,
+			Currency:   currency,
+			NetWorth:   template.JS(jsonData),
+		}
+
+		err = parseAndExecuteTemplate("templates/balances.html", w, data)
+		if err != nil {
+			logAndReturnError(w, err)
+			return
+		}
+	}
+}
+
 func handleOpenFile() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		filePath := r.URL.Query().Get("path")
@@ -418,7 +673,7 @@ func handleOpenFile() http.HandlerFunc {
 	}
 }
 
-func handleRefreshFiles(dataHandler *DataHandler) http.HandlerFunc {
+func handleRefreshFiles(dataHandler *DataHandler, broker *EventsBroker) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -435,10 +690,47 @@ func handleRefreshFiles(dataHandler *DataHandler) http.HandlerFunc {
 		}
 
 		log.Println("Files refreshed successfully")
+		// Let other opened pages know about new data.
+		broker.Publish(EventReload)
 		w.WriteHeader(http.StatusOK)
 	}
 }
 
+// handleEvents streams server events to the page with Server-Sent Events.
+func handleEvents(broker *EventsBroker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		controller := http.NewResponseController(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		events := broker.Subscribe()
+		defer broker.Unsubscribe(events)
+
+		// Ask browser to reconnect quickly if server is restarted.
+		fmt.Fprint(w, "retry: 3000\n\n")
+		if err := controller.Flush(); err != nil {
+			logAndReturnError(w, err)
+			return
+		}
+		heartbeat := time.NewTicker(30 * time.Second)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-events:
+				fmt.Fprintf(w, "event: %s\ndata: {}\n\n", event)
+			case <-heartbeat.C:
+				// Comment line keeps connection alive through proxies.
+				fmt.Fprint(w, ": ping\n\n")
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
 // Helper function to encode JSON and panic on error (since this is server startup)
 func mustEncodeJSON(v interface{}) string {
 	data, err := json.Marshal(v)
@@ -464,6 +756,11 @@ func (lw *logWriter) WriteHeader(code int) {
 	lw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap allows http.ResponseController to access original ResponseWriter, e.g. to flush events.
+func (lw *logWriter) Unwrap() http.ResponseWriter {
+	return lw.ResponseWriter
+}
+
 func (lw *logWriter) Write(b []byte) (int, error) {
 	if lw.statusCode == 0 {
 		lw.statusCode = 200
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestDataHandler creates DataHandler with data from testdata files and configuration in temporary directory.
+func newTestDataHandler(t *testing.T) *DataHandler {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	config := `
+timeZoneLocation: "UTC"
+ensureTerminal: false
+sources:
+  - parser: inecoXlsx
+    glob: "testdata/ineco/valid_*.xlsx"
+groups:
+  Food:
+    substrings: ["FOOD"]
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dataHandler := NewDataHandler(configPath, nil)
+	if err := dataHandler.RebuildFromFiles(); err != nil {
+		t.Fatalf("can't build data: %v", err)
+	}
+	return dataHandler
+}
+
+func TestDataHandler_RebuildKeepsOldSnapshot(t *testing.T) {
+	// Arrange
+	dataHandler := newTestDataHandler(t)
+	oldSnapshot := dataHandler.GetSnapshot()
+	oldStatistics, err := oldSnapshot.GetMonthlyStatistics()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	err = dataHandler.RebuildFromFiles()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("rebuild failed: %v", err)
+	}
+	newSnapshot := dataHandler.GetSnapshot()
+	if newSnapshot == oldSnapshot || newSnapshot.DataMart == oldSnapshot.DataMart {
+		t.Errorf("rebuild should swap in new snapshot")
+	}
+	statistics, err := oldSnapshot.GetMonthlyStatistics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &statistics[0] != &oldStatistics[0] {
+		t.Errorf("old snapshot statistics are changed by rebuild")
+	}
+}
+
+func TestDataHandler_UpdateGroups(t *testing.T) {
+	tests := []struct {
+		name           string
+		update         func(groups map[string]*GroupConfig) error
+		expectedError  string
+		expectedGroups []string
+	}{
+		{
+			name: "add_group",
+			update: func(groups map[string]*GroupConfig) error {
+				groups["Taxi"] = &GroupConfig{Substrings: []string{"TAXI"}}
+				return nil
+			},
+			expectedGroups: []string{"Food", "Taxi"},
+		},
+		{
+			name: "change_group",
+			update: func(groups map[string]*GroupConfig) error {
+				groups["Food"].Substrings = []string{"GROCERY"}
+				return nil
+			},
+			expectedGroups: []string{"Food"},
+		},
+		{
+			name: "failed_update",
+			update: func(groups map[string]*GroupConfig) error {
+				delete(groups, "Food")
+				return errGroupAlreadyExists
+			},
+			expectedError:  errGroupAlreadyExists.Error(),
+			expectedGroups: []string{"Food"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Arrange
+			dataHandler := newTestDataHandler(t)
+			oldSnapshot := dataHandler.GetSnapshot()
+
+			// Act
+			err := dataHandler.UpdateGroups(tt.update)
+
+			// Assert
+			if tt.expectedError != "" {
+				checkErrorContainsSubstring(t, err, tt.expectedError)
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(oldSnapshot.Config.Groups) != 1 || oldSnapshot.Config.Groups["Food"].Substrings[0] != "FOOD" {
+				t.Errorf("groups of old snapshot are changed: %v", oldSnapshot.Config.Groups)
+			}
+			config, err := readConfig(dataHandler.ConfigPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, groups := range []map[string]*GroupConfig{config.Groups, dataHandler.GetSnapshot().Config.Groups} {
+				if len(groups) != len(tt.expectedGroups) {
+					t.Errorf("expected groups %v, got %v", tt.expectedGroups, groups)
+				}
+				for _, name := range tt.expectedGroups {
+					if _, ok := groups[name]; !ok {
+						t.Errorf("expected group %s, got %v", name, groups)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDataHandler_RenameGroup(t *testing.T) {
+	// Arrange
+	dataHandler := newTestDataHandler(t)
+	journalEntries, err := dataHandler.GetJournalEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	overridden, split := journalEntries[0].Fingerprint, journalEntries[1].Fingerprint
+	err = dataHandler.updateConfig(func(config *Config) error {
+		config.Groups["Food:Groceries"] = &GroupConfig{Substrings: []string{"GROCERY"}}
+		config.Budgets = map[string]*BudgetConfig{"Food": {Limit: floatPtr(100)}, "Taxi": {Limit: floatPtr(10)}}
+		config.CategoryOverrides = map[string]*CategoryOverride{overridden: {Group: "Food:Groceries"}}
+		config.TransactionSplits = map[string]*TransactionSplit{split: {Parts: []SplitPart{
+			{Group: "Food", Percent: floatPtr(50)}, {Group: "Taxi"},
+		}}}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	err = dataHandler.RenameGroup("Food", "Meals")
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := readConfig(dataHandler.ConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, config := range []*Config{config, dataHandler.GetSnapshot().Config} {
+		groups := make([]string, 0, len(config.Groups))
+		for name := range config.Groups {
+			groups = append(groups, name)
+		}
+		sort.Strings(groups)
+		budgets := make([]string, 0, len(config.Budgets))
+		for name := range config.Budgets {
+			budgets = append(budgets, name)
+		}
+		sort.Strings(budgets)
+		if strings.Join(groups, ",") != "Meals,Meals:Groceries" || strings.Join(budgets, ",") != "Meals,Taxi" {
+			t.Errorf("expected renamed groups and budgets, got %v and %v", groups, budgets)
+		}
+		if override := config.CategoryOverrides[overridden]; override == nil || override.Group != "Meals:Groceries" {
+			t.Errorf("expected override in 'Meals:Groceries', got %+v", override)
+		}
+		if parts := config.TransactionSplits[split].Parts; parts[0].Group != "Meals" || parts[1].Group != "Taxi" {
+			t.Errorf("expected split into 'Meals' and 'Taxi', got %+v", parts)
+		}
+	}
+
+	// Act - rename to existing group.
+	err = dataHandler.RenameGroup("Meals:Groceries", "Meals")
+
+	// Assert
+	checkErrorContainsSubstring(t, err, errGroupAlreadyExists.Error())
+}
+
+func TestDataHandler_RenameAndDeleteSalaryGroup(t *testing.T) {
+	// Arrange
+	dataHandler := newTestDataHandler(t)
+	err := dataHandler.updateConfig(func(config *Config) error {
+		config.Period = &PeriodConfig{Type: PeriodSalary, SalaryGroup: "Food"}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	err = dataHandler.RenameGroup("Food", "Salary")
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := readConfig(dataHandler.ConfigPath)
+	if err != nil {
+		t.Fatalf("renamed configuration can't be read: %v", err)
+	}
+	for _, config := range []*Config{config, dataHandler.GetSnapshot().Config} {
+		if config.Period.SalaryGroup != "Salary" {
+			t.Errorf("expected salary group 'Salary', got '%s'", config.Period.SalaryGroup)
+		}
+	}
+
+	// Act - delete salary group.
+	err = dataHandler.DeleteGroup("Salary")
+
+	// Assert
+	checkErrorContainsSubstring(t, err, errGroupIsSalaryGroup.Error())
+	if _, err := readConfig(dataHandler.ConfigPath); err != nil {
+		t.Errorf("configuration is broken by refused deletion: %v", err)
+	}
+	if _, ok := dataHandler.GetSnapshot().Config.Groups["Salary"]; !ok {
+		t.Errorf("salary group is deleted")
+	}
+}
+
+// TestUI_ConcurrentRequests is intended to be run with `-race` flag to catch data races between pages,
+// categorization changes and rebuilds.
+func TestUI_ConcurrentRequests(t *testing.T) {
+	// Arrange
+	initTemplateFunctions()
+	if err := initSharedTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	dataHandler := newTestDataHandler(t)
+	server := httptest.NewServer(newServeMux(dataHandler, NewEventsBroker()))
+	defer server.Close()
+	statistics, err := dataHandler.GetMonthlyStatistics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var transactionsURL string
+	for currency, stat := range statistics[0] {
+		for group := range stat.Expense {
+			month := i18n.T("date_format", "val", stat.Start)[:7]
+			query := url.Values{"month": {month}, "group": {group}, "type": {"expense"}, "currency": {currency}}
+			transactionsURL = "/transactions?" + query.Encode()
+			break
+		}
+	}
+	const rounds = 5
+	var waitGroup sync.WaitGroup
+	errors := make(chan string, 100)
+	request := func(method, path, body string) {
+		defer waitGroup.Done()
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		if err != nil {
+			errors <- err.Error()
+			return
+		}
+		response, err := server.Client().Do(req)
+		if err != nil {
+			errors <- err.Error()
+			return
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			errors <- fmt.Sprintf("%s %s returned %d", method, path, response.StatusCode)
+		}
+	}
+
+	// Act
+	for i := 0; i < rounds; i++ {
+		waitGroup.Add(9)
+		go request("GET", "/", "")
+		go request("GET", transactionsURL, "")
+		go request("GET", "/categorization", "")
+		go request("GET", "/groups", "")
+		go request("GET", "/files", "")
+		go request("GET", "/recurring", "")
+		go request("GET", "/balances", "")
+		go request("POST", "/refresh-files", "")
+		go request("POST", "/categorization", fmt.Sprintf(`{"action": "upsertGroup", "groupName": "Group %d", "substrings": ["SUBSTRING %d"]}`, i, i))
+	}
+	waitGroup.Wait()
+	close(errors)
+
+	// Assert
+	for err := range errors {
+		t.Error(err)
+	}
+	groups := dataHandler.GetSnapshot().Config.Groups
+	if len(groups) != rounds+1 {
+		t.Errorf("expected %d groups without lost updates, got %v", rounds+1, groups)
+	}
+}
+
+func TestBuildCoverageTimelines(t *testing.T) {
+	// Arrange
+	coverage := BuildCoverage([]FileInfo{
+		newTestFileInfo("jan", "acc", utcDate(2024, 1, 1), utcDate(2024, 1, 10), intPtr(0), intPtr(100)),
+		newTestFileInfo("feb", "acc", utcDate(2024, 1, 16), utcDate(2024, 1, 20), intPtr(100), intPtr(100)),
+	})
+
+	// Act
+	actual, from, to := buildCoverageTimelines(coverage)
+
+	// Assert
+	if !from.Equal(utcDate(2024, 1, 1)) || !to.Equal(utcDate(2024, 1, 20)) {
+		t.Errorf("expected timeline 2024-01-01..2024-01-20, got %v..%v", from, to)
+	}
+	expected := []string{"coverage-file 0.00 50.00", "coverage-file 75.00 25.00", "coverage-gap 50.00 25.00"}
+	segments := []string{}
+	for _, segment := range actual[0].Segments {
+		segments = append(segments, fmt.Sprintf("%s %s %s", segment.Class, segment.Left, segment.Width))
+	}
+	if strings.Join(segments, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("expected segments %v, got %v", expected, segments)
+	}
+}
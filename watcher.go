@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"log"
+	"maps"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// filesWatcherInterval is how often watched files are checked.
+	filesWatcherInterval = 1 * time.Second
+	// filesWatcherDebounce is how long files should stay unchanged before rebuild.
+	// Allows to copy a bunch of statements or finish writing of a big file before parsing.
+	filesWatcherDebounce = 2 * time.Second
+	// EventReload is a name of the event which asks pages to reload.
+	EventReload = "reload"
+)
+
+// watchedFileState is a state of file which is enough to find out that file was changed.
+type watchedFileState struct {
+	size    int64
+	modTime int64
+}
+
+// FilesWatcher polls files matching glob patterns and calls `onChange` when they are changed.
+// Polling is used because it works the same way on all OS-es and for files on network drives.
+// Bursts of changes are debounced: `onChange` is called only when files stay unchanged for `debounce` period.
+type FilesWatcher struct {
+	interval time.Duration
+	debounce time.Duration
+	// getPatterns returns glob patterns to watch. Called on each check because configuration may change.
+	getPatterns func() []string
+	// isOwnChange returns true if file got the state by write of the application itself, may be nil.
+	// Such changes don't call `onChange` because data is already updated.
+	isOwnChange func(file string, state watchedFileState) bool
+	onChange    func()
+}
+
+// NewFilesWatcher creates FilesWatcher with default interval and debounce period.
+func NewFilesWatcher(
+	getPatterns func() []string,
+	isOwnChange func(file string, state watchedFileState) bool,
+	onChange func(),
+) *FilesWatcher {
+	return &FilesWatcher{
+		interval:    filesWatcherInterval,
+		debounce:    filesWatcherDebounce,
+		getPatterns: getPatterns,
+		isOwnChange: isOwnChange,
+		onChange:    onChange,
+	}
+}
+
+// Run checks files until context is cancelled.
+func (w *FilesWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	snapshot := takeFilesSnapshot(w.getPatterns())
+	// reported is a snapshot when `onChange` was called last time, own changes are compared with it.
+	reported := snapshot
+	var changedAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			current := takeFilesSnapshot(w.getPatterns())
+			if !maps.Equal(current, snapshot) {
+				snapshot = current
+				changedAt = now
+				continue
+			}
+			if !changedAt.IsZero() && now.Sub(changedAt) >= w.debounce {
+				changedAt = time.Time{}
+				if w.hasForeignChanges(reported, current) {
+					w.onChange()
+				}
+				reported = current
+			}
+		}
+	}
+}
+
+// hasForeignChanges returns true if some file was changed not by the application itself.
+// Files are compared after debounce, so partially written files don't look like foreign changes.
+func (w *FilesWatcher) hasForeignChanges(before, after map[string]watchedFileState) bool {
+	for file := range before {
+		if _, ok := after[file]; !ok {
+			return true
+		}
+	}
+	for file, state := range after {
+		if before[file] == state {
+			continue
+		}
+		if w.isOwnChange == nil || !w.isOwnChange(file, state) {
+			return true
+		}
+	}
+	return false
+}
+
+// takeFilesSnapshot returns states of all files matching patterns.
+// Wrong patterns and not accessible files are skipped.
+func takeFilesSnapshot(patterns []string) map[string]watchedFileState {
+	snapshot := make(map[string]watchedFileState)
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		files, err := getFilesByGlob(pattern)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			info, err := os.Stat(file)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			snapshot[file] = watchedFileState{size: info.Size(), modTime: info.ModTime().UnixNano()}
+		}
+	}
+	return snapshot
+}
+
+// recordOwnWrite remembers state of the file just written by the application,
+// so files watcher doesn't rebuild data because of it.
+func (dh *DataHandler) recordOwnWrite(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	dh.ownWritesMutex.Lock()
+	defer dh.ownWritesMutex.Unlock()
+	if dh.ownWrites == nil {
+		dh.ownWrites = make(map[string]watchedFileState)
+	}
+	dh.ownWrites[absolutePath(path)] = watchedFileState{size: info.Size(), modTime: info.ModTime().UnixNano()}
+}
+
+// isOwnWrite returns true if the file has state which the application left after writing it.
+func (dh *DataHandler) isOwnWrite(file string, state watchedFileState) bool {
+	dh.ownWritesMutex.Lock()
+	defer dh.ownWritesMutex.Unlock()
+	written, ok := dh.ownWrites[absolutePath(file)]
+	return ok && written == state
+}
+
+// getWatchedPatterns returns glob patterns of all files which affect data: sources, inbox, configuration file
+// and category overrides file.
+func (dh *DataHandler) getWatchedPatterns() []string {
+	config := dh.GetSnapshot().Config
+	patterns := []string{dh.ConfigPath, getCategoryOverridesPath(dh.ConfigPath), config.InboxGlob}
+	for _, source := range config.GetSources() {
+		patterns = append(patterns, source.Glob)
+	}
+	return patterns
+}
+
+// EventsBroker delivers server events to subscribed clients (opened pages).
+type EventsBroker struct {
+	mutex   sync.Mutex
+	clients map[chan string]struct{}
+}
+
+// NewEventsBroker creates EventsBroker without clients.
+func NewEventsBroker() *EventsBroker {
+	return &EventsBroker{clients: make(map[chan string]struct{})}
+}
+
+// Subscribe returns channel to receive events from.
+func (b *EventsBroker) Subscribe() chan string {
+	// Buffer allows to don't lose event while client is busy with writing previous one.
+	events := make(chan string, 1)
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.clients[events] = struct{}{}
+	return events
+}
+
+// Unsubscribe stops sending events into the channel.
+func (b *EventsBroker) Unsubscribe(events chan string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.clients, events)
+}
+
+// Publish sends event to all clients. Doesn't block on slow clients, they just miss event
+// if they already have not handled event in the channel.
+func (b *EventsBroker) Publish(event string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for events := range b.clients {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// rebuildOnFilesChange rebuilds data from files and notifies pages about it.
+func rebuildOnFilesChange(dataHandler *DataHandler, broker *EventsBroker) {
+	log.Println(i18n.T("Files are changed, rebuilding data"))
+	if err := dataHandler.RebuildFromFiles(); err != nil {
+		log.Println(i18n.T("Can't rebuild data after files change", "err", err))
+		return
+	}
+	broker.Publish(EventReload)
+}
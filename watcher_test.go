@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFilesWatcher_DebouncesChanges(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "*.csv")
+	var calls atomic.Int32
+	watcher := &FilesWatcher{
+		interval:    10 * time.Millisecond,
+		debounce:    100 * time.Millisecond,
+		getPatterns: func() []string { return []string{pattern} },
+		onChange:    func() { calls.Add(1) },
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Run(ctx)
+	time.Sleep(30 * time.Millisecond)
+
+	// Act
+	for i := 0; i < 5; i++ {
+		file := filepath.Join(dir, "statement"+strings.Repeat("_", i)+".csv")
+		if err := os.WriteFile(file, []byte("content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	time.Sleep(300 * time.Millisecond)
+
+	// Assert
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 call for burst of changes, got %d", calls.Load())
+	}
+}
+
+func TestFilesWatcher_IgnoresOwnChanges(t *testing.T) {
+	tests := []struct {
+		name          string
+		isOwnWrite    bool
+		expectedCalls int32
+	}{
+		{"own_write", true, 0},
+		{"foreign_write", false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			file := filepath.Join(dir, "config.yaml")
+			if err := os.WriteFile(file, []byte("groups: {}"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			dataHandler := NewDataHandler(file, nil)
+			var calls atomic.Int32
+			watcher := &FilesWatcher{
+				interval:    10 * time.Millisecond,
+				debounce:    50 * time.Millisecond,
+				getPatterns: func() []string { return []string{file} },
+				isOwnChange: dataHandler.isOwnWrite,
+				onChange:    func() { calls.Add(1) },
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go watcher.Run(ctx)
+			time.Sleep(30 * time.Millisecond)
+
+			// Act
+			if err := os.WriteFile(file, []byte("groups: {a: {}}"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.isOwnWrite {
+				dataHandler.recordOwnWrite(file)
+			}
+			time.Sleep(200 * time.Millisecond)
+
+			// Assert
+			if calls.Load() != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, calls.Load())
+			}
+		})
+	}
+}
+
+func TestTakeFilesSnapshot(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	file := filepath.Join(dir, "statement.csv")
+	if err := os.WriteFile(file, []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "folder.csv"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	before := takeFilesSnapshot([]string{filepath.Join(dir, "*.csv"), "", "[wrong"})
+
+	// Act
+	if err := os.WriteFile(file, []byte("changed content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	after := takeFilesSnapshot([]string{filepath.Join(dir, "*.csv")})
+
+	// Assert
+	if len(before) != 1 || len(after) != 1 {
+		t.Errorf("expected only one file in snapshots, got %v and %v", before, after)
+	}
+	if before[file] == after[file] {
+		t.Errorf("snapshot doesn't reflect change of file: %v", after)
+	}
+}
+
+func TestHandleEvents(t *testing.T) {
+	// Arrange
+	broker := NewEventsBroker()
+	server := httptest.NewServer(handleEvents(broker))
+	defer server.Close()
+	response, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	reader := bufio.NewReader(response.Body)
+	// Wait for the first message which is sent after subscription.
+	if line, err := reader.ReadString('\n'); err != nil || !strings.HasPrefix(line, "retry:") {
+		t.Fatalf("unexpected first line %q: %v", line, err)
+	}
+
+	// Act
+	broker.Publish(EventReload)
+
+	// Assert
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("wrong content type %s", contentType)
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("event is not received: %v", err)
+		}
+		if line == "event: reload\n" {
+			break
+		}
+	}
+}
+
+func TestEventsBroker_Unsubscribe(t *testing.T) {
+	// Arrange
+	broker := NewEventsBroker()
+	subscribed := broker.Subscribe()
+	unsubscribed := broker.Subscribe()
+	broker.Unsubscribe(unsubscribed)
+
+	// Act
+	broker.Publish(EventReload)
+	broker.Publish(EventReload) // Should not block on full channel.
+
+	// Assert
+	if event := <-subscribed; event != EventReload {
+		t.Errorf("expected %s event, got %s", EventReload, event)
+	}
+	select {
+	case event := <-unsubscribed:
+		t.Errorf("unsubscribed channel got %s event", event)
+	default:
+	}
+}